@@ -0,0 +1,113 @@
+package pgx
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+)
+
+// AdvisoryLock is a session level advisory lock held by a connection. It is returned by Conn.AdvisoryLock and
+// Conn.TryAdvisoryLock. The lock is held until Unlock is called or the connection is closed.
+//
+// A *pgxpool.Conn that holds an advisory lock when it is released unlocks all advisory locks before it is returned to
+// the pool so the lock is not inherited by the next user of the connection.
+type AdvisoryLock struct {
+	conn     *Conn
+	key      int64
+	unlocked bool
+}
+
+// AdvisoryLockKey returns an advisory lock key for s. It allows locks to be named by strings. The key is the 64-bit
+// FNV-1a hash of s so it is stable across processes and versions, but it does not match keys computed on the server
+// with functions such as hashtext.
+func AdvisoryLockKey(s string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	return int64(h.Sum64())
+}
+
+// AdvisoryLock acquires the session level advisory lock key with pg_advisory_lock. It waits until the lock is available
+// or ctx is done. Advisory locks are reentrant: a lock acquired multiple times must be unlocked the same number of
+// times.
+func (c *Conn) AdvisoryLock(ctx context.Context, key int64) (*AdvisoryLock, error) {
+	_, err := c.Exec(ctx, "select pg_advisory_lock($1)", key)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.addAdvisoryLock(key), nil
+}
+
+// TryAdvisoryLock acquires the session level advisory lock key with pg_try_advisory_lock if it is available without
+// waiting. It returns nil and false if the lock is held by another session.
+func (c *Conn) TryAdvisoryLock(ctx context.Context, key int64) (*AdvisoryLock, bool, error) {
+	var acquired bool
+	err := c.QueryRow(ctx, "select pg_try_advisory_lock($1)", key).Scan(&acquired)
+	if err != nil {
+		return nil, false, err
+	}
+	if !acquired {
+		return nil, false, nil
+	}
+
+	return c.addAdvisoryLock(key), true, nil
+}
+
+// HoldsAdvisoryLocks returns true if c holds any advisory locks acquired with AdvisoryLock or TryAdvisoryLock that have
+// not been unlocked.
+func (c *Conn) HoldsAdvisoryLocks() bool {
+	return len(c.advisoryLocks) > 0
+}
+
+// UnlockAllAdvisoryLocks releases all session level advisory locks held by c with pg_advisory_unlock_all, including
+// locks that were not acquired with AdvisoryLock or TryAdvisoryLock. Unlock returns an error for the locks released
+// this way.
+func (c *Conn) UnlockAllAdvisoryLocks(ctx context.Context) error {
+	_, err := c.Exec(ctx, "select pg_advisory_unlock_all()")
+	if err != nil {
+		return err
+	}
+
+	c.advisoryLocks = nil
+	return nil
+}
+
+func (c *Conn) addAdvisoryLock(key int64) *AdvisoryLock {
+	if c.advisoryLocks == nil {
+		c.advisoryLocks = make(map[int64]int)
+	}
+	c.advisoryLocks[key]++
+
+	return &AdvisoryLock{conn: c, key: key}
+}
+
+// Key returns the key of the lock.
+func (l *AdvisoryLock) Key() int64 {
+	return l.key
+}
+
+// Unlock releases the lock with pg_advisory_unlock. It returns an error if the lock has already been unlocked.
+func (l *AdvisoryLock) Unlock(ctx context.Context) error {
+	if l.unlocked {
+		return fmt.Errorf("advisory lock %d is already unlocked", l.key)
+	}
+
+	var released bool
+	err := l.conn.QueryRow(ctx, "select pg_advisory_unlock($1)", l.key).Scan(&released)
+	if err != nil {
+		return err
+	}
+
+	l.unlocked = true
+	if n := l.conn.advisoryLocks[l.key]; n > 1 {
+		l.conn.advisoryLocks[l.key] = n - 1
+	} else {
+		delete(l.conn.advisoryLocks, l.key)
+	}
+
+	if !released {
+		return fmt.Errorf("advisory lock %d was not held by the session", l.key)
+	}
+
+	return nil
+}
@@ -0,0 +1,101 @@
+package pgx_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxtest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdvisoryLockKey(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, pgx.AdvisoryLockKey("foo"), pgx.AdvisoryLockKey("foo"))
+	require.NotEqual(t, pgx.AdvisoryLockKey("foo"), pgx.AdvisoryLockKey("bar"))
+}
+
+func TestConnAdvisoryLock(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	conn1 := mustConnectString(t, os.Getenv("PGX_TEST_DATABASE"))
+	defer closeConn(t, conn1)
+	conn2 := mustConnectString(t, os.Getenv("PGX_TEST_DATABASE"))
+	defer closeConn(t, conn2)
+
+	pgxtest.SkipCockroachDB(t, conn1, "Server does not support advisory locks")
+
+	key := pgx.AdvisoryLockKey(t.Name())
+
+	lock, err := conn1.AdvisoryLock(ctx, key)
+	require.NoError(t, err)
+	require.Equal(t, key, lock.Key())
+	require.True(t, conn1.HoldsAdvisoryLocks())
+
+	_, acquired, err := conn2.TryAdvisoryLock(ctx, key)
+	require.NoError(t, err)
+	require.False(t, acquired)
+	require.False(t, conn2.HoldsAdvisoryLocks())
+
+	require.NoError(t, lock.Unlock(ctx))
+	require.False(t, conn1.HoldsAdvisoryLocks())
+	require.Error(t, lock.Unlock(ctx))
+
+	lock2, acquired, err := conn2.TryAdvisoryLock(ctx, key)
+	require.NoError(t, err)
+	require.True(t, acquired)
+	require.NoError(t, lock2.Unlock(ctx))
+}
+
+func TestConnAdvisoryLockReentrant(t *testing.T) {
+	t.Parallel()
+
+	defaultConnTestRunner.RunTest(context.Background(), t, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		pgxtest.SkipCockroachDB(t, conn, "Server does not support advisory locks")
+
+		key := pgx.AdvisoryLockKey(t.Name())
+
+		lock1, err := conn.AdvisoryLock(ctx, key)
+		require.NoError(t, err)
+		lock2, err := conn.AdvisoryLock(ctx, key)
+		require.NoError(t, err)
+
+		require.NoError(t, lock1.Unlock(ctx))
+		require.True(t, conn.HoldsAdvisoryLocks())
+		require.NoError(t, lock2.Unlock(ctx))
+		require.False(t, conn.HoldsAdvisoryLocks())
+	})
+}
+
+func TestConnUnlockAllAdvisoryLocks(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	conn1 := mustConnectString(t, os.Getenv("PGX_TEST_DATABASE"))
+	defer closeConn(t, conn1)
+	conn2 := mustConnectString(t, os.Getenv("PGX_TEST_DATABASE"))
+	defer closeConn(t, conn2)
+
+	pgxtest.SkipCockroachDB(t, conn1, "Server does not support advisory locks")
+
+	key := pgx.AdvisoryLockKey(t.Name())
+
+	lock, err := conn1.AdvisoryLock(ctx, key)
+	require.NoError(t, err)
+	_, err = conn1.AdvisoryLock(ctx, key)
+	require.NoError(t, err)
+
+	require.NoError(t, conn1.UnlockAllAdvisoryLocks(ctx))
+	require.False(t, conn1.HoldsAdvisoryLocks())
+	require.Error(t, lock.Unlock(ctx))
+
+	lock2, acquired, err := conn2.TryAdvisoryLock(ctx, key)
+	require.NoError(t, err)
+	require.True(t, acquired)
+	require.NoError(t, lock2.Unlock(ctx))
+}
@@ -2,10 +2,17 @@ package pgx
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+	"unicode"
 
 	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 // QueuedQuery is a query that has been queued for execution via a Batch.
@@ -14,11 +21,42 @@ type QueuedQuery struct {
 	arguments []any
 	fn        batchItemFunc
 	sd        *pgconn.StatementDescription
+
+	execParams *queuedExecParams // set when queued with Batch.QueueExecParams
+	copyFrom   *queuedCopyFrom   // set when queued with Batch.QueueCopyFrom
+	maxRows    uint32            // set when queued with Batch.QueueLimited
+	tag        any               // set when queued with Batch.QueueTagged
+	timeout    time.Duration     // set by WithTimeout
+}
+
+// queuedExecParams holds the already encoded parameters of a query queued with Batch.QueueExecParams.
+type queuedExecParams struct {
+	paramValues   [][]byte
+	paramOIDs     []uint32
+	paramFormats  []int16
+	resultFormats []int16
 }
 
 type batchItemFunc func(br BatchResults) error
 
-// Query sets fn to be called when the response to qq is received.
+// SQL returns the SQL of qq. It may have been modified by a QueryRewriter when the batch was sent.
+func (qq *QueuedQuery) SQL() string {
+	return qq.query
+}
+
+// Tag returns the tag qq was queued with by Batch.QueueTagged. It returns nil if qq was not queued with QueueTagged.
+func (qq *QueuedQuery) Tag() any {
+	return qq.tag
+}
+
+// Arguments returns the arguments of qq. They may have been modified by a QueryRewriter when the batch was sent.
+func (qq *QueuedQuery) Arguments() []any {
+	return qq.arguments
+}
+
+// Query sets fn to be called when the response to qq is received. BatchResults.Close calls the callback functions of
+// the queries whose results have not been read in queue order, so the results of a batch can be handled without
+// reading them in the order they were queued.
 func (qq *QueuedQuery) Query(fn func(rows Rows) error) {
 	qq.fn = func(br BatchResults) error {
 		rows, err := br.Query()
@@ -37,7 +75,7 @@ func (qq *QueuedQuery) Query(fn func(rows Rows) error) {
 	}
 }
 
-// Query sets fn to be called when the response to qq is received.
+// QueryRow sets fn to be called when the response to qq is received.
 func (qq *QueuedQuery) QueryRow(fn func(row Row) error) {
 	qq.fn = func(br BatchResults) error {
 		row := br.QueryRow()
@@ -57,27 +95,335 @@ func (qq *QueuedQuery) Exec(fn func(ct pgconn.CommandTag) error) {
 	}
 }
 
+// SendBatchOptions control how a Batch is sent and how its results are read.
+type SendBatchOptions struct {
+	// FailFast causes the first error encountered while reading results to abort the batch. The remaining results are
+	// immediately drained and all subsequent reads, including Close, return that error. This is useful for all or
+	// nothing batches where the remaining results would only report that the transaction was aborted.
+	FailFast bool
+
+	// RejectTypedNilArgs causes SendBatch to fail with an error naming the item and argument if any argument is a typed
+	// nil such as a nil *int, []byte, or map. By default typed nils are sent as NULL exactly like an untyped nil. An
+	// untyped nil is always sent as NULL. This detects bugs such as an unset pointer or a nil slice that was meant to be
+	// an empty array.
+	RejectTypedNilArgs bool
+
+	// Buffered causes SendBatch to read the results of every query into memory before returning. This allows them to be
+	// read in any order with BatchExecAt and BatchQueryAt, and the connection is available for use as
+	// soon as SendBatch returns. The tradeoff is that all rows of all queries are held in memory at the same time, so it
+	// should not be used for batches that return large result sets.
+	Buffered bool
+
+	// ReadBufferSize is the minimum size of the buffer used to read the results of the batch. A larger buffer can reduce
+	// the number of reads needed for batches that return a lot of data at the cost of memory. The connection's previous
+	// buffer size is restored when the results are closed. If ReadBufferSize <= 0 the connection's buffer size is used.
+	ReadBufferSize int
+
+	// OnFlush is called each time queries of the batch have been flushed to the server with the number of queries
+	// flushed so far. It can be used to observe the progress of sending a large batch. It is called before the results
+	// are read. All queries of a batch are currently flushed at once, so it is called a single time with the number of
+	// queued queries unless the batch is sent in chunks with MaxChunkQueries or MaxChunkBytes. Then it is called once
+	// per chunk. With IsolateErrors it is called again each time the queries after a failed query are sent again.
+	OnFlush func(itemsFlushed int)
+
+	// AutoRegisterTypes causes SendBatch to load and register the data types of result columns that are not registered
+	// in the connection's type map before the queries are sent, so their results can be decoded and scanned like those
+	// of registered types. Array, composite, domain, enum, and range types are supported, along with any unregistered
+	// types they depend on. Other types are left unregistered and are read in the text format as usual.
+	//
+	// Loading the types costs extra round trips, but only when a batch returns a type that is not yet registered. It is
+	// only supported with the query exec modes that describe statements before they are executed,
+	// QueryExecModeCacheStatement, QueryExecModeCacheDescribe, and QueryExecModeDescribeExec. It is ignored otherwise.
+	AutoRegisterTypes bool
+
+	// MaxChunkQueries and MaxChunkBytes split a large batch into chunks that are sent in separate round trips. A chunk
+	// ends before the query that would make it contain more than MaxChunkQueries queries or more than MaxChunkBytes
+	// bytes. The size of a query is approximated by the size of its SQL plus the size of its string and []byte
+	// arguments. A value <= 0 disables the corresponding limit.
+	//
+	// The results of a chunked batch are always read into memory as with Buffered. Each chunk runs in its own implicit
+	// transaction, so unless the batch is sent inside an explicit transaction the chunks before a failed chunk are
+	// committed. If a chunk fails the remaining chunks are not sent and the queries in them fail with an error that
+	// wraps the error of the failed chunk. BatchFailedItems returns the failed and unsent queries so they can be
+	// sent again.
+	MaxChunkQueries int
+	MaxChunkBytes   int
+
+	// IsolateErrors wraps each query in a savepoint so the failure of one query does not prevent the rest of the batch
+	// from running. The error of a failed query is returned when its results are read and its changes are rolled back,
+	// but the results of the other queries are not affected. This is useful for bulk loads that tolerate the failure of
+	// individual rows.
+	//
+	// Savepoints require a transaction block. If the batch is not sent inside a transaction it is sent inside a new one
+	// that is committed once all queries have run. Each failure costs an extra round trip to roll back to the savepoint
+	// and to send the queries after the failed one again. The results are always read into memory as with Buffered and
+	// MaxChunkQueries and MaxChunkBytes are ignored. Close only returns an error if the batch as a whole failed, e.g.
+	// because the transaction could not be committed. Transaction control statements must not be queued in the batch.
+	IsolateErrors bool
+
+	// Tracer is used to trace the batch instead of the BatchTracer of the connection. This allows individual batches,
+	// such as those of a high volume code path, to be traced differently. Set it to NoopBatchTracer{} to not trace the
+	// batch. If Tracer is nil the connection's tracer is used.
+	Tracer BatchTracer
+
+	// Tx causes the batch to run in an explicit transaction with the given options. The statement that begins the
+	// transaction is sent before the first query and the statement that commits it after the last query, so no extra
+	// round trips are needed. If a query fails the transaction is rolled back when the results are closed, so the
+	// connection is never left in a failed transaction. An error committing the transaction, such as a serialization
+	// failure, is returned by Close.
+	//
+	// A batch that is sent in chunks with MaxChunkQueries or MaxChunkBytes or with IsolateErrors runs in a single
+	// transaction that is committed after the last chunk. The batch must not be sent while the connection is in a
+	// transaction and transaction control statements must not be queued in the batch.
+	Tx *TxOptions
+}
+
+var errBatchTxInTransaction = errors.New("SendBatchOptions.Tx cannot be used while the connection is in a transaction")
+
 // Batch queries are a way of bundling multiple queries together to avoid
 // unnecessary network round trips. A Batch must only be sent once.
 type Batch struct {
-	queuedQueries []*QueuedQuery
+	queuedQueries   []*QueuedQuery
+	applicationName *string
+	readOnly        bool
+	frozen          bool
+	queriesFlushed  int // the number of queries flushed to the server the last time the batch was sent
+
+	// Options control how the batch is sent and read.
+	Options SendBatchOptions
 }
 
-// Queue queues a query to batch b. query can be an SQL query or the name of a prepared statement.
+// Queue queues a query to batch b. query can be an SQL query or the name of a prepared statement. If the first argument
+// is a QueryRewriter such as NamedArgs the query and arguments are rewritten when the batch is sent, as with Conn.Query.
 func (b *Batch) Queue(query string, arguments ...any) *QueuedQuery {
-	qq := &QueuedQuery{
-		query:     query,
-		arguments: arguments,
+	b.checkNotFrozen()
+
+	qq := b.appendQueuedQuery()
+	qq.query = query
+	qq.arguments = arguments
+	return qq
+}
+
+// QueueTagged queues a query to batch b like Queue and attaches tag to it. tag is not sent to the server. It is returned
+// by BatchCurrentTag while the results of the query are being read. This allows correlating a result with the
+// application value that produced it, such as the domain object being saved, without tracking the position of the query
+// in the batch.
+func (b *Batch) QueueTagged(tag any, query string, arguments ...any) *QueuedQuery {
+	qq := b.Queue(query, arguments...)
+	qq.tag = tag
+	return qq
+}
+
+// QueueLimited queues a query to batch b like Queue but the server returns at most maxRows rows for it. The limit is
+// applied with the row limit of the protocol level Execute message so the SQL is not modified. If the limit is reached
+// the remaining rows are discarded by the server and the command tag of the result is empty. maxRows must be greater
+// than 0. QueueLimited cannot be used with QueryExecModeSimpleProtocol.
+func (b *Batch) QueueLimited(query string, maxRows int, arguments ...any) *QueuedQuery {
+	if maxRows <= 0 || int64(maxRows) > math.MaxUint32 {
+		panic(fmt.Sprintf("QueueLimited: maxRows must be between 1 and %d, got %d", uint32(math.MaxUint32), maxRows))
+	}
+
+	qq := b.Queue(query, arguments...)
+	qq.maxRows = uint32(maxRows)
+	return qq
+}
+
+// QueueExecParams queues a query to batch b that is sent exactly as specified. The arguments have the same meaning as
+// for pgconn.PgConn.ExecParams. No argument encoding, type inference, prepared statement lookup, or statement caching
+// is done for the query. QueueExecParams cannot be used with QueryExecModeSimpleProtocol.
+func (b *Batch) QueueExecParams(sql string, paramValues [][]byte, paramOIDs []uint32, paramFormats, resultFormats []int16) *QueuedQuery {
+	b.checkNotFrozen()
+
+	qq := b.appendQueuedQuery()
+	qq.query = sql
+	qq.execParams = &queuedExecParams{
+		paramValues:   paramValues,
+		paramOIDs:     paramOIDs,
+		paramFormats:  paramFormats,
+		resultFormats: resultFormats,
+	}
+	return qq
+}
+
+// appendQueuedQuery appends an empty QueuedQuery to b and returns it. A QueuedQuery retained by Reset is reused if
+// there is one.
+func (b *Batch) appendQueuedQuery() *QueuedQuery {
+	n := len(b.queuedQueries)
+	if n < cap(b.queuedQueries) {
+		if qq := b.queuedQueries[:n+1][n]; qq != nil {
+			b.queuedQueries = b.queuedQueries[:n+1]
+			return qq
+		}
 	}
+
+	qq := &QueuedQuery{}
 	b.queuedQueries = append(b.queuedQueries, qq)
 	return qq
 }
 
+// Reset removes all queued queries from b and clears its options and settings so it can be used to build a new batch.
+// The memory used by the queued queries is retained and reused by subsequent calls to Queue. This allows a Batch to be
+// kept in a sync.Pool so that high throughput applications do not allocate a new Batch for each round trip.
+//
+// b must not be reset until the results of sending it have been closed. The *QueuedQuery values returned before the
+// reset are reused so they must not be used afterwards. Reset panics if b is frozen.
+func (b *Batch) Reset() {
+	b.checkNotFrozen()
+
+	for _, qq := range b.queuedQueries {
+		*qq = QueuedQuery{} // release the arguments and callback functions for garbage collection
+	}
+	b.queuedQueries = b.queuedQueries[:0]
+	b.applicationName = nil
+	b.readOnly = false
+	b.queriesFlushed = 0
+	b.Options = SendBatchOptions{}
+}
+
+// SetApplicationName causes application_name to be set to name while the batch runs so its queries can be identified
+// in pg_stat_activity and the server logs. The previous application_name is restored after the last query.
+//
+// This depends on the batch running atomically. The batch is run in an implicit transaction unless it contains
+// transaction control statements. If a query fails the implicit transaction is rolled back, which also reverts
+// application_name. If the batch itself contains statements such as commit or rollback application_name may not be
+// restored.
+func (b *Batch) SetApplicationName(name string) {
+	b.checkNotFrozen()
+	b.applicationName = &name
+}
+
+// SetReadOnly causes the batch to run in a read only transaction if readOnly is true. This is enforced in two layers:
+//
+//   - Before the batch is sent each query is classified with the same heuristic as Classify. If any query is known to
+//     write SendBatch fails without sending anything.
+//   - The first statement sent sets transaction_read_only for the current transaction like SET TRANSACTION READ ONLY.
+//     Any query that attempts to write fails on the server with SQLSTATE 25006 (read_only_sql_transaction).
+//
+// The server side enforcement depends on the batch running atomically. It lasts until the end of the implicit
+// transaction of the batch, so queries after a commit or rollback statement in the batch are not read only. If the
+// batch is sent while the connection is in a transaction the rest of that transaction is read only.
+func (b *Batch) SetReadOnly(readOnly bool) {
+	b.checkNotFrozen()
+	b.readOnly = readOnly
+}
+
+// Freeze prevents further structural changes to b and returns b. Methods that queue queries or change how the batch
+// runs, such as Queue and SetApplicationName, panic when called on a frozen batch. This allows a built batch to be
+// handed off or cached without it being accidentally modified. Use Clone to get a copy that can be modified. Options is
+// an exported field so it is not protected.
+//
+// A frozen batch can still be sent. Sending does not add or remove queued queries but it does apply any QueryRewriter,
+// such as NamedArgs, to the queued queries, so a frozen batch must not be inspected concurrently with being sent.
+func (b *Batch) Freeze() *Batch {
+	b.frozen = true
+	return b
+}
+
+// Frozen returns true if Freeze has been called on b.
+func (b *Batch) Frozen() bool {
+	return b.frozen
+}
+
+func (b *Batch) checkNotFrozen() {
+	if b.frozen {
+		panic("pgx: cannot modify a frozen Batch")
+	}
+}
+
+// QueuedQueries returns a copy of the queries queued so far. Sending the batch does not modify the returned queries.
+func (b *Batch) QueuedQueries() []QueuedQuery {
+	qqs := make([]QueuedQuery, len(b.queuedQueries))
+	for i, qq := range b.queuedQueries {
+		qqs[i] = *qq
+	}
+	return qqs
+}
+
+// Clone returns a copy of b that can be sent independently of b. The queued queries, including any callback functions,
+// and the options are copied. The copy is not frozen even if b is. The arguments of the queries are not deep copied.
+// Sending a batch may modify its queued queries so Clone must be called before b is sent.
+func (b *Batch) Clone() *Batch {
+	clone := &Batch{
+		queuedQueries:   make([]*QueuedQuery, len(b.queuedQueries)),
+		applicationName: b.applicationName,
+		readOnly:        b.readOnly,
+		Options:         b.Options,
+	}
+	for i, qq := range b.queuedQueries {
+		qqCopy := *qq
+		clone.queuedQueries[i] = &qqCopy
+	}
+	return clone
+}
+
+// AppendBatch appends copies of the queries queued in other, including any callback functions and tags, to b. This
+// allows separate components to build their own batches and a coordinator to send them in a single round trip. The
+// options and settings of other such as SetApplicationName and SetReadOnly are not copied; those of b apply to all
+// queries. other is not modified and can be frozen. As with Clone, AppendBatch must be called before other is sent.
+// AppendBatch panics if b is frozen.
+func (b *Batch) AppendBatch(other *Batch) {
+	b.checkNotFrozen()
+
+	for _, qq := range other.queuedQueries {
+		qqCopy := b.appendQueuedQuery()
+		*qqCopy = *qq
+		qqCopy.sd = nil
+	}
+}
+
+// notifyFlush records that itemsFlushed queries of b have been flushed to the server and calls the OnFlush option of b
+// if it is set.
+func (b *Batch) notifyFlush(itemsFlushed int) {
+	b.queriesFlushed = itemsFlushed
+	if b.Options.OnFlush != nil {
+		b.Options.OnFlush(itemsFlushed)
+	}
+}
+
+// copyItems returns a new batch with the options of b and copies of the queries of b starting at index from. The
+// copies are not bound to a prepared statement description so they can be sent again.
+func (b *Batch) copyItems(from int) *Batch {
+	copied := &Batch{
+		applicationName: b.applicationName,
+		readOnly:        b.readOnly,
+		Options:         b.Options,
+	}
+	for _, qq := range b.queuedQueries[from:] {
+		qqCopy := *qq
+		qqCopy.sd = nil
+		copied.queuedQueries = append(copied.queuedQueries, &qqCopy)
+	}
+	return copied
+}
+
+var _ io.WriterTo = (*Batch)(nil)
+
+// WriteTo writes the SQL of each query queued in b to w as a script. Each query is terminated by a semicolon and a
+// newline. Trailing whitespace and semicolons are removed from the SQL first. Arguments are not inlined, so placeholders
+// such as $1 are written as is. The SQL is written as it was queued. e.g. a query queued by prepared statement name is
+// written as the name and the rewriting done by NamedArgs happens only when the batch is sent.
+func (b *Batch) WriteTo(w io.Writer) (int64, error) {
+	var total int64
+	for _, qq := range b.queuedQueries {
+		sql := strings.TrimRightFunc(qq.query, func(r rune) bool {
+			return r == ';' || unicode.IsSpace(r)
+		})
+		n, err := io.WriteString(w, sql+";\n")
+		total += int64(n)
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
 // Len returns number of queries that have been queued so far.
 func (b *Batch) Len() int {
 	return len(b.queuedQueries)
 }
 
+var _ io.Closer = (BatchResults)(nil)
+
 type BatchResults interface {
 	// Exec reads the results from the next query in the batch as if the query has been sent with Conn.Exec. Prefer
 	// calling Exec on the QueuedQuery.
@@ -100,10 +446,175 @@ type BatchResults interface {
 	// connection will have been closed.
 	//
 	// Close is safe to call multiple times. If it returns an error subsequent calls will return the same error. Callback
-	// functions will not be rerun.
+	// functions will not be rerun. This holds regardless of whether the results were fully read, partially read, or
+	// reading them failed. BatchResults therefore satisfies io.Closer and can be used with generic cleanup code.
 	Close() error
 }
 
+// batchResultsAs returns the first BatchResults that implements T in the chain of br. The chain consists of br followed
+// by the results returned by repeatedly calling Unwrap() BatchResults. Wrappers, such as those returned by a
+// ConnConfig.BatchResultsMiddleware, implement Unwrap so functions such as BatchAll can read the results they wrap.
+func batchResultsAs[T any](br BatchResults) (T, bool) {
+	for {
+		if r, ok := br.(T); ok {
+			return r, true
+		}
+		u, ok := br.(interface{ Unwrap() BatchResults })
+		if !ok {
+			var zero T
+			return zero, false
+		}
+		br = u.Unwrap()
+	}
+}
+
+// errBatchResultsUnsupported returns the error for calling name with br if no BatchResults in the chain of br
+// implements it.
+func errBatchResultsUnsupported(br BatchResults, name string) error {
+	return fmt.Errorf("%s: %T does not support %s", name, br, name)
+}
+
+// BatchExecSelect reads the results from the next query in br like Exec and returns the number of rows reported by its
+// command tag. The rows are discarded without being decoded. It is cheaper than scanning the rows when only the count
+// is needed. It returns an error if the command tag is not that of a SELECT, e.g. if the query was queued with
+// Batch.QueueLimited and the limit was reached.
+func BatchExecSelect(br BatchResults) (int64, error) {
+	if r, ok := batchResultsAs[interface{ ExecSelect() (int64, error) }](br); ok {
+		return r.ExecSelect()
+	}
+	return execSelect(br)
+}
+
+// BatchInTransaction reports whether the connection was inside a transaction block when the batch of br was sent. This
+// includes a transaction block that has already failed. It does not consider transaction control statements queued in
+// the batch itself.
+func BatchInTransaction(br BatchResults) (bool, error) {
+	if r, ok := batchResultsAs[interface{ InTransaction() bool }](br); ok {
+		return r.InTransaction(), nil
+	}
+	return false, errBatchResultsUnsupported(br, "InTransaction")
+}
+
+// VerifyBatchComplete returns an error if the number of results read so far from br is not exactly the number of
+// queries queued in the batch. i.e. if too few results have been read or if more results were requested than were
+// queued. Results read by Close count as read.
+func VerifyBatchComplete(br BatchResults) error {
+	if r, ok := batchResultsAs[interface{ VerifyComplete() error }](br); ok {
+		return r.VerifyComplete()
+	}
+	return errBatchResultsUnsupported(br, "VerifyComplete")
+}
+
+// BatchDiscardNext reads and discards the results from the next query in br. Unlike Exec the query is not traced. Use
+// it for queries whose results are intentionally thrown away.
+func BatchDiscardNext(br BatchResults) error {
+	if r, ok := batchResultsAs[interface{ DiscardNext() error }](br); ok {
+		return r.DiscardNext()
+	}
+	_, err := br.Exec()
+	return err
+}
+
+// BatchSkip reads and discards the results of the next n queries in br like BatchDiscardNext. This is useful for setup
+// statements, such as SET LOCAL, whose results are of no interest. Callback functions of the skipped queries are not
+// called. The results of all n queries are discarded even if one of them failed and the first error is returned.
+func BatchSkip(br BatchResults, n int) error {
+	if r, ok := batchResultsAs[interface{ Skip(n int) error }](br); ok {
+		return r.Skip(n)
+	}
+	return skipBatchResults(br, n)
+}
+
+// BatchAllFieldDescriptions returns the field descriptions of every result read so far from br that returns rows, keyed
+// by the index of the query in the batch. The returned map must not be modified.
+func BatchAllFieldDescriptions(br BatchResults) (map[int][]pgconn.FieldDescription, error) {
+	if r, ok := batchResultsAs[interface {
+		AllFieldDescriptions() map[int][]pgconn.FieldDescription
+	}](br); ok {
+		return r.AllFieldDescriptions(), nil
+	}
+	return nil, errBatchResultsUnsupported(br, "AllFieldDescriptions")
+}
+
+// BatchExecAt returns the results of the query at index in the batch of br as if the query has been sent with
+// Conn.Exec. It does not change which query Exec, Query, and QueryRow read next. It requires the batch to have been
+// sent with SendBatchOptions.Buffered.
+func BatchExecAt(br BatchResults, index int) (pgconn.CommandTag, error) {
+	if r, ok := batchResultsAs[interface {
+		ExecAt(index int) (pgconn.CommandTag, error)
+	}](br); ok {
+		return r.ExecAt(index)
+	}
+	return pgconn.CommandTag{}, errBatchResultsUnsupported(br, "ExecAt")
+}
+
+// BatchQueryAt returns the results of the query at index in the batch of br as if the query has been sent with
+// Conn.Query. It does not change which query Exec, Query, and QueryRow read next. It requires the batch to have been
+// sent with SendBatchOptions.Buffered.
+func BatchQueryAt(br BatchResults, index int) (Rows, error) {
+	if r, ok := batchResultsAs[interface{ QueryAt(index int) (Rows, error) }](br); ok {
+		return r.QueryAt(index)
+	}
+	err := errBatchResultsUnsupported(br, "QueryAt")
+	return &baseRows{err: err, closed: true}, err
+}
+
+// BatchSnapshots returns a snapshot of the result of every query in the batch of br in queue order. The snapshots do
+// not depend on the connection or on br and can be read concurrently, e.g. by handing each to a different goroutine.
+// It does not change which query Exec, Query, and QueryRow read next. It requires the batch to have been sent with
+// SendBatchOptions.Buffered.
+func BatchSnapshots(br BatchResults) ([]BatchResultSnapshot, error) {
+	if r, ok := batchResultsAs[interface {
+		Snapshots() ([]BatchResultSnapshot, error)
+	}](br); ok {
+		return r.Snapshots()
+	}
+	return nil, errBatchResultsUnsupported(br, "Snapshots")
+}
+
+// BatchItemNotices returns the notices, such as those raised by RAISE NOTICE or RAISE WARNING in PL/pgSQL, that were
+// received while reading the results of the query at index in the batch of br. Notices are only attributed to a query
+// once its results have been read, so the results of the query at index should be read before calling
+// BatchItemNotices. Notices are still passed to the notice handler of the connection.
+func BatchItemNotices(br BatchResults, index int) ([]pgconn.Notice, error) {
+	if r, ok := batchResultsAs[interface {
+		ItemNotices(index int) []pgconn.Notice
+	}](br); ok {
+		return r.ItemNotices(index), nil
+	}
+	return nil, errBatchResultsUnsupported(br, "ItemNotices")
+}
+
+// BatchFailedItems returns a new Batch with the options of the batch of br and copies of the queries whose results
+// were an error in the order they were queued. Queries that were not run because an earlier query failed are included.
+// It should be called after the results have been read, e.g. after Close. The copies use the SQL and arguments that
+// were sent, i.e. after any QueryRewriter such as NamedArgs was applied.
+//
+// A batch runs in an implicit transaction unless it contains transaction control statements. In that case a failure
+// also rolls back the queries that succeeded before it, so resending only the failed queries is only correct if the
+// queries are committed independently.
+func BatchFailedItems(br BatchResults) (*Batch, error) {
+	if r, ok := batchResultsAs[interface{ FailedItems() *Batch }](br); ok {
+		return r.FailedItems(), nil
+	}
+	return nil, errBatchResultsUnsupported(br, "FailedItems")
+}
+
+// BatchCurrentTag returns the tag of the query whose results were most recently read from br with Exec, Query,
+// QueryRow, or BatchDiscardNext. It returns nil if no results have been read or the query was not queued with
+// Batch.QueueTagged.
+func BatchCurrentTag(br BatchResults) (any, error) {
+	if r, ok := batchResultsAs[interface{ CurrentTag() any }](br); ok {
+		return r.CurrentTag(), nil
+	}
+	return nil, errBatchResultsUnsupported(br, "CurrentTag")
+}
+
+// BatchResultsMiddleware wraps the BatchResults returned by SendBatch. See ConnConfig.BatchResultsMiddleware.
+type BatchResultsMiddleware func(br BatchResults) BatchResults
+
+var errBatchNotBuffered = errors.New("batch was not sent with SendBatchOptions.Buffered")
+
 type batchResults struct {
 	ctx       context.Context
 	conn      *Conn
@@ -113,6 +624,38 @@ type batchResults struct {
 	qqIdx     int
 	closed    bool
 	endTraced bool
+	inTx      bool
+
+	batchTracer BatchTracer // the tracer of the batch. See SendBatchOptions.Tracer.
+
+	extraReads int // number of reads attempted after all queued queries were read
+
+	// prevReadBufferSize is the read buffer size to restore on Close if it was changed by SendBatchOptions.ReadBufferSize.
+	prevReadBufferSize int
+
+	inFlight bool // Close clears Conn.batchInFlight
+
+	collectingNotices bool
+	prevNoticeHandler pgconn.NoticeHandler // notice handler to restore on Close when collectingNotices is true
+	noticeHandler     pgconn.NoticeHandler // created once so it can be reused with the results
+	notices           map[int][]pgconn.Notice
+
+	fieldDescriptions map[int][]pgconn.FieldDescription
+
+	reusableRows *baseRows // set when the results are stored in a ReusableBatchResults
+
+	flushTime        time.Time // when the batch was flushed. Only set when the batch is traced.
+	firstByteLatency time.Duration
+
+	statsRecorder batchStatsRecorder
+
+	rollbackTx bool // roll back the transaction begun for SendBatchOptions.Tx if it is still open when closed
+
+	failed      bool // a query failed. Queries after a failure are not read so only the first failure is recorded.
+	failedIdx   int
+	unsent      *Batch // set if the batch failed before it was sent
+	lastRows    *baseRows
+	lastRowsIdx int
 }
 
 // Exec reads the results from the next query in the batch as if the query has been sent with Exec.
@@ -126,36 +669,83 @@ func (br *batchResults) Exec() (pgconn.CommandTag, error) {
 
 	query, arguments, _ := br.nextQueryAndArgs()
 
-	if !br.mrr.NextResult() {
-		err := br.mrr.Close()
-		if err == nil {
-			err = errors.New("no result")
-		}
-		if br.conn.batchTracer != nil {
-			br.conn.batchTracer.TraceBatchQuery(br.ctx, br.conn, TraceBatchQueryData{
-				SQL:  query,
-				Args: arguments,
-				Err:  err,
-			})
-		}
-		return pgconn.CommandTag{}, err
+	return br.drainResult(query, arguments)
+}
+
+// DiscardNext reads and discards the results from the next query in the batch without tracing it.
+func (br *batchResults) DiscardNext() error {
+	if br.err != nil {
+		return br.err
+	}
+	if br.closed {
+		return fmt.Errorf("batch already closed")
 	}
 
-	commandTag, err := br.mrr.ResultReader().Close()
-	br.err = err
+	br.nextQueryAndArgs()
+
+	_, err := br.closeNextResult()
+	return err
+}
+
+// Skip reads and discards the results from the next n queries in the batch.
+func (br *batchResults) Skip(n int) error {
+	return skipBatchResults(br, n)
+}
 
-	if br.conn.batchTracer != nil {
-		br.conn.batchTracer.TraceBatchQuery(br.ctx, br.conn, TraceBatchQueryData{
+// drainResult reads and discards the next result. It is used for results that will not be returned as Rows.
+func (br *batchResults) drainResult(query string, arguments []any) (pgconn.CommandTag, error) {
+	commandTag, err := br.closeNextResult()
+
+	if br.batchTracer != nil {
+		br.batchTracer.TraceBatchQuery(br.ctx, br.conn, TraceBatchQueryData{
 			SQL:        query,
 			Args:       arguments,
 			CommandTag: commandTag,
-			Err:        br.err,
+			Err:        err,
 		})
 	}
 
+	return commandTag, err
+}
+
+// closeNextResult reads the next result to completion.
+func (br *batchResults) closeNextResult() (pgconn.CommandTag, error) {
+	hasResult := br.mrr.NextResult()
+	br.recordFirstByteLatency()
+	if !hasResult {
+		err := br.mrr.Close()
+		if err == nil {
+			err = errors.New("no result")
+		}
+		err = br.wrapItemErr(err)
+		br.recordItemErr(err)
+		br.abortIfFailFast(err)
+		return pgconn.CommandTag{}, err
+	}
+
+	rr := br.mrr.ResultReader()
+	br.recordFieldDescriptions(rr.FieldDescriptions())
+	commandTag, err := rr.Close()
+	br.err = br.wrapItemErr(err)
+	br.recordItemErr(br.err)
+	br.abortIfFailFast(br.err)
+
 	return commandTag, br.err
 }
 
+// abortIfFailFast closes br with err if err is not nil and the batch was sent with the FailFast option.
+func (br *batchResults) abortIfFailFast(err error) {
+	if err == nil || br.closed || br.b == nil || !br.b.Options.FailFast {
+		return
+	}
+
+	if br.err == nil {
+		br.err = err
+	}
+	br.closed = true
+	br.mrr.Close()
+}
+
 // Query reads the results from the next query in the batch as if the query has been sent with Query.
 func (br *batchResults) Query() (Rows, error) {
 	query, arguments, ok := br.nextQueryAndArgs()
@@ -172,18 +762,29 @@ func (br *batchResults) Query() (Rows, error) {
 		return &baseRows{err: alreadyClosedErr, closed: true}, alreadyClosedErr
 	}
 
-	rows := br.conn.getRows(br.ctx, query, arguments)
-	rows.batchTracer = br.conn.batchTracer
+	if rows, ok := br.interceptQuery(query, arguments); ok {
+		return rows, rows.Err()
+	}
+
+	rows := br.getRows(query, arguments)
+	rows.batchTracer = br.batchTracer
+	br.lastRows = rows
+	br.lastRowsIdx = br.qqIdx - 1
 
-	if !br.mrr.NextResult() {
+	hasResult := br.mrr.NextResult()
+	br.recordFirstByteLatency()
+	if !hasResult {
 		rows.err = br.mrr.Close()
 		if rows.err == nil {
 			rows.err = errors.New("no result")
 		}
+		rows.err = br.wrapItemErr(rows.err)
 		rows.closed = true
+		br.recordItemErr(rows.err)
+		br.abortIfFailFast(rows.err)
 
-		if br.conn.batchTracer != nil {
-			br.conn.batchTracer.TraceBatchQuery(br.ctx, br.conn, TraceBatchQueryData{
+		if br.batchTracer != nil {
+			br.batchTracer.TraceBatchQuery(br.ctx, br.conn, TraceBatchQueryData{
 				SQL:  query,
 				Args: arguments,
 				Err:  rows.err,
@@ -194,29 +795,71 @@ func (br *batchResults) Query() (Rows, error) {
 	}
 
 	rows.resultReader = br.mrr.ResultReader()
+	rows.batchItemIdx = br.qqIdx
+	br.recordFieldDescriptions(rows.resultReader.FieldDescriptions())
 	return rows, nil
 }
 
+// interceptQuery consults the connection's BatchQueryInterceptor, if any. If the interceptor serves the query the real
+// result is drained to keep the connection in sync with the server.
+func (br *batchResults) interceptQuery(query string, arguments []any) (Rows, bool) {
+	if br.conn.batchQueryInterceptor == nil {
+		return nil, false
+	}
+
+	rows, ok := br.conn.batchQueryInterceptor.InterceptQuery(br.ctx, query, arguments)
+	if !ok {
+		return nil, false
+	}
+
+	_, err := br.drainResult(query, arguments)
+	if err != nil {
+		rows.Close()
+		return &baseRows{err: err, closed: true}, true
+	}
+
+	return rows, true
+}
+
 // QueryRow reads the results from the next query in the batch as if the query has been sent with QueryRow.
 func (br *batchResults) QueryRow() Row {
 	rows, _ := br.Query()
-	return (*connRow)(rows.(*baseRows))
-
+	return rowsToRow(rows)
 }
 
 // Close closes the batch operation. Any error that occurred during a batch operation may have made it impossible to
 // resyncronize the connection with the server. In this case the underlying connection will have been closed.
 func (br *batchResults) Close() error {
 	defer func() {
+		br.rollbackOpenTx()
 		if !br.endTraced {
-			if br.conn != nil && br.conn.batchTracer != nil {
-				br.conn.batchTracer.TraceBatchEnd(br.ctx, br.conn, TraceBatchEndData{Err: br.err})
+			if br.batchTracer != nil {
+				br.batchTracer.TraceBatchEnd(br.ctx, br.conn, TraceBatchEndData{Err: br.err, FirstByteLatency: br.firstByteLatency})
 			}
 			br.endTraced = true
 		}
+		if br.prevReadBufferSize > 0 {
+			br.conn.pgConn.Frontend().SetReadBufferSize(br.prevReadBufferSize)
+			br.prevReadBufferSize = 0
+		}
+		if br.collectingNotices {
+			br.conn.pgConn.SetNoticeHandler(br.prevNoticeHandler)
+			br.collectingNotices = false
+		}
+		if br.inFlight {
+			br.conn.batchInFlight = false
+			br.inFlight = false
+		}
+		br.statsRecorder.finish()
 	}()
 
 	if br.err != nil {
+		if !br.closed {
+			br.closed = true
+			if br.mrr != nil {
+				br.mrr.Close()
+			}
+		}
 		return br.err
 	}
 
@@ -246,17 +889,229 @@ func (br *batchResults) Close() error {
 	return br.err
 }
 
+// InTransaction reports whether the connection was inside a transaction block when the batch was sent.
+func (br *batchResults) InTransaction() bool {
+	return br.inTx
+}
+
 func (br *batchResults) earlyError() error {
 	return br.err
 }
 
+// skipItemTimeoutResults reads and discards the results of restoring statement_timeout after the previous query and
+// of setting it for the next query if they were queued with QueuedQuery.WithTimeout. If an earlier query failed the
+// results are left to be discarded by Close so the error of the failed query is kept.
+func (br *batchResults) skipItemTimeoutResults() {
+	if br.qqIdx > 1 && br.b.queuedQueries[br.qqIdx-2].timeout > 0 && br.err == nil && !br.closed {
+		br.skipResult()
+	}
+	if br.b.queuedQueries[br.qqIdx-1].timeout > 0 && br.err == nil && !br.closed {
+		br.skipResult()
+	}
+}
+
+// skipResult reads and discards a result that does not correspond to a queued query.
+func (br *batchResults) skipResult() {
+	_, err := br.closeNextResult()
+	if err != nil && br.err == nil {
+		br.err = err
+	}
+}
+
+func (br *batchResults) setInTransaction(inTx bool) {
+	br.inTx = inTx
+}
+
+func (br *batchResults) restoreReadBufferSizeOnClose(n int) {
+	br.prevReadBufferSize = n
+}
+
+func (br *batchResults) clearInFlightOnClose() {
+	br.inFlight = true
+}
+
+func (br *batchResults) rollbackTxOnClose() {
+	br.rollbackTx = true
+}
+
+// rollbackOpenTx rolls back the transaction begun for SendBatchOptions.Tx if a failure left it open.
+func (br *batchResults) rollbackOpenTx() {
+	if !br.rollbackTx {
+		return
+	}
+	br.rollbackTx = false
+
+	if br.conn.pgConn.TxStatus() != 'I' {
+		// The batch context may be what failed the batch, so it cannot be used to send the rollback.
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_, err := br.conn.pgConn.Exec(ctx, "rollback").ReadAll()
+		if err != nil && br.err == nil {
+			br.err = err
+		}
+	}
+}
+
+func (br *batchResults) setFlushTime(t time.Time) {
+	br.flushTime = t
+}
+
+func (br *batchResults) setBatchTracer(t BatchTracer) {
+	br.batchTracer = t
+}
+
+// recordFirstByteLatency records the time from flushing the batch until the first result was received.
+func (br *batchResults) recordFirstByteLatency() {
+	if !br.flushTime.IsZero() && br.firstByteLatency == 0 {
+		br.firstByteLatency = time.Since(br.flushTime)
+	}
+}
+
+// collectNotices attributes the notices received on the connection to the query whose results are being read until
+// br is closed.
+func (br *batchResults) collectNotices() {
+	if br.noticeHandler == nil {
+		br.noticeHandler = func(pgConn *pgconn.PgConn, n *pgconn.Notice) {
+			if idx := br.qqIdx - 1; idx >= 0 && br.extraReads == 0 {
+				if br.notices == nil {
+					br.notices = make(map[int][]pgconn.Notice)
+				}
+				br.notices[idx] = append(br.notices[idx], *n)
+			}
+			if br.prevNoticeHandler != nil {
+				br.prevNoticeHandler(pgConn, n)
+			}
+		}
+	}
+	br.collectingNotices = true
+	br.prevNoticeHandler = br.conn.pgConn.SetNoticeHandler(br.noticeHandler)
+}
+
+// ItemNotices returns the notices received while reading the results of the query at index.
+func (br *batchResults) ItemNotices(index int) []pgconn.Notice {
+	return br.notices[index]
+}
+
+// recordItemErr records err as the error of the query whose results are being read if it is the first failure.
+func (br *batchResults) recordItemErr(err error) {
+	if err == nil || br.failed || br.b == nil || br.qqIdx == 0 || br.extraReads > 0 {
+		return
+	}
+	br.failed = true
+	br.failedIdx = br.qqIdx - 1
+}
+
+// wrapItemErr identifies the query whose results are being read in err if it was reported by the server.
+func (br *batchResults) wrapItemErr(err error) error {
+	if err == nil || br.b == nil || br.qqIdx == 0 || br.extraReads > 0 {
+		return err
+	}
+	idx := br.qqIdx - 1
+	qq := br.b.queuedQueries[idx]
+	return wrapBatchItemErr(idx, qq.query, qq.arguments, err)
+}
+
+func (br *batchResults) setUnsentBatch(b *Batch) {
+	br.unsent = b
+}
+
+// FailedItems returns a new batch with copies of the queries that failed or were not run because of a failure.
+func (br *batchResults) FailedItems() *Batch {
+	if br.unsent != nil {
+		return br.unsent.copyItems(0)
+	}
+	if br.b == nil {
+		return &Batch{}
+	}
+
+	failed, failedIdx := br.failed, br.failedIdx
+	if br.lastRows != nil && br.lastRows.err != nil && (!failed || br.lastRowsIdx < failedIdx) {
+		failed, failedIdx = true, br.lastRowsIdx
+	}
+	if !failed {
+		return br.b.copyItems(len(br.b.queuedQueries))
+	}
+	return br.b.copyItems(failedIdx)
+}
+
+// getRows returns the rows used to read the results of the next query.
+func (br *batchResults) getRows(query string, arguments []any) *baseRows {
+	if br.reusableRows != nil {
+		return br.conn.resetRows(br.reusableRows, br.ctx, query, arguments)
+	}
+	return br.conn.getRows(br.ctx, query, arguments)
+}
+
+// Snapshots is not supported because the results were not buffered.
+func (br *batchResults) Snapshots() ([]BatchResultSnapshot, error) {
+	return nil, errBatchNotBuffered
+}
+
+// ExecAt is not supported because the results were not buffered.
+func (br *batchResults) ExecAt(index int) (pgconn.CommandTag, error) {
+	return pgconn.CommandTag{}, errBatchNotBuffered
+}
+
+// QueryAt is not supported because the results were not buffered.
+func (br *batchResults) QueryAt(index int) (Rows, error) {
+	return &baseRows{err: errBatchNotBuffered, closed: true}, errBatchNotBuffered
+}
+
+// AllFieldDescriptions returns the field descriptions of every result read so far that returns rows.
+func (br *batchResults) AllFieldDescriptions() map[int][]pgconn.FieldDescription {
+	return br.fieldDescriptions
+}
+
+// recordFieldDescriptions records fds as the field descriptions of the most recently read query.
+func (br *batchResults) recordFieldDescriptions(fds []pgconn.FieldDescription) {
+	if len(fds) == 0 || br.qqIdx == 0 || br.extraReads > 0 {
+		return
+	}
+
+	if br.fieldDescriptions == nil {
+		br.fieldDescriptions = make(map[int][]pgconn.FieldDescription)
+	}
+	// pgconn reuses the memory of fds for subsequent results.
+	br.fieldDescriptions[br.qqIdx-1] = append([]pgconn.FieldDescription(nil), fds...)
+}
+
+// AsRows returns a Rows that reads the rows of every remaining query in the batch in sequence.
+func (br *batchResults) AsRows() Rows {
+	return newBatchRows(br, br.b, br.qqIdx)
+}
+
+// QueryProject reads the results from the next query in the batch like Query but only exposes the columns named cols.
+func (br *batchResults) QueryProject(cols []string) (Rows, error) {
+	return queryProject(br, cols)
+}
+
+// ExecSelect reads the results from the next query in the batch and returns the number of rows it selected.
+func (br *batchResults) ExecSelect() (int64, error) {
+	return execSelect(br)
+}
+
+// CurrentTag returns the tag of the query whose results were most recently read.
+func (br *batchResults) CurrentTag() any {
+	return currentBatchTag(br.b, br.qqIdx)
+}
+
+// VerifyComplete returns an error if the number of results read is not the number of queries queued in the batch.
+func (br *batchResults) VerifyComplete() error {
+	return verifyBatchComplete(br.b, br.qqIdx, br.extraReads)
+}
+
 func (br *batchResults) nextQueryAndArgs() (query string, args []any, ok bool) {
-	if br.b != nil && br.qqIdx < len(br.b.queuedQueries) {
-		bi := br.b.queuedQueries[br.qqIdx]
-		query = bi.query
-		args = bi.arguments
-		ok = true
-		br.qqIdx++
+	if br.b != nil {
+		if br.qqIdx < len(br.b.queuedQueries) {
+			bi := br.b.queuedQueries[br.qqIdx]
+			query = bi.query
+			args = bi.arguments
+			ok = true
+			br.qqIdx++
+			br.skipItemTimeoutResults()
+		} else {
+			br.extraReads++
+		}
 	}
 	return
 }
@@ -271,6 +1126,39 @@ type pipelineBatchResults struct {
 	qqIdx     int
 	closed    bool
 	endTraced bool
+	inTx      bool
+
+	batchTracer BatchTracer // the tracer of the batch. See SendBatchOptions.Tracer.
+
+	extraReads int // number of reads attempted after all queued queries were read
+
+	// prevReadBufferSize is the read buffer size to restore on Close if it was changed by SendBatchOptions.ReadBufferSize.
+	prevReadBufferSize int
+
+	inFlight bool // Close clears Conn.batchInFlight
+
+	collectingNotices bool
+	prevNoticeHandler pgconn.NoticeHandler // notice handler to restore on Close when collectingNotices is true
+	noticeHandler     pgconn.NoticeHandler // created once so it can be reused with the results
+	notices           map[int][]pgconn.Notice
+
+	fieldDescriptions map[int][]pgconn.FieldDescription
+
+	reusableRows *baseRows // set when the results are stored in a ReusableBatchResults
+
+	flushTime        time.Time // when the batch was flushed. Only set when the batch is traced.
+	firstByteLatency time.Duration
+
+	statsRecorder batchStatsRecorder
+
+	rollbackTx bool // roll back the transaction begun for SendBatchOptions.Tx if it is still open when closed
+
+	failed      bool // a query failed. Queries after a failure are not read so only the first failure is recorded.
+	failedIdx   int
+	unsent      *Batch // set if the batch failed before it was sent
+	lastRowsIdx int
+
+	maxPipelineDepth int // the largest number of queries sent but not yet synced
 }
 
 // Exec reads the results from the next query in the batch as if the query has been sent with Exec.
@@ -282,34 +1170,94 @@ func (br *pipelineBatchResults) Exec() (pgconn.CommandTag, error) {
 		return pgconn.CommandTag{}, fmt.Errorf("batch already closed")
 	}
 	if br.lastRows != nil && br.lastRows.err != nil {
+		br.abortIfFailFast(br.lastRows.err)
 		return pgconn.CommandTag{}, br.err
 	}
 
 	query, arguments, _ := br.nextQueryAndArgs()
 
+	return br.drainResult(query, arguments)
+}
+
+// Skip reads and discards the results from the next n queries in the batch.
+func (br *pipelineBatchResults) Skip(n int) error {
+	return skipBatchResults(br, n)
+}
+
+// DiscardNext reads and discards the results from the next query in the batch without tracing it.
+func (br *pipelineBatchResults) DiscardNext() error {
+	if br.err != nil {
+		return br.err
+	}
+	if br.closed {
+		return fmt.Errorf("batch already closed")
+	}
+	if br.lastRows != nil && br.lastRows.err != nil {
+		br.abortIfFailFast(br.lastRows.err)
+		return br.lastRows.err
+	}
+
+	br.nextQueryAndArgs()
+
+	_, err := br.closeNextResult()
+	return err
+}
+
+// drainResult reads and discards the next result. It is used for results that will not be returned as Rows.
+func (br *pipelineBatchResults) drainResult(query string, arguments []any) (pgconn.CommandTag, error) {
+	commandTag, err := br.closeNextResult()
+
+	if br.batchTracer != nil {
+		br.batchTracer.TraceBatchQuery(br.ctx, br.conn, TraceBatchQueryData{
+			SQL:        query,
+			Args:       arguments,
+			CommandTag: commandTag,
+			Err:        err,
+		})
+	}
+
+	return commandTag, err
+}
+
+// closeNextResult reads the next result to completion.
+func (br *pipelineBatchResults) closeNextResult() (pgconn.CommandTag, error) {
 	results, err := br.pipeline.GetResults()
+	br.recordFirstByteLatency()
 	if err != nil {
-		br.err = err
-		return pgconn.CommandTag{}, err
+		br.err = br.wrapItemErr(err)
+		br.recordItemErr(br.err)
+		br.abortIfFailFast(br.err)
+		return pgconn.CommandTag{}, br.err
 	}
 	var commandTag pgconn.CommandTag
 	switch results := results.(type) {
 	case *pgconn.ResultReader:
-		commandTag, br.err = results.Close()
+		br.recordFieldDescriptions(results.FieldDescriptions())
+		commandTag, err = results.Close()
+		br.err = br.wrapItemErr(err)
+		br.recordItemErr(br.err)
+		br.abortIfFailFast(br.err)
 	default:
-		return pgconn.CommandTag{}, fmt.Errorf("unexpected pipeline result: %T", results)
+		err = fmt.Errorf("unexpected pipeline result: %T", results)
+		br.recordItemErr(err)
+		br.abortIfFailFast(err)
+		return pgconn.CommandTag{}, err
 	}
 
-	if br.conn.batchTracer != nil {
-		br.conn.batchTracer.TraceBatchQuery(br.ctx, br.conn, TraceBatchQueryData{
-			SQL:        query,
-			Args:       arguments,
-			CommandTag: commandTag,
-			Err:        br.err,
-		})
+	return commandTag, br.err
+}
+
+// abortIfFailFast closes br with err if err is not nil and the batch was sent with the FailFast option.
+func (br *pipelineBatchResults) abortIfFailFast(err error) {
+	if err == nil || br.closed || br.b == nil || !br.b.Options.FailFast {
+		return
 	}
 
-	return commandTag, err
+	if br.err == nil {
+		br.err = err
+	}
+	br.closed = true
+	br.pipeline.Close()
 }
 
 // Query reads the results from the next query in the batch as if the query has been sent with Query.
@@ -325,6 +1273,7 @@ func (br *pipelineBatchResults) Query() (Rows, error) {
 
 	if br.lastRows != nil && br.lastRows.err != nil {
 		br.err = br.lastRows.err
+		br.abortIfFailFast(br.err)
 		return &baseRows{err: br.err, closed: true}, br.err
 	}
 
@@ -333,18 +1282,24 @@ func (br *pipelineBatchResults) Query() (Rows, error) {
 		query = "batch query"
 	}
 
-	rows := br.conn.getRows(br.ctx, query, arguments)
-	rows.batchTracer = br.conn.batchTracer
+	if rows, ok := br.interceptQuery(query, arguments); ok {
+		return rows, rows.Err()
+	}
+
+	rows := br.getRows(query, arguments)
+	rows.batchTracer = br.batchTracer
 	br.lastRows = rows
 
 	results, err := br.pipeline.GetResults()
+	br.recordFirstByteLatency()
 	if err != nil {
+		err = br.wrapItemErr(err)
 		br.err = err
 		rows.err = err
 		rows.closed = true
 
-		if br.conn.batchTracer != nil {
-			br.conn.batchTracer.TraceBatchQuery(br.ctx, br.conn, TraceBatchQueryData{
+		if br.batchTracer != nil {
+			br.batchTracer.TraceBatchQuery(br.ctx, br.conn, TraceBatchQueryData{
 				SQL:  query,
 				Args: arguments,
 				Err:  err,
@@ -354,6 +1309,8 @@ func (br *pipelineBatchResults) Query() (Rows, error) {
 		switch results := results.(type) {
 		case *pgconn.ResultReader:
 			rows.resultReader = results
+			rows.batchItemIdx = br.qqIdx
+			br.recordFieldDescriptions(results.FieldDescriptions())
 		default:
 			err = fmt.Errorf("unexpected pipeline result: %T", results)
 			br.err = err
@@ -361,35 +1318,76 @@ func (br *pipelineBatchResults) Query() (Rows, error) {
 			rows.closed = true
 		}
 	}
+	br.recordItemErr(rows.err)
+	br.abortIfFailFast(rows.err)
 
 	return rows, rows.err
 }
 
+// interceptQuery consults the connection's BatchQueryInterceptor, if any. If the interceptor serves the query the real
+// result is drained to keep the connection in sync with the server.
+func (br *pipelineBatchResults) interceptQuery(query string, arguments []any) (Rows, bool) {
+	if br.conn.batchQueryInterceptor == nil {
+		return nil, false
+	}
+
+	rows, ok := br.conn.batchQueryInterceptor.InterceptQuery(br.ctx, query, arguments)
+	if !ok {
+		return nil, false
+	}
+
+	_, err := br.drainResult(query, arguments)
+	if err != nil {
+		rows.Close()
+		return &baseRows{err: err, closed: true}, true
+	}
+
+	return rows, true
+}
+
 // QueryRow reads the results from the next query in the batch as if the query has been sent with QueryRow.
 func (br *pipelineBatchResults) QueryRow() Row {
 	rows, _ := br.Query()
-	return (*connRow)(rows.(*baseRows))
-
+	return rowsToRow(rows)
 }
 
 // Close closes the batch operation. Any error that occurred during a batch operation may have made it impossible to
 // resyncronize the connection with the server. In this case the underlying connection will have been closed.
 func (br *pipelineBatchResults) Close() error {
 	defer func() {
+		br.rollbackOpenTx()
 		if !br.endTraced {
-			if br.conn.batchTracer != nil {
-				br.conn.batchTracer.TraceBatchEnd(br.ctx, br.conn, TraceBatchEndData{Err: br.err})
+			if br.batchTracer != nil {
+				br.batchTracer.TraceBatchEnd(br.ctx, br.conn, TraceBatchEndData{Err: br.err, FirstByteLatency: br.firstByteLatency, MaxPipelineDepth: br.maxPipelineDepth})
 			}
 			br.endTraced = true
 		}
+		if br.prevReadBufferSize > 0 {
+			br.conn.pgConn.Frontend().SetReadBufferSize(br.prevReadBufferSize)
+			br.prevReadBufferSize = 0
+		}
+		if br.collectingNotices {
+			br.conn.pgConn.SetNoticeHandler(br.prevNoticeHandler)
+			br.collectingNotices = false
+		}
+		if br.inFlight {
+			br.conn.batchInFlight = false
+			br.inFlight = false
+		}
+		br.statsRecorder.finish()
 	}()
 
-	if br.err != nil {
-		return br.err
+	if br.err == nil && br.lastRows != nil && br.lastRows.err != nil {
+		br.err = br.lastRows.err
 	}
 
-	if br.lastRows != nil && br.lastRows.err != nil {
-		br.err = br.lastRows.err
+	if br.err != nil {
+		if !br.closed {
+			br.closed = true
+			if br.pipeline != nil {
+				br.pipeline.Close()
+			}
+		}
 		return br.err
 	}
 
@@ -419,17 +1417,384 @@ func (br *pipelineBatchResults) Close() error {
 	return br.err
 }
 
+// InTransaction reports whether the connection was inside a transaction block when the batch was sent.
+func (br *pipelineBatchResults) InTransaction() bool {
+	return br.inTx
+}
+
 func (br *pipelineBatchResults) earlyError() error {
 	return br.err
 }
 
+// skipItemTimeoutResults reads and discards the results of restoring statement_timeout after the previous query and
+// of setting it for the next query if they were queued with QueuedQuery.WithTimeout. If an earlier query failed the
+// results are left to be discarded by Close so the error of the failed query is kept.
+func (br *pipelineBatchResults) skipItemTimeoutResults() {
+	if br.qqIdx > 1 && br.b.queuedQueries[br.qqIdx-2].timeout > 0 && br.err == nil && !br.closed {
+		br.skipResult()
+	}
+	if br.b.queuedQueries[br.qqIdx-1].timeout > 0 && br.err == nil && !br.closed {
+		br.skipResult()
+	}
+}
+
+// skipResult reads and discards a result that does not correspond to a queued query.
+func (br *pipelineBatchResults) skipResult() {
+	_, err := br.closeNextResult()
+	if err != nil && br.err == nil {
+		br.err = err
+	}
+}
+
+func (br *pipelineBatchResults) setInTransaction(inTx bool) {
+	br.inTx = inTx
+}
+
+func (br *pipelineBatchResults) restoreReadBufferSizeOnClose(n int) {
+	br.prevReadBufferSize = n
+}
+
+func (br *pipelineBatchResults) clearInFlightOnClose() {
+	br.inFlight = true
+}
+
+func (br *pipelineBatchResults) rollbackTxOnClose() {
+	br.rollbackTx = true
+}
+
+// rollbackOpenTx rolls back the transaction begun for SendBatchOptions.Tx if a failure left it open.
+func (br *pipelineBatchResults) rollbackOpenTx() {
+	if !br.rollbackTx {
+		return
+	}
+	br.rollbackTx = false
+
+	if br.conn.pgConn.TxStatus() != 'I' {
+		// The batch context may be what failed the batch, so it cannot be used to send the rollback.
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_, err := br.conn.pgConn.Exec(ctx, "rollback").ReadAll()
+		if err != nil && br.err == nil {
+			br.err = err
+		}
+	}
+}
+
+func (br *pipelineBatchResults) setFlushTime(t time.Time) {
+	br.flushTime = t
+}
+
+func (br *pipelineBatchResults) setBatchTracer(t BatchTracer) {
+	br.batchTracer = t
+}
+
+// recordFirstByteLatency records the time from flushing the batch until the first result was received.
+func (br *pipelineBatchResults) recordFirstByteLatency() {
+	if !br.flushTime.IsZero() && br.firstByteLatency == 0 {
+		br.firstByteLatency = time.Since(br.flushTime)
+	}
+}
+
+// collectNotices attributes the notices received on the connection to the query whose results are being read until
+// br is closed.
+func (br *pipelineBatchResults) collectNotices() {
+	if br.noticeHandler == nil {
+		br.noticeHandler = func(pgConn *pgconn.PgConn, n *pgconn.Notice) {
+			if idx := br.qqIdx - 1; idx >= 0 && br.extraReads == 0 {
+				if br.notices == nil {
+					br.notices = make(map[int][]pgconn.Notice)
+				}
+				br.notices[idx] = append(br.notices[idx], *n)
+			}
+			if br.prevNoticeHandler != nil {
+				br.prevNoticeHandler(pgConn, n)
+			}
+		}
+	}
+	br.collectingNotices = true
+	br.prevNoticeHandler = br.conn.pgConn.SetNoticeHandler(br.noticeHandler)
+}
+
+// ItemNotices returns the notices received while reading the results of the query at index.
+func (br *pipelineBatchResults) ItemNotices(index int) []pgconn.Notice {
+	return br.notices[index]
+}
+
+// recordItemErr records err as the error of the query whose results are being read if it is the first failure.
+func (br *pipelineBatchResults) recordItemErr(err error) {
+	if err == nil || br.failed || br.b == nil || br.qqIdx == 0 || br.extraReads > 0 {
+		return
+	}
+	br.failed = true
+	br.failedIdx = br.qqIdx - 1
+}
+
+// wrapItemErr identifies the query whose results are being read in err if it was reported by the server.
+func (br *pipelineBatchResults) wrapItemErr(err error) error {
+	if err == nil || br.b == nil || br.qqIdx == 0 || br.extraReads > 0 {
+		return err
+	}
+	idx := br.qqIdx - 1
+	qq := br.b.queuedQueries[idx]
+	return wrapBatchItemErr(idx, qq.query, qq.arguments, err)
+}
+
+func (br *pipelineBatchResults) setUnsentBatch(b *Batch) {
+	br.unsent = b
+}
+
+// FailedItems returns a new batch with copies of the queries that failed or were not run because of a failure.
+func (br *pipelineBatchResults) FailedItems() *Batch {
+	if br.unsent != nil {
+		return br.unsent.copyItems(0)
+	}
+	if br.b == nil {
+		return &Batch{}
+	}
+
+	failed, failedIdx := br.failed, br.failedIdx
+	if br.lastRows != nil && br.lastRows.err != nil && (!failed || br.lastRowsIdx < failedIdx) {
+		failed, failedIdx = true, br.lastRowsIdx
+	}
+	if !failed {
+		return br.b.copyItems(len(br.b.queuedQueries))
+	}
+	return br.b.copyItems(failedIdx)
+}
+
+// getRows returns the rows used to read the results of the next query.
+func (br *pipelineBatchResults) getRows(query string, arguments []any) *baseRows {
+	if br.reusableRows != nil {
+		return br.conn.resetRows(br.reusableRows, br.ctx, query, arguments)
+	}
+	return br.conn.getRows(br.ctx, query, arguments)
+}
+
+// Snapshots is not supported because the results were not buffered.
+func (br *pipelineBatchResults) Snapshots() ([]BatchResultSnapshot, error) {
+	return nil, errBatchNotBuffered
+}
+
+// ExecAt is not supported because the results were not buffered.
+func (br *pipelineBatchResults) ExecAt(index int) (pgconn.CommandTag, error) {
+	return pgconn.CommandTag{}, errBatchNotBuffered
+}
+
+// QueryAt is not supported because the results were not buffered.
+func (br *pipelineBatchResults) QueryAt(index int) (Rows, error) {
+	return &baseRows{err: errBatchNotBuffered, closed: true}, errBatchNotBuffered
+}
+
+// AllFieldDescriptions returns the field descriptions of every result read so far that returns rows.
+func (br *pipelineBatchResults) AllFieldDescriptions() map[int][]pgconn.FieldDescription {
+	return br.fieldDescriptions
+}
+
+// recordFieldDescriptions records fds as the field descriptions of the most recently read query.
+func (br *pipelineBatchResults) recordFieldDescriptions(fds []pgconn.FieldDescription) {
+	if len(fds) == 0 || br.qqIdx == 0 || br.extraReads > 0 {
+		return
+	}
+
+	if br.fieldDescriptions == nil {
+		br.fieldDescriptions = make(map[int][]pgconn.FieldDescription)
+	}
+	// pgconn reuses the memory of fds for subsequent results.
+	br.fieldDescriptions[br.qqIdx-1] = append([]pgconn.FieldDescription(nil), fds...)
+}
+
+// AsRows returns a Rows that reads the rows of every remaining query in the batch in sequence.
+func (br *pipelineBatchResults) AsRows() Rows {
+	return newBatchRows(br, br.b, br.qqIdx)
+}
+
+// QueryProject reads the results from the next query in the batch like Query but only exposes the columns named cols.
+func (br *pipelineBatchResults) QueryProject(cols []string) (Rows, error) {
+	return queryProject(br, cols)
+}
+
+// ExecSelect reads the results from the next query in the batch and returns the number of rows it selected.
+func (br *pipelineBatchResults) ExecSelect() (int64, error) {
+	return execSelect(br)
+}
+
+// CurrentTag returns the tag of the query whose results were most recently read.
+func (br *pipelineBatchResults) CurrentTag() any {
+	return currentBatchTag(br.b, br.qqIdx)
+}
+
+// VerifyComplete returns an error if the number of results read is not the number of queries queued in the batch.
+func (br *pipelineBatchResults) VerifyComplete() error {
+	return verifyBatchComplete(br.b, br.qqIdx, br.extraReads)
+}
+
 func (br *pipelineBatchResults) nextQueryAndArgs() (query string, args []any, ok bool) {
-	if br.b != nil && br.qqIdx < len(br.b.queuedQueries) {
-		bi := br.b.queuedQueries[br.qqIdx]
-		query = bi.query
-		args = bi.arguments
-		ok = true
-		br.qqIdx++
+	if br.b != nil {
+		if br.qqIdx < len(br.b.queuedQueries) {
+			bi := br.b.queuedQueries[br.qqIdx]
+			query = bi.query
+			args = bi.arguments
+			ok = true
+			br.qqIdx++
+			br.skipItemTimeoutResults()
+		} else {
+			br.extraReads++
+		}
 	}
 	return
 }
+
+// execSelect reads the results of the next query of br with Exec and returns the row count of its SELECT command tag.
+func execSelect(br BatchResults) (int64, error) {
+	commandTag, err := br.Exec()
+	if err != nil {
+		return 0, err
+	}
+	if !commandTag.Select() {
+		return 0, fmt.Errorf("ExecSelect: expected SELECT command tag, got %q", commandTag.String())
+	}
+	return commandTag.RowsAffected(), nil
+}
+
+// skipBatchResults calls BatchDiscardNext n times and returns the first error.
+func skipBatchResults(br BatchResults, n int) error {
+	var firstErr error
+	for i := 0; i < n; i++ {
+		if err := BatchDiscardNext(br); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// currentBatchTag returns the tag of the query of b that was read before the query at qqIdx.
+func currentBatchTag(b *Batch, qqIdx int) any {
+	if b == nil || qqIdx == 0 || qqIdx > len(b.queuedQueries) {
+		return nil
+	}
+	return b.queuedQueries[qqIdx-1].tag
+}
+
+func verifyBatchComplete(b *Batch, readCount, extraReads int) error {
+	if b == nil {
+		return nil
+	}
+	if extraReads > 0 {
+		return fmt.Errorf("batch has %d queued queries but %d results were requested", len(b.queuedQueries), readCount+extraReads)
+	}
+	if readCount != len(b.queuedQueries) {
+		return fmt.Errorf("batch has %d queued queries but only %d results were read", len(b.queuedQueries), readCount)
+	}
+	return nil
+}
+
+// BatchItemToSlice reads the results of the next query in the batch and decodes them into a []T. The query must
+// return exactly one row with a single json or jsonb column such as the result of json_agg. The JSON value must be an
+// array; it is unmarshaled with encoding/json. A NULL value results in a nil slice.
+func BatchItemToSlice[T any](br BatchResults) ([]T, error) {
+	rows, err := br.Query()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	fds := rows.FieldDescriptions()
+	if len(fds) != 1 {
+		return nil, fmt.Errorf("BatchItemToSlice: expected 1 column, got %d", len(fds))
+	}
+
+	if !rows.Next() {
+		if rows.Err() != nil {
+			return nil, rows.Err()
+		}
+		return nil, noRowsError(rows)
+	}
+
+	src := rows.RawValues()[0]
+	if fds[0].DataTypeOID == pgtype.JSONBOID && fds[0].Format == BinaryFormatCode && len(src) > 0 {
+		// The binary format of jsonb is a version byte followed by the JSON text.
+		if src[0] != 1 {
+			return nil, fmt.Errorf("BatchItemToSlice: unknown jsonb version number %d", src[0])
+		}
+		src = src[1:]
+	}
+
+	var s []T
+	if src != nil {
+		err = json.Unmarshal(src, &s)
+		if err != nil {
+			return nil, fmt.Errorf("BatchItemToSlice: %w", err)
+		}
+	}
+
+	if rows.Next() {
+		return nil, fmt.Errorf("BatchItemToSlice: expected 1 row, got more")
+	}
+
+	rows.Close()
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+
+	return s, nil
+}
+
+// CollectBatchReturning reads the results of the next n queries in the batch and returns the value of the column named
+// col from each. Each query must return exactly one row such as an INSERT ... RETURNING of a single row. This is
+// typically used to collect the generated keys of a batch of inserts in the order they were queued.
+func CollectBatchReturning[T any](br BatchResults, n int, col string) ([]T, error) {
+	values := make([]T, 0, n)
+
+	for i := 0; i < n; i++ {
+		value, err := collectBatchReturningValue[T](br, col)
+		if err != nil {
+			return nil, fmt.Errorf("CollectBatchReturning: query %d: %w", i, err)
+		}
+		values = append(values, value)
+	}
+
+	return values, nil
+}
+
+func collectBatchReturningValue[T any](br BatchResults, col string) (T, error) {
+	var value T
+
+	rows, err := br.Query()
+	if err != nil {
+		return value, err
+	}
+	defer rows.Close()
+
+	fds := rows.FieldDescriptions()
+	colIdx := -1
+	for i := range fds {
+		if fds[i].Name == col {
+			colIdx = i
+			break
+		}
+	}
+	if colIdx == -1 {
+		return value, fmt.Errorf("no column named %q", col)
+	}
+
+	if !rows.Next() {
+		if rows.Err() != nil {
+			return value, rows.Err()
+		}
+		return value, noRowsError(rows)
+	}
+
+	dest := make([]any, len(fds))
+	dest[colIdx] = &value
+	err = rows.Scan(dest...)
+	if err != nil {
+		return value, err
+	}
+
+	if rows.Next() {
+		return value, errors.New("returned more than one row")
+	}
+
+	rows.Close()
+	return value, rows.Err()
+}
@@ -0,0 +1,74 @@
+package pgx
+
+import (
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// BatchItemResult is the result of a single query of a batch as returned by BatchAll.
+type BatchItemResult struct {
+	// CommandTag is the command tag of the query. It is empty if the query failed.
+	CommandTag pgconn.CommandTag
+
+	// Rows are the rows returned by the query as collected by RowToMap. It is nil if the query does not return rows.
+	Rows []map[string]any
+
+	// Err is the error the query failed with, if any.
+	Err error
+}
+
+// BatchAll reads the results of every remaining query in br in queue order and then closes br. Each BatchItemResult
+// holds the command tag, the rows, and the error of one query. A query that was not run because an earlier query
+// failed has an error. The callback functions set with QueuedQuery.Query, QueuedQuery.QueryRow, and QueuedQuery.Exec
+// of the remaining queries are not called. The returned error is the error returned by Close.
+//
+// All rows are held in memory, so it is intended for queries that return few rows or none at all.
+func BatchAll(br BatchResults) ([]BatchItemResult, error) {
+	if r, ok := batchResultsAs[interface {
+		All() ([]BatchItemResult, error)
+	}](br); ok {
+		return r.All()
+	}
+	return nil, errBatchResultsUnsupported(br, "All")
+}
+
+// readAllBatchResults reads the results of the queries of b from qqIdx to the end with br.Query and then closes br.
+func readAllBatchResults(br BatchResults, b *Batch, qqIdx int) ([]BatchItemResult, error) {
+	var results []BatchItemResult
+	if b != nil && qqIdx < len(b.queuedQueries) {
+		results = make([]BatchItemResult, len(b.queuedQueries)-qqIdx)
+	}
+
+	for i := range results {
+		rows, err := br.Query()
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+
+		if len(rows.FieldDescriptions()) > 0 {
+			results[i].Rows, err = CollectRows(rows, RowToMap)
+		} else {
+			rows.Close()
+			err = rows.Err()
+		}
+		results[i].CommandTag = rows.CommandTag()
+		results[i].Err = err
+	}
+
+	return results, br.Close()
+}
+
+// All reads the results of all remaining queries in the batch and closes it.
+func (br *batchResults) All() ([]BatchItemResult, error) {
+	return readAllBatchResults(br, br.b, br.qqIdx)
+}
+
+// All reads the results of all remaining queries in the batch and closes it.
+func (br *pipelineBatchResults) All() ([]BatchItemResult, error) {
+	return readAllBatchResults(br, br.b, br.qqIdx)
+}
+
+// All reads the results of all remaining queries in the batch and closes it.
+func (br *bufferedBatchResults) All() ([]BatchItemResult, error) {
+	return readAllBatchResults(br, br.b, br.qqIdx)
+}
@@ -0,0 +1,66 @@
+package pgx_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxtest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnSendBatchAll(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		_, err := conn.Exec(ctx, "create temporary table batch_all (id int primary key)")
+		require.NoError(t, err)
+
+		batch := &pgx.Batch{}
+		batch.Queue("insert into batch_all (id) values (1), (2)")
+		batch.Queue("select id from batch_all order by id")
+		batch.Queue("select id from batch_all where id > 5")
+
+		results, err := pgx.BatchAll(conn.SendBatch(ctx, batch))
+		require.NoError(t, err)
+		require.Len(t, results, 3)
+
+		require.NoError(t, results[0].Err)
+		require.EqualValues(t, 2, results[0].CommandTag.RowsAffected())
+		require.Nil(t, results[0].Rows)
+
+		require.NoError(t, results[1].Err)
+		require.EqualValues(t, []map[string]any{{"id": int32(1)}, {"id": int32(2)}}, results[1].Rows)
+
+		require.NoError(t, results[2].Err)
+		require.Empty(t, results[2].Rows)
+
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestConnSendBatchAllError(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		batch := &pgx.Batch{}
+		batch.Queue("select 1")
+		batch.Queue("select 1/0")
+		batch.Queue("select 3")
+
+		br := conn.SendBatch(ctx, batch)
+		_, err := br.Exec()
+		require.NoError(t, err)
+
+		results, err := pgx.BatchAll(br)
+		var pgErr *pgconn.PgError
+		require.ErrorAs(t, err, &pgErr)
+		require.Len(t, results, 2)
+		require.ErrorAs(t, results[0].Err, &pgErr)
+		require.Equal(t, "22012", pgErr.Code)
+		require.Error(t, results[1].Err)
+
+		ensureConnValid(t, conn)
+	})
+}
@@ -0,0 +1,495 @@
+package pgx
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// bufferedResult is the fully read result of a single query in a batch.
+type bufferedResult struct {
+	fieldDescriptions []pgconn.FieldDescription
+	rows              [][][]byte
+	commandTag        pgconn.CommandTag
+	notices           []pgconn.Notice
+
+	// queryErr is set if the query failed before any rows could be read. rowsErr is set if it failed while reading
+	// rows.
+	queryErr error
+	rowsErr  error
+}
+
+func (r *bufferedResult) err() error {
+	if r.queryErr != nil {
+		return r.queryErr
+	}
+	return r.rowsErr
+}
+
+// bufferedBatchResults implements BatchResults for a batch sent with SendBatchOptions.Buffered.
+type bufferedBatchResults struct {
+	typeMap  *pgtype.Map
+	b        *Batch
+	results  []bufferedResult
+	closeErr error // error returned by closing the underlying results
+
+	qqIdx      int
+	extraReads int
+	err        error
+	closed     bool
+	inTx       bool
+
+	fieldDescriptions map[int][]pgconn.FieldDescription
+	notices           map[int][]pgconn.Notice
+
+	statsRecorder batchStatsRecorder
+}
+
+// bufferBatchResults reads all results from br into memory and closes it.
+func bufferBatchResults(c *Conn, b *Batch, br sentBatchResults) *bufferedBatchResults {
+	bbr := &bufferedBatchResults{
+		typeMap: c.typeMap,
+		b:       b,
+		results: make([]bufferedResult, len(b.queuedQueries)),
+	}
+
+	for i := range bbr.results {
+		result := &bbr.results[i]
+
+		rows, err := br.Query()
+		if err != nil {
+			result.queryErr = err
+			continue
+		}
+
+		result.fieldDescriptions = append([]pgconn.FieldDescription(nil), rows.FieldDescriptions()...)
+		for rows.Next() {
+			rawValues := rows.RawValues()
+			row := make([][]byte, len(rawValues))
+			for j, v := range rawValues {
+				if v != nil {
+					row[j] = append(make([]byte, 0, len(v)), v...)
+				}
+			}
+			result.rows = append(result.rows, row)
+		}
+		rows.Close()
+		result.commandTag = rows.CommandTag()
+		result.rowsErr = rows.Err()
+	}
+
+	bbr.fieldDescriptions = br.AllFieldDescriptions()
+	bbr.closeErr = br.Close()
+
+	for i := range bbr.results {
+		if notices := br.ItemNotices(i); notices != nil {
+			if bbr.notices == nil {
+				bbr.notices = make(map[int][]pgconn.Notice)
+			}
+			bbr.notices[i] = notices
+			bbr.results[i].notices = notices
+		}
+	}
+
+	return bbr
+}
+
+// Exec reads the results from the next query in the batch as if the query has been sent with Exec.
+func (br *bufferedBatchResults) Exec() (pgconn.CommandTag, error) {
+	if br.closed {
+		return pgconn.CommandTag{}, fmt.Errorf("batch already closed")
+	}
+
+	result, err := br.next()
+	if err != nil {
+		return pgconn.CommandTag{}, err
+	}
+	return result.commandTag, result.err()
+}
+
+// Query reads the results from the next query in the batch as if the query has been sent with Query.
+func (br *bufferedBatchResults) Query() (Rows, error) {
+	if br.closed {
+		alreadyClosedErr := fmt.Errorf("batch already closed")
+		return &baseRows{err: alreadyClosedErr, closed: true}, alreadyClosedErr
+	}
+
+	result, err := br.next()
+	if err != nil {
+		return &baseRows{err: err, closed: true}, err
+	}
+	return br.rows(result)
+}
+
+// QueryRow reads the results from the next query in the batch as if the query has been sent with QueryRow.
+func (br *bufferedBatchResults) QueryRow() Row {
+	rows, _ := br.Query()
+	return rowsToRow(rows)
+}
+
+// DiscardNext discards the results from the next query in the batch.
+func (br *bufferedBatchResults) DiscardNext() error {
+	if br.closed {
+		return fmt.Errorf("batch already closed")
+	}
+
+	result, err := br.next()
+	if err != nil {
+		return err
+	}
+	return result.err()
+}
+
+// Skip discards the results from the next n queries in the batch.
+func (br *bufferedBatchResults) Skip(n int) error {
+	return skipBatchResults(br, n)
+}
+
+// ExecAt returns the results of the query at index as if the query has been sent with Exec.
+func (br *bufferedBatchResults) ExecAt(index int) (pgconn.CommandTag, error) {
+	result, err := br.at(index)
+	if err != nil {
+		return pgconn.CommandTag{}, err
+	}
+	return result.commandTag, result.err()
+}
+
+// QueryAt returns the results of the query at index as if the query has been sent with Query.
+func (br *bufferedBatchResults) QueryAt(index int) (Rows, error) {
+	result, err := br.at(index)
+	if err != nil {
+		return &baseRows{err: err, closed: true}, err
+	}
+	return br.rows(result)
+}
+
+// Snapshots returns a snapshot of the result of every query in the batch.
+func (br *bufferedBatchResults) Snapshots() ([]BatchResultSnapshot, error) {
+	snapshots := make([]BatchResultSnapshot, len(br.results))
+	for i := range br.results {
+		snapshots[i] = BatchResultSnapshot{result: &br.results[i]}
+	}
+	return snapshots, nil
+}
+
+// FailedItems returns a new batch with copies of the queries whose results were an error.
+func (br *bufferedBatchResults) FailedItems() *Batch {
+	failed := &Batch{
+		applicationName: br.b.applicationName,
+		readOnly:        br.b.readOnly,
+		Options:         br.b.Options,
+	}
+	for i := range br.results {
+		if br.results[i].err() != nil {
+			qq := *br.b.queuedQueries[i]
+			qq.sd = nil
+			failed.queuedQueries = append(failed.queuedQueries, &qq)
+		}
+	}
+	return failed
+}
+
+// ItemNotices returns the notices received while the results of the query at index were buffered.
+func (br *bufferedBatchResults) ItemNotices(index int) []pgconn.Notice {
+	return br.notices[index]
+}
+
+// AsRows returns a Rows that reads the rows of every remaining query in the batch in sequence.
+func (br *bufferedBatchResults) AsRows() Rows {
+	return newBatchRows(br, br.b, br.qqIdx)
+}
+
+// QueryProject reads the results from the next query in the batch like Query but only exposes the columns named cols.
+func (br *bufferedBatchResults) QueryProject(cols []string) (Rows, error) {
+	return queryProject(br, cols)
+}
+
+// ExecSelect reads the results from the next query in the batch and returns the number of rows it selected.
+func (br *bufferedBatchResults) ExecSelect() (int64, error) {
+	return execSelect(br)
+}
+
+// CurrentTag returns the tag of the query whose results were most recently read.
+func (br *bufferedBatchResults) CurrentTag() any {
+	return currentBatchTag(br.b, br.qqIdx)
+}
+
+// Close runs any callback functions registered for queries that have not been read. The underlying connection was
+// already released when the results were buffered.
+func (br *bufferedBatchResults) Close() error {
+	if br.closed {
+		return br.err
+	}
+
+	for br.err == nil && br.qqIdx < len(br.b.queuedQueries) {
+		if fn := br.b.queuedQueries[br.qqIdx].fn; fn != nil {
+			err := fn(br)
+			if err != nil {
+				br.err = err
+			}
+		} else {
+			br.qqIdx++
+		}
+	}
+
+	br.closed = true
+	br.statsRecorder.finish()
+
+	if br.err == nil {
+		br.err = br.closeErr
+	}
+
+	return br.err
+}
+
+// InTransaction reports whether the connection was inside a transaction block when the batch was sent.
+func (br *bufferedBatchResults) InTransaction() bool {
+	return br.inTx
+}
+
+// VerifyComplete returns an error if the number of results read is not the number of queries queued in the batch.
+// Results read with ExecAt and QueryAt are not counted.
+func (br *bufferedBatchResults) VerifyComplete() error {
+	return verifyBatchComplete(br.b, br.qqIdx, br.extraReads)
+}
+
+// AllFieldDescriptions returns the field descriptions of every result that returns rows.
+func (br *bufferedBatchResults) AllFieldDescriptions() map[int][]pgconn.FieldDescription {
+	return br.fieldDescriptions
+}
+
+func (br *bufferedBatchResults) earlyError() error {
+	return nil
+}
+
+func (br *bufferedBatchResults) setInTransaction(inTx bool) {
+	br.inTx = inTx
+}
+
+// The results are read before a bufferedBatchResults is created, so the hooks for reading them do nothing.
+
+func (br *bufferedBatchResults) setBatchTracer(t BatchTracer)       {}
+func (br *bufferedBatchResults) setFlushTime(t time.Time)           {}
+func (br *bufferedBatchResults) setUnsentBatch(b *Batch)            {}
+func (br *bufferedBatchResults) restoreReadBufferSizeOnClose(n int) {}
+func (br *bufferedBatchResults) clearInFlightOnClose()              {}
+func (br *bufferedBatchResults) rollbackTxOnClose()                 {}
+func (br *bufferedBatchResults) collectNotices()                    {}
+func (br *bufferedBatchResults) skipResult()                        {}
+
+// firstError returns the first error of any query in the batch or the error of closing the underlying results.
+func (br *bufferedBatchResults) firstError() error {
+	for i := range br.results {
+		if err := br.results[i].err(); err != nil {
+			return err
+		}
+	}
+	return br.closeErr
+}
+
+// setResult sets the result of the query at index to the result of the query at srcIndex in src, which contains the
+// results of a batch that was sent in place of the batch of br. Errors that identify a query by its index are changed
+// to identify the query at index.
+func (br *bufferedBatchResults) setResult(index int, src *bufferedBatchResults, srcIndex int) {
+	result := src.results[srcIndex]
+	result.queryErr = reindexItemErr(result.queryErr, index)
+	result.rowsErr = reindexItemErr(result.rowsErr, index)
+	br.results[index] = result
+
+	if fds, ok := src.fieldDescriptions[srcIndex]; ok {
+		if br.fieldDescriptions == nil {
+			br.fieldDescriptions = make(map[int][]pgconn.FieldDescription)
+		}
+		br.fieldDescriptions[index] = fds
+	}
+	if notices, ok := src.notices[srcIndex]; ok {
+		if br.notices == nil {
+			br.notices = make(map[int][]pgconn.Notice)
+		}
+		br.notices[index] = notices
+	}
+}
+
+// reindexItemErr returns err changed to identify the query at index if it identifies a query by its index.
+func reindexItemErr(err error, index int) error {
+	switch itemErr := err.(type) {
+	case *BatchQueryError:
+		if itemErr.Index != index {
+			return &BatchQueryError{Index: index, SQL: itemErr.SQL, Args: itemErr.Args, Err: itemErr.Err}
+		}
+	case *BatchStatementTimeoutError:
+		if itemErr.ItemIndex != index {
+			return &BatchStatementTimeoutError{ItemIndex: index, SQL: itemErr.SQL, Err: itemErr.Err}
+		}
+	}
+	return err
+}
+
+// setUnsent sets the result of every query starting at index from to err. It is used for queries that were not sent.
+func (br *bufferedBatchResults) setUnsent(from int, err error) {
+	for i := from; i < len(br.results); i++ {
+		br.results[i] = bufferedResult{queryErr: err}
+	}
+}
+
+func (br *bufferedBatchResults) next() (*bufferedResult, error) {
+	if br.qqIdx >= len(br.results) {
+		br.extraReads++
+		if br.closeErr != nil {
+			return nil, br.closeErr
+		}
+		return nil, errors.New("no result")
+	}
+
+	result := &br.results[br.qqIdx]
+	br.qqIdx++
+	return result, nil
+}
+
+func (br *bufferedBatchResults) at(index int) (*bufferedResult, error) {
+	if index < 0 || index >= len(br.results) {
+		return nil, fmt.Errorf("batch index %d out of range [0, %d)", index, len(br.results))
+	}
+	return &br.results[index], nil
+}
+
+func (br *bufferedBatchResults) rows(result *bufferedResult) (Rows, error) {
+	if result.queryErr != nil {
+		return &baseRows{err: result.queryErr, closed: true}, result.queryErr
+	}
+	return &bufferedRows{typeMap: br.typeMap, result: result}, nil
+}
+
+// BatchResultSnapshot is the result of a single query in a batch sent with SendBatchOptions.Buffered. It is never
+// modified so it is safe for concurrent use. Note that all rows of the result are held in memory for as long as the
+// snapshot is referenced.
+type BatchResultSnapshot struct {
+	result *bufferedResult
+}
+
+// CommandTag returns the command tag of the query.
+func (s BatchResultSnapshot) CommandTag() pgconn.CommandTag {
+	return s.result.commandTag
+}
+
+// Err returns the error the query failed with, if any.
+func (s BatchResultSnapshot) Err() error {
+	return s.result.err()
+}
+
+// FieldDescriptions returns the field descriptions of the result. The returned slice must not be modified.
+func (s BatchResultSnapshot) FieldDescriptions() []pgconn.FieldDescription {
+	return s.result.fieldDescriptions
+}
+
+// Rows returns a Rows that reads the rows of the result and decodes them with typeMap. Each call returns a new Rows
+// positioned before the first row. A *pgtype.Map is not safe for concurrent use so snapshots read by different
+// goroutines must use different maps, e.g. one created with pgtype.NewMap with the same types registered as the
+// connection.
+func (s BatchResultSnapshot) Rows(typeMap *pgtype.Map) (Rows, error) {
+	if s.result.queryErr != nil {
+		return &baseRows{err: s.result.queryErr, closed: true}, s.result.queryErr
+	}
+	return &bufferedRows{typeMap: typeMap, result: s.result}, nil
+}
+
+// bufferedRows implements Rows for a bufferedResult.
+type bufferedRows struct {
+	typeMap *pgtype.Map
+	result  *bufferedResult
+
+	rowIdx int
+	values [][]byte
+	err    error
+	closed bool
+}
+
+func (rows *bufferedRows) Close() {
+	if rows.closed {
+		return
+	}
+
+	rows.closed = true
+	if rows.err == nil {
+		rows.err = rows.result.rowsErr
+	}
+}
+
+func (rows *bufferedRows) Err() error {
+	return rows.err
+}
+
+func (rows *bufferedRows) CommandTag() pgconn.CommandTag {
+	return rows.result.commandTag
+}
+
+func (rows *bufferedRows) FieldDescriptions() []pgconn.FieldDescription {
+	return rows.result.fieldDescriptions
+}
+
+func (rows *bufferedRows) Next() bool {
+	if rows.closed {
+		return false
+	}
+
+	if rows.rowIdx < len(rows.result.rows) {
+		rows.values = rows.result.rows[rows.rowIdx]
+		rows.rowIdx++
+		return true
+	}
+
+	rows.Close()
+	return false
+}
+
+func (rows *bufferedRows) Scan(dest ...any) error {
+	if len(dest) == 1 {
+		if rc, ok := dest[0].(RowScanner); ok {
+			return rc.ScanRow(rows)
+		}
+	}
+
+	err := ScanRow(rows.typeMap, rows.result.fieldDescriptions, rows.values, dest...)
+	if err != nil {
+		rows.fatal(err)
+	}
+	return err
+}
+
+func (rows *bufferedRows) Values() ([]any, error) {
+	if rows.closed {
+		return nil, errors.New("rows is closed")
+	}
+
+	values, err := decodeRowValues(rows.typeMap, rows.result.fieldDescriptions, rows.values)
+	if err != nil {
+		rows.fatal(err)
+	}
+	return values, rows.Err()
+}
+
+func (rows *bufferedRows) RawValues() [][]byte {
+	return rows.values
+}
+
+// Conn returns nil because buffered rows do not depend on a connection.
+func (rows *bufferedRows) Conn() *Conn {
+	return nil
+}
+
+// Notices returns the notices received while the results of the query were buffered.
+func (rows *bufferedRows) Notices() []pgconn.Notice {
+	return rows.result.notices
+}
+
+func (rows *bufferedRows) fatal(err error) {
+	if rows.err != nil {
+		return
+	}
+
+	rows.err = err
+	rows.Close()
+}
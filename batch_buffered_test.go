@@ -0,0 +1,154 @@
+package pgx_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxtest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnSendBatchBufferedReverseOrder(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		mustExec(t, conn, `create temporary table ledger(id int primary key, amount int not null)`)
+
+		batch := &pgx.Batch{Options: pgx.SendBatchOptions{Buffered: true}}
+		batch.Queue("insert into ledger(id, amount) values (1, 10)")
+		batch.Queue("insert into ledger(id, amount) values (2, 20)")
+		batch.Queue("select id, amount from ledger order by id")
+		batch.Queue("select sum(amount)::int4 from ledger")
+
+		br := conn.SendBatch(ctx, batch)
+
+		// The results are buffered so the connection is usable before the batch results are read.
+		ensureConnValid(t, conn)
+
+		rows, err := pgx.BatchQueryAt(br, 3)
+		require.NoError(t, err)
+		sums, err := pgx.CollectRows(rows, pgx.RowTo[int32])
+		require.NoError(t, err)
+		require.Equal(t, []int32{30}, sums)
+
+		rows, err = pgx.BatchQueryAt(br, 2)
+		require.NoError(t, err)
+		type entry struct {
+			ID     int32
+			Amount int32
+		}
+		entries, err := pgx.CollectRows(rows, pgx.RowToStructByPos[entry])
+		require.NoError(t, err)
+		require.Equal(t, []entry{{1, 10}, {2, 20}}, entries)
+
+		for i := 1; i >= 0; i-- {
+			ct, err := pgx.BatchExecAt(br, i)
+			require.NoError(t, err)
+			require.Equal(t, "INSERT 0 1", ct.String())
+		}
+
+		// Reading a buffered result more than once returns the same result.
+		rows, err = pgx.BatchQueryAt(br, 3)
+		require.NoError(t, err)
+		sums, err = pgx.CollectRows(rows, pgx.RowTo[int32])
+		require.NoError(t, err)
+		require.Equal(t, []int32{30}, sums)
+
+		_, err = pgx.BatchExecAt(br, 4)
+		require.EqualError(t, err, "batch index 4 out of range [0, 4)")
+
+		// Sequential reads are not affected by ExecAt and QueryAt.
+		ct, err := br.Exec()
+		require.NoError(t, err)
+		require.Equal(t, "INSERT 0 1", ct.String())
+		require.Error(t, pgx.VerifyBatchComplete(br))
+
+		require.NoError(t, br.Close())
+	})
+}
+
+func TestConnSendBatchBufferedError(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		batch := &pgx.Batch{Options: pgx.SendBatchOptions{Buffered: true}}
+		batch.Queue("select 1")
+		batch.Queue("select 1/0")
+		batch.Queue("select 3")
+
+		br := conn.SendBatch(ctx, batch)
+		ensureConnValid(t, conn)
+
+		_, err := pgx.BatchExecAt(br, 0)
+		require.NoError(t, err)
+
+		var pgErr *pgconn.PgError
+		rows, err := pgx.BatchQueryAt(br, 1)
+		if err == nil {
+			rows.Close()
+			err = rows.Err()
+		}
+		require.ErrorAs(t, err, &pgErr)
+		require.Equal(t, "22012", pgErr.Code)
+
+		_, err = pgx.BatchExecAt(br, 2)
+		require.Error(t, err)
+
+		require.ErrorAs(t, br.Close(), &pgErr)
+	})
+}
+
+func TestConnSendBatchBufferedSnapshots(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		batch := &pgx.Batch{Options: pgx.SendBatchOptions{Buffered: true}}
+		for i := 0; i < 8; i++ {
+			batch.Queue("select n from generate_series(1, $1::int4) n", i)
+		}
+
+		br := conn.SendBatch(ctx, batch)
+		snapshots, err := pgx.BatchSnapshots(br)
+		require.NoError(t, err)
+		require.NoError(t, br.Close())
+		require.Len(t, snapshots, 8)
+
+		sums := make([]int32, len(snapshots))
+		errs := make(chan error, len(snapshots))
+		for i := range snapshots {
+			go func(i int) {
+				rows, err := snapshots[i].Rows(pgtype.NewMap())
+				if err != nil {
+					errs <- err
+					return
+				}
+				ns, err := pgx.CollectRows(rows, pgx.RowTo[int32])
+				for _, n := range ns {
+					sums[i] += n
+				}
+				errs <- err
+			}(i)
+		}
+		for range snapshots {
+			require.NoError(t, <-errs)
+		}
+
+		for i, sum := range sums {
+			require.EqualValues(t, i*(i+1)/2, sum)
+			require.EqualValues(t, i, snapshots[i].CommandTag().RowsAffected())
+			require.NoError(t, snapshots[i].Err())
+		}
+	})
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		batch := &pgx.Batch{}
+		batch.Queue("select 1")
+		br := conn.SendBatch(ctx, batch)
+		_, err := pgx.BatchSnapshots(br)
+		require.EqualError(t, err, "batch was not sent with SendBatchOptions.Buffered")
+		require.NoError(t, br.Close())
+	})
+}
@@ -0,0 +1,63 @@
+package pgx
+
+import (
+	"sync"
+)
+
+// BatchBuilder queues queries to a Batch from multiple goroutines. This allows writes from many goroutines, such as the
+// workers of a pool, to be coalesced into batches that are sent by a single goroutine. The zero value is an empty
+// BatchBuilder ready to use. A BatchBuilder must not be copied after first use.
+//
+// Queries appear in the batch in the order the calls that queued them acquired the builder. The queries queued by a
+// single call to QueueFunc are always contiguous in the batch.
+type BatchBuilder struct {
+	mu sync.Mutex
+	b  *Batch
+}
+
+// Queue queues a query like Batch.Queue. It does not return the QueuedQuery because the batch may be taken by another
+// goroutine at any time. Use QueueFunc to set a callback function on the queued query.
+func (bb *BatchBuilder) Queue(query string, arguments ...any) {
+	bb.QueueFunc(func(b *Batch) {
+		b.Queue(query, arguments...)
+	})
+}
+
+// QueueFunc calls fn with the batch being built while holding the builder. fn can queue any number of queries and set
+// callback functions on them without other goroutines interleaving their queries. fn must not retain b or call methods
+// of bb.
+func (bb *BatchBuilder) QueueFunc(fn func(b *Batch)) {
+	bb.mu.Lock()
+	defer bb.mu.Unlock()
+
+	if bb.b == nil {
+		bb.b = &Batch{}
+	}
+	fn(bb.b)
+}
+
+// Len returns the number of queries queued since the batch was last taken.
+func (bb *BatchBuilder) Len() int {
+	bb.mu.Lock()
+	defer bb.mu.Unlock()
+
+	if bb.b == nil {
+		return 0
+	}
+	return bb.b.Len()
+}
+
+// Take returns the batch built so far and starts a new empty batch. The returned batch is owned by the caller and is
+// no longer modified by bb, so its options can be set and it can be sent. Take returns an empty batch if no queries
+// have been queued.
+func (bb *BatchBuilder) Take() *Batch {
+	bb.mu.Lock()
+	defer bb.mu.Unlock()
+
+	b := bb.b
+	bb.b = nil
+	if b == nil {
+		b = &Batch{}
+	}
+	return b
+}
@@ -0,0 +1,67 @@
+package pgx_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxtest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchBuilderConcurrentQueue(t *testing.T) {
+	t.Parallel()
+
+	var bb pgx.BatchBuilder
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				bb.QueueFunc(func(b *pgx.Batch) {
+					b.Queue(fmt.Sprintf("select %d", worker))
+					b.Queue(fmt.Sprintf("select %d", worker))
+				})
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	require.Equal(t, 2000, bb.Len())
+
+	batch := bb.Take()
+	require.Equal(t, 0, bb.Len())
+	require.Equal(t, 2000, batch.Len())
+
+	qqs := batch.QueuedQueries()
+	for i := 0; i < len(qqs); i += 2 {
+		require.Equal(t, qqs[i].SQL(), qqs[i+1].SQL())
+	}
+
+	require.Equal(t, 0, bb.Take().Len())
+}
+
+func TestConnSendBatchFromBatchBuilder(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		var bb pgx.BatchBuilder
+		var rowsAffected int64
+		bb.Queue("select 1")
+		bb.QueueFunc(func(b *pgx.Batch) {
+			b.Queue("select n from generate_series(1, 3) n").Exec(func(ct pgconn.CommandTag) error {
+				rowsAffected = ct.RowsAffected()
+				return nil
+			})
+		})
+
+		err := conn.SendBatch(ctx, bb.Take()).Close()
+		require.NoError(t, err)
+		require.EqualValues(t, 3, rowsAffected)
+	})
+}
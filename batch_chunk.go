@@ -0,0 +1,143 @@
+package pgx
+
+import (
+	"context"
+	"fmt"
+)
+
+// chunkBounds returns the index of the first query of each chunk b is split into by SendBatchOptions.MaxChunkQueries
+// and SendBatchOptions.MaxChunkBytes. It returns nil if b is sent as a whole.
+func (b *Batch) chunkBounds() []int {
+	maxQueries, maxBytes := b.Options.MaxChunkQueries, b.Options.MaxChunkBytes
+	if maxQueries <= 0 && maxBytes <= 0 {
+		return nil
+	}
+
+	var bounds []int
+	chunkQueries, chunkBytes := 0, 0
+	for i, qq := range b.queuedQueries {
+		size := qq.approximateSize()
+		if chunkQueries > 0 && (maxQueries > 0 && chunkQueries >= maxQueries || maxBytes > 0 && chunkBytes+size > maxBytes) {
+			chunkQueries, chunkBytes = 0, 0
+		}
+		if chunkQueries == 0 {
+			bounds = append(bounds, i)
+		}
+		chunkQueries++
+		chunkBytes += size
+	}
+
+	if len(bounds) < 2 {
+		return nil
+	}
+	return bounds
+}
+
+// approximateSize returns the size of the SQL of qq plus the size of its string and []byte arguments. Other arguments
+// are small compared to them and their encoded size is not known until the batch is sent, so they are not counted.
+func (qq *QueuedQuery) approximateSize() int {
+	size := len(qq.query)
+	if qq.execParams != nil {
+		for _, v := range qq.execParams.paramValues {
+			size += len(v)
+		}
+		return size
+	}
+
+	for _, arg := range qq.arguments {
+		switch arg := arg.(type) {
+		case string:
+			size += len(arg)
+		case []byte:
+			size += len(arg)
+		}
+	}
+	return size
+}
+
+// sendBatchChunks sends the queries of b in chunks starting at the indexes in bounds. Each chunk is sent and buffered
+// before the next one is sent. If a chunk fails the remaining chunks are not sent. The results of all chunks are
+// combined into a single bufferedBatchResults.
+func (c *Conn) sendBatchChunks(ctx context.Context, b *Batch, bounds []int) sentBatchResults {
+	combined := &bufferedBatchResults{
+		typeMap: c.typeMap,
+		b:       b,
+		results: make([]bufferedResult, len(b.queuedQueries)),
+		inTx:    c.pgConn.TxStatus() != 'I',
+	}
+
+	var tx Tx
+	if b.Options.Tx != nil {
+		err := errBatchTxInTransaction
+		if !combined.inTx {
+			tx, err = c.BeginTx(ctx, *b.Options.Tx)
+		}
+		if err != nil {
+			combined.closeErr = err
+			combined.setUnsent(0, err)
+			return combined
+		}
+		defer func() {
+			_ = tx.Rollback(ctx) // does nothing if the transaction was committed. Otherwise there is already an error to return.
+		}()
+	}
+
+	options := b.Options
+	options.Buffered = true
+	options.MaxChunkQueries = 0
+	options.MaxChunkBytes = 0
+	options.Tx = nil
+
+	for i, start := range bounds {
+		end := len(b.queuedQueries)
+		if i+1 < len(bounds) {
+			end = bounds[i+1]
+		}
+
+		chunk := &Batch{
+			queuedQueries:   b.queuedQueries[start:end:end],
+			applicationName: b.applicationName,
+			readOnly:        b.readOnly,
+			Options:         options,
+		}
+		flushedBefore := start
+		chunk.Options.OnFlush = func(itemsFlushed int) { b.notifyFlush(flushedBefore + itemsFlushed) }
+
+		br := c.sendBatchInto(ctx, chunk, nil)
+		if err := br.earlyError(); err != nil {
+			br.Close()
+			combined.closeErr = err
+			combined.setUnsent(start, err)
+			return combined
+		}
+
+		bbr := br.(*bufferedBatchResults)
+		var err error
+		for j := start; j < end; j++ {
+			combined.setResult(j, bbr, j-start)
+			if err == nil {
+				err = combined.results[j].err()
+			}
+		}
+		if err == nil {
+			err = bbr.closeErr
+		}
+
+		if err != nil {
+			combined.closeErr = err
+			if end < len(b.queuedQueries) {
+				combined.setUnsent(end, fmt.Errorf("not sent because an earlier chunk of the batch failed: %w", err))
+			}
+			return combined
+		}
+	}
+
+	if tx != nil {
+		err := tx.Commit(ctx)
+		if err != nil {
+			combined.closeErr = err
+		}
+	}
+
+	return combined
+}
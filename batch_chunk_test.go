@@ -0,0 +1,101 @@
+package pgx_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxtest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnSendBatchChunked(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		var flushed []int
+
+		batch := &pgx.Batch{}
+		batch.Options.MaxChunkQueries = 2
+		batch.Options.OnFlush = func(n int) { flushed = append(flushed, n) }
+		for i := 0; i < 5; i++ {
+			batch.Queue("select $1::int4", i)
+		}
+
+		br := conn.SendBatch(ctx, batch)
+		for i := 0; i < 5; i++ {
+			var n int32
+			require.NoError(t, br.QueryRow().Scan(&n))
+			require.EqualValues(t, i, n)
+		}
+		require.NoError(t, br.Close())
+		require.Equal(t, []int{2, 4, 5}, flushed)
+
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestConnSendBatchChunkedByBytes(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		var flushed []int
+
+		batch := &pgx.Batch{}
+		batch.Options.MaxChunkBytes = 40
+		batch.Options.OnFlush = func(n int) { flushed = append(flushed, n) }
+		batch.Queue("select $1::text", "a")
+		batch.Queue("select $1::text", "bb")
+		batch.Queue("select $1::text", "a very long argument that exceeds the limit")
+		batch.Queue("select $1::text", "c")
+
+		br := conn.SendBatch(ctx, batch)
+		for _, expected := range []string{"a", "bb", "a very long argument that exceeds the limit", "c"} {
+			var s string
+			require.NoError(t, br.QueryRow().Scan(&s))
+			require.Equal(t, expected, s)
+		}
+		require.NoError(t, br.Close())
+		require.Equal(t, []int{2, 3, 4}, flushed)
+	})
+}
+
+func TestConnSendBatchChunkedFailure(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		batch := &pgx.Batch{}
+		batch.Options.MaxChunkQueries = 2
+		batch.Queue("select 1")
+		batch.Queue("select 2")
+		batch.Queue("select 1/0")
+		batch.Queue("select 4")
+		batch.Queue("select 5")
+
+		br := conn.SendBatch(ctx, batch)
+
+		for i := 0; i < 2; i++ {
+			_, err := br.Exec()
+			require.NoError(t, err)
+		}
+
+		_, err := br.Exec()
+		var pgErr *pgconn.PgError
+		require.ErrorAs(t, err, &pgErr)
+		require.Equal(t, "22012", pgErr.Code)
+
+		_, err = br.Exec()
+		require.ErrorContains(t, err, "earlier chunk")
+		require.ErrorAs(t, err, &pgErr)
+
+		failed, err := pgx.BatchFailedItems(br)
+		require.NoError(t, err)
+		require.Len(t, failed.QueuedQueries(), 3)
+		require.Equal(t, "select 1/0", failed.QueuedQueries()[0].SQL())
+
+		require.Error(t, br.Close())
+
+		ensureConnValid(t, conn)
+	})
+}
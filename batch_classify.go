@@ -0,0 +1,162 @@
+package pgx
+
+import (
+	"strings"
+	"unicode"
+)
+
+// StatementKind is the kind of a statement as determined by Batch.Classify.
+type StatementKind int
+
+const (
+	// StatementKindUnknown is a statement that could not be classified. e.g. a prepared statement name, a transaction
+	// control statement, SET, CALL, DO, COPY, or EXPLAIN.
+	StatementKindUnknown StatementKind = iota
+
+	// StatementKindRead is a statement that only reads data such as SELECT or VALUES.
+	StatementKindRead
+
+	// StatementKindWrite is a statement that modifies data or the schema such as INSERT, UPDATE, DELETE, or DDL.
+	StatementKindWrite
+)
+
+func (k StatementKind) String() string {
+	switch k {
+	case StatementKindRead:
+		return "read"
+	case StatementKindWrite:
+		return "write"
+	default:
+		return "unknown"
+	}
+}
+
+// Classify returns the StatementKind of each query queued in b in queue order. It does not execute anything.
+//
+// Classify is a heuristic based on the first keyword of each query, not a full SQL parser. Comments, string literals,
+// and quoted identifiers are skipped. A query starting with WITH is a write if a data-modifying keyword such as INSERT,
+// UPDATE, DELETE, or MERGE appears anywhere in it, e.g. WITH t AS (...) INSERT INTO ..., or WITH t AS (DELETE ...
+// RETURNING *) SELECT ..., and a read otherwise. Side effects of functions called by a query are not detected, so
+// select nextval('seq') is classified as a read. Queries that are the name of a prepared statement are unknown.
+func (b *Batch) Classify() []StatementKind {
+	kinds := make([]StatementKind, len(b.queuedQueries))
+	for i, qq := range b.queuedQueries {
+		kinds[i] = classifyStatement(qq.query)
+	}
+	return kinds
+}
+
+func classifyStatement(sql string) StatementKind {
+	words := sqlKeywords(sql)
+	if len(words) == 0 {
+		return StatementKindUnknown
+	}
+
+	switch words[0] {
+	case "select", "values", "table", "show":
+		return StatementKindRead
+	case "insert", "update", "delete", "merge", "create", "alter", "drop", "truncate", "grant", "revoke", "comment",
+		"reindex", "cluster", "vacuum", "refresh", "security", "lock":
+		return StatementKindWrite
+	case "with":
+		for _, w := range words[1:] {
+			switch w {
+			case "insert", "update", "delete", "merge":
+				return StatementKindWrite
+			}
+		}
+		return StatementKindRead
+	}
+
+	return StatementKindUnknown
+}
+
+// sqlKeywords returns the unquoted words of sql in lower case. Comments, string literals, quoted identifiers, and
+// dollar-quoted strings are skipped.
+func sqlKeywords(sql string) []string {
+	var words []string
+	scanSQL(sql, func(word string) { words = append(words, word) }, nil)
+	return words
+}
+
+// scanSQL calls word with each unquoted word of sql in lower case and placeholder with the number of each positional
+// placeholder such as $1. Comments, string literals, quoted identifiers, and dollar-quoted strings are skipped. word and
+// placeholder may be nil.
+func scanSQL(sql string, word func(word string), placeholder func(n int)) {
+	for i := 0; i < len(sql); {
+		c := sql[i]
+		switch {
+		case c == '-' && strings.HasPrefix(sql[i:], "--"):
+			end := strings.IndexByte(sql[i:], '\n')
+			if end < 0 {
+				return
+			}
+			i += end + 1
+		case c == '/' && strings.HasPrefix(sql[i:], "/*"):
+			end := strings.Index(sql[i+2:], "*/")
+			if end < 0 {
+				return
+			}
+			i += 2 + end + 2
+		case c == '\'' || c == '"':
+			end := strings.IndexByte(sql[i+1:], c)
+			if end < 0 {
+				return
+			}
+			i += 1 + end + 1
+		case c == '$':
+			tagEnd := strings.IndexByte(sql[i+1:], '$')
+			tag := ""
+			if tagEnd >= 0 {
+				tag = sql[i : i+1+tagEnd+1]
+			}
+			if tag == "" || !isDollarQuoteTag(tag[1:len(tag)-1]) {
+				// A placeholder such as $1.
+				i++
+				n := 0
+				for ; i < len(sql) && sql[i] >= '0' && sql[i] <= '9'; i++ {
+					n = n*10 + int(sql[i]-'0')
+				}
+				if n > 0 && placeholder != nil {
+					placeholder(n)
+				}
+				continue
+			}
+			end := strings.Index(sql[i+len(tag):], tag)
+			if end < 0 {
+				return
+			}
+			i += len(tag) + end + len(tag)
+		case c < 0x80 && (unicode.IsLetter(rune(c)) || c == '_'):
+			start := i
+			for i < len(sql) && sql[i] < 0x80 && (unicode.IsLetter(rune(sql[i])) || unicode.IsDigit(rune(sql[i])) || sql[i] == '_' || sql[i] == '$') {
+				i++
+			}
+			if i-start == 1 && (c == 'e' || c == 'E') && i < len(sql) && sql[i] == '\'' {
+				// An escape string such as E'it\'s'.
+				for i++; i < len(sql) && sql[i] != '\''; i++ {
+					if sql[i] == '\\' {
+						i++
+					}
+				}
+				i++
+				continue
+			}
+			if word != nil {
+				word(strings.ToLower(sql[start:i]))
+			}
+		default:
+			i++
+		}
+	}
+}
+
+// isDollarQuoteTag reports whether tag is valid between the dollar signs of a dollar-quoted string.
+func isDollarQuoteTag(tag string) bool {
+	for i, r := range tag {
+		if !(unicode.IsLetter(r) || r == '_' || (i > 0 && unicode.IsDigit(r))) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,58 @@
+package pgx_test
+
+import (
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchClassify(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		sql  string
+		kind pgx.StatementKind
+	}{
+		{"select 1", pgx.StatementKindRead},
+		{"  SELECT * from t", pgx.StatementKindRead},
+		{"values (1), (2)", pgx.StatementKindRead},
+		{"(select 1) union (select 2)", pgx.StatementKindRead},
+		{"-- update the totals\nselect sum(n) from t", pgx.StatementKindRead},
+		{"/* delete */ select 1", pgx.StatementKindRead},
+		{"insert into t(n) values ($1)", pgx.StatementKindWrite},
+		{"Update t set n = $1", pgx.StatementKindWrite},
+		{"delete from t", pgx.StatementKindWrite},
+		{"create table t(n int)", pgx.StatementKindWrite},
+		{"drop table t", pgx.StatementKindWrite},
+		{"with x as (select 1) select * from x", pgx.StatementKindRead},
+		{"with x as (select 'insert' as s) select * from x", pgx.StatementKindRead},
+		{`with "update" as (select 1) select * from "update"`, pgx.StatementKindRead},
+		{"with x as (select $1::int) insert into t select * from x", pgx.StatementKindWrite},
+		{"with d as (delete from t returning *) select count(*) from d", pgx.StatementKindWrite},
+		{"with x as (select $$update$$) select * from x", pgx.StatementKindRead},
+		{"begin", pgx.StatementKindUnknown},
+		{"set search_path = public", pgx.StatementKindUnknown},
+		{"my_prepared_statement", pgx.StatementKindUnknown},
+		{"", pgx.StatementKindUnknown},
+	}
+
+	batch := &pgx.Batch{}
+	for _, tt := range tests {
+		batch.Queue(tt.sql)
+	}
+
+	kinds := batch.Classify()
+	require.Len(t, kinds, len(tests))
+	for i, tt := range tests {
+		require.Equalf(t, tt.kind, kinds[i], "%q", tt.sql)
+	}
+}
+
+func TestStatementKindString(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "read", pgx.StatementKindRead.String())
+	require.Equal(t, "write", pgx.StatementKindWrite.String())
+	require.Equal(t, "unknown", pgx.StatementKindUnknown.String())
+}
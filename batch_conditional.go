@@ -0,0 +1,75 @@
+package pgx
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// conditionalRowCountSetting is the transaction local setting used to pass the row count of a query queued with
+// Batch.QueueConditional to the next one.
+const conditionalRowCountSetting = "pgx.conditional_row_count"
+
+// QueueConditional queues sql to run only if the most recent query queued with QueueConditional before it affected or
+// returned at least one row. The first query queued with QueueConditional in a batch always runs. A query that does not
+// run counts as affecting no rows so the rest of the chain does not run either. e.g. an UPDATE followed by an INSERT
+// into an audit table can be queued so the INSERT only runs if the UPDATE matched a row.
+//
+// The condition is evaluated by the server. sql is wrapped in a PL/pgSQL DO block that checks the row count recorded
+// by the previous conditional query, runs sql with EXECUTE, and records its row count in the transaction local setting
+// pgx.conditional_row_count. This has the following limitations:
+//
+//   - A DO block cannot have parameters so the arguments are inlined into the SQL as literals in the same way as
+//     QueryExecModeSimpleProtocol. This requires standard_conforming_strings=on and client_encoding=UTF8.
+//   - Any rows returned by sql are discarded. The result of the queued query is always the command tag of the DO block.
+//   - The row count of queries queued with Queue is not recorded. Conditional queries only depend on each other.
+//   - All conditional queries must run in the same transaction. The batch must not contain transaction control
+//     statements between them.
+//   - The server must support PL/pgSQL.
+func (b *Batch) QueueConditional(sql string, arguments ...any) *QueuedQuery {
+	first := true
+	for _, qq := range b.queuedQueries {
+		if len(qq.arguments) > 0 {
+			if _, ok := qq.arguments[0].(*conditionalQueryRewriter); ok {
+				first = false
+				break
+			}
+		}
+	}
+
+	args := make([]any, 0, len(arguments)+1)
+	args = append(args, &conditionalQueryRewriter{first: first})
+	args = append(args, arguments...)
+	return b.Queue(sql, args...)
+}
+
+// conditionalQueryRewriter rewrites a query queued with Batch.QueueConditional into a DO block.
+type conditionalQueryRewriter struct {
+	first bool // the first conditional query in the batch runs unconditionally
+}
+
+func (r *conditionalQueryRewriter) RewriteQuery(ctx context.Context, conn *Conn, sql string, args []any) (newSQL string, newArgs []any, err error) {
+	stmt, err := conn.sanitizeForSimpleQuery(sql, args...)
+	if err != nil {
+		return "", nil, err
+	}
+	if strings.Contains(stmt, "$pgx_conditional$") || strings.Contains(stmt, "$pgx_stmt$") {
+		return "", nil, errors.New("conditional query must not contain $pgx_conditional$ or $pgx_stmt$")
+	}
+
+	condition := "true"
+	if !r.first {
+		condition = "coalesce(nullif(current_setting('" + conditionalRowCountSetting + "', true), '')::bigint, 0) > 0"
+	}
+
+	var sb strings.Builder
+	sb.WriteString("do $pgx_conditional$\ndeclare\n\tn bigint := 0;\nbegin\n\tif ")
+	sb.WriteString(condition)
+	sb.WriteString(" then\n\t\texecute $pgx_stmt$")
+	sb.WriteString(stmt)
+	sb.WriteString("$pgx_stmt$;\n\t\tget diagnostics n = row_count;\n\tend if;\n\tperform set_config('")
+	sb.WriteString(conditionalRowCountSetting)
+	sb.WriteString("', n::text, true);\nend\n$pgx_conditional$")
+
+	return sb.String(), nil, nil
+}
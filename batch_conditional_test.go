@@ -0,0 +1,71 @@
+package pgx_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxtest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchQueueConditional(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		pgxtest.SkipCockroachDB(t, conn, "Server does not support PL/PGSQL (https://github.com/cockroachdb/cockroach/issues/17511)")
+
+		mustExec(t, conn, `create temporary table accounts(id int primary key, balance int not null)`)
+		mustExec(t, conn, `create temporary table audit(account_id int not null, note text not null)`)
+		mustExec(t, conn, `insert into accounts(id, balance) values (1, 100)`)
+
+		for _, id := range []int{1, 2} {
+			batch := &pgx.Batch{}
+			batch.QueueConditional("update accounts set balance = balance - $1 where id = $2", 10, id)
+			batch.QueueConditional("insert into audit(account_id, note) values ($1, $2)", id, "it's a withdrawal")
+			batch.QueueConditional("select * from audit where account_id = $1", id)
+			batch.Queue("select count(*) from audit")
+
+			br := conn.SendBatch(ctx, batch)
+			for i := 0; i < 3; i++ {
+				ct, err := br.Exec()
+				require.NoError(t, err)
+				require.Equal(t, "DO", ct.String())
+			}
+			require.NoError(t, br.Close())
+		}
+
+		rows, _ := conn.Query(ctx, "select account_id::text || ': ' || note from audit")
+		notes, err := pgx.CollectRows(rows, pgx.RowTo[string])
+		require.NoError(t, err)
+		require.Equal(t, []string{"1: it's a withdrawal"}, notes)
+
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestBatchQueueConditionalSkipsRestOfChain(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		pgxtest.SkipCockroachDB(t, conn, "Server does not support PL/PGSQL (https://github.com/cockroachdb/cockroach/issues/17511)")
+
+		mustExec(t, conn, `create temporary table t(n int not null)`)
+
+		batch := &pgx.Batch{}
+		batch.QueueConditional("select 1 where false")
+		batch.QueueConditional("insert into t(n) values (1)")
+		batch.QueueConditional("select 1")
+		batch.QueueConditional("insert into t(n) values (2)")
+
+		err := conn.SendBatch(ctx, batch).Close()
+		require.NoError(t, err)
+
+		var n int64
+		err = conn.QueryRow(ctx, "select count(*) from t").Scan(&n)
+		require.NoError(t, err)
+		require.EqualValues(t, 0, n)
+
+		ensureConnValid(t, conn)
+	})
+}
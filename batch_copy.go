@@ -0,0 +1,81 @@
+package pgx
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/internal/pgio"
+)
+
+// queuedCopyFrom holds the source of a COPY queued with Batch.QueueCopyFrom.
+type queuedCopyFrom struct {
+	tableName   Identifier
+	columnNames []string
+	rowSrc      CopyFromSource
+	data        []byte // the rows of rowSrc in the binary copy format. It is set when the batch is first sent.
+}
+
+// QueueCopyFrom queues a COPY of the rows of rowSrc into the columns columnNames of tableName like Conn.CopyFrom. The
+// copy data is sent in the same round trip as the other queries of the batch, so a copy and the statements around it,
+// such as truncating a staging table before the copy and merging it afterwards, are run without waiting for each other.
+// The result of the copy is read like that of any other query, e.g. with BatchResults.Exec, and its command tag reports
+// the number of rows copied.
+//
+// The rows are encoded in the binary format when the batch is sent. This requires the types of the columns so they are
+// described first, which takes an additional round trip unless the description is already cached by the connection.
+// All rows are held in memory until the batch is sent. QueueCopyFrom cannot be used with QueryExecModeSimpleProtocol.
+func (b *Batch) QueueCopyFrom(tableName Identifier, columnNames []string, rowSrc CopyFromSource) *QueuedQuery {
+	b.checkNotFrozen()
+
+	qq := b.appendQueuedQuery()
+	qq.query = fmt.Sprintf("copy %s ( %s ) from stdin binary", tableName.Sanitize(), quoteColumnNames(columnNames))
+	qq.copyFrom = &queuedCopyFrom{
+		tableName:   tableName,
+		columnNames: columnNames,
+		rowSrc:      rowSrc,
+	}
+	return qq
+}
+
+// encodeBatchCopyData encodes the rows of each query of b queued with QueueCopyFrom whose rows have not already been
+// encoded. A CopyFromSource can only be read once so the encoded rows are kept for when the query is sent again, e.g.
+// as part of BatchFailedItems.
+func (c *Conn) encodeBatchCopyData(ctx context.Context, b *Batch, mode QueryExecMode) error {
+	switch mode {
+	case QueryExecModeExec, QueryExecModeSimpleProtocol:
+		// These modes do not describe statements. Use the same mode as Conn.CopyFrom does for them.
+		mode = QueryExecModeDescribeExec
+	}
+
+	for i, bi := range b.queuedQueries {
+		cf := bi.copyFrom
+		if cf == nil || cf.data != nil {
+			continue
+		}
+
+		sd, err := c.getStatementDescription(ctx, mode, fmt.Sprintf("select %s from %s", quoteColumnNames(cf.columnNames), cf.tableName.Sanitize()))
+		if err != nil {
+			return fmt.Errorf("batch item %d: statement description failed: %w", i, err)
+		}
+
+		ct := &copyFrom{conn: c, tableName: cf.tableName, columnNames: cf.columnNames, rowSrc: cf.rowSrc}
+
+		buf := append([]byte(nil), "PGCOPY\n\377\r\n\000"...)
+		buf = pgio.AppendInt32(buf, 0)
+		buf = pgio.AppendInt32(buf, 0)
+
+		for moreRows := true; moreRows; {
+			moreRows, buf, err = ct.buildCopyBuf(buf, sd)
+			if err != nil {
+				return fmt.Errorf("batch item %d: %w", i, err)
+			}
+		}
+		if err := cf.rowSrc.Err(); err != nil {
+			return fmt.Errorf("batch item %d: %w", i, err)
+		}
+
+		cf.data = buf
+	}
+
+	return nil
+}
@@ -0,0 +1,95 @@
+package pgx_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxtest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnSendBatchQueueCopyFrom(t *testing.T) {
+	t.Parallel()
+
+	modes := []pgx.QueryExecMode{
+		pgx.QueryExecModeCacheStatement,
+		pgx.QueryExecModeCacheDescribe,
+		pgx.QueryExecModeDescribeExec,
+		pgx.QueryExecModeExec,
+	}
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, modes, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		_, err := conn.Exec(ctx, "create temporary table batch_copy_staging (id int4, name text); create temporary table batch_copy (id int4 primary key, name text)")
+		require.NoError(t, err)
+
+		batch := &pgx.Batch{}
+		batch.Queue("truncate batch_copy_staging")
+		batch.QueueCopyFrom(pgx.Identifier{"batch_copy_staging"}, []string{"id", "name"}, pgx.CopyFromRows([][]any{
+			{1, "foo"},
+			{2, "bar"},
+			{3, nil},
+		}))
+		batch.Queue("insert into batch_copy select * from batch_copy_staging")
+		batch.Queue("select count(*) from batch_copy")
+
+		br := conn.SendBatch(ctx, batch)
+
+		_, err = br.Exec()
+		require.NoError(t, err)
+
+		ct, err := br.Exec()
+		require.NoError(t, err)
+		require.EqualValues(t, 3, ct.RowsAffected())
+
+		ct, err = br.Exec()
+		require.NoError(t, err)
+		require.EqualValues(t, 3, ct.RowsAffected())
+
+		var n int64
+		require.NoError(t, br.QueryRow().Scan(&n))
+		require.EqualValues(t, 3, n)
+
+		require.NoError(t, br.Close())
+
+		ensureConnValid(t, conn)
+	})
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, []pgx.QueryExecMode{pgx.QueryExecModeSimpleProtocol}, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		batch := &pgx.Batch{}
+		batch.QueueCopyFrom(pgx.Identifier{"foo"}, []string{"a"}, pgx.CopyFromRows(nil))
+
+		err := conn.SendBatch(ctx, batch).Close()
+		require.EqualError(t, err, "batch item 0: QueueCopyFrom is not supported with QueryExecModeSimpleProtocol")
+	})
+}
+
+func TestConnSendBatchQueueCopyFromError(t *testing.T) {
+	t.Parallel()
+
+	modes := []pgx.QueryExecMode{
+		pgx.QueryExecModeCacheStatement,
+		pgx.QueryExecModeExec,
+	}
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, modes, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		_, err := conn.Exec(ctx, "create temporary table batch_copy_error (id int4 primary key)")
+		require.NoError(t, err)
+
+		batch := &pgx.Batch{}
+		batch.QueueCopyFrom(pgx.Identifier{"batch_copy_error"}, []string{"id"}, pgx.CopyFromRows([][]any{{1}, {1}}))
+		batch.Queue("select 1")
+
+		br := conn.SendBatch(ctx, batch)
+
+		_, err = br.Exec()
+		var pgErr *pgconn.PgError
+		require.ErrorAs(t, err, &pgErr)
+		require.Equal(t, "23505", pgErr.Code)
+
+		require.Error(t, br.Close())
+
+		ensureConnValid(t, conn)
+	})
+}
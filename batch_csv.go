@@ -0,0 +1,99 @@
+package pgx
+
+import (
+	"encoding/csv"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// BatchCSVOptions configures BatchItemToCSVWithOptions.
+type BatchCSVOptions struct {
+	// Null is written for NULL values. The default is an empty field.
+	Null string
+}
+
+// BatchItemToCSV reads the results of the next query in the batch and writes them to w. The first record is a header
+// with the column names. Each row is written as a record of the text format of its values. NULL values are written as
+// empty fields. Values received in the binary format are converted to text by the connection's type map so their
+// formatting may differ slightly from the server's text output. w is flushed before returning. It returns the number of
+// rows written.
+func BatchItemToCSV(br BatchResults, w *csv.Writer) (int64, error) {
+	return BatchItemToCSVWithOptions(br, w, BatchCSVOptions{})
+}
+
+// BatchItemToCSVWithOptions is like BatchItemToCSV but it is configured by opts.
+func BatchItemToCSVWithOptions(br BatchResults, w *csv.Writer, opts BatchCSVOptions) (int64, error) {
+	rows, err := br.Query()
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	fds := rows.FieldDescriptions()
+	record := make([]string, len(fds))
+	for i, fd := range fds {
+		record[i] = fd.Name
+	}
+	err = w.Write(record)
+	if err != nil {
+		return 0, err
+	}
+
+	var typeMap *pgtype.Map
+	if conn := rows.Conn(); conn != nil {
+		typeMap = conn.TypeMap()
+	} else {
+		typeMap = pgtype.NewMap()
+	}
+
+	var rowCount int64
+	var buf []byte
+	for rows.Next() {
+		for i, src := range rows.RawValues() {
+			if src == nil {
+				record[i] = opts.Null
+				continue
+			}
+			if fds[i].Format == TextFormatCode {
+				record[i] = string(src)
+				continue
+			}
+
+			buf, err = binaryToText(typeMap, fds[i].DataTypeOID, src, buf[:0])
+			if err != nil {
+				return rowCount, fmt.Errorf("BatchItemToCSV: column %s: %w", fds[i].Name, err)
+			}
+			record[i] = string(buf)
+		}
+
+		err = w.Write(record)
+		if err != nil {
+			return rowCount, err
+		}
+		rowCount++
+	}
+
+	rows.Close()
+	if rows.Err() != nil {
+		return rowCount, rows.Err()
+	}
+
+	w.Flush()
+	return rowCount, w.Error()
+}
+
+// binaryToText appends the text format of the binary format value src of type oid to buf.
+func binaryToText(m *pgtype.Map, oid uint32, src, buf []byte) ([]byte, error) {
+	dt, ok := m.TypeForOID(oid)
+	if !ok {
+		return nil, fmt.Errorf("unknown type OID %d", oid)
+	}
+
+	value, err := dt.Codec.DecodeValue(m, oid, BinaryFormatCode, src)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.Encode(oid, TextFormatCode, value, buf)
+}
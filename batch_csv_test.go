@@ -0,0 +1,64 @@
+package pgx_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxtest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchItemToCSV(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		batch := &pgx.Batch{}
+		batch.Queue("select n, 'name ' || n as name, case when n = 2 then null else n * 1.5 end as score, n % 2 = 0 as even from generate_series(1, 3) n")
+		batch.Queue("select 'a,b' as s, null::text as t")
+		batch.Queue("select 1 where false")
+
+		br := conn.SendBatch(ctx, batch)
+
+		var buf bytes.Buffer
+		w := csv.NewWriter(&buf)
+		n, err := pgx.BatchItemToCSV(br, w)
+		require.NoError(t, err)
+		require.EqualValues(t, 3, n)
+		require.Equal(t, "n,name,score,even\n1,name 1,1.5,f\n2,name 2,,t\n3,name 3,4.5,f\n", buf.String())
+
+		buf.Reset()
+		n, err = pgx.BatchItemToCSVWithOptions(br, w, pgx.BatchCSVOptions{Null: `\N`})
+		require.NoError(t, err)
+		require.EqualValues(t, 1, n)
+		require.Equal(t, "s,t\n\"a,b\",\\N\n", buf.String())
+
+		buf.Reset()
+		n, err = pgx.BatchItemToCSV(br, w)
+		require.NoError(t, err)
+		require.EqualValues(t, 0, n)
+		require.Equal(t, "?column?\n", buf.String())
+
+		require.NoError(t, br.Close())
+	})
+}
+
+func TestBatchItemToCSVQueryError(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		batch := &pgx.Batch{}
+		batch.Queue("select n, 10 / (2 - n) from generate_series(1, 3) n")
+
+		br := conn.SendBatch(ctx, batch)
+
+		var buf bytes.Buffer
+		_, err := pgx.BatchItemToCSV(br, csv.NewWriter(&buf))
+		require.Error(t, err)
+
+		require.Error(t, br.Close())
+		ensureConnValid(t, conn)
+	})
+}
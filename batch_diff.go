@@ -0,0 +1,70 @@
+package pgx
+
+import "reflect"
+
+// BatchDiff describes a difference between a query that was queued and the query that was executed at the same
+// position in the batch.
+type BatchDiff struct {
+	// Index is the position of the query in the batch.
+	Index int
+
+	IntendedSQL  string
+	IntendedArgs []any
+	ExecutedSQL  string
+	ExecutedArgs []any
+
+	// Missing is true if there was no executed query at Index.
+	Missing bool
+
+	// Unexpected is true if there was no intended query at Index.
+	Unexpected bool
+}
+
+// DiffBatch compares the queries that were intended to be run with the queries that were executed as recorded by
+// BatchTracer.TraceBatchQuery. It returns a BatchDiff for each position where the SQL or arguments differ. Arguments
+// are compared with reflect.DeepEqual. A nil result means that they are identical.
+//
+// intended is typically obtained by calling Batch.QueuedQueries before the batch is sent. This makes the effect of
+// query rewriters such as NamedArgs visible.
+func DiffBatch(intended []QueuedQuery, executed []TraceBatchQueryData) []BatchDiff {
+	var diffs []BatchDiff
+
+	n := len(intended)
+	if len(executed) > n {
+		n = len(executed)
+	}
+
+	for i := 0; i < n; i++ {
+		diff := BatchDiff{Index: i}
+		if i < len(intended) {
+			diff.IntendedSQL = intended[i].query
+			diff.IntendedArgs = intended[i].arguments
+		} else {
+			diff.Unexpected = true
+		}
+		if i < len(executed) {
+			diff.ExecutedSQL = executed[i].SQL
+			diff.ExecutedArgs = executed[i].Args
+		} else {
+			diff.Missing = true
+		}
+
+		if diff.Missing || diff.Unexpected || diff.IntendedSQL != diff.ExecutedSQL || !batchArgsEqual(diff.IntendedArgs, diff.ExecutedArgs) {
+			diffs = append(diffs, diff)
+		}
+	}
+
+	return diffs
+}
+
+func batchArgsEqual(a, b []any) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !reflect.DeepEqual(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,72 @@
+package pgx_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxtest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffBatch(t *testing.T) {
+	t.Parallel()
+
+	batch := &pgx.Batch{}
+	batch.Queue("select $1::int4", 1)
+	batch.Queue("select $1::text", "a")
+	batch.Queue("select 3")
+	intended := batch.QueuedQueries()
+
+	executed := []pgx.TraceBatchQueryData{
+		{SQL: "select $1::int4", Args: []any{1}},
+		{SQL: "select $1::text", Args: []any{"b"}},
+	}
+
+	diffs := pgx.DiffBatch(intended, executed)
+	require.Equal(t, []pgx.BatchDiff{
+		{Index: 1, IntendedSQL: "select $1::text", IntendedArgs: []any{"a"}, ExecutedSQL: "select $1::text", ExecutedArgs: []any{"b"}},
+		{Index: 2, IntendedSQL: "select 3", Missing: true},
+	}, diffs)
+
+	executed = append(executed[:1], pgx.TraceBatchQueryData{SQL: "select $1::text", Args: []any{"a"}}, pgx.TraceBatchQueryData{SQL: "select 3"}, pgx.TraceBatchQueryData{SQL: "select 4"})
+	diffs = pgx.DiffBatch(intended, executed)
+	require.Equal(t, []pgx.BatchDiff{{Index: 3, ExecutedSQL: "select 4", Unexpected: true}}, diffs)
+
+	require.Nil(t, pgx.DiffBatch(intended, executed[:3]))
+}
+
+func TestDiffBatchShowsQueryRewriterChanges(t *testing.T) {
+	t.Parallel()
+
+	tracer := &testTracer{}
+
+	ctr := defaultConnTestRunner
+	ctr.CreateConfig = func(ctx context.Context, t testing.TB) *pgx.ConnConfig {
+		config := defaultConnTestRunner.CreateConfig(ctx, t)
+		config.Tracer = tracer
+		return config
+	}
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, ctr, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		var executed []pgx.TraceBatchQueryData
+		tracer.traceBatchQuery = func(ctx context.Context, conn *pgx.Conn, data pgx.TraceBatchQueryData) {
+			executed = append(executed, data)
+		}
+
+		batch := &pgx.Batch{}
+		batch.Queue("select 1")
+		batch.Queue("select @n::int4", pgx.NamedArgs{"n": 2})
+		intended := batch.QueuedQueries()
+
+		err := conn.SendBatch(ctx, batch).Close()
+		require.NoError(t, err)
+
+		diffs := pgx.DiffBatch(intended, executed)
+		require.Len(t, diffs, 1)
+		require.Equal(t, 1, diffs[0].Index)
+		require.Equal(t, "select @n::int4", diffs[0].IntendedSQL)
+		require.Equal(t, "select $1::int4", diffs[0].ExecutedSQL)
+		require.Equal(t, []any{2}, diffs[0].ExecutedArgs)
+	})
+}
@@ -0,0 +1,55 @@
+package pgx
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// BatchQueryError is returned when the server reports an error for a query in a batch. It identifies the query that
+// failed so the caller does not have to count the results that were read. A query canceled by statement_timeout
+// returns a *BatchStatementTimeoutError instead.
+type BatchQueryError struct {
+	// Index is the index of the query in the batch.
+	Index int
+
+	// SQL and Args are the SQL and arguments of the query. They may have been modified by a QueryRewriter when the
+	// batch was sent.
+	SQL  string
+	Args []any
+
+	// Err is the error returned by the server. It contains a *pgconn.PgError.
+	Err error
+}
+
+func (e *BatchQueryError) Error() string {
+	return fmt.Sprintf("batch item %d: %v", e.Index, e.Err)
+}
+
+func (e *BatchQueryError) Unwrap() error {
+	return e.Err
+}
+
+// wrapBatchItemErr wraps err, which was returned for the batch query at itemIndex, in a *BatchStatementTimeoutError if
+// it was caused by statement_timeout or in a *BatchQueryError if it is any other error reported by the server. Other
+// errors, such as network errors, and errors that already identify a query are returned unchanged.
+func wrapBatchItemErr(itemIndex int, sql string, args []any, err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+
+	var queryErr *BatchQueryError
+	if errors.As(err, &queryErr) {
+		return err
+	}
+	if _, ok := IsBatchStatementTimeout(err); ok {
+		return err
+	}
+
+	if isStatementTimeout(pgErr) {
+		return &BatchStatementTimeoutError{ItemIndex: itemIndex, SQL: sql, Err: err}
+	}
+	return &BatchQueryError{Index: itemIndex, SQL: sql, Args: args, Err: err}
+}
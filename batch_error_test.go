@@ -0,0 +1,77 @@
+package pgx_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxtest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnSendBatchQueryErrorIdentifiesItem(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		batch := &pgx.Batch{}
+		batch.Queue("select $1::int4", 1)
+		batch.Queue("select $1::int4 / 0", 2)
+		batch.Queue("select 3")
+
+		br := conn.SendBatch(ctx, batch)
+
+		_, err := br.Exec()
+		require.NoError(t, err)
+
+		_, err = br.Exec()
+		var queryErr *pgx.BatchQueryError
+		require.ErrorAs(t, err, &queryErr)
+		require.Equal(t, 1, queryErr.Index)
+		require.Equal(t, "select $1::int4 / 0", queryErr.SQL)
+		require.Equal(t, []any{2}, queryErr.Args)
+		require.ErrorContains(t, err, "batch item 1: ")
+
+		var pgErr *pgconn.PgError
+		require.ErrorAs(t, err, &pgErr)
+		require.Equal(t, "22012", pgErr.Code)
+
+		err = br.Close()
+		require.ErrorAs(t, err, &queryErr)
+		require.Equal(t, 1, queryErr.Index)
+
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestConnSendBatchQueryErrorIdentifiesItemReadingRows(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		batch := &pgx.Batch{}
+		batch.Queue("select 1")
+		batch.Queue("select 10 / (3 - n) from generate_series(1, 5) n")
+
+		br := conn.SendBatch(ctx, batch)
+
+		_, err := br.Exec()
+		require.NoError(t, err)
+
+		rows, err := br.Query()
+		if err == nil {
+			for rows.Next() {
+			}
+			err = rows.Err()
+		}
+		var queryErr *pgx.BatchQueryError
+		require.ErrorAs(t, err, &queryErr)
+		require.Equal(t, 1, queryErr.Index)
+
+		var pgErr *pgconn.PgError
+		require.ErrorAs(t, err, &pgErr)
+		require.Equal(t, "22012", pgErr.Code)
+
+		require.Error(t, br.Close())
+		ensureConnValid(t, conn)
+	})
+}
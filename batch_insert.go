@@ -0,0 +1,147 @@
+package pgx
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// maxExtendedProtocolParams is the maximum number of parameters a single statement may have when using the extended
+// protocol.
+const maxExtendedProtocolParams = 65535
+
+// NewUpsertBatch returns a Batch of multi-row INSERT ... ON CONFLICT statements that insert rows into table. columns
+// are the names of the columns that rows provide values for. Each row must have exactly len(columns) values.
+//
+// conflictCols are the columns of the unique constraint or index that determines a conflict. When a row conflicts with
+// an existing row all columns not in conflictCols are updated from the proposed row (i.e. col = excluded.col). If every
+// column is a conflict column the conflicting row is left unchanged (do nothing).
+//
+// Rows are not deduplicated. When columns are updated the rows in the same statement must not have the same values for
+// conflictCols, as PostgreSQL fails the statement with "ON CONFLICT DO UPDATE command cannot affect row a second time"
+// (SQLSTATE 21000). Remove such duplicates before calling NewUpsertBatch, or pass a rowsPerStatement of 1 to apply them
+// in order.
+//
+// rowsPerStatement is the maximum number of rows included in each statement. If rowsPerStatement is <= 0 or would cause
+// a statement to exceed the 65535 parameter limit of the extended protocol then as many rows as the limit allows are
+// used.
+//
+// NewUpsertBatch panics if columns or conflictCols is empty or if a row does not have len(columns) values.
+func NewUpsertBatch(table Identifier, columns []string, conflictCols []string, rows [][]any, rowsPerStatement int) *Batch {
+	if len(columns) == 0 {
+		panic("NewUpsertBatch: columns must not be empty")
+	}
+	if len(conflictCols) == 0 {
+		panic("NewUpsertBatch: conflictCols must not be empty")
+	}
+
+	var suffix strings.Builder
+	suffix.WriteString(" on conflict (")
+	conflictSet := make(map[string]struct{}, len(conflictCols))
+	for i, col := range conflictCols {
+		if i > 0 {
+			suffix.WriteString(", ")
+		}
+		suffix.WriteString(quoteIdentifier(col))
+		conflictSet[col] = struct{}{}
+	}
+	suffix.WriteString(") do ")
+
+	updateCount := 0
+	for _, col := range columns {
+		if _, ok := conflictSet[col]; ok {
+			continue
+		}
+		if updateCount == 0 {
+			suffix.WriteString("update set ")
+		} else {
+			suffix.WriteString(", ")
+		}
+		quotedCol := quoteIdentifier(col)
+		suffix.WriteString(quotedCol)
+		suffix.WriteString(" = excluded.")
+		suffix.WriteString(quotedCol)
+		updateCount++
+	}
+	if updateCount == 0 {
+		suffix.WriteString("nothing")
+	}
+
+	b := &Batch{}
+	queueMultiRowInserts(b, "NewUpsertBatch", table, columns, rows, rowsPerStatement, suffix.String())
+	return b
+}
+
+// QueueInsertRows queues to b multi-row INSERT statements that insert rows into table. columns are the names of the
+// columns that rows provide values for. Each row must have exactly len(columns) values. This is a middle ground between
+// queueing an INSERT per row and CopyFrom: the rows are inserted by as few statements as possible, but the statements
+// can be combined with other queries in the batch and support ordinary INSERT semantics such as triggers and defaults.
+//
+// A statement is limited to 65535 parameters with the extended protocol, so the rows are split into as many statements
+// as needed. The queued queries are returned in order so callbacks can be set on them. No query is queued if rows is
+// empty.
+//
+// QueueInsertRows panics if columns is empty or if a row does not have len(columns) values.
+func (b *Batch) QueueInsertRows(table Identifier, columns []string, rows [][]any) []*QueuedQuery {
+	if len(columns) == 0 {
+		panic("QueueInsertRows: columns must not be empty")
+	}
+
+	return queueMultiRowInserts(b, "QueueInsertRows", table, columns, rows, 0, "")
+}
+
+// queueMultiRowInserts queues to b multi-row INSERT statements for rows and returns the queued queries. Each statement
+// has at most rowsPerStatement rows and is terminated with suffix. caller is used in panic messages.
+func queueMultiRowInserts(b *Batch, caller string, table Identifier, columns []string, rows [][]any, rowsPerStatement int, suffix string) []*QueuedQuery {
+	maxRows := maxExtendedProtocolParams / len(columns)
+	if rowsPerStatement <= 0 || rowsPerStatement > maxRows {
+		rowsPerStatement = maxRows
+	}
+
+	var prefix strings.Builder
+	prefix.WriteString("insert into ")
+	prefix.WriteString(table.Sanitize())
+	prefix.WriteString(" (")
+	for i, col := range columns {
+		if i > 0 {
+			prefix.WriteString(", ")
+		}
+		prefix.WriteString(quoteIdentifier(col))
+	}
+	prefix.WriteString(") values ")
+
+	var queued []*QueuedQuery
+	for start := 0; start < len(rows); start += rowsPerStatement {
+		end := start + rowsPerStatement
+		if end > len(rows) {
+			end = len(rows)
+		}
+
+		var sb strings.Builder
+		sb.WriteString(prefix.String())
+		args := make([]any, 0, (end-start)*len(columns))
+		for i, row := range rows[start:end] {
+			if len(row) != len(columns) {
+				panic(fmt.Sprintf("%s: row %d has %d values, expected %d", caller, start+i, len(row), len(columns)))
+			}
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			sb.WriteByte('(')
+			for j := range row {
+				if j > 0 {
+					sb.WriteString(", ")
+				}
+				sb.WriteByte('$')
+				sb.WriteString(strconv.Itoa(len(args) + j + 1))
+			}
+			sb.WriteByte(')')
+			args = append(args, row...)
+		}
+		sb.WriteString(suffix)
+
+		queued = append(queued, b.Queue(sb.String(), args...))
+	}
+
+	return queued
+}
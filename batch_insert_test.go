@@ -0,0 +1,143 @@
+package pgx_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxtest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewUpsertBatch(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		mustExec(t, conn, `create temporary table widgets(
+	id int primary key,
+	name text not null,
+	qty int not null
+);`)
+		mustExec(t, conn, `insert into widgets(id, name, qty) values (1, 'old', 0)`)
+
+		rows := [][]any{
+			{1, "a", 10},
+			{2, "b", 20},
+			{3, "c", 30},
+			{4, "d", 40},
+			{5, "e", 50},
+		}
+
+		batch := pgx.NewUpsertBatch(pgx.Identifier{"widgets"}, []string{"id", "name", "qty"}, []string{"id"}, rows, 2)
+		require.Equal(t, 3, batch.Len())
+
+		err := conn.SendBatch(ctx, batch).Close()
+		require.NoError(t, err)
+
+		type widget struct {
+			ID   int32
+			Name string
+			Qty  int32
+		}
+
+		dbRows, _ := conn.Query(ctx, "select id, name, qty from widgets order by id")
+		widgets, err := pgx.CollectRows(dbRows, pgx.RowToStructByPos[widget])
+		require.NoError(t, err)
+		require.Equal(t, []widget{{1, "a", 10}, {2, "b", 20}, {3, "c", 30}, {4, "d", 40}, {5, "e", 50}}, widgets)
+	})
+}
+
+func TestNewUpsertBatchAllConflictColumns(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		mustExec(t, conn, `create temporary table tags(name text primary key);`)
+		mustExec(t, conn, `insert into tags(name) values ('a')`)
+
+		batch := pgx.NewUpsertBatch(pgx.Identifier{"tags"}, []string{"name"}, []string{"name"}, [][]any{{"a"}, {"b"}}, 0)
+		require.Equal(t, 1, batch.Len())
+
+		br := conn.SendBatch(ctx, batch)
+		ct, err := br.Exec()
+		require.NoError(t, err)
+		require.EqualValues(t, 1, ct.RowsAffected())
+		require.NoError(t, br.Close())
+	})
+}
+
+func TestNewUpsertBatchDuplicateConflictKeys(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		mustExec(t, conn, `create temporary table widgets(id int primary key, name text not null);`)
+
+		rows := [][]any{{1, "a"}, {1, "b"}}
+
+		// Duplicate conflict keys in the same statement fail.
+		batch := pgx.NewUpsertBatch(pgx.Identifier{"widgets"}, []string{"id", "name"}, []string{"id"}, rows, 0)
+		require.Equal(t, 1, batch.Len())
+		err := conn.SendBatch(ctx, batch).Close()
+		var pgErr *pgconn.PgError
+		require.ErrorAs(t, err, &pgErr)
+		require.Equal(t, "21000", pgErr.Code)
+
+		// One row per statement applies them in order.
+		batch = pgx.NewUpsertBatch(pgx.Identifier{"widgets"}, []string{"id", "name"}, []string{"id"}, rows, 1)
+		require.Equal(t, 2, batch.Len())
+		require.NoError(t, conn.SendBatch(ctx, batch).Close())
+
+		var name string
+		require.NoError(t, conn.QueryRow(ctx, "select name from widgets where id = 1").Scan(&name))
+		require.Equal(t, "b", name)
+	})
+}
+
+func TestNewUpsertBatchPanicsOnMismatchedRow(t *testing.T) {
+	t.Parallel()
+
+	require.Panics(t, func() {
+		pgx.NewUpsertBatch(pgx.Identifier{"widgets"}, []string{"id", "name"}, []string{"id"}, [][]any{{1}}, 0)
+	})
+}
+
+func TestBatchQueueInsertRows(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		mustExec(t, conn, `create temporary table insert_rows(id int primary key, name text)`)
+
+		batch := &pgx.Batch{}
+		queued := batch.QueueInsertRows(pgx.Identifier{"insert_rows"}, []string{"id", "name"}, [][]any{{1, "a"}, {2, nil}, {3, "c"}})
+		require.Len(t, queued, 1)
+		batch.Queue("select count(*) from insert_rows")
+
+		br := conn.SendBatch(ctx, batch)
+		ct, err := br.Exec()
+		require.NoError(t, err)
+		require.EqualValues(t, 3, ct.RowsAffected())
+
+		var n int64
+		require.NoError(t, br.QueryRow().Scan(&n))
+		require.EqualValues(t, 3, n)
+		require.NoError(t, br.Close())
+	})
+}
+
+func TestBatchQueueInsertRowsSplitsAtParameterLimit(t *testing.T) {
+	t.Parallel()
+
+	rows := make([][]any, 40000)
+	for i := range rows {
+		rows[i] = []any{i, "name"}
+	}
+
+	batch := &pgx.Batch{}
+	queued := batch.QueueInsertRows(pgx.Identifier{"insert_rows"}, []string{"id", "name"}, rows)
+	require.Len(t, queued, 2)
+	require.Len(t, queued[0].Arguments(), 65534)
+	require.Len(t, queued[1].Arguments(), 80000-65534)
+
+	require.Empty(t, batch.QueueInsertRows(pgx.Identifier{"insert_rows"}, []string{"id"}, nil))
+	require.Panics(t, func() { batch.QueueInsertRows(pgx.Identifier{"insert_rows"}, nil, nil) })
+}
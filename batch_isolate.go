@@ -0,0 +1,125 @@
+package pgx
+
+import (
+	"context"
+)
+
+// Statements used to isolate the queries of a batch sent with SendBatchOptions.IsolateErrors.
+const (
+	isolateSavepointSQL         = "savepoint pgx_batch_item"
+	isolateReleaseSavepointSQL  = "release savepoint pgx_batch_item"
+	isolateRollbackSavepointSQL = "rollback to savepoint pgx_batch_item"
+)
+
+// sendBatchIsolated sends b with each query wrapped in a savepoint. When a query fails the rest of the batch is not run
+// by the server, so the connection is rolled back to the savepoint of the failed query and the queries after it are
+// sent again. The results of all queries are combined into a single bufferedBatchResults.
+func (c *Conn) sendBatchIsolated(ctx context.Context, b *Batch) sentBatchResults {
+	combined := &bufferedBatchResults{
+		typeMap: c.typeMap,
+		b:       b,
+		results: make([]bufferedResult, len(b.queuedQueries)),
+		inTx:    c.pgConn.TxStatus() != 'I',
+	}
+
+	if b.Options.Tx != nil && combined.inTx {
+		combined.closeErr = errBatchTxInTransaction
+		combined.setUnsent(0, errBatchTxInTransaction)
+		return combined
+	}
+
+	var tx Tx
+	if !combined.inTx {
+		var txOptions TxOptions
+		if b.Options.Tx != nil {
+			txOptions = *b.Options.Tx
+		}
+
+		var err error
+		tx, err = c.BeginTx(ctx, txOptions)
+		if err != nil {
+			combined.closeErr = err
+			combined.setUnsent(0, err)
+			return combined
+		}
+		defer func() {
+			_ = tx.Rollback(ctx) // does nothing if the transaction was committed. Otherwise there is already an error to return.
+		}()
+	}
+
+	options := b.Options
+	options.Buffered = true
+	options.IsolateErrors = false
+	options.MaxChunkQueries = 0
+	options.MaxChunkBytes = 0
+	options.Tx = nil
+
+	for start := 0; start < len(b.queuedQueries); {
+		attempt := &Batch{
+			applicationName: b.applicationName,
+			readOnly:        b.readOnly,
+			Options:         options,
+		}
+		// Each query is sent with the statements that create and release its savepoint.
+		flushedBefore := start
+		attempt.Options.OnFlush = func(itemsFlushed int) { b.notifyFlush(flushedBefore + itemsFlushed/3) }
+		for _, qq := range b.queuedQueries[start:] {
+			qq.sd = nil // the statement description from an earlier attempt may be stale
+			attempt.queuedQueries = append(attempt.queuedQueries, &QueuedQuery{query: isolateSavepointSQL}, qq, &QueuedQuery{query: isolateReleaseSavepointSQL})
+		}
+
+		br := c.sendBatchInto(ctx, attempt, nil)
+		if err := br.earlyError(); err != nil {
+			br.Close()
+			combined.closeErr = err
+			combined.setUnsent(start, err)
+			return combined
+		}
+		bbr := br.(*bufferedBatchResults)
+
+		failed := -1
+		for i := start; i < len(b.queuedQueries); i++ {
+			attemptIdx := 3 * (i - start)
+			if err := bbr.results[attemptIdx].err(); err != nil {
+				combined.results[i] = bufferedResult{queryErr: err}
+				failed = i
+				break
+			}
+			combined.setResult(i, bbr, attemptIdx+1)
+			if combined.results[i].err() != nil {
+				failed = i
+				break
+			}
+			if err := bbr.results[attemptIdx+2].err(); err != nil {
+				combined.results[i] = bufferedResult{queryErr: err}
+				failed = i
+				break
+			}
+		}
+
+		if failed < 0 {
+			if bbr.closeErr != nil {
+				combined.closeErr = bbr.closeErr
+				return combined
+			}
+			break
+		}
+
+		_, err := c.Exec(ctx, isolateRollbackSavepointSQL)
+		if err != nil {
+			combined.closeErr = err
+			combined.setUnsent(failed+1, err)
+			return combined
+		}
+		start = failed + 1
+	}
+
+	if tx != nil {
+		err := tx.Commit(ctx)
+		if err != nil {
+			combined.closeErr = err
+		}
+	}
+
+	return combined
+}
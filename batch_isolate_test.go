@@ -0,0 +1,100 @@
+package pgx_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxtest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnSendBatchIsolateErrors(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		pgxtest.SkipCockroachDB(t, conn, "Server does not support savepoints in the same way")
+
+		_, err := conn.Exec(ctx, "create temporary table isolate_errors (id int primary key)")
+		require.NoError(t, err)
+
+		batch := &pgx.Batch{}
+		batch.Options.IsolateErrors = true
+		batch.Queue("insert into isolate_errors (id) values (1)")
+		batch.Queue("insert into isolate_errors (id) values (1)")
+		batch.Queue("insert into isolate_errors (id) values (2)")
+		batch.Queue("select 1/0")
+		batch.Queue("select count(*) from isolate_errors")
+
+		br := conn.SendBatch(ctx, batch)
+
+		_, err = br.Exec()
+		require.NoError(t, err)
+
+		_, err = br.Exec()
+		var pgErr *pgconn.PgError
+		require.ErrorAs(t, err, &pgErr)
+		require.Equal(t, "23505", pgErr.Code)
+
+		_, err = br.Exec()
+		require.NoError(t, err)
+
+		_, err = br.Exec()
+		require.ErrorAs(t, err, &pgErr)
+		require.Equal(t, "22012", pgErr.Code)
+
+		var n int64
+		require.NoError(t, br.QueryRow().Scan(&n))
+		require.EqualValues(t, 2, n)
+
+		require.NoError(t, br.Close())
+		failed, err := pgx.BatchFailedItems(br)
+		require.NoError(t, err)
+		require.Len(t, failed.QueuedQueries(), 2)
+
+		err = conn.QueryRow(ctx, "select count(*) from isolate_errors").Scan(&n)
+		require.NoError(t, err)
+		require.EqualValues(t, 2, n)
+
+		require.EqualValues(t, 'I', conn.PgConn().TxStatus())
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestConnSendBatchIsolateErrorsInTransaction(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		pgxtest.SkipCockroachDB(t, conn, "Server does not support savepoints in the same way")
+
+		tx, err := conn.Begin(ctx)
+		require.NoError(t, err)
+		defer tx.Rollback(ctx)
+
+		_, err = tx.Exec(ctx, "create temporary table isolate_errors_tx (id int primary key)")
+		require.NoError(t, err)
+
+		batch := &pgx.Batch{}
+		batch.Options.IsolateErrors = true
+		batch.Queue("insert into isolate_errors_tx (id) values (1)")
+		batch.Queue("insert into isolate_errors_tx (id) values (1)")
+		batch.Queue("insert into isolate_errors_tx (id) values (2)")
+
+		br := tx.SendBatch(ctx, batch)
+		_, err = br.Exec()
+		require.NoError(t, err)
+		_, err = br.Exec()
+		require.Error(t, err)
+		_, err = br.Exec()
+		require.NoError(t, err)
+		require.NoError(t, br.Close())
+
+		require.EqualValues(t, 'T', conn.PgConn().TxStatus())
+
+		var n int64
+		err = tx.QueryRow(ctx, "select count(*) from isolate_errors_tx").Scan(&n)
+		require.NoError(t, err)
+		require.EqualValues(t, 2, n)
+	})
+}
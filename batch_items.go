@@ -0,0 +1,119 @@
+package pgx
+
+import (
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// BatchItem is a handle to the results of a single query of a batch. It is yielded by BatchItems. The results
+// can only be read once with one of Exec, Query, or QueryRow and only until the iteration continues to the next item.
+type BatchItem struct {
+	br    BatchResults
+	index int
+	read  bool
+	done  bool
+}
+
+// Index returns the index of the query in the batch.
+func (item *BatchItem) Index() int {
+	return item.index
+}
+
+// Exec reads the results of the query as if the query has been sent with Conn.Exec.
+func (item *BatchItem) Exec() (pgconn.CommandTag, error) {
+	if err := item.markRead(); err != nil {
+		return pgconn.CommandTag{}, err
+	}
+	return item.br.Exec()
+}
+
+// Query reads the results of the query as if the query has been sent with Conn.Query. The returned Rows must be closed
+// before the iteration continues. Use CollectRows or a similar function to read all rows and close them.
+func (item *BatchItem) Query() (Rows, error) {
+	if err := item.markRead(); err != nil {
+		return &baseRows{err: err, closed: true}, err
+	}
+	return item.br.Query()
+}
+
+// QueryRow reads the results of the query as if the query has been sent with Conn.QueryRow.
+func (item *BatchItem) QueryRow() Row {
+	if err := item.markRead(); err != nil {
+		return rowsToRow(&baseRows{err: err, closed: true})
+	}
+	return item.br.QueryRow()
+}
+
+func (item *BatchItem) markRead() error {
+	if item.done {
+		return fmt.Errorf("batch item %d: iteration has continued past the item", item.index)
+	}
+	if item.read {
+		return fmt.Errorf("batch item %d: results have already been read", item.index)
+	}
+	item.read = true
+	return nil
+}
+
+// BatchItems returns an iterator over the results of the remaining queries in br in queue order. Each yielded BatchItem
+// reads the results of one query with its Exec, Query, or QueryRow methods, so results cannot be attributed to the
+// wrong query. The results of an item that are not read before the iteration continues are discarded without calling
+// the callback function of the query. br is closed when the iteration ends, including when the loop body breaks out
+// early. Call Close afterwards to get the error, if any, from closing it. No items are yielded if the batch could not
+// be sent.
+//
+// The iterator has the signature of iter.Seq from Go 1.23, so it can be used with a range over function loop:
+//
+//	for item := range pgx.BatchItems(br) {
+//		rows, err := item.Query()
+//		// ...
+//	}
+//	err := br.Close()
+func BatchItems(br BatchResults) func(yield func(*BatchItem) bool) {
+	if r, ok := batchResultsAs[interface {
+		Items() func(yield func(*BatchItem) bool)
+	}](br); ok {
+		return r.Items()
+	}
+	return batchItems(br, nil, 0)
+}
+
+// batchItems returns an iterator over the results of the queries of b from qqIdx to the end that are read from br. br
+// is closed when the iteration ends.
+func batchItems(br BatchResults, b *Batch, qqIdx int) func(yield func(*BatchItem) bool) {
+	return func(yield func(*BatchItem) bool) {
+		defer br.Close()
+
+		if b == nil {
+			return
+		}
+
+		for i := qqIdx; i < len(b.queuedQueries); i++ {
+			item := &BatchItem{br: br, index: i}
+			more := yield(item)
+			item.done = true
+			if !item.read {
+				BatchDiscardNext(br)
+			}
+			if !more {
+				return
+			}
+		}
+	}
+}
+
+// Items returns an iterator over the results of the remaining queries in the batch.
+func (br *batchResults) Items() func(yield func(*BatchItem) bool) {
+	return batchItems(br, br.b, br.qqIdx)
+}
+
+// Items returns an iterator over the results of the remaining queries in the batch.
+func (br *pipelineBatchResults) Items() func(yield func(*BatchItem) bool) {
+	return batchItems(br, br.b, br.qqIdx)
+}
+
+// Items returns an iterator over the results of the remaining queries in the batch.
+func (br *bufferedBatchResults) Items() func(yield func(*BatchItem) bool) {
+	return batchItems(br, br.b, br.qqIdx)
+}
@@ -0,0 +1,76 @@
+package pgx_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxtest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnSendBatchItems(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		batch := &pgx.Batch{}
+		batch.Queue("select n from generate_series(1, 3) n")
+		batch.Queue("select 'skipped'")
+		batch.Queue("select $1::int4", 42)
+
+		br := conn.SendBatch(ctx, batch)
+
+		var indexes []int
+		pgx.BatchItems(br)(func(item *pgx.BatchItem) bool {
+			indexes = append(indexes, item.Index())
+			switch item.Index() {
+			case 0:
+				rows, err := item.Query()
+				require.NoError(t, err)
+				nums, err := pgx.CollectRows(rows, pgx.RowTo[int32])
+				require.NoError(t, err)
+				require.Equal(t, []int32{1, 2, 3}, nums)
+
+				_, err = item.Exec()
+				require.EqualError(t, err, "batch item 0: results have already been read")
+			case 2:
+				var n int32
+				require.NoError(t, item.QueryRow().Scan(&n))
+				require.EqualValues(t, 42, n)
+			}
+			return true
+		})
+		require.Equal(t, []int{0, 1, 2}, indexes)
+		require.NoError(t, br.Close())
+
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestConnSendBatchItemsBreak(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		batch := &pgx.Batch{}
+		batch.Queue("select 1")
+		batch.Queue("select 2")
+		batch.Queue("select 3")
+
+		br := conn.SendBatch(ctx, batch)
+
+		var items []*pgx.BatchItem
+		pgx.BatchItems(br)(func(item *pgx.BatchItem) bool {
+			items = append(items, item)
+			_, err := item.Exec()
+			require.NoError(t, err)
+			return item.Index() < 1
+		})
+		require.Len(t, items, 2)
+
+		_, err := items[0].Exec()
+		require.EqualError(t, err, "batch item 0: iteration has continued past the item")
+
+		// The batch was closed when the iteration ended so the connection can be used.
+		ensureConnValid(t, conn)
+	})
+}
@@ -0,0 +1,62 @@
+package pgx
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// CheckPlaceholders returns an error if the highest positional placeholder such as $3 in the SQL of any query queued
+// in b does not equal the number of arguments queued with it. The error names every mismatched query. Placeholders in
+// comments, string literals, quoted identifiers, and dollar-quoted strings are not counted.
+//
+// Queries that are checked by other means are skipped: queries whose first argument is a QueryRewriter such as
+// NamedArgs because the placeholders are only known once the query is rewritten, and queries queued by the name of a
+// prepared statement. Queries queued with QueueExecParams are checked against the number of parameter values.
+func (b *Batch) CheckPlaceholders() error {
+	var errs []string
+
+	for i, qq := range b.queuedQueries {
+		argCount := len(qq.arguments)
+		if qq.execParams != nil {
+			argCount = len(qq.execParams.paramValues)
+		} else if argCount > 0 {
+			if _, ok := qq.arguments[0].(QueryRewriter); ok {
+				continue
+			}
+		}
+		if isStatementName(qq.query) {
+			continue
+		}
+
+		maxPlaceholder := 0
+		scanSQL(qq.query, nil, func(n int) {
+			if n > maxPlaceholder {
+				maxPlaceholder = n
+			}
+		})
+
+		if maxPlaceholder != argCount {
+			errs = append(errs, fmt.Sprintf("batch item %d: highest placeholder is $%d but %d arguments were queued", i, maxPlaceholder, argCount))
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+
+	return nil
+}
+
+// isStatementName reports whether sql is a single word that may be the name of a prepared statement.
+func isStatementName(sql string) bool {
+	if sql == "" {
+		return false
+	}
+	for _, r := range sql {
+		if !(r == '_' || r == '-' || r == '.' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,74 @@
+package pgx_test
+
+import (
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchCheckPlaceholders(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		sql  string
+		args []any
+		ok   bool
+	}{
+		{"select 1", nil, true},
+		{"select $1, $2", []any{1, 2}, true},
+		{"select $2, $1, $2", []any{1, 2}, true},
+		{"select $1, $2, $3", []any{1, 2}, false},
+		{"select $1", []any{1, 2}, false},
+		{"select '$3', $1", []any{1}, true},
+		{`select "$3", $1`, []any{1}, true},
+		{"select E'it\\'s $3', $1", []any{1}, true},
+		{"select $$ $3 $$, $1", []any{1}, true},
+		{"select $tag$ $3 $tag$, $1", []any{1}, true},
+		{"select 1 -- $3\n, $1", []any{1}, true},
+		{"select /* $3 */ $1", []any{1}, true},
+		{"select @a", []any{pgx.NamedArgs{"a": 1}}, true},
+		{"my_prepared_statement", []any{1, 2}, true},
+	}
+
+	for _, tt := range tests {
+		batch := &pgx.Batch{}
+		batch.Queue(tt.sql, tt.args...)
+		err := batch.CheckPlaceholders()
+		if tt.ok {
+			require.NoErrorf(t, err, "%q", tt.sql)
+		} else {
+			require.Errorf(t, err, "%q", tt.sql)
+		}
+	}
+}
+
+func TestBatchCheckPlaceholdersNamesMismatchedItems(t *testing.T) {
+	t.Parallel()
+
+	batch := &pgx.Batch{}
+	batch.Queue("select $1", 1)
+	batch.Queue("select $1, $2, $3", 1, 2)
+	batch.Queue("select 1", 1)
+	batch.QueueExecParams("select $1::text", [][]byte{[]byte("a")}, nil, nil, nil)
+
+	err := batch.CheckPlaceholders()
+	require.EqualError(t, err, "batch item 1: highest placeholder is $3 but 2 arguments were queued; batch item 2: highest placeholder is $0 but 1 arguments were queued")
+}
+
+func FuzzBatchCheckPlaceholders(f *testing.F) {
+	f.Add("select $1, '$2', $$ $3 $$", 1)
+	f.Add("select E'\\'' /* $1 */ -- $2", 0)
+	f.Add("$", 0)
+	f.Add("$a$", 0)
+
+	f.Fuzz(func(t *testing.T, sql string, argCount int) {
+		if argCount < 0 || argCount > 100 {
+			return
+		}
+		batch := &pgx.Batch{}
+		batch.Queue(sql, make([]any, argCount)...)
+		batch.CheckPlaceholders()
+		batch.Classify()
+	})
+}
@@ -0,0 +1,96 @@
+package pgx
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/internal/stmtcache"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// PrepareBatch prepares the queries queued in b without executing them and adds their descriptions to the statement
+// cache used by DefaultQueryExecMode. All queries that are not already cached are prepared in a single round trip.
+// This can be used to warm the cache with the hot queries of an application when a connection is established, e.g. in
+// pgxpool.Config.AfterConnect, so the first batch or query that uses them does not pay for the extra round trip.
+//
+// Each queued query is rewritten by its QueryRewriter, such as NamedArgs, as it would be when sent, but b is not
+// modified and can be sent afterwards. Queries queued with QueueExecParams or QueueCopyFrom and queries that name a
+// statement prepared with Prepare are skipped. PrepareBatch does nothing if DefaultQueryExecMode does not cache
+// statements or descriptions, i.e. it is not QueryExecModeCacheStatement or QueryExecModeCacheDescribe.
+func (c *Conn) PrepareBatch(ctx context.Context, b *Batch) error {
+	var sdCache stmtcache.Cache
+	switch c.config.DefaultQueryExecMode {
+	case QueryExecModeCacheStatement:
+		if c.statementCache == nil {
+			return errDisabledStatementCache
+		}
+		sdCache = c.statementCache
+	case QueryExecModeCacheDescribe:
+		if c.descriptionCache == nil {
+			return errDisabledDescriptionCache
+		}
+		sdCache = c.descriptionCache
+	default:
+		return nil
+	}
+
+	if c.batchInFlight {
+		return ErrConnBusy
+	}
+
+	if err := c.deallocateInvalidatedCachedStatements(ctx); err != nil {
+		return err
+	}
+
+	distinctNewQueries := []*pgconn.StatementDescription{}
+	seen := make(map[string]struct{})
+
+	for _, bi := range b.queuedQueries {
+		if bi.execParams != nil || bi.copyFrom != nil {
+			continue
+		}
+
+		sql, _, err := c.rewriteQueuedQuery(ctx, bi)
+		if err != nil {
+			return err
+		}
+
+		if _, ok := c.preparedStatements[sql]; ok {
+			continue
+		}
+		if sdCache.Get(sql) != nil {
+			continue
+		}
+		if _, present := seen[sql]; present {
+			continue
+		}
+
+		sd := &pgconn.StatementDescription{SQL: sql}
+		if c.config.DefaultQueryExecMode == QueryExecModeCacheStatement {
+			sd.Name = stmtcache.NextStatementName()
+		}
+		seen[sql] = struct{}{}
+		distinctNewQueries = append(distinctNewQueries, sd)
+	}
+
+	if len(distinctNewQueries) == 0 {
+		return nil
+	}
+
+	pipeline := c.pgConn.StartPipeline(ctx)
+	err := prepareDistinctQueries(pipeline, distinctNewQueries)
+	if err != nil {
+		pipeline.Close()
+		return err
+	}
+
+	err = pipeline.Close()
+	if err != nil {
+		return err
+	}
+
+	for _, sd := range distinctNewQueries {
+		sdCache.Put(sd)
+	}
+
+	return nil
+}
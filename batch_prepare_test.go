@@ -0,0 +1,69 @@
+package pgx_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxtest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnPrepareBatch(t *testing.T) {
+	t.Parallel()
+
+	modes := []pgx.QueryExecMode{
+		pgx.QueryExecModeCacheStatement,
+		pgx.QueryExecModeCacheDescribe,
+		pgx.QueryExecModeDescribeExec,
+	}
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, modes, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		batch := &pgx.Batch{}
+		batch.Queue("select $1::int4 + 1 as prepare_batch", 1)
+		batch.Queue("select @n::int4 + 2 as prepare_batch", pgx.NamedArgs{"n": 1})
+		batch.Queue("select $1::int4 + 1 as prepare_batch", 2)
+
+		err := conn.PrepareBatch(ctx, batch)
+		require.NoError(t, err)
+		require.Equal(t, "select @n::int4 + 2 as prepare_batch", batch.QueuedQueries()[1].SQL())
+
+		var preparedCount int64
+		err = conn.QueryRow(ctx, "select count(*) from pg_prepared_statements where statement like '%as prepare_batch'").Scan(&preparedCount)
+		require.NoError(t, err)
+		if conn.Config().DefaultQueryExecMode == pgx.QueryExecModeCacheStatement {
+			require.EqualValues(t, 2, preparedCount)
+		} else {
+			require.EqualValues(t, 0, preparedCount)
+		}
+
+		br := conn.SendBatch(ctx, batch)
+		for _, expected := range []int32{2, 3, 3} {
+			var n int32
+			require.NoError(t, br.QueryRow().Scan(&n))
+			require.Equal(t, expected, n)
+		}
+		require.NoError(t, br.Close())
+
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestConnPrepareBatchError(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, pgxtest.KnownOIDQueryExecModes, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		batch := &pgx.Batch{}
+		batch.Queue("select 1")
+		batch.Queue("selct 2")
+
+		err := conn.PrepareBatch(ctx, batch)
+		if conn.Config().DefaultQueryExecMode == pgx.QueryExecModeDescribeExec {
+			require.NoError(t, err)
+		} else {
+			require.ErrorContains(t, err, "syntax error")
+		}
+
+		ensureConnValid(t, conn)
+	})
+}
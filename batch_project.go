@@ -0,0 +1,153 @@
+package pgx
+
+import (
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// projectedRows implements Rows over a subset of the columns of another Rows. It is returned by BatchQueryProject.
+type projectedRows struct {
+	rows Rows
+	idxs []int // index in rows of each projected column
+
+	fds       []pgconn.FieldDescription
+	scanDest  []any // reused by Scan. Columns that are not projected are nil so they are skipped.
+	rawValues [][]byte
+	err       error
+}
+
+// BatchQueryProject reads the results from the next query in br like Query but the returned Rows only expose the columns
+// named cols in the order given. The server still sends every column; the other columns are skipped by the client.
+// This allows generic consumers such as RowToStructByName to scan a narrower shape. An error is returned if any of
+// cols is not a column of the result. If there are duplicate column names the first is used.
+func BatchQueryProject(br BatchResults, cols []string) (Rows, error) {
+	if r, ok := batchResultsAs[interface {
+		QueryProject(cols []string) (Rows, error)
+	}](br); ok {
+		return r.QueryProject(cols)
+	}
+	return queryProject(br, cols)
+}
+
+// queryProject reads the results of the next query of br with Query and projects them to the columns named cols.
+func queryProject(br BatchResults, cols []string) (Rows, error) {
+	rows, err := br.Query()
+	if err != nil {
+		return rows, err
+	}
+
+	fds := rows.FieldDescriptions()
+	pr := &projectedRows{
+		rows:     rows,
+		idxs:     make([]int, len(cols)),
+		fds:      make([]pgconn.FieldDescription, len(cols)),
+		scanDest: make([]any, len(fds)),
+	}
+
+colLoop:
+	for i, col := range cols {
+		for j, fd := range fds {
+			if fd.Name == col {
+				pr.idxs[i] = j
+				pr.fds[i] = fd
+				continue colLoop
+			}
+		}
+
+		rows.Close()
+		return nil, fmt.Errorf("QueryProject: column %q not found", col)
+	}
+
+	return pr, nil
+}
+
+func (pr *projectedRows) Close() {
+	pr.rows.Close()
+}
+
+func (pr *projectedRows) Err() error {
+	if pr.err != nil {
+		return pr.err
+	}
+	return pr.rows.Err()
+}
+
+func (pr *projectedRows) CommandTag() pgconn.CommandTag {
+	return pr.rows.CommandTag()
+}
+
+// FieldDescriptions returns the field descriptions of the projected columns.
+func (pr *projectedRows) FieldDescriptions() []pgconn.FieldDescription {
+	return pr.fds
+}
+
+func (pr *projectedRows) Next() bool {
+	return pr.rows.Next()
+}
+
+// Scan reads the values of the projected columns of the current row into dest.
+func (pr *projectedRows) Scan(dest ...any) error {
+	if len(dest) == 1 {
+		if rc, ok := dest[0].(RowScanner); ok {
+			return rc.ScanRow(pr)
+		}
+	}
+
+	if len(dest) != len(pr.idxs) {
+		pr.err = fmt.Errorf("number of field descriptions must equal number of destinations, got %d and %d", len(pr.idxs), len(dest))
+		pr.rows.Close()
+		return pr.err
+	}
+
+	for i, idx := range pr.idxs {
+		pr.scanDest[idx] = dest[i]
+	}
+	err := pr.rows.Scan(pr.scanDest...)
+	for _, idx := range pr.idxs {
+		pr.scanDest[idx] = nil
+	}
+	return err
+}
+
+// Values returns the decoded values of the projected columns of the current row.
+func (pr *projectedRows) Values() ([]any, error) {
+	values, err := pr.rows.Values()
+	if err != nil {
+		return nil, err
+	}
+
+	projected := make([]any, len(pr.idxs))
+	for i, idx := range pr.idxs {
+		projected[i] = values[idx]
+	}
+	return projected, nil
+}
+
+// RawValues returns the unparsed bytes of the projected columns of the current row.
+func (pr *projectedRows) RawValues() [][]byte {
+	rawValues := pr.rows.RawValues()
+	if rawValues == nil {
+		return nil
+	}
+
+	if pr.rawValues == nil {
+		pr.rawValues = make([][]byte, len(pr.idxs))
+	}
+	for i, idx := range pr.idxs {
+		pr.rawValues[i] = rawValues[idx]
+	}
+	return pr.rawValues
+}
+
+func (pr *projectedRows) Conn() *Conn {
+	return pr.rows.Conn()
+}
+
+func (pr *projectedRows) Notices() []pgconn.Notice {
+	return RowsNotices(pr.rows)
+}
+
+func (pr *projectedRows) QuerySQL() (sql string, argCount int) {
+	return querySQL(pr.rows)
+}
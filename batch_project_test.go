@@ -0,0 +1,70 @@
+package pgx_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxtest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchResultsQueryProject(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		type person struct {
+			Name string
+			Age  int32
+		}
+
+		batch := &pgx.Batch{}
+		batch.Queue("select n as id, 'name ' || n as name, n::int4 + 20 as age, 'x' as extra from generate_series(1, 2) n")
+		batch.Queue("select 'Joe' as name, 42::int4 as age, true as active")
+		batch.Queue("select 1 as id")
+		batch.Queue("select 2")
+
+		br := conn.SendBatch(ctx, batch)
+
+		rows, err := pgx.BatchQueryProject(br, []string{"age", "name"})
+		require.NoError(t, err)
+		fds := rows.FieldDescriptions()
+		require.Len(t, fds, 2)
+		require.Equal(t, "age", fds[0].Name)
+		require.Equal(t, "name", fds[1].Name)
+
+		var ages []int32
+		var names []string
+		for rows.Next() {
+			var age int32
+			var name string
+			require.NoError(t, rows.Scan(&age, &name))
+			ages = append(ages, age)
+			names = append(names, name)
+
+			values, err := rows.Values()
+			require.NoError(t, err)
+			require.Equal(t, []any{age, name}, values)
+			require.Len(t, rows.RawValues(), 2)
+		}
+		require.NoError(t, rows.Err())
+		require.Equal(t, []int32{21, 22}, ages)
+		require.Equal(t, []string{"name 1", "name 2"}, names)
+
+		rows, err = pgx.BatchQueryProject(br, []string{"name", "age"})
+		require.NoError(t, err)
+		people, err := pgx.CollectRows(rows, pgx.RowToStructByName[person])
+		require.NoError(t, err)
+		require.Equal(t, []person{{Name: "Joe", Age: 42}}, people)
+
+		_, err = pgx.BatchQueryProject(br, []string{"missing"})
+		require.EqualError(t, err, `QueryProject: column "missing" not found`)
+
+		var n int32
+		require.NoError(t, br.QueryRow().Scan(&n))
+		require.EqualValues(t, 2, n)
+
+		require.NoError(t, br.Close())
+		ensureConnValid(t, conn)
+	})
+}
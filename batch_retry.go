@@ -0,0 +1,161 @@
+package pgx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// SendBatchWithRetry sends b like Conn.SendBatch. If a query in the batch fails with a serialization failure (SQLSTATE
+// 40001) or a deadlock (SQLSTATE 40P01) the entire batch is sent again until it succeeds or maxAttempts attempts have
+// been made. The results of the last attempt are returned.
+//
+// Retrying is only safe when a failed attempt has no effect. Therefore conn must not be in a transaction when
+// SendBatchWithRetry is called and b must run atomically: either in the implicit transaction of the batch or in a
+// transaction explicitly started by the first query and committed by the last query. Any other transaction control
+// statement causes an error to be returned without sending the batch. If an attempt leaves conn in a transaction it is
+// rolled back before the batch is resent. Statements that are not transactional such as nextval, or side effects
+// outside of the database, are repeated by each attempt.
+//
+// The results are always buffered as if SendBatchOptions.Buffered was set so that the failure of any query is detected
+// before the results are returned. Callback functions registered with QueuedQuery.Query, QueuedQuery.QueryRow, or
+// QueuedQuery.Exec are only called for the last attempt. ConnConfig.BatchResultsMiddleware is likewise only applied to
+// the results of the last attempt.
+//
+// b is not sent itself. Each attempt sends a Clone of b.
+func SendBatchWithRetry(ctx context.Context, conn *Conn, b *Batch, maxAttempts int) (BatchResults, error) {
+	if maxAttempts < 1 {
+		return nil, fmt.Errorf("maxAttempts must be at least 1, got %d", maxAttempts)
+	}
+	if conn.PgConn().TxStatus() != 'I' {
+		return nil, errors.New("batch cannot be retried inside a transaction")
+	}
+	if err := checkBatchAtomic(b); err != nil {
+		return nil, err
+	}
+
+	template := b.Clone()
+	template.Options.Buffered = true
+
+	for attempt := 1; ; attempt++ {
+		attemptBatch := template.Clone()
+		// The middleware is only applied to the results that are returned so that the attempt can be inspected.
+		br := conn.sendBatchInto(ctx, attemptBatch, nil)
+
+		if attempt == maxAttempts {
+			return conn.applyBatchResultsMiddleware(br), nil
+		}
+
+		snapshots, err := BatchSnapshots(br)
+		if err != nil {
+			br.Close()
+			return nil, err
+		}
+
+		itemIdx, reason, err := batchRetryReason(snapshots)
+		if err == nil {
+			return conn.applyBatchResultsMiddleware(br), nil
+		}
+
+		// The callback functions are only called for the attempt whose results are returned.
+		for _, qq := range attemptBatch.queuedQueries {
+			qq.fn = nil
+		}
+		br.Close()
+
+		if conn.PgConn().TxStatus() != 'I' {
+			_, rollbackErr := conn.Exec(ctx, "rollback")
+			if rollbackErr != nil {
+				return nil, fmt.Errorf("rollback before retrying batch: %w", rollbackErr)
+			}
+		}
+
+		if conn.batchRetryTracer != nil {
+			conn.batchRetryTracer.TraceBatchRetry(ctx, conn, TraceBatchRetryData{
+				ItemIndex: itemIdx,
+				Attempt:   attempt + 1,
+				Reason:    reason,
+				Err:       err,
+			})
+		}
+	}
+}
+
+// batchRetryReason returns the index and error of the first query in snapshots that failed if the failure is a
+// serialization failure or a deadlock. Otherwise err is nil.
+func batchRetryReason(snapshots []BatchResultSnapshot) (itemIdx int, reason string, err error) {
+	for i, s := range snapshots {
+		if s.Err() == nil {
+			continue
+		}
+
+		var pgErr *pgconn.PgError
+		if errors.As(s.Err(), &pgErr) {
+			switch pgErr.Code {
+			case "40001":
+				return i, "serialization failure", s.Err()
+			case "40P01":
+				return i, "deadlock detected", s.Err()
+			}
+		}
+		return 0, "", nil
+	}
+
+	return 0, "", nil
+}
+
+// checkBatchAtomic returns an error if b contains transaction control statements other than a BEGIN or START
+// TRANSACTION as the first query and a COMMIT or END as the last query.
+func checkBatchAtomic(b *Batch) error {
+	n := len(b.queuedQueries)
+	explicitTx := n >= 2 && isBeginStatement(b.queuedQueries[0].query) && isCommitStatement(b.queuedQueries[n-1].query)
+
+	for i, qq := range b.queuedQueries {
+		if explicitTx && (i == 0 || i == n-1) {
+			continue
+		}
+		if isTransactionControlStatement(qq.query) {
+			return fmt.Errorf("batch cannot be retried: batch item %d is a transaction control statement", i)
+		}
+	}
+
+	return nil
+}
+
+// firstKeyword returns the first word of sql in lower case.
+func firstKeyword(sql string) string {
+	fields := strings.FieldsFunc(sql, func(r rune) bool {
+		return r == ' ' || r == '\t' || r == '\n' || r == '\r' || r == ';' || r == '('
+	})
+	if len(fields) == 0 {
+		return ""
+	}
+	return strings.ToLower(fields[0])
+}
+
+func isBeginStatement(sql string) bool {
+	switch firstKeyword(sql) {
+	case "begin", "start":
+		return true
+	}
+	return false
+}
+
+func isCommitStatement(sql string) bool {
+	switch firstKeyword(sql) {
+	case "commit", "end":
+		return true
+	}
+	return false
+}
+
+func isTransactionControlStatement(sql string) bool {
+	switch firstKeyword(sql) {
+	case "begin", "start", "commit", "end", "rollback", "abort", "savepoint", "release":
+		return true
+	}
+	return false
+}
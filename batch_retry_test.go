@@ -0,0 +1,180 @@
+package pgx_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxtest"
+	"github.com/stretchr/testify/require"
+)
+
+// failUntilAttemptSQL fails with a serialization failure until the retry_attempts sequence reaches 3. Sequences are not
+// transactional so the count survives the rollback of failed attempts.
+const failUntilAttemptSQL = `do $$
+begin
+	if nextval('retry_attempts') < 3 then
+		raise exception 'could not serialize access' using errcode = 'serialization_failure';
+	end if;
+end
+$$`
+
+func TestSendBatchWithRetry(t *testing.T) {
+	t.Parallel()
+
+	tracer := &testTracer{}
+
+	ctr := defaultConnTestRunner
+	ctr.CreateConfig = func(ctx context.Context, t testing.TB) *pgx.ConnConfig {
+		config := defaultConnTestRunner.CreateConfig(ctx, t)
+		config.Tracer = tracer
+		return config
+	}
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, ctr, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		mustExec(t, conn, `create temporary sequence retry_attempts`)
+		mustExec(t, conn, `create temporary table ledger(id int primary key)`)
+
+		var retries []pgx.TraceBatchRetryData
+		tracer.traceBatchRetry = func(ctx context.Context, conn *pgx.Conn, data pgx.TraceBatchRetryData) {
+			retries = append(retries, data)
+		}
+		defer func() { tracer.traceBatchRetry = nil }()
+
+		batch := &pgx.Batch{}
+		batch.Queue("insert into ledger(id) values (1)")
+		batch.Queue(failUntilAttemptSQL)
+		var callbackCalls int
+		batch.Queue("select count(*) from ledger").QueryRow(func(row pgx.Row) error {
+			callbackCalls++
+			var n int64
+			err := row.Scan(&n)
+			require.EqualValues(t, 1, n)
+			return err
+		})
+
+		br, err := pgx.SendBatchWithRetry(ctx, conn, batch, 5)
+		require.NoError(t, err)
+		require.NoError(t, br.Close())
+		require.Equal(t, 1, callbackCalls)
+
+		require.Len(t, retries, 2)
+		for i, r := range retries {
+			require.Equal(t, 1, r.ItemIndex)
+			require.Equal(t, i+2, r.Attempt)
+			require.Equal(t, "serialization failure", r.Reason)
+			var pgErr *pgconn.PgError
+			require.ErrorAs(t, r.Err, &pgErr)
+			require.Equal(t, "40001", pgErr.Code)
+		}
+
+		var n int64
+		err = conn.QueryRow(ctx, "select count(*) from ledger").Scan(&n)
+		require.NoError(t, err)
+		require.EqualValues(t, 1, n)
+
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestSendBatchWithRetryBatchResultsMiddleware(t *testing.T) {
+	t.Parallel()
+
+	closeCount := 0
+	ctr := defaultConnTestRunner
+	ctr.CreateConfig = func(ctx context.Context, t testing.TB) *pgx.ConnConfig {
+		config := defaultConnTestRunner.CreateConfig(ctx, t)
+		config.BatchResultsMiddleware = func(br pgx.BatchResults) pgx.BatchResults {
+			return closeCountingBatchResults{BatchResults: br, closeCount: &closeCount}
+		}
+		return config
+	}
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, ctr, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		closeCount = 0
+		mustExec(t, conn, `create temporary sequence retry_attempts`)
+
+		batch := &pgx.Batch{}
+		batch.Queue(failUntilAttemptSQL)
+
+		br, err := pgx.SendBatchWithRetry(ctx, conn, batch, 5)
+		require.NoError(t, err)
+		require.IsType(t, closeCountingBatchResults{}, br)
+		require.NoError(t, br.Close())
+		require.Equal(t, 1, closeCount)
+
+		var attempts int64
+		err = conn.QueryRow(ctx, "select last_value from retry_attempts").Scan(&attempts)
+		require.NoError(t, err)
+		require.EqualValues(t, 3, attempts)
+
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestSendBatchWithRetryGivesUpAfterMaxAttempts(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		mustExec(t, conn, `create temporary sequence retry_attempts`)
+
+		batch := &pgx.Batch{}
+		batch.Queue("begin isolation level serializable")
+		batch.Queue(failUntilAttemptSQL)
+		batch.Queue("commit")
+
+		br, err := pgx.SendBatchWithRetry(ctx, conn, batch, 2)
+		require.NoError(t, err)
+		err = br.Close()
+		var pgErr *pgconn.PgError
+		require.ErrorAs(t, err, &pgErr)
+		require.Equal(t, "40001", pgErr.Code)
+
+		var attempts int64
+		err = conn.QueryRow(ctx, "select last_value from retry_attempts").Scan(&attempts)
+		require.NoError(t, err)
+		require.EqualValues(t, 2, attempts)
+
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestSendBatchWithRetryRejectsNonAtomicBatches(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		batch := &pgx.Batch{}
+		batch.Queue("select 1")
+		batch.Queue("commit")
+		batch.Queue("select 2")
+
+		_, err := pgx.SendBatchWithRetry(ctx, conn, batch, 3)
+		require.EqualError(t, err, "batch cannot be retried: batch item 1 is a transaction control statement")
+
+		tx, err := conn.Begin(ctx)
+		require.NoError(t, err)
+		defer tx.Rollback(ctx)
+
+		batch = &pgx.Batch{}
+		batch.Queue("select 1")
+		_, err = pgx.SendBatchWithRetry(ctx, conn, batch, 3)
+		require.EqualError(t, err, "batch cannot be retried inside a transaction")
+	})
+}
+
+func TestBatchClone(t *testing.T) {
+	t.Parallel()
+
+	batch := &pgx.Batch{Options: pgx.SendBatchOptions{FailFast: true}}
+	batch.Queue("select $1::int4", 1)
+
+	clone := batch.Clone()
+	clone.Queue("select 2")
+
+	require.Equal(t, 1, batch.Len())
+	require.Equal(t, 2, clone.Len())
+	require.True(t, clone.Options.FailFast)
+	require.Equal(t, "select $1::int4", clone.QueuedQueries()[0].SQL())
+	require.Equal(t, []any{1}, clone.QueuedQueries()[0].Arguments())
+}
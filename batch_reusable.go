@@ -0,0 +1,58 @@
+package pgx
+
+// ReusableBatchResults stores the results of a batch sent with Conn.SendBatchInto so that their memory can be reused by
+// the next batch. This reduces the allocations per batch of applications that send many batches.
+//
+// The BatchResults returned by SendBatchInto, the Rows and Row returned by its Query and QueryRow methods, and the maps
+// returned by AllFieldDescriptions are only valid until the ReusableBatchResults is passed to SendBatchInto again. In
+// addition each call to Query or QueryRow reuses the Rows of the previous call, so a Rows must not be used after the
+// results of the next query in the batch are read. The BatchResults must be closed before the ReusableBatchResults is
+// reused. A ReusableBatchResults must only be used by one batch at a time, so it cannot be shared by connections that
+// are used concurrently.
+//
+// The zero value is ready to use.
+type ReusableBatchResults struct {
+	br   batchResults
+	pbr  pipelineBatchResults
+	rows baseRows
+}
+
+// batchResults returns br stored in rbr. If rbr is nil a newly allocated copy of br is returned.
+func (rbr *ReusableBatchResults) batchResults(br batchResults) *batchResults {
+	if rbr == nil {
+		p := new(batchResults)
+		*p = br
+		return p
+	}
+
+	br.fieldDescriptions = clearMap(rbr.br.fieldDescriptions)
+	br.notices = clearMap(rbr.br.notices)
+	br.noticeHandler = rbr.br.noticeHandler
+	br.reusableRows = &rbr.rows
+	rbr.br = br
+	return &rbr.br
+}
+
+// pipelineBatchResults returns br stored in rbr. If rbr is nil a newly allocated copy of br is returned.
+func (rbr *ReusableBatchResults) pipelineBatchResults(br pipelineBatchResults) *pipelineBatchResults {
+	if rbr == nil {
+		p := new(pipelineBatchResults)
+		*p = br
+		return p
+	}
+
+	br.fieldDescriptions = clearMap(rbr.pbr.fieldDescriptions)
+	br.notices = clearMap(rbr.pbr.notices)
+	br.noticeHandler = rbr.pbr.noticeHandler
+	br.reusableRows = &rbr.rows
+	rbr.pbr = br
+	return &rbr.pbr
+}
+
+// clearMap deletes all entries of m so its memory can be reused.
+func clearMap[K comparable, V any](m map[K]V) map[K]V {
+	for k := range m {
+		delete(m, k)
+	}
+	return m
+}
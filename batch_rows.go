@@ -0,0 +1,163 @@
+package pgx
+
+import (
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// BatchAsRows returns a Rows that reads the rows of every remaining query in br in sequence as a single stream. Next
+// advances to the next query when the rows of the current query are exhausted. FieldDescriptions and CommandTag reflect
+// the current query. It is intended for batches of queries that return the same columns. Closing the returned Rows
+// reads and discards the results of any remaining queries.
+func BatchAsRows(br BatchResults) Rows {
+	if r, ok := batchResultsAs[interface{ AsRows() Rows }](br); ok {
+		return r.AsRows()
+	}
+	return &baseRows{err: errBatchResultsUnsupported(br, "AsRows"), closed: true}
+}
+
+// batchRows implements Rows over the results of the remaining queries in a batch. It is returned by BatchAsRows.
+type batchRows struct {
+	br        sentBatchResults
+	first     int // index of the first query in the batch
+	remaining int // number of queries whose results have not been read from br
+	read      int // number of queries whose results have been read from br
+
+	rows   Rows // rows of the current query
+	err    error
+	closed bool
+}
+
+func newBatchRows(br sentBatchResults, b *Batch, qqIdx int) *batchRows {
+	remaining := 0
+	if b != nil && qqIdx < len(b.queuedQueries) {
+		remaining = len(b.queuedQueries) - qqIdx
+	}
+	return &batchRows{br: br, first: qqIdx, remaining: remaining}
+}
+
+// Close closes the rows of the current query and reads and discards the results of any remaining queries.
+func (r *batchRows) Close() {
+	if r.closed {
+		return
+	}
+	r.closed = true
+
+	if r.rows != nil {
+		r.rows.Close()
+		if err := r.rows.Err(); err != nil && r.err == nil {
+			r.err = err
+		}
+	}
+
+	for ; r.remaining > 0; r.remaining-- {
+		r.read++
+		rows, err := r.br.Query()
+		if err == nil {
+			rows.Close()
+			err = rows.Err()
+		}
+		if err != nil && r.err == nil {
+			r.err = err
+		}
+	}
+}
+
+// Err returns the first error encountered by any query.
+func (r *batchRows) Err() error {
+	return r.err
+}
+
+// CommandTag returns the command tag of the current query.
+func (r *batchRows) CommandTag() pgconn.CommandTag {
+	if r.rows == nil {
+		return pgconn.CommandTag{}
+	}
+	return r.rows.CommandTag()
+}
+
+// FieldDescriptions returns the field descriptions of the current query.
+func (r *batchRows) FieldDescriptions() []pgconn.FieldDescription {
+	if r.rows == nil {
+		return nil
+	}
+	return r.rows.FieldDescriptions()
+}
+
+// Next prepares the next row for reading. When the rows of a query are exhausted it advances to the next query in the
+// batch. It returns false when the rows of every query have been read or an error occurs.
+func (r *batchRows) Next() bool {
+	if r.closed {
+		return false
+	}
+
+	for {
+		if r.rows != nil {
+			if r.rows.Next() {
+				return true
+			}
+			if err := r.rows.Err(); err != nil {
+				r.err = err
+				r.Close()
+				return false
+			}
+		}
+
+		if r.remaining == 0 {
+			r.Close()
+			return false
+		}
+
+		rows, err := r.br.Query()
+		r.remaining--
+		r.read++
+		if err != nil {
+			r.err = err
+			r.Close()
+			return false
+		}
+		r.rows = rows
+	}
+}
+
+// Scan reads the values from the current row into dest values positionally.
+func (r *batchRows) Scan(dest ...any) error {
+	if r.rows == nil {
+		return errors.New("no current row")
+	}
+	return r.rows.Scan(dest...)
+}
+
+// Values returns the decoded row values.
+func (r *batchRows) Values() ([]any, error) {
+	if r.rows == nil {
+		return nil, errors.New("no current row")
+	}
+	return r.rows.Values()
+}
+
+// RawValues returns the unparsed bytes of the row values.
+func (r *batchRows) RawValues() [][]byte {
+	if r.rows == nil {
+		return nil
+	}
+	return r.rows.RawValues()
+}
+
+// Conn returns the underlying *Conn on which the batch was sent.
+func (r *batchRows) Conn() *Conn {
+	if r.rows == nil {
+		return nil
+	}
+	return r.rows.Conn()
+}
+
+// Notices returns the notices received while reading the results of the queries that have been read.
+func (r *batchRows) Notices() []pgconn.Notice {
+	var notices []pgconn.Notice
+	for i := r.first; i < r.first+r.read; i++ {
+		notices = append(notices, r.br.ItemNotices(i)...)
+	}
+	return notices
+}
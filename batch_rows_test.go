@@ -0,0 +1,103 @@
+package pgx_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxtest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchResultsAsRows(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		for _, buffered := range []bool{false, true} {
+			batch := &pgx.Batch{Options: pgx.SendBatchOptions{Buffered: buffered}}
+			batch.Queue("select n::int4, 'a' from generate_series(1, 2) n")
+			batch.Queue("select n::int4, 'b' from generate_series(1, 0) n")
+			batch.Queue("select n::int4, 'c' from generate_series(3, 4) n")
+
+			br := conn.SendBatch(ctx, batch)
+
+			type row struct {
+				N int32
+				S string
+			}
+			rows, err := pgx.CollectRows(pgx.BatchAsRows(br), pgx.RowToStructByPos[row])
+			require.NoError(t, err)
+			require.Equal(t, []row{{1, "a"}, {2, "a"}, {3, "c"}, {4, "c"}}, rows)
+
+			require.NoError(t, pgx.VerifyBatchComplete(br))
+			require.NoError(t, br.Close())
+		}
+
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestBatchResultsAsRowsFieldDescriptionsOfCurrentQuery(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		batch := &pgx.Batch{}
+		batch.Queue("select 1::int4 as a")
+		batch.Queue("select 2::int4 as b")
+
+		br := conn.SendBatch(ctx, batch)
+		rows := pgx.BatchAsRows(br)
+
+		var names []string
+		for rows.Next() {
+			names = append(names, rows.FieldDescriptions()[0].Name)
+		}
+		require.NoError(t, rows.Err())
+		require.Equal(t, []string{"a", "b"}, names)
+
+		require.NoError(t, br.Close())
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestBatchResultsAsRowsError(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		batch := &pgx.Batch{}
+		batch.Queue("select 1::int4")
+		batch.Queue("select 1/n from generate_series(0, 1) n")
+		batch.Queue("select 3::int4")
+
+		br := conn.SendBatch(ctx, batch)
+		_, err := pgx.CollectRows(pgx.BatchAsRows(br), pgx.RowTo[int32])
+		var pgErr *pgconn.PgError
+		require.ErrorAs(t, err, &pgErr)
+		require.Equal(t, "22012", pgErr.Code)
+
+		br.Close()
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestBatchResultsAsRowsCloseDiscardsRemainingQueries(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		batch := &pgx.Batch{}
+		batch.Queue("select 1::int4")
+		batch.Queue("select 2::int4")
+		batch.Queue("select 3::int4")
+
+		br := conn.SendBatch(ctx, batch)
+		rows := pgx.BatchAsRows(br)
+		require.True(t, rows.Next())
+		rows.Close()
+		require.NoError(t, rows.Err())
+
+		require.NoError(t, pgx.VerifyBatchComplete(br))
+		require.NoError(t, br.Close())
+		ensureConnValid(t, conn)
+	})
+}
@@ -0,0 +1,111 @@
+package pgx
+
+import (
+	"time"
+)
+
+// BatchStats are statistics about sending a batch and reading its results. They are returned by BatchResultsStats.
+type BatchStats struct {
+	// Queries is the number of queued queries that were sent to the server. It is less than the number of queued
+	// queries if the batch failed before all of them were sent, e.g. because a chunk failed with MaxChunkQueries.
+	Queries int
+
+	// BytesWritten is the number of bytes written to the server. This includes statements that pgx sends on behalf of
+	// the batch such as those of SetApplicationName or IsolateErrors.
+	BytesWritten uint64
+
+	// BytesRead is the number of bytes of the messages read from the server.
+	BytesRead uint64
+
+	// Duration is the time from when the batch was sent until its results were closed.
+	Duration time.Duration
+}
+
+// BatchResultsStats returns statistics about sending the batch of br and reading its results, such as the number of
+// bytes written and read. They are recorded until the results are closed, so BatchResultsStats should be called after
+// Close.
+func BatchResultsStats(br BatchResults) (BatchStats, error) {
+	if r, ok := batchResultsAs[interface{ Stats() BatchStats }](br); ok {
+		return r.Stats(), nil
+	}
+	return BatchStats{}, errBatchResultsUnsupported(br, "Stats")
+}
+
+// batchStatsRecorder records the BatchStats of a batch from when it is sent until its results are closed.
+type batchStatsRecorder struct {
+	conn         *Conn
+	b            *Batch
+	start        time.Time
+	bytesWritten uint64
+	bytesRead    uint64
+
+	recording bool
+	stats     BatchStats
+}
+
+// startBatchStats starts recording the statistics of sending b.
+func (c *Conn) startBatchStats(b *Batch) batchStatsRecorder {
+	b.queriesFlushed = 0
+
+	frontend := c.pgConn.Frontend()
+	return batchStatsRecorder{
+		conn:         c,
+		b:            b,
+		start:        time.Now(),
+		bytesWritten: frontend.BytesWritten(),
+		bytesRead:    frontend.BytesRead(),
+		recording:    true,
+	}
+}
+
+// finish stops recording. It does nothing if r is not recording.
+func (r *batchStatsRecorder) finish() {
+	if !r.recording {
+		return
+	}
+	r.recording = false
+
+	frontend := r.conn.pgConn.Frontend()
+	r.stats = BatchStats{
+		Queries:      r.b.queriesFlushed,
+		BytesWritten: frontend.BytesWritten() - r.bytesWritten,
+		BytesRead:    frontend.BytesRead() - r.bytesRead,
+		Duration:     time.Since(r.start),
+	}
+}
+
+func (br *batchResults) recordStats(r batchStatsRecorder) {
+	br.statsRecorder = r
+	if br.closed {
+		br.statsRecorder.finish()
+	}
+}
+
+// Stats returns statistics about the batch. They are complete once the results have been closed.
+func (br *batchResults) Stats() BatchStats {
+	return br.statsRecorder.stats
+}
+
+func (br *pipelineBatchResults) recordStats(r batchStatsRecorder) {
+	br.statsRecorder = r
+	if br.closed {
+		br.statsRecorder.finish()
+	}
+}
+
+// Stats returns statistics about the batch. They are complete once the results have been closed.
+func (br *pipelineBatchResults) Stats() BatchStats {
+	return br.statsRecorder.stats
+}
+
+func (br *bufferedBatchResults) recordStats(r batchStatsRecorder) {
+	br.statsRecorder = r
+	if br.closed {
+		br.statsRecorder.finish()
+	}
+}
+
+// Stats returns statistics about the batch. They are complete once the results have been closed.
+func (br *bufferedBatchResults) Stats() BatchStats {
+	return br.statsRecorder.stats
+}
@@ -0,0 +1,53 @@
+package pgx_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxtest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnSendBatchStats(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		batch := &pgx.Batch{}
+		batch.Queue("select n from generate_series(1, 100) n")
+		batch.Queue("select $1::text", "foo")
+
+		br := conn.SendBatch(ctx, batch)
+		rows, err := br.Query()
+		require.NoError(t, err)
+		rows.Close()
+		require.NoError(t, rows.Err())
+		require.NoError(t, br.Close())
+
+		stats, err := pgx.BatchResultsStats(br)
+		require.NoError(t, err)
+		require.Equal(t, 2, stats.Queries)
+		require.Greater(t, stats.BytesWritten, uint64(len("select n from generate_series(1, 100) n")))
+		require.Greater(t, stats.BytesRead, uint64(100))
+		require.Greater(t, stats.Duration, time.Duration(0))
+	})
+}
+
+func TestConnSendBatchStatsChunkFailure(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		batch := &pgx.Batch{}
+		batch.Options.MaxChunkQueries = 2
+		batch.Queue("select 1")
+		batch.Queue("select 1/0")
+		batch.Queue("select 3")
+
+		br := conn.SendBatch(ctx, batch)
+		require.Error(t, br.Close())
+		stats, err := pgx.BatchResultsStats(br)
+		require.NoError(t, err)
+		require.Equal(t, 2, stats.Queries)
+	})
+}
@@ -1,14 +1,17 @@
 package pgx_test
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"testing"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxtest"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -280,6 +283,24 @@ func TestConnSendBatchMany(t *testing.T) {
 	})
 }
 
+func TestConnSendBatchExecError(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		batch := &pgx.Batch{}
+		batch.Queue("select 1/$1::int4", 0)
+
+		br := conn.SendBatch(ctx, batch)
+		_, err := br.Exec()
+		var pgErr *pgconn.PgError
+		require.ErrorAs(t, err, &pgErr)
+		require.Equal(t, "22012", pgErr.Code)
+		br.Close()
+
+		ensureConnValid(t, conn)
+	})
+}
+
 func TestConnSendBatchWithPreparedStatement(t *testing.T) {
 	t.Parallel()
 
@@ -482,256 +503,1112 @@ func TestConnSendBatchCloseRowsPartiallyRead(t *testing.T) {
 	})
 }
 
-func TestConnSendBatchQueryError(t *testing.T) {
+func TestConnSendBatchEncodeErrorIdentifiesItemAndArg(t *testing.T) {
 	t.Parallel()
 
-	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
-
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, pgxtest.KnownOIDQueryExecModes, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
 		batch := &pgx.Batch{}
-		batch.Queue("select n from generate_series(0,5) n where 100/(5-n) > 0")
-		batch.Queue("select n from generate_series(0,5) n")
-
-		br := conn.SendBatch(context.Background(), batch)
-
-		rows, err := br.Query()
-		if err != nil {
-			t.Error(err)
-		}
-
-		for i := 0; rows.Next(); i++ {
-			var n int
-			if err := rows.Scan(&n); err != nil {
-				t.Error(err)
-			}
-			if n != i {
-				t.Errorf("n => %v, want %v", n, i)
-			}
-		}
-
-		if pgErr, ok := rows.Err().(*pgconn.PgError); !(ok && pgErr.Code == "22012") {
-			t.Errorf("rows.Err() => %v, want error code %v", rows.Err(), 22012)
-		}
-
-		err = br.Close()
-		if pgErr, ok := err.(*pgconn.PgError); !(ok && pgErr.Code == "22012") {
-			t.Errorf("br.Close() => %v, want error code %v", err, 22012)
-		}
+		batch.Queue("select $1::int8", 1)
+		batch.Queue("select $1::int8, $2::int4", 2, []string{"not a number"})
 
+		err := conn.SendBatch(ctx, batch).Close()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "batch item 1 arg 1 ([]string -> int4)")
 	})
 }
 
-func TestConnSendBatchQuerySyntaxError(t *testing.T) {
+func TestConnSendBatchFormattedArgs(t *testing.T) {
 	t.Parallel()
 
 	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
-
 		batch := &pgx.Batch{}
-		batch.Queue("select 1 1")
+		batch.Queue("select $1::numeric::text", pgx.Text("1.500000000000000000000000000001"))
+		batch.Queue("select $1::int4", pgx.Text(int32(42)))
 
-		br := conn.SendBatch(context.Background(), batch)
+		br := conn.SendBatch(ctx, batch)
+
+		var s string
+		err := br.QueryRow().Scan(&s)
+		require.NoError(t, err)
+		require.Equal(t, "1.500000000000000000000000000001", s)
 
 		var n int32
+		err = br.QueryRow().Scan(&n)
+		require.NoError(t, err)
+		require.EqualValues(t, 42, n)
+
+		require.NoError(t, br.Close())
+	})
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, pgxtest.KnownOIDQueryExecModes, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		batch := &pgx.Batch{}
+		batch.Queue("select $1::int8", pgx.Binary(int64(7)))
+
+		br := conn.SendBatch(ctx, batch)
+		var n int64
 		err := br.QueryRow().Scan(&n)
-		if pgErr, ok := err.(*pgconn.PgError); !(ok && pgErr.Code == "42601") {
-			t.Errorf("rows.Err() => %v, want error code %v", err, 42601)
-		}
+		require.NoError(t, err)
+		require.EqualValues(t, 7, n)
+		require.NoError(t, br.Close())
+	})
 
-		err = br.Close()
-		if err == nil {
-			t.Error("Expected error")
-		}
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, []pgx.QueryExecMode{pgx.QueryExecModeExec, pgx.QueryExecModeSimpleProtocol}, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		batch := &pgx.Batch{}
+		batch.Queue("select $1::int8", pgx.Binary(int64(7)))
 
+		err := conn.SendBatch(ctx, batch).Close()
+		require.ErrorContains(t, err, "binary format argument is not supported")
 	})
 }
 
-func TestConnSendBatchQueryRowInsert(t *testing.T) {
+func TestConnSendBatchQueueLimited(t *testing.T) {
 	t.Parallel()
 
-	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
-
-		sql := `create temporary table ledger(
-	  id serial primary key,
-	  description varchar not null,
-	  amount int not null
-	);`
-		mustExec(t, conn, sql)
+	modes := []pgx.QueryExecMode{
+		pgx.QueryExecModeCacheStatement,
+		pgx.QueryExecModeCacheDescribe,
+		pgx.QueryExecModeDescribeExec,
+		pgx.QueryExecModeExec,
+	}
 
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, modes, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
 		batch := &pgx.Batch{}
-		batch.Queue("select 1")
-		batch.Queue("insert into ledger(description, amount) values($1, $2),($1, $2)", "q1", 1)
+		batch.QueueLimited("select n from generate_series(1, $1::int4) n", 3, 1000)
+		batch.QueueLimited("select n from generate_series(1, $1::int4) n", 10, 2)
+		batch.Queue("select $1::int4", 42)
 
-		br := conn.SendBatch(context.Background(), batch)
+		br := conn.SendBatch(ctx, batch)
 
-		var value int
-		err := br.QueryRow().Scan(&value)
-		if err != nil {
-			t.Error(err)
-		}
+		rows, err := br.Query()
+		require.NoError(t, err)
+		nums, err := pgx.CollectRows(rows, pgx.RowTo[int32])
+		require.NoError(t, err)
+		require.Equal(t, []int32{1, 2, 3}, nums)
 
-		ct, err := br.Exec()
-		if err != nil {
-			t.Error(err)
-		}
-		if ct.RowsAffected() != 2 {
-			t.Errorf("ct.RowsAffected() => %v, want %v", ct.RowsAffected(), 2)
-		}
+		rows, err = br.Query()
+		require.NoError(t, err)
+		nums, err = pgx.CollectRows(rows, pgx.RowTo[int32])
+		require.NoError(t, err)
+		require.Equal(t, []int32{1, 2}, nums)
+		require.Equal(t, "SELECT 2", rows.CommandTag().String())
 
-		br.Close()
+		var n int32
+		err = br.QueryRow().Scan(&n)
+		require.NoError(t, err)
+		require.EqualValues(t, 42, n)
+
+		require.NoError(t, br.Close())
+	})
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, []pgx.QueryExecMode{pgx.QueryExecModeSimpleProtocol}, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		batch := &pgx.Batch{}
+		batch.QueueLimited("select 1", 1)
 
+		err := conn.SendBatch(ctx, batch).Close()
+		require.EqualError(t, err, "batch item 0: QueueLimited is not supported with QueryExecModeSimpleProtocol")
 	})
 }
 
-func TestConnSendBatchQueryPartialReadInsert(t *testing.T) {
+func TestConnSendBatchReadBufferSize(t *testing.T) {
 	t.Parallel()
 
 	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		frontend := conn.PgConn().Frontend()
+		defaultSize := frontend.ReadBufferSize()
 
-		sql := `create temporary table ledger(
-	  id serial primary key,
-	  description varchar not null,
-	  amount int not null
-	);`
-		mustExec(t, conn, sql)
-
-		batch := &pgx.Batch{}
-		batch.Queue("select 1 union all select 2 union all select 3")
-		batch.Queue("insert into ledger(description, amount) values($1, $2),($1, $2)", "q1", 1)
+		batch := &pgx.Batch{Options: pgx.SendBatchOptions{ReadBufferSize: 1 << 20}}
+		batch.Queue("select repeat('x', 100000) from generate_series(1, 10)")
+		batch.Queue("select 1")
 
-		br := conn.SendBatch(context.Background(), batch)
+		br := conn.SendBatch(ctx, batch)
+		require.Equal(t, 1<<20, frontend.ReadBufferSize())
 
 		rows, err := br.Query()
-		if err != nil {
-			t.Error(err)
+		require.NoError(t, err)
+		strs, err := pgx.CollectRows(rows, pgx.RowTo[string])
+		require.NoError(t, err)
+		require.Len(t, strs, 10)
+		for _, s := range strs {
+			require.Len(t, s, 100000)
 		}
-		rows.Close()
 
-		ct, err := br.Exec()
-		if err != nil {
-			t.Error(err)
-		}
-		if ct.RowsAffected() != 2 {
-			t.Errorf("ct.RowsAffected() => %v, want %v", ct.RowsAffected(), 2)
-		}
+		var n int32
+		err = br.QueryRow().Scan(&n)
+		require.NoError(t, err)
+		require.EqualValues(t, 1, n)
 
-		br.Close()
+		require.NoError(t, br.Close())
+		require.Equal(t, defaultSize, frontend.ReadBufferSize())
 
+		ensureConnValid(t, conn)
 	})
 }
 
-func TestTxSendBatch(t *testing.T) {
+func TestConnSendBatchDuplicateStatementNameDifferentSQL(t *testing.T) {
 	t.Parallel()
 
-	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, []pgx.QueryExecMode{pgx.QueryExecModeCacheStatement}, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		_, err := conn.Exec(ctx, "select 1::int4")
+		require.NoError(t, err)
 
-		sql := `create temporary table ledger1(
-	  id serial primary key,
-	  description varchar not null
-	);`
-		mustExec(t, conn, sql)
+		var name string
+		err = conn.QueryRow(ctx, "select name from pg_prepared_statements where statement = 'select 1::int4'", pgx.QueryExecModeSimpleProtocol).Scan(&name)
+		require.NoError(t, err)
 
-		sql = `create temporary table ledger2(
-	  id int primary key,
-	  amount int not null
-	);`
-		mustExec(t, conn, sql)
+		// Replace the cached statement on the server with a different statement of the same name.
+		_, err = conn.Exec(ctx, "deallocate "+name, pgx.QueryExecModeSimpleProtocol)
+		require.NoError(t, err)
+		_, err = conn.Prepare(ctx, name, "select 2::int4")
+		require.NoError(t, err)
 
-		tx, _ := conn.Begin(context.Background())
 		batch := &pgx.Batch{}
-		batch.Queue("insert into ledger1(description) values($1) returning id", "q1")
+		batch.Queue("select 1::int4")
+		batch.Queue(name)
 
-		br := tx.SendBatch(context.Background(), batch)
+		err = conn.SendBatch(ctx, batch).Close()
+		require.EqualError(t, err, fmt.Sprintf("batch items 0 and 1 use prepared statement %q with different SQL", name))
 
-		var id int
-		err := br.QueryRow().Scan(&id)
-		if err != nil {
-			t.Error(err)
-		}
-		br.Close()
+		ensureConnValid(t, conn)
+	})
+}
 
-		batch = &pgx.Batch{}
-		batch.Queue("insert into ledger2(id,amount) values($1, $2)", id, 2)
-		batch.Queue("select amount from ledger2 where id = $1", id)
+func TestConnSendBatchQueueExecParams(t *testing.T) {
+	t.Parallel()
 
-		br = tx.SendBatch(context.Background(), batch)
+	modes := []pgx.QueryExecMode{
+		pgx.QueryExecModeCacheStatement,
+		pgx.QueryExecModeCacheDescribe,
+		pgx.QueryExecModeDescribeExec,
+		pgx.QueryExecModeExec,
+	}
 
-		ct, err := br.Exec()
-		if err != nil {
-			t.Error(err)
-		}
-		if ct.RowsAffected() != 1 {
-			t.Errorf("ct.RowsAffected() => %v, want %v", ct.RowsAffected(), 1)
-		}
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, modes, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		batch := &pgx.Batch{}
+		batch.Queue("select $1::int4", 1)
+		batch.QueueExecParams("select $1 + 1", [][]byte{[]byte("41")}, []uint32{pgtype.Int4OID}, []int16{pgx.TextFormatCode}, []int16{pgx.BinaryFormatCode})
+		batch.Queue("select $1::int4", 3)
 
-		var amount int
-		err = br.QueryRow().Scan(&amount)
-		if err != nil {
-			t.Error(err)
-		}
+		br := conn.SendBatch(ctx, batch)
 
-		br.Close()
-		tx.Commit(context.Background())
+		var n int32
+		err := br.QueryRow().Scan(&n)
+		require.NoError(t, err)
+		require.EqualValues(t, 1, n)
 
-		var count int
-		conn.QueryRow(context.Background(), "select count(1) from ledger1 where id = $1", id).Scan(&count)
-		if count != 1 {
-			t.Errorf("count => %v, want %v", count, 1)
-		}
+		rows, err := br.Query()
+		require.NoError(t, err)
+		require.True(t, rows.Next())
+		require.Equal(t, pgx.BinaryFormatCode, rows.FieldDescriptions()[0].Format)
+		require.Equal(t, []byte{0, 0, 0, 42}, rows.RawValues()[0])
+		rows.Close()
+		require.NoError(t, rows.Err())
 
-		err = br.Close()
-		if err != nil {
-			t.Fatal(err)
-		}
+		err = br.QueryRow().Scan(&n)
+		require.NoError(t, err)
+		require.EqualValues(t, 3, n)
+
+		require.NoError(t, br.Close())
+	})
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, []pgx.QueryExecMode{pgx.QueryExecModeSimpleProtocol}, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		batch := &pgx.Batch{}
+		batch.QueueExecParams("select 1", nil, nil, nil, nil)
 
+		err := conn.SendBatch(ctx, batch).Close()
+		require.EqualError(t, err, "batch item 0: QueueExecParams is not supported with QueryExecModeSimpleProtocol")
 	})
 }
 
-func TestTxSendBatchRollback(t *testing.T) {
+func TestConnSendBatchAllFieldDescriptions(t *testing.T) {
 	t.Parallel()
 
 	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
-
-		sql := `create temporary table ledger1(
-	  id serial primary key,
-	  description varchar not null
-	);`
-		mustExec(t, conn, sql)
-
-		tx, _ := conn.Begin(context.Background())
 		batch := &pgx.Batch{}
-		batch.Queue("insert into ledger1(description) values($1) returning id", "q1")
+		batch.Queue("select 1::int4 as a, 'x'::text as b")
+		batch.Queue("create temporary table all_field_descriptions(id int)")
+		batch.Queue("select 2::int8 as c")
 
-		br := tx.SendBatch(context.Background(), batch)
+		br := conn.SendBatch(ctx, batch)
+		fds, err := pgx.BatchAllFieldDescriptions(br)
+		require.NoError(t, err)
+		require.Empty(t, fds)
 
-		var id int
-		err := br.QueryRow().Scan(&id)
-		if err != nil {
-			t.Error(err)
-		}
-		br.Close()
-		tx.Rollback(context.Background())
+		rows, err := br.Query()
+		require.NoError(t, err)
+		rows.Close()
 
-		row := conn.QueryRow(context.Background(), "select count(1) from ledger1 where id = $1", id)
-		var count int
-		row.Scan(&count)
-		if count != 0 {
-			t.Errorf("count => %v, want %v", count, 0)
-		}
+		_, err = br.Exec()
+		require.NoError(t, err)
 
+		_, err = br.Exec()
+		require.NoError(t, err)
+
+		fds, err = pgx.BatchAllFieldDescriptions(br)
+		require.NoError(t, err)
+		require.Len(t, fds, 2)
+		require.Len(t, fds[0], 2)
+		require.Equal(t, "a", fds[0][0].Name)
+		require.EqualValues(t, pgtype.Int4OID, fds[0][0].DataTypeOID)
+		require.Equal(t, "b", fds[0][1].Name)
+		require.EqualValues(t, pgtype.TextOID, fds[0][1].DataTypeOID)
+		require.Len(t, fds[2], 1)
+		require.Equal(t, "c", fds[2][0].Name)
+		require.EqualValues(t, pgtype.Int8OID, fds[2][0].DataTypeOID)
+
+		require.NoError(t, br.Close())
 	})
 }
 
-func TestConnBeginBatchDeferredError(t *testing.T) {
+func TestBatchItemToSlice(t *testing.T) {
 	t.Parallel()
 
 	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		type person struct {
+			Name string `json:"name"`
+			Age  int    `json:"age"`
+		}
 
-		pgxtest.SkipCockroachDB(t, conn, "Server does not support deferred constraint (https://github.com/cockroachdb/cockroach/issues/31632)")
+		batch := &pgx.Batch{}
+		batch.Queue(`select json_agg(json_build_object('name', n, 'age', a)) from (values ('Alice', 30), ('Bob', 40)) t(n, a)`)
+		batch.Queue(`select jsonb_agg(n) from generate_series(1, 3) n`)
+		batch.Queue(`select json_agg(n) from generate_series(1, 0) n`)
+		batch.Queue(`select 1`)
 
-		mustExec(t, conn, `create temporary table t (
-		id text primary key,
-		n int not null,
-		unique (n) deferrable initially deferred
-	);
+		br := conn.SendBatch(ctx, batch)
+
+		people, err := pgx.BatchItemToSlice[person](br)
+		require.NoError(t, err)
+		require.Equal(t, []person{{"Alice", 30}, {"Bob", 40}}, people)
+
+		numbers, err := pgx.BatchItemToSlice[int](br)
+		require.NoError(t, err)
+		require.Equal(t, []int{1, 2, 3}, numbers)
+
+		numbers, err = pgx.BatchItemToSlice[int](br)
+		require.NoError(t, err)
+		require.Nil(t, numbers)
+
+		_, err = pgx.BatchItemToSlice[int](br)
+		require.Error(t, err)
+
+		require.NoError(t, br.Close())
+	})
+}
+
+func TestConnSendBatchRejectTypedNilArgs(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		var nilInt *int32
+
+		batch := &pgx.Batch{}
+		batch.Queue("select $1::int4 is null, $2::int4[] is null", nilInt, []int32(nil))
+		br := conn.SendBatch(ctx, batch)
+		var intIsNull, arrayIsNull bool
+		err := br.QueryRow().Scan(&intIsNull, &arrayIsNull)
+		require.NoError(t, err)
+		require.True(t, intIsNull)
+		require.True(t, arrayIsNull)
+		require.NoError(t, br.Close())
+		ensureConnValid(t, conn)
+
+		batch = &pgx.Batch{Options: pgx.SendBatchOptions{RejectTypedNilArgs: true}}
+		batch.Queue("select $1::int4", nil)
+		batch.Queue("select $1::int4, $2::int4", 1, nilInt)
+		err = conn.SendBatch(ctx, batch).Close()
+		require.EqualError(t, err, "batch item 1 arg 1: typed nil *int32 is not allowed with RejectTypedNilArgs")
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestCollectBatchReturning(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		mustExec(t, conn, `create temporary table widgets(id serial primary key, name text not null)`)
+
+		batch := &pgx.Batch{}
+		batch.Queue("insert into widgets(name) values ($1) returning name, id", "a")
+		batch.Queue("insert into widgets(name) values ($1) returning name, id", "b")
+		batch.Queue("insert into widgets(name) values ($1) returning name, id", "c")
+		batch.Queue("insert into widgets(name) select 'd' where false returning id")
+
+		br := conn.SendBatch(ctx, batch)
+
+		ids, err := pgx.CollectBatchReturning[int32](br, 3, "id")
+		require.NoError(t, err)
+		require.Equal(t, []int32{1, 2, 3}, ids)
+
+		_, err = pgx.CollectBatchReturning[int32](br, 1, "id")
+		require.ErrorIs(t, err, pgx.ErrNoRows)
+		require.EqualError(t, err, "CollectBatchReturning: query 0: no rows in result set")
+
+		require.NoError(t, br.Close())
+	})
+}
+
+func TestConnSendBatchNamedArgs(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		batch := &pgx.Batch{}
+		batch.Queue("select @a::int4 + @b::int4, @a::int4", pgx.NamedArgs{"a": 1, "b": 2})
+		batch.Queue("select @s::text", pgx.NamedArgs{"s": "foo"})
+
+		br := conn.SendBatch(ctx, batch)
+
+		var sum, a int32
+		require.NoError(t, br.QueryRow().Scan(&sum, &a))
+		require.EqualValues(t, 3, sum)
+		require.EqualValues(t, 1, a)
+
+		var s string
+		require.NoError(t, br.QueryRow().Scan(&s))
+		require.Equal(t, "foo", s)
+
+		require.NoError(t, br.Close())
+
+		qqs := batch.QueuedQueries()
+		require.Equal(t, "select $1::int4 + $2::int4, $1::int4", qqs[0].SQL())
+		require.Equal(t, []any{1, 2}, qqs[0].Arguments())
+	})
+}
+
+func TestConnSendBatchNamedArgsListExpansion(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		batch := &pgx.Batch{}
+		batch.Queue("select n from generate_series(1, 10) n where n in (@ns) order by n", pgx.NamedArgs{"ns": pgx.InList([]int32{2, 4, 6})})
+		batch.Queue("select n from generate_series(1, 10) n where n = any(@ns) order by n", pgx.NamedArgs{"ns": []int32{3, 5}})
+
+		br := conn.SendBatch(ctx, batch)
+
+		rows, _ := br.Query()
+		ns, err := pgx.CollectRows(rows, pgx.RowTo[int32])
+		require.NoError(t, err)
+		require.Equal(t, []int32{2, 4, 6}, ns)
+
+		rows, _ = br.Query()
+		ns, err = pgx.CollectRows(rows, pgx.RowTo[int32])
+		require.NoError(t, err)
+		require.Equal(t, []int32{3, 5}, ns)
+
+		require.NoError(t, br.Close())
+	})
+}
+
+func TestConnSendBatchQueryError(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+
+		batch := &pgx.Batch{}
+		batch.Queue("select n from generate_series(0,5) n where 100/(5-n) > 0")
+		batch.Queue("select n from generate_series(0,5) n")
+
+		br := conn.SendBatch(context.Background(), batch)
+
+		rows, err := br.Query()
+		if err != nil {
+			t.Error(err)
+		}
+
+		for i := 0; rows.Next(); i++ {
+			var n int
+			if err := rows.Scan(&n); err != nil {
+				t.Error(err)
+			}
+			if n != i {
+				t.Errorf("n => %v, want %v", n, i)
+			}
+		}
+
+		var pgErr *pgconn.PgError
+		if !(errors.As(rows.Err(), &pgErr) && pgErr.Code == "22012") {
+			t.Errorf("rows.Err() => %v, want error code %v", rows.Err(), 22012)
+		}
+
+		err = br.Close()
+		if !(errors.As(err, &pgErr) && pgErr.Code == "22012") {
+			t.Errorf("br.Close() => %v, want error code %v", err, 22012)
+		}
+
+	})
+}
+
+func TestConnSendBatchFailFast(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		batch := &pgx.Batch{Options: pgx.SendBatchOptions{FailFast: true}}
+		batch.Queue("select 1")
+		batch.Queue("select 1/0")
+		batch.Queue("select 3")
+		batch.Queue("select 4")
+
+		br := conn.SendBatch(ctx, batch)
+
+		_, err := br.Exec()
+		require.NoError(t, err)
+
+		_, err = br.Exec()
+		var pgErr *pgconn.PgError
+		require.ErrorAs(t, err, &pgErr)
+		require.Equal(t, "22012", pgErr.Code)
+
+		_, err = br.Exec()
+		require.ErrorIs(t, err, pgErr)
+
+		rows, err := br.Query()
+		require.ErrorIs(t, err, pgErr)
+		rows.Close()
+
+		require.ErrorIs(t, br.Close(), pgErr)
+
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestConnSendBatchFailedItems(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		batch := &pgx.Batch{}
+		batch.Queue("select 1")
+		batch.Queue("select 1/0")
+		batch.Queue("select 3")
+
+		br := conn.SendBatch(ctx, batch)
+
+		_, err := br.Exec()
+		require.NoError(t, err)
+		_, err = br.Exec()
+		require.Error(t, err)
+		_, err = br.Exec()
+		require.Error(t, err)
+
+		failed, err := pgx.BatchFailedItems(br)
+		require.NoError(t, err)
+		require.Equal(t, 2, failed.Len())
+		require.Equal(t, "select 1/0", failed.QueuedQueries()[0].SQL)
+		require.Equal(t, "select 3", failed.QueuedQueries()[1].SQL)
+
+		require.Error(t, br.Close())
+		failed, err = pgx.BatchFailedItems(br)
+		require.NoError(t, err)
+		require.Equal(t, 2, failed.Len())
+
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestConnSendBatchFailedItemsNoFailure(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		batch := &pgx.Batch{}
+		batch.Queue("select 1")
+		batch.Queue("select 2")
+
+		br := conn.SendBatch(ctx, batch)
+		require.NoError(t, br.Close())
+		failed, err := pgx.BatchFailedItems(br)
+		require.NoError(t, err)
+		require.Equal(t, 0, failed.Len())
+
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestConnSendBatchFailedItemsEarlyError(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		ctx, cancel := context.WithCancel(ctx)
+		cancel()
+
+		batch := &pgx.Batch{}
+		batch.Queue("select 1")
+		batch.Queue("select 2")
+
+		br := conn.SendBatch(ctx, batch)
+		require.Error(t, br.Close())
+
+		failed, err := pgx.BatchFailedItems(br)
+		require.NoError(t, err)
+		require.Equal(t, 2, failed.Len())
+		require.Equal(t, "select 1", failed.QueuedQueries()[0].SQL)
+		require.Equal(t, "select 2", failed.QueuedQueries()[1].SQL)
+	})
+}
+
+func TestConnSendBatchReadOnly(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		batch := &pgx.Batch{}
+		batch.SetReadOnly(true)
+		batch.Queue("select 1")
+		batch.Queue("show transaction_read_only")
+
+		br := conn.SendBatch(ctx, batch)
+
+		var n int32
+		require.NoError(t, br.QueryRow().Scan(&n))
+		require.EqualValues(t, 1, n)
+
+		var readOnly string
+		require.NoError(t, br.QueryRow().Scan(&readOnly))
+		require.Equal(t, "on", readOnly)
+
+		require.NoError(t, br.Close())
+
+		require.NoError(t, conn.QueryRow(ctx, "show transaction_read_only").Scan(&readOnly))
+		require.Equal(t, "off", readOnly)
+
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestConnSendBatchReadOnlyServerSideWrite(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		batch := &pgx.Batch{}
+		batch.SetReadOnly(true)
+		batch.Queue("select 1")
+		batch.Queue("do $$ begin execute 'create table pgx_read_only_batch(n int)'; end $$")
+
+		br := conn.SendBatch(ctx, batch)
+
+		_, err := br.Exec()
+		require.NoError(t, err)
+
+		_, err = br.Exec()
+		var pgErr *pgconn.PgError
+		require.ErrorAs(t, err, &pgErr)
+		require.Equal(t, "25006", pgErr.Code)
+
+		require.Error(t, br.Close())
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestConnSendBatchReadOnlyClientSideWrite(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		batch := &pgx.Batch{}
+		batch.SetReadOnly(true)
+		batch.Queue("select 1")
+		batch.Queue("insert into pgx_read_only_batch(n) values (1)")
+
+		err := conn.SendBatch(ctx, batch).Close()
+		require.EqualError(t, err, "batch item 1: write statement is not allowed in a read only batch")
+
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestConnSendBatchQueueTagged(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		type order struct{ id int }
+
+		batch := &pgx.Batch{}
+		qq := batch.QueueTagged(&order{id: 1}, "select $1::int4", 1)
+		require.Equal(t, &order{id: 1}, qq.Tag())
+		batch.Queue("select 2")
+		batch.QueueTagged("third", "select 3")
+
+		br := conn.SendBatch(ctx, batch)
+		tag, err := pgx.BatchCurrentTag(br)
+		require.NoError(t, err)
+		require.Nil(t, tag)
+
+		_, err = br.Exec()
+		require.NoError(t, err)
+		tag, err = pgx.BatchCurrentTag(br)
+		require.NoError(t, err)
+		require.Equal(t, &order{id: 1}, tag)
+
+		_, err = br.Exec()
+		require.NoError(t, err)
+		tag, err = pgx.BatchCurrentTag(br)
+		require.NoError(t, err)
+		require.Nil(t, tag)
+
+		var n int32
+		require.NoError(t, br.QueryRow().Scan(&n))
+		require.EqualValues(t, 3, n)
+		tag, err = pgx.BatchCurrentTag(br)
+		require.NoError(t, err)
+		require.Equal(t, "third", tag)
+
+		require.NoError(t, br.Close())
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestConnSendBatchExecSelect(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		batch := &pgx.Batch{}
+		batch.Queue("select n from generate_series(1, 42) n")
+		batch.Queue("select 1 where false")
+		batch.Queue("set search_path to default")
+		batch.Queue("select 4")
+
+		br := conn.SendBatch(ctx, batch)
+
+		n, err := pgx.BatchExecSelect(br)
+		require.NoError(t, err)
+		require.EqualValues(t, 42, n)
+
+		n, err = pgx.BatchExecSelect(br)
+		require.NoError(t, err)
+		require.EqualValues(t, 0, n)
+
+		_, err = pgx.BatchExecSelect(br)
+		require.EqualError(t, err, `ExecSelect: expected SELECT command tag, got "SET"`)
+
+		var i int32
+		require.NoError(t, br.QueryRow().Scan(&i))
+		require.EqualValues(t, 4, i)
+
+		require.NoError(t, br.Close())
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestConnSendBatchCloseIsIdempotent(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		closeTwice := func(closer io.Closer) (error, error) {
+			return closer.Close(), closer.Close()
+		}
+
+		// Fully read
+		batch := &pgx.Batch{}
+		batch.Queue("select 1")
+		batch.Queue("select 2")
+		br := conn.SendBatch(ctx, batch)
+		_, err := br.Exec()
+		require.NoError(t, err)
+		_, err = br.Exec()
+		require.NoError(t, err)
+		err1, err2 := closeTwice(br)
+		require.NoError(t, err1)
+		require.NoError(t, err2)
+		ensureConnValid(t, conn)
+
+		// Partially read
+		br = conn.SendBatch(ctx, batch)
+		_, err = br.Exec()
+		require.NoError(t, err)
+		err1, err2 = closeTwice(br)
+		require.NoError(t, err1)
+		require.NoError(t, err2)
+		ensureConnValid(t, conn)
+
+		// Error while reading
+		batch = &pgx.Batch{}
+		batch.Queue("select 1/0")
+		batch.Queue("select 2")
+		br = conn.SendBatch(ctx, batch)
+		_, err = br.Exec()
+		var pgErr *pgconn.PgError
+		require.ErrorAs(t, err, &pgErr)
+		err1, err2 = closeTwice(br)
+		require.ErrorAs(t, err1, &pgErr)
+		require.Equal(t, err1, err2)
+		ensureConnValid(t, conn)
+
+		// Error while closing
+		br = conn.SendBatch(ctx, batch)
+		err1, err2 = closeTwice(br)
+		require.ErrorAs(t, err1, &pgErr)
+		require.Equal(t, err1, err2)
+		ensureConnValid(t, conn)
+
+		// Error before sending
+		batch = &pgx.Batch{}
+		batch.Queue("select $1::int4", []string{"not a number"})
+		br = conn.SendBatch(ctx, batch)
+		err1, err2 = closeTwice(br)
+		require.Error(t, err1)
+		require.Equal(t, err1, err2)
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestConnSendBatchQuerySyntaxError(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+
+		batch := &pgx.Batch{}
+		batch.Queue("select 1 1")
+
+		br := conn.SendBatch(context.Background(), batch)
+
+		var n int32
+		err := br.QueryRow().Scan(&n)
+		var pgErr *pgconn.PgError
+		if !(errors.As(err, &pgErr) && pgErr.Code == "42601") {
+			t.Errorf("rows.Err() => %v, want error code %v", err, 42601)
+		}
+
+		err = br.Close()
+		if err == nil {
+			t.Error("Expected error")
+		}
+
+	})
+}
+
+func TestConnSendBatchQueryRowInsert(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+
+		sql := `create temporary table ledger(
+	  id serial primary key,
+	  description varchar not null,
+	  amount int not null
+	);`
+		mustExec(t, conn, sql)
+
+		batch := &pgx.Batch{}
+		batch.Queue("select 1")
+		batch.Queue("insert into ledger(description, amount) values($1, $2),($1, $2)", "q1", 1)
+
+		br := conn.SendBatch(context.Background(), batch)
+
+		var value int
+		err := br.QueryRow().Scan(&value)
+		if err != nil {
+			t.Error(err)
+		}
+
+		ct, err := br.Exec()
+		if err != nil {
+			t.Error(err)
+		}
+		if ct.RowsAffected() != 2 {
+			t.Errorf("ct.RowsAffected() => %v, want %v", ct.RowsAffected(), 2)
+		}
+
+		br.Close()
+
+	})
+}
+
+func TestConnSendBatchQueryPartialReadInsert(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+
+		sql := `create temporary table ledger(
+	  id serial primary key,
+	  description varchar not null,
+	  amount int not null
+	);`
+		mustExec(t, conn, sql)
+
+		batch := &pgx.Batch{}
+		batch.Queue("select 1 union all select 2 union all select 3")
+		batch.Queue("insert into ledger(description, amount) values($1, $2),($1, $2)", "q1", 1)
+
+		br := conn.SendBatch(context.Background(), batch)
+
+		rows, err := br.Query()
+		if err != nil {
+			t.Error(err)
+		}
+		rows.Close()
+
+		ct, err := br.Exec()
+		if err != nil {
+			t.Error(err)
+		}
+		if ct.RowsAffected() != 2 {
+			t.Errorf("ct.RowsAffected() => %v, want %v", ct.RowsAffected(), 2)
+		}
+
+		br.Close()
+
+	})
+}
+
+func TestTxSendBatch(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+
+		sql := `create temporary table ledger1(
+	  id serial primary key,
+	  description varchar not null
+	);`
+		mustExec(t, conn, sql)
+
+		sql = `create temporary table ledger2(
+	  id int primary key,
+	  amount int not null
+	);`
+		mustExec(t, conn, sql)
+
+		tx, _ := conn.Begin(context.Background())
+		batch := &pgx.Batch{}
+		batch.Queue("insert into ledger1(description) values($1) returning id", "q1")
+
+		br := tx.SendBatch(context.Background(), batch)
+
+		var id int
+		err := br.QueryRow().Scan(&id)
+		if err != nil {
+			t.Error(err)
+		}
+		br.Close()
+
+		batch = &pgx.Batch{}
+		batch.Queue("insert into ledger2(id,amount) values($1, $2)", id, 2)
+		batch.Queue("select amount from ledger2 where id = $1", id)
+
+		br = tx.SendBatch(context.Background(), batch)
+
+		ct, err := br.Exec()
+		if err != nil {
+			t.Error(err)
+		}
+		if ct.RowsAffected() != 1 {
+			t.Errorf("ct.RowsAffected() => %v, want %v", ct.RowsAffected(), 1)
+		}
+
+		var amount int
+		err = br.QueryRow().Scan(&amount)
+		if err != nil {
+			t.Error(err)
+		}
+
+		br.Close()
+		tx.Commit(context.Background())
+
+		var count int
+		conn.QueryRow(context.Background(), "select count(1) from ledger1 where id = $1", id).Scan(&count)
+		if count != 1 {
+			t.Errorf("count => %v, want %v", count, 1)
+		}
+
+		err = br.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+	})
+}
+
+func TestTxSendBatchRollback(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+
+		sql := `create temporary table ledger1(
+	  id serial primary key,
+	  description varchar not null
+	);`
+		mustExec(t, conn, sql)
+
+		tx, _ := conn.Begin(context.Background())
+		batch := &pgx.Batch{}
+		batch.Queue("insert into ledger1(description) values($1) returning id", "q1")
+
+		br := tx.SendBatch(context.Background(), batch)
+
+		var id int
+		err := br.QueryRow().Scan(&id)
+		if err != nil {
+			t.Error(err)
+		}
+		br.Close()
+		tx.Rollback(context.Background())
+
+		row := conn.QueryRow(context.Background(), "select count(1) from ledger1 where id = $1", id)
+		var count int
+		row.Scan(&count)
+		if count != 0 {
+			t.Errorf("count => %v, want %v", count, 0)
+		}
+
+	})
+}
+
+func TestConnSendBatchInTransaction(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		batch := &pgx.Batch{}
+		batch.Queue("select 1")
+		br := conn.SendBatch(ctx, batch)
+		inTx, err := pgx.BatchInTransaction(br)
+		require.NoError(t, err)
+		require.False(t, inTx)
+		require.NoError(t, br.Close())
+
+		tx, err := conn.Begin(ctx)
+		require.NoError(t, err)
+		defer tx.Rollback(ctx)
+
+		batch = &pgx.Batch{}
+		batch.Queue("select 1")
+		br = tx.SendBatch(ctx, batch)
+		inTx, err = pgx.BatchInTransaction(br)
+		require.NoError(t, err)
+		require.True(t, inTx)
+		require.NoError(t, br.Close())
+
+		// A transaction started by the batch itself does not count.
+		require.NoError(t, tx.Rollback(ctx))
+		batch = &pgx.Batch{}
+		batch.Queue("begin")
+		batch.Queue("select 1")
+		batch.Queue("commit")
+		br = conn.SendBatch(ctx, batch)
+		inTx, err = pgx.BatchInTransaction(br)
+		require.NoError(t, err)
+		require.False(t, inTx)
+		require.NoError(t, br.Close())
+	})
+}
+
+func TestConnSendBatchVerifyComplete(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		batch := &pgx.Batch{}
+		batch.Queue("select 1")
+		batch.Queue("select 2")
+
+		br := conn.SendBatch(ctx, batch)
+		_, err := br.Exec()
+		require.NoError(t, err)
+		require.EqualError(t, pgx.VerifyBatchComplete(br), "batch has 2 queued queries but only 1 results were read")
+
+		_, err = br.Exec()
+		require.NoError(t, err)
+		require.NoError(t, pgx.VerifyBatchComplete(br))
+
+		_, err = br.Exec()
+		require.Error(t, err)
+		require.EqualError(t, pgx.VerifyBatchComplete(br), "batch has 2 queued queries but 3 results were requested")
+		require.NoError(t, br.Close())
+
+		br = conn.SendBatch(ctx, batch)
+		require.NoError(t, br.Close())
+		require.NoError(t, pgx.VerifyBatchComplete(br))
+	})
+}
+
+type closeCountingBatchResults struct {
+	pgx.BatchResults
+	closeCount *int
+}
+
+func (br closeCountingBatchResults) Close() error {
+	*br.closeCount++
+	return br.BatchResults.Close()
+}
+
+func TestConnSendBatchResultsMiddleware(t *testing.T) {
+	t.Parallel()
+
+	closeCount := 0
+	ctr := defaultConnTestRunner
+	ctr.CreateConfig = func(ctx context.Context, t testing.TB) *pgx.ConnConfig {
+		config := defaultConnTestRunner.CreateConfig(ctx, t)
+		config.BatchResultsMiddleware = func(br pgx.BatchResults) pgx.BatchResults {
+			return closeCountingBatchResults{BatchResults: br, closeCount: &closeCount}
+		}
+		return config
+	}
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, ctr, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		closeCount = 0
+
+		batch := &pgx.Batch{}
+		batch.Queue("select 1")
+		br := conn.SendBatch(ctx, batch)
+		require.IsType(t, closeCountingBatchResults{}, br)
+		require.NoError(t, br.Close())
+		require.Equal(t, 1, closeCount)
+
+		tx, err := conn.Begin(ctx)
+		require.NoError(t, err)
+		defer tx.Rollback(ctx)
+
+		batch = &pgx.Batch{}
+		batch.Queue("select 1")
+		require.NoError(t, tx.SendBatch(ctx, batch).Close())
+		require.Equal(t, 2, closeCount)
+	})
+}
+
+func TestConnSendBatchSetApplicationName(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		var original string
+		err := conn.QueryRow(ctx, "show application_name").Scan(&original)
+		require.NoError(t, err)
+
+		batch := &pgx.Batch{}
+		batch.SetApplicationName("request-1234")
+		batch.Queue("select current_setting('application_name')")
+		batch.Queue("select application_name from pg_stat_activity where pid = pg_backend_pid()")
+
+		br := conn.SendBatch(ctx, batch)
+		var name string
+		err = br.QueryRow().Scan(&name)
+		require.NoError(t, err)
+		require.Equal(t, "request-1234", name)
+		err = br.QueryRow().Scan(&name)
+		require.NoError(t, err)
+		require.Equal(t, "request-1234", name)
+		require.NoError(t, pgx.VerifyBatchComplete(br))
+		require.NoError(t, br.Close())
+
+		err = conn.QueryRow(ctx, "show application_name").Scan(&name)
+		require.NoError(t, err)
+		require.Equal(t, original, name)
+
+		// A failed batch also reverts the application name.
+		batch = &pgx.Batch{}
+		batch.SetApplicationName("request-5678")
+		batch.Queue("select 1/0")
+		require.Error(t, conn.SendBatch(ctx, batch).Close())
+
+		err = conn.QueryRow(ctx, "show application_name").Scan(&name)
+		require.NoError(t, err)
+		require.Equal(t, original, name)
+	})
+}
+
+func TestConnBeginBatchDeferredError(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+
+		pgxtest.SkipCockroachDB(t, conn, "Server does not support deferred constraint (https://github.com/cockroachdb/cockroach/issues/31632)")
+
+		mustExec(t, conn, `create temporary table t (
+		id text primary key,
+		n int not null,
+		unique (n) deferrable initially deferred
+	);
 
 	insert into t (id, n) values ('a', 1), ('b', 2), ('c', 3);`)
 
@@ -760,13 +1637,51 @@ func TestConnBeginBatchDeferredError(t *testing.T) {
 			t.Fatal("expected error 23505 but got none")
 		}
 
-		if err, ok := err.(*pgconn.PgError); !ok || err.Code != "23505" {
+		var pgErr *pgconn.PgError
+		if !errors.As(err, &pgErr) || pgErr.Code != "23505" {
 			t.Fatalf("expected error 23505, got %v", err)
 		}
 
 	})
 }
 
+func TestConnDeallocateBatchStatements(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, []pgx.QueryExecMode{pgx.QueryExecModeCacheStatement}, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		_, err := conn.Prepare(ctx, "ps", "select 1")
+		require.NoError(t, err)
+
+		countPreparedStatements := func() int {
+			var n int
+			err := conn.QueryRow(ctx, "select count(*) from pg_prepared_statements", pgx.QueryExecModeSimpleProtocol).Scan(&n)
+			require.NoError(t, err)
+			return n
+		}
+		baseline := countPreparedStatements()
+
+		batch := &pgx.Batch{}
+		batch.Queue("ps")
+		batch.Queue("select $1::int4", 1)
+		batch.Queue("select $1::int4", 2)
+		batch.Queue("select $1::text", "x")
+		err = conn.SendBatch(ctx, batch).Close()
+		require.NoError(t, err)
+		require.Equal(t, baseline+2, countPreparedStatements())
+
+		err = conn.DeallocateBatchStatements(ctx, batch)
+		require.NoError(t, err)
+		require.Equal(t, baseline, countPreparedStatements())
+
+		// The explicitly prepared statement and the deallocated queries can still be used.
+		batch = &pgx.Batch{}
+		batch.Queue("ps")
+		batch.Queue("select $1::int4", 1)
+		err = conn.SendBatch(ctx, batch).Close()
+		require.NoError(t, err)
+	})
+}
+
 func TestConnSendBatchNoStatementCache(t *testing.T) {
 	config := mustParseConfig(t, os.Getenv("PGX_TEST_DATABASE"))
 	config.DefaultQueryExecMode = pgx.QueryExecModeDescribeExec
@@ -923,3 +1838,461 @@ func ExampleConn_SendBatch() {
 	// 3
 	// 5
 }
+
+func TestBatchWriteTo(t *testing.T) {
+	t.Parallel()
+
+	batch := &pgx.Batch{}
+	batch.Queue("create table t(n int)")
+	batch.Queue("insert into t(n) values ($1);  \n", 1)
+	batch.Queue("select * from t where n = @n", pgx.NamedArgs{"n": 1})
+
+	var buf bytes.Buffer
+	n, err := batch.WriteTo(&buf)
+	require.NoError(t, err)
+
+	expected := "create table t(n int);\ninsert into t(n) values ($1);\nselect * from t where n = @n;\n"
+	require.Equal(t, expected, buf.String())
+	require.EqualValues(t, len(expected), n)
+}
+
+func TestConnSendBatchItemNotices(t *testing.T) {
+	t.Parallel()
+
+	var connNotices []string
+	ctr := defaultConnTestRunner
+	ctr.CreateConfig = func(ctx context.Context, t testing.TB) *pgx.ConnConfig {
+		config := defaultConnTestRunner.CreateConfig(ctx, t)
+		config.OnNotice = func(c *pgconn.PgConn, notice *pgconn.Notice) {
+			connNotices = append(connNotices, notice.Message)
+		}
+		config.RuntimeParams["client_min_messages"] = "notice"
+		return config
+	}
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, ctr, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		pgxtest.SkipCockroachDB(t, conn, "Server does not support PL/PGSQL (https://github.com/cockroachdb/cockroach/issues/17511)")
+
+		for _, buffered := range []bool{false, true} {
+			connNotices = nil
+
+			batch := &pgx.Batch{Options: pgx.SendBatchOptions{Buffered: buffered}}
+			batch.Queue("select 1")
+			batch.Queue(`do $$
+begin
+  raise notice 'first';
+  raise warning 'second';
+end$$`)
+			batch.Queue("select 2")
+
+			br := conn.SendBatch(ctx, batch)
+			for i := 0; i < batch.Len(); i++ {
+				_, err := br.Exec()
+				require.NoError(t, err)
+			}
+
+			notices, err := pgx.BatchItemNotices(br, 0)
+			require.NoError(t, err)
+			require.Nil(t, notices)
+			notices, err = pgx.BatchItemNotices(br, 1)
+			require.NoError(t, err)
+			require.Len(t, notices, 2)
+			require.Equal(t, "NOTICE", notices[0].Severity)
+			require.Equal(t, "first", notices[0].Message)
+			require.Equal(t, "WARNING", notices[1].Severity)
+			require.Equal(t, "second", notices[1].Message)
+			notices, err = pgx.BatchItemNotices(br, 2)
+			require.NoError(t, err)
+			require.Nil(t, notices)
+
+			require.NoError(t, br.Close())
+			require.Equal(t, []string{"first", "second"}, connNotices)
+		}
+
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestConnSendBatchInto(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		rbr := &pgx.ReusableBatchResults{}
+
+		for i := 0; i < 3; i++ {
+			batch := &pgx.Batch{}
+			batch.Queue("select n::int4 from generate_series(1, $1::int4) n", i+1)
+			batch.Queue("select $1::text as s", fmt.Sprint(i))
+
+			br := conn.SendBatchInto(ctx, batch, rbr)
+
+			rows, err := br.Query()
+			require.NoError(t, err)
+			nums, err := pgx.CollectRows(rows, pgx.RowTo[int32])
+			require.NoError(t, err)
+			require.Len(t, nums, i+1)
+
+			var s string
+			err = br.QueryRow().Scan(&s)
+			require.NoError(t, err)
+			require.Equal(t, fmt.Sprint(i), s)
+
+			fds, err := pgx.BatchAllFieldDescriptions(br)
+			require.NoError(t, err)
+			require.Len(t, fds, 2)
+			require.NoError(t, pgx.VerifyBatchComplete(br))
+			require.NoError(t, br.Close())
+		}
+
+		batch := &pgx.Batch{}
+		batch.Queue("select 1/0")
+		err := conn.SendBatchInto(ctx, batch, rbr).Close()
+		var pgErr *pgconn.PgError
+		require.ErrorAs(t, err, &pgErr)
+		require.Equal(t, "22012", pgErr.Code)
+
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestConnSendBatchOnFlush(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		var flushes []int
+		batch := &pgx.Batch{Options: pgx.SendBatchOptions{OnFlush: func(itemsFlushed int) {
+			flushes = append(flushes, itemsFlushed)
+		}}}
+		batch.Queue("select 1")
+		batch.Queue("select 2")
+		batch.Queue("select 3")
+
+		br := conn.SendBatch(ctx, batch)
+		require.Equal(t, []int{3}, flushes)
+		require.NoError(t, br.Close())
+
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestBatchFreeze(t *testing.T) {
+	t.Parallel()
+
+	batch := &pgx.Batch{}
+	batch.Queue("select 1")
+	require.False(t, batch.Frozen())
+
+	frozen := batch.Freeze()
+	require.Same(t, batch, frozen)
+	require.True(t, frozen.Frozen())
+
+	require.PanicsWithValue(t, "pgx: cannot modify a frozen Batch", func() { frozen.Queue("select 2") })
+	require.Panics(t, func() { frozen.QueueLimited("select 2", 1) })
+	require.Panics(t, func() { frozen.QueueTagged("tag", "select 2") })
+	require.Panics(t, func() { frozen.QueueConditional("select 2") })
+	require.Panics(t, func() { frozen.QueueExecParams("select 2", nil, nil, nil, nil) })
+	require.Panics(t, func() { frozen.SetApplicationName("app") })
+	require.Panics(t, func() { frozen.SetReadOnly(true) })
+	require.Equal(t, 1, frozen.Len())
+
+	clone := frozen.Clone()
+	require.False(t, clone.Frozen())
+	clone.Queue("select 2")
+	require.Equal(t, 2, clone.Len())
+	require.Equal(t, 1, frozen.Len())
+}
+
+func TestConnSendBatchFrozen(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		batch := &pgx.Batch{}
+		batch.Queue("select $1::int4", 1)
+		batch.Freeze()
+
+		var n int32
+		br := conn.SendBatch(ctx, batch)
+		require.NoError(t, br.QueryRow().Scan(&n))
+		require.EqualValues(t, 1, n)
+		require.NoError(t, br.Close())
+	})
+}
+
+func TestConnSendBatchAutoRegisterTypes(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, pgxtest.KnownOIDQueryExecModes, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		pgxtest.SkipCockroachDB(t, conn, "Server does not support composite types")
+
+		tx, err := conn.Begin(ctx)
+		require.NoError(t, err)
+		defer tx.Rollback(ctx)
+
+		_, err = tx.Exec(ctx, `create type auto_register_mood as enum ('sad', 'happy');
+create type auto_register_person as (name text, moods auto_register_mood[]);`)
+		require.NoError(t, err)
+
+		batch := &pgx.Batch{}
+		batch.Options.AutoRegisterTypes = true
+		batch.Queue("select row('Alice', array['happy']::auto_register_mood[])::auto_register_person")
+
+		var name string
+		var moods []string
+		br := tx.SendBatch(ctx, batch)
+		require.NoError(t, br.QueryRow().Scan(pgtype.CompositeFields{&name, &moods}))
+		require.NoError(t, br.Close())
+		require.Equal(t, "Alice", name)
+		require.Equal(t, []string{"happy"}, moods)
+
+		for _, typeName := range []string{"auto_register_mood", "_auto_register_mood", "auto_register_person"} {
+			_, ok := conn.TypeMap().TypeForName(typeName)
+			require.Truef(t, ok, "%s was not registered", typeName)
+		}
+	})
+}
+
+func TestConnSendBatchWhileBatchInFlight(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		batch := &pgx.Batch{}
+		batch.Queue("select 1")
+		br := conn.SendBatch(ctx, batch)
+
+		busyBatch := &pgx.Batch{}
+		busyBatch.Queue("select 2")
+		busyBR := conn.SendBatch(ctx, busyBatch)
+		_, err := busyBR.Exec()
+		require.ErrorIs(t, err, pgx.ErrConnBusy)
+		require.ErrorIs(t, busyBR.Close(), pgx.ErrConnBusy)
+
+		// Closing the results of the rejected batch must not release the connection for another batch.
+		_, err = conn.SendBatch(ctx, busyBatch).Exec()
+		require.ErrorIs(t, err, pgx.ErrConnBusy)
+
+		var n int32
+		require.NoError(t, br.QueryRow().Scan(&n))
+		require.EqualValues(t, 1, n)
+		require.NoError(t, br.Close())
+
+		br = conn.SendBatch(ctx, busyBatch)
+		require.NoError(t, br.QueryRow().Scan(&n))
+		require.EqualValues(t, 2, n)
+		require.NoError(t, br.Close())
+
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestBatchReset(t *testing.T) {
+	batch := &pgx.Batch{}
+	first := batch.QueueTagged("tag", "select $1::int4", 1)
+	batch.SetReadOnly(true)
+	batch.Options.Buffered = true
+
+	batch.Reset()
+	require.Equal(t, 0, batch.Len())
+	require.Equal(t, pgx.SendBatchOptions{}, batch.Options)
+
+	reused := batch.Queue("select 2")
+	require.Same(t, first, reused)
+	require.Equal(t, "select 2", reused.SQL())
+	require.Nil(t, reused.Arguments())
+	require.Nil(t, reused.Tag())
+
+	batch.Queue("select 3")
+	require.Equal(t, 2, batch.Len())
+
+	allocs := testing.AllocsPerRun(10, func() {
+		batch.Reset()
+		batch.Queue("select 1")
+		batch.Queue("select 2")
+	})
+	require.Zero(t, allocs)
+
+	batch.Freeze()
+	require.Panics(t, func() { batch.Reset() })
+}
+
+func TestConnSendBatchAfterReset(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		batch := &pgx.Batch{}
+		for i := 0; i < 3; i++ {
+			batch.Reset()
+			batch.Queue("select $1::int4", i)
+			batch.Queue("select $1::int4", i+1)
+
+			br := conn.SendBatch(ctx, batch)
+			for j := 0; j < 2; j++ {
+				var n int32
+				require.NoError(t, br.QueryRow().Scan(&n))
+				require.EqualValues(t, i+j, n)
+			}
+			require.NoError(t, br.Close())
+		}
+
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestBatchAppendBatch(t *testing.T) {
+	t.Parallel()
+
+	batch := &pgx.Batch{}
+	batch.Queue("select 1")
+
+	other := &pgx.Batch{}
+	other.QueueTagged("tag", "select $1::int4", 2)
+	other.SetReadOnly(true)
+	other.Freeze()
+
+	batch.AppendBatch(other)
+	require.Equal(t, 2, batch.Len())
+	require.Equal(t, 1, other.Len())
+
+	qqs := batch.QueuedQueries()
+	require.Equal(t, "select $1::int4", qqs[1].SQL())
+	require.Equal(t, []any{2}, qqs[1].Arguments())
+	require.Equal(t, "tag", qqs[1].Tag())
+
+	batch.Queue("select 3")
+	require.Equal(t, 1, other.Len())
+
+	batch.Freeze()
+	require.Panics(t, func() { batch.AppendBatch(other) })
+}
+
+func TestConnSendBatchAppendBatch(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		var results []int32
+
+		first := &pgx.Batch{}
+		first.Queue("select $1::int4", 1).QueryRow(func(row pgx.Row) error {
+			var n int32
+			err := row.Scan(&n)
+			results = append(results, n)
+			return err
+		})
+
+		second := &pgx.Batch{}
+		second.Queue("select $1::int4", 2).QueryRow(func(row pgx.Row) error {
+			var n int32
+			err := row.Scan(&n)
+			results = append(results, n)
+			return err
+		})
+
+		batch := &pgx.Batch{}
+		batch.AppendBatch(first)
+		batch.AppendBatch(second)
+
+		require.NoError(t, conn.SendBatch(ctx, batch).Close())
+		require.Equal(t, []int32{1, 2}, results)
+
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestConnSendBatchSkip(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		batch := &pgx.Batch{}
+		batch.Queue("set local work_mem = '8MB'")
+		batch.Queue("select n from generate_series(1, 100) n")
+		batch.Queue("select $1::int4", 42)
+
+		br := conn.SendBatch(ctx, batch)
+		require.NoError(t, pgx.BatchSkip(br, 2))
+
+		var n int32
+		require.NoError(t, br.QueryRow().Scan(&n))
+		require.EqualValues(t, 42, n)
+		require.NoError(t, br.Close())
+
+		ensureConnValid(t, conn)
+	})
+}
+
+// minimalBatchResults implements only the methods of the pgx.BatchResults interface.
+type minimalBatchResults struct {
+	execCount int
+}
+
+func (br *minimalBatchResults) Exec() (pgconn.CommandTag, error) {
+	br.execCount++
+	return pgconn.NewCommandTag("SELECT 1"), nil
+}
+
+func (br *minimalBatchResults) Query() (pgx.Rows, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (br *minimalBatchResults) QueryRow() pgx.Row {
+	return nil
+}
+
+func (br *minimalBatchResults) Close() error {
+	return nil
+}
+
+func TestBatchResultsFunctionsWithMinimalBatchResults(t *testing.T) {
+	t.Parallel()
+
+	br := &minimalBatchResults{}
+
+	n, err := pgx.BatchExecSelect(br)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, n)
+
+	require.NoError(t, pgx.BatchSkip(br, 2))
+	require.Equal(t, 3, br.execCount)
+
+	_, err = pgx.BatchAll(br)
+	require.EqualError(t, err, "All: *pgx_test.minimalBatchResults does not support All")
+
+	rows := pgx.BatchAsRows(br)
+	require.False(t, rows.Next())
+	require.Error(t, rows.Err())
+
+	_, err = pgx.BatchInTransaction(br)
+	require.EqualError(t, err, "InTransaction: *pgx_test.minimalBatchResults does not support InTransaction")
+	_, err = pgx.BatchCurrentTag(br)
+	require.Error(t, err)
+	_, err = pgx.BatchResultsStats(br)
+	require.Error(t, err)
+}
+
+// inTxBatchResults is a minimalBatchResults that reports that the batch was sent in a transaction.
+type inTxBatchResults struct {
+	minimalBatchResults
+}
+
+func (br *inTxBatchResults) InTransaction() bool {
+	return true
+}
+
+// unwrappingBatchResults wraps a pgx.BatchResults like a ConnConfig.BatchResultsMiddleware.
+type unwrappingBatchResults struct {
+	pgx.BatchResults
+}
+
+func (br *unwrappingBatchResults) Unwrap() pgx.BatchResults {
+	return br.BatchResults
+}
+
+func TestBatchResultsFunctionsFollowUnwrap(t *testing.T) {
+	t.Parallel()
+
+	br := &unwrappingBatchResults{BatchResults: &unwrappingBatchResults{BatchResults: &inTxBatchResults{}}}
+	inTx, err := pgx.BatchInTransaction(br)
+	require.NoError(t, err)
+	require.True(t, inTx)
+
+	_, err = pgx.BatchCurrentTag(br)
+	require.EqualError(t, err, "CurrentTag: *pgx_test.unwrappingBatchResults does not support CurrentTag")
+}
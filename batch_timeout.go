@@ -0,0 +1,78 @@
+package pgx
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// BatchStatementTimeoutError is returned when a query in a batch is canceled by statement_timeout. It identifies the
+// query that timed out.
+type BatchStatementTimeoutError struct {
+	// ItemIndex is the index of the query in the batch.
+	ItemIndex int
+
+	// SQL is the SQL of the query as it was queued.
+	SQL string
+
+	// Err is the error returned by the server. It contains a *pgconn.PgError with code 57014.
+	Err error
+}
+
+func (e *BatchStatementTimeoutError) Error() string {
+	return fmt.Sprintf("batch item %d (%s): %v", e.ItemIndex, e.SQL, e.Err)
+}
+
+func (e *BatchStatementTimeoutError) Unwrap() error {
+	return e.Err
+}
+
+// IsBatchStatementTimeout returns the index of the batched query that was canceled by statement_timeout and true if err
+// is or wraps a *BatchStatementTimeoutError.
+func IsBatchStatementTimeout(err error) (itemIndex int, ok bool) {
+	var timeoutErr *BatchStatementTimeoutError
+	if errors.As(err, &timeoutErr) {
+		return timeoutErr.ItemIndex, true
+	}
+	return 0, false
+}
+
+// isStatementTimeout reports whether pgErr was caused by statement_timeout. query_canceled (57014) is also used when a
+// query is canceled by a cancel request so the message must be checked as well. This requires lc_messages to be English.
+func isStatementTimeout(pgErr *pgconn.PgError) bool {
+	return pgErr.Code == "57014" && strings.Contains(pgErr.Message, "statement timeout")
+}
+
+// WithTimeout limits the time the server may spend running qq to d and returns qq. It is enforced by the server with
+// statement_timeout, which is set for qq only. If qq exceeds d it is canceled and the error returned for it is a
+// *BatchStatementTimeoutError. As with any error in a batch, the queries after qq in the same implicit transaction are
+// not run. d is rounded up to a whole millisecond and must be greater than 0.
+//
+// Each query with a timeout costs two additional statements in the batch to set and restore statement_timeout.
+func (qq *QueuedQuery) WithTimeout(d time.Duration) *QueuedQuery {
+	if d <= 0 {
+		panic(fmt.Sprintf("WithTimeout: d must be greater than 0, got %v", d))
+	}
+
+	qq.timeout = d
+	return qq
+}
+
+// setItemStatementTimeoutSQL sets statement_timeout to $1 for a query queued with QueuedQuery.WithTimeout. The previous
+// value is saved so it can be restored by restoreItemStatementTimeoutSQL after the query. Both settings are local to
+// the implicit transaction of the batch.
+const setItemStatementTimeoutSQL = "select set_config('pgx.saved_statement_timeout', current_setting('statement_timeout'), true), set_config('statement_timeout', $1, true)"
+
+// restoreItemStatementTimeoutSQL restores the statement_timeout saved by setItemStatementTimeoutSQL.
+const restoreItemStatementTimeoutSQL = "select set_config('statement_timeout', current_setting('pgx.saved_statement_timeout'), true)"
+
+// statementTimeoutSetting returns the value of statement_timeout in milliseconds for d. d is rounded up because 0
+// disables the timeout.
+func statementTimeoutSetting(d time.Duration) string {
+	ms := (d + time.Millisecond - 1) / time.Millisecond
+	return strconv.FormatInt(int64(ms), 10)
+}
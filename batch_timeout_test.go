@@ -0,0 +1,143 @@
+package pgx_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxtest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsBatchStatementTimeout(t *testing.T) {
+	t.Parallel()
+
+	pgErr := &pgconn.PgError{Code: "57014", Message: "canceling statement due to statement timeout"}
+	err := fmt.Errorf("wrapped: %w", &pgx.BatchStatementTimeoutError{ItemIndex: 2, SQL: "select pg_sleep(10)", Err: pgErr})
+
+	idx, ok := pgx.IsBatchStatementTimeout(err)
+	require.True(t, ok)
+	require.Equal(t, 2, idx)
+	require.ErrorIs(t, err, pgErr)
+	require.ErrorContains(t, err, "batch item 2 (select pg_sleep(10))")
+
+	_, ok = pgx.IsBatchStatementTimeout(pgErr)
+	require.False(t, ok)
+
+	_, ok = pgx.IsBatchStatementTimeout(errors.New("other"))
+	require.False(t, ok)
+}
+
+func TestConnSendBatchStatementTimeout(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		batch := &pgx.Batch{}
+		batch.Queue("set statement_timeout = 50")
+		batch.Queue("select 1")
+		batch.Queue("select pg_sleep(5)")
+		batch.Queue("select 3")
+
+		br := conn.SendBatch(ctx, batch)
+
+		_, err := br.Exec()
+		require.NoError(t, err)
+		_, err = br.Exec()
+		require.NoError(t, err)
+
+		_, err = br.Exec()
+		idx, ok := pgx.IsBatchStatementTimeout(err)
+		require.Truef(t, ok, "%v", err)
+		require.Equal(t, 2, idx)
+		require.ErrorContains(t, err, "select pg_sleep(5)")
+
+		var pgErr *pgconn.PgError
+		require.ErrorAs(t, err, &pgErr)
+		require.Equal(t, "57014", pgErr.Code)
+
+		require.Error(t, br.Close())
+
+		_, err = conn.Exec(ctx, "set statement_timeout = 0")
+		require.NoError(t, err)
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestConnSendBatchStatementTimeoutQuery(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		batch := &pgx.Batch{}
+		batch.Queue("set statement_timeout = 50")
+		batch.Queue("select pg_sleep(5)")
+
+		br := conn.SendBatch(ctx, batch)
+
+		_, err := br.Exec()
+		require.NoError(t, err)
+
+		rows, err := br.Query()
+		if err == nil {
+			for rows.Next() {
+			}
+			err = rows.Err()
+		}
+		idx, ok := pgx.IsBatchStatementTimeout(err)
+		require.Truef(t, ok, "%v", err)
+		require.Equal(t, 1, idx)
+
+		require.Error(t, br.Close())
+
+		_, err = conn.Exec(ctx, "set statement_timeout = 0")
+		require.NoError(t, err)
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestConnSendBatchWithTimeout(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		batch := &pgx.Batch{}
+		batch.Queue("select 1").WithTimeout(time.Minute)
+		batch.Queue("select current_setting('statement_timeout')")
+		batch.Queue("select pg_sleep(5)").WithTimeout(50 * time.Millisecond)
+		batch.Queue("select 3")
+
+		br := conn.SendBatch(ctx, batch)
+
+		var n int32
+		require.NoError(t, br.QueryRow().Scan(&n))
+		require.EqualValues(t, 1, n)
+
+		var statementTimeout string
+		require.NoError(t, br.QueryRow().Scan(&statementTimeout))
+		require.Equal(t, "0", statementTimeout)
+
+		_, err := br.Exec()
+		idx, ok := pgx.IsBatchStatementTimeout(err)
+		require.Truef(t, ok, "%v", err)
+		require.Equal(t, 2, idx)
+
+		_, err = br.Exec()
+		require.Error(t, err)
+
+		require.Error(t, br.Close())
+
+		err = conn.QueryRow(ctx, "select current_setting('statement_timeout')").Scan(&statementTimeout)
+		require.NoError(t, err)
+		require.Equal(t, "0", statementTimeout)
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestQueuedQueryWithTimeoutPanicsOnNonPositiveDuration(t *testing.T) {
+	t.Parallel()
+
+	batch := &pgx.Batch{}
+	require.Panics(t, func() { batch.Queue("select 1").WithTimeout(0) })
+}
@@ -0,0 +1,52 @@
+package pgx
+
+import (
+	"context"
+)
+
+// BatchTx begins a transaction, calls fn to queue queries to a new Batch, and sends the batch in the transaction. If fn
+// returns an error, the batch cannot be sent, or any query in the batch fails the transaction is rolled back and the
+// first error is returned. Otherwise the transaction is committed and the results of the batch are returned.
+//
+// The results are read into memory before the transaction ends as with SendBatchOptions.Buffered, so the connection is
+// available for use as soon as BatchTx returns. fn may set other options of the batch. Callback functions registered
+// with QueuedQuery.Query, QueuedQuery.QueryRow, and QueuedQuery.Exec are run when the returned results are read or
+// closed, which is after the transaction is committed, so they cannot cause it to be rolled back.
+func (c *Conn) BatchTx(ctx context.Context, fn func(*Batch) error) (BatchResults, error) {
+	tx, err := c.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx) // does nothing if the transaction was committed. Otherwise there is already an error to return.
+	}()
+
+	b := &Batch{}
+	err = fn(b)
+	if err != nil {
+		return nil, err
+	}
+
+	b.Options.Buffered = true
+	br := c.sendBatchInto(ctx, b, nil)
+	err = br.earlyError()
+	if err != nil {
+		br.Close()
+	} else if bbr, ok := br.(*bufferedBatchResults); ok {
+		err = bbr.firstError()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.config.BatchResultsMiddleware != nil {
+		return c.config.BatchResultsMiddleware(br), nil
+	}
+
+	return br, nil
+}
@@ -0,0 +1,178 @@
+package pgx_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxtest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnBatchTx(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		_, err := conn.Exec(ctx, "create temporary table batch_tx(n int primary key)")
+		require.NoError(t, err)
+
+		br, err := conn.BatchTx(ctx, func(b *pgx.Batch) error {
+			b.Queue("insert into batch_tx(n) values (1)")
+			b.Queue("insert into batch_tx(n) values (2)")
+			b.Queue("select count(*) from batch_tx")
+			return nil
+		})
+		require.NoError(t, err)
+
+		_, err = br.Exec()
+		require.NoError(t, err)
+		_, err = br.Exec()
+		require.NoError(t, err)
+		var n int64
+		require.NoError(t, br.QueryRow().Scan(&n))
+		require.EqualValues(t, 2, n)
+		require.NoError(t, br.Close())
+
+		require.NoError(t, conn.QueryRow(ctx, "select count(*) from batch_tx").Scan(&n))
+		require.EqualValues(t, 2, n)
+		require.Equal(t, byte('I'), conn.PgConn().TxStatus())
+
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestConnBatchTxRollsBackOnQueryError(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		_, err := conn.Exec(ctx, "create temporary table batch_tx(n int primary key)")
+		require.NoError(t, err)
+
+		br, err := conn.BatchTx(ctx, func(b *pgx.Batch) error {
+			b.Queue("insert into batch_tx(n) values (1)")
+			b.Queue("insert into batch_tx(n) values (1)")
+			return nil
+		})
+		require.Nil(t, br)
+		var pgErr *pgconn.PgError
+		require.ErrorAs(t, err, &pgErr)
+		require.Equal(t, "23505", pgErr.Code)
+
+		var n int64
+		require.NoError(t, conn.QueryRow(ctx, "select count(*) from batch_tx").Scan(&n))
+		require.EqualValues(t, 0, n)
+		require.Equal(t, byte('I'), conn.PgConn().TxStatus())
+
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestConnBatchTxRollsBackOnFnError(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		fnErr := errors.New("fn failed")
+		br, err := conn.BatchTx(ctx, func(b *pgx.Batch) error {
+			b.Queue("select 1")
+			return fnErr
+		})
+		require.Nil(t, br)
+		require.ErrorIs(t, err, fnErr)
+		require.Equal(t, byte('I'), conn.PgConn().TxStatus())
+
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestConnSendBatchTxOption(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		batch := &pgx.Batch{}
+		batch.Options.Tx = &pgx.TxOptions{IsoLevel: pgx.Serializable}
+		batch.Queue("select current_setting('transaction_isolation')")
+		batch.Queue("select 1")
+
+		br := conn.SendBatch(ctx, batch)
+		var isoLevel string
+		require.NoError(t, br.QueryRow().Scan(&isoLevel))
+		require.Equal(t, "serializable", isoLevel)
+		_, err := br.Exec()
+		require.NoError(t, err)
+		require.NoError(t, br.Close())
+
+		require.Equal(t, byte('I'), conn.PgConn().TxStatus())
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestConnSendBatchTxOptionRollsBackOnQueryError(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		_, err := conn.Exec(ctx, "create temporary table batch_tx_option(n int primary key)")
+		require.NoError(t, err)
+
+		batch := &pgx.Batch{}
+		batch.Options.Tx = &pgx.TxOptions{}
+		batch.Queue("insert into batch_tx_option(n) values (1)")
+		batch.Queue("insert into batch_tx_option(n) values (1)")
+		batch.Queue("insert into batch_tx_option(n) values (2)")
+
+		br := conn.SendBatch(ctx, batch)
+		_, err = br.Exec()
+		require.NoError(t, err)
+		var pgErr *pgconn.PgError
+		require.ErrorAs(t, br.Close(), &pgErr)
+		require.Equal(t, "23505", pgErr.Code)
+
+		require.Equal(t, byte('I'), conn.PgConn().TxStatus())
+
+		var n int64
+		require.NoError(t, conn.QueryRow(ctx, "select count(*) from batch_tx_option").Scan(&n))
+		require.EqualValues(t, 0, n)
+
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestConnSendBatchTxOptionChunked(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		_, err := conn.Exec(ctx, "create temporary table batch_tx_option(n int primary key)")
+		require.NoError(t, err)
+
+		batch := &pgx.Batch{}
+		batch.Options.Tx = &pgx.TxOptions{}
+		batch.Options.MaxChunkQueries = 1
+		batch.Queue("insert into batch_tx_option(n) values (1)")
+		batch.Queue("insert into batch_tx_option(n) values (1)")
+
+		require.Error(t, conn.SendBatch(ctx, batch).Close())
+		require.Equal(t, byte('I'), conn.PgConn().TxStatus())
+
+		var n int64
+		require.NoError(t, conn.QueryRow(ctx, "select count(*) from batch_tx_option").Scan(&n))
+		require.EqualValues(t, 0, n)
+	})
+}
+
+func TestConnSendBatchTxOptionInTransaction(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		tx, err := conn.Begin(ctx)
+		require.NoError(t, err)
+		defer tx.Rollback(ctx)
+
+		batch := &pgx.Batch{}
+		batch.Options.Tx = &pgx.TxOptions{}
+		batch.Queue("select 1")
+
+		err = tx.SendBatch(ctx, batch).Close()
+		require.ErrorContains(t, err, "cannot be used while the connection is in a transaction")
+	})
+}
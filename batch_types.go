@@ -0,0 +1,108 @@
+package pgx
+
+import (
+	"context"
+	"fmt"
+)
+
+// loadableTypesSQL selects the name and the OIDs of the types each type depends on of the types with OIDs in $1 that
+// LoadType supports. Multirange types are not included because pg_range.rngmultitypid only exists in PostgreSQL 14+.
+const loadableTypesSQL = `select t.oid, t.oid::regtype::text, array(
+	select d from unnest(array[t.typelem, t.typbasetype, r.rngsubtype]) d where d <> 0
+	union all
+	select a.atttypid from pg_attribute a where a.attrelid = t.typrelid and a.attnum > 0 and not a.attisdropped
+)
+from pg_type t
+	left join pg_range r on r.rngtypid = t.oid
+where t.oid = any($1) and (t.typtype in ('c', 'd', 'e', 'r') or t.typcategory = 'A')`
+
+// unregisteredBatchResultOIDs returns the distinct OIDs of the result columns of the described queries in b that are
+// not registered in the connection's type map.
+func (c *Conn) unregisteredBatchResultOIDs(b *Batch) []uint32 {
+	var oids []uint32
+	seen := make(map[uint32]struct{})
+	for _, bi := range b.queuedQueries {
+		if bi.sd == nil {
+			continue
+		}
+		for _, fd := range bi.sd.Fields {
+			if _, ok := seen[fd.DataTypeOID]; ok {
+				continue
+			}
+			seen[fd.DataTypeOID] = struct{}{}
+			if _, ok := c.typeMap.TypeForOID(fd.DataTypeOID); !ok {
+				oids = append(oids, fd.DataTypeOID)
+			}
+		}
+	}
+	return oids
+}
+
+// registerTypeOIDs loads and registers the types with the given OIDs and any unregistered types they depend on. Types
+// that LoadType does not support, or that depend on such a type, are skipped.
+func (c *Conn) registerTypeOIDs(ctx context.Context, oids []uint32) error {
+	type loadableType struct {
+		name string
+		deps []uint32
+	}
+	loadable := make(map[uint32]loadableType)
+
+	for pending := oids; len(pending) > 0; {
+		var next []uint32
+		var oid uint32
+		var name string
+		var deps []uint32
+		rows, _ := c.Query(ctx, loadableTypesSQL, pending)
+		_, err := ForEachRow(rows, []any{&oid, &name, &deps}, func() error {
+			loadable[oid] = loadableType{name: name, deps: append([]uint32(nil), deps...)}
+			for _, dep := range deps {
+				if _, ok := c.typeMap.TypeForOID(dep); ok {
+					continue
+				}
+				if _, ok := loadable[dep]; ok {
+					continue
+				}
+				next = append(next, dep)
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("auto register types: %w", err)
+		}
+		pending = next
+	}
+
+	// register registers the type with oid after the types it depends on. It returns false if the type cannot be
+	// registered.
+	var register func(oid uint32) (bool, error)
+	register = func(oid uint32) (bool, error) {
+		if _, ok := c.typeMap.TypeForOID(oid); ok {
+			return true, nil
+		}
+		lt, ok := loadable[oid]
+		if !ok {
+			return false, nil
+		}
+		for _, dep := range lt.deps {
+			ok, err := register(dep)
+			if !ok || err != nil {
+				return false, err
+			}
+		}
+
+		dt, err := c.LoadType(ctx, lt.name)
+		if err != nil {
+			return false, fmt.Errorf("auto register type %s: %w", lt.name, err)
+		}
+		c.typeMap.RegisterType(dt)
+		return true, nil
+	}
+
+	for _, oid := range oids {
+		if _, err := register(oid); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
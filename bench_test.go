@@ -691,7 +691,7 @@ func BenchmarkMultipleQueriesBatchNoStatementCache(b *testing.B) {
 	conn := mustConnect(b, config)
 	defer closeConn(b, conn)
 
-	benchmarkMultipleQueriesBatch(b, conn, 3)
+	benchmarkMultipleQueriesBatch(b, conn, 3, nil)
 }
 
 func BenchmarkMultipleQueriesBatchPrepareStatementCache(b *testing.B) {
@@ -703,7 +703,7 @@ func BenchmarkMultipleQueriesBatchPrepareStatementCache(b *testing.B) {
 	conn := mustConnect(b, config)
 	defer closeConn(b, conn)
 
-	benchmarkMultipleQueriesBatch(b, conn, 3)
+	benchmarkMultipleQueriesBatch(b, conn, 3, nil)
 }
 
 func BenchmarkMultipleQueriesBatchDescribeStatementCache(b *testing.B) {
@@ -715,10 +715,33 @@ func BenchmarkMultipleQueriesBatchDescribeStatementCache(b *testing.B) {
 	conn := mustConnect(b, config)
 	defer closeConn(b, conn)
 
-	benchmarkMultipleQueriesBatch(b, conn, 3)
+	benchmarkMultipleQueriesBatch(b, conn, 3, nil)
 }
 
-func benchmarkMultipleQueriesBatch(b *testing.B, conn *pgx.Conn, queryCount int) {
+func BenchmarkMultipleQueriesBatchPrepareStatementCacheReusableResults(b *testing.B) {
+	config := mustParseConfig(b, os.Getenv("PGX_TEST_DATABASE"))
+	config.DefaultQueryExecMode = pgx.QueryExecModeCacheStatement
+	config.StatementCacheCapacity = 32
+	config.DescriptionCacheCapacity = 0
+
+	conn := mustConnect(b, config)
+	defer closeConn(b, conn)
+
+	benchmarkMultipleQueriesBatch(b, conn, 3, &pgx.ReusableBatchResults{})
+}
+
+func BenchmarkMultipleQueriesBatchExecReusableResults(b *testing.B) {
+	config := mustParseConfig(b, os.Getenv("PGX_TEST_DATABASE"))
+	config.DefaultQueryExecMode = pgx.QueryExecModeExec
+
+	conn := mustConnect(b, config)
+	defer closeConn(b, conn)
+
+	benchmarkMultipleQueriesBatch(b, conn, 3, &pgx.ReusableBatchResults{})
+}
+
+func benchmarkMultipleQueriesBatch(b *testing.B, conn *pgx.Conn, queryCount int, rbr *pgx.ReusableBatchResults) {
+	b.ReportAllocs()
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
 		batch := &pgx.Batch{}
@@ -726,7 +749,7 @@ func benchmarkMultipleQueriesBatch(b *testing.B, conn *pgx.Conn, queryCount int)
 			batch.Queue("select n from generate_series(0,5) n")
 		}
 
-		br := conn.SendBatch(context.Background(), batch)
+		br := conn.SendBatchInto(context.Background(), batch, rbr)
 
 		for j := 0; j < queryCount; j++ {
 			rows, err := br.Query()
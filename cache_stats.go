@@ -0,0 +1,62 @@
+package pgx
+
+import "github.com/jackc/pgx/v5/internal/stmtcache"
+
+// StatementCacheStats are the statistics of a statement or description cache of a Conn. The counters are cumulative
+// for the lifetime of the connection.
+type StatementCacheStats struct {
+	// Len is the number of cached statement descriptions.
+	Len int
+
+	// Cap is the maximum number of cached statement descriptions.
+	Cap int
+
+	// Hits is the number of queries that found their statement description in the cache.
+	Hits int64
+
+	// Misses is the number of queries that did not find their statement description in the cache and had to prepare or
+	// describe the statement.
+	Misses int64
+
+	// Evictions is the number of statement descriptions that were removed from the cache to make room for another. A
+	// high number relative to Misses suggests the cache capacity is too small for the workload.
+	Evictions int64
+}
+
+// CacheStats are the statistics of the statement caches of a Conn.
+type CacheStats struct {
+	// StatementCache is the cache of server-side prepared statements used by QueryExecModeCacheStatement. It is the zero
+	// value if the cache is disabled.
+	StatementCache StatementCacheStats
+
+	// DescriptionCache is the cache of statement descriptions used by QueryExecModeCacheDescribe. It is the zero value if
+	// the cache is disabled.
+	DescriptionCache StatementCacheStats
+
+	// PreparedStatements is the number of statements prepared by name with Prepare.
+	PreparedStatements int
+}
+
+// CacheStats returns the statistics of the statement caches of c.
+func (c *Conn) CacheStats() CacheStats {
+	return CacheStats{
+		StatementCache:     statementCacheStats(c.statementCache),
+		DescriptionCache:   statementCacheStats(c.descriptionCache),
+		PreparedStatements: len(c.preparedStatements),
+	}
+}
+
+func statementCacheStats(sc stmtcache.Cache) StatementCacheStats {
+	if sc == nil {
+		return StatementCacheStats{}
+	}
+
+	stats := sc.Stats()
+	return StatementCacheStats{
+		Len:       sc.Len(),
+		Cap:       sc.Cap(),
+		Hits:      stats.Hits,
+		Misses:    stats.Misses,
+		Evictions: stats.Evictions,
+	}
+}
@@ -0,0 +1,117 @@
+package pgx_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnCacheStats(t *testing.T) {
+	t.Parallel()
+
+	config := mustParseConfig(t, os.Getenv("PGX_TEST_DATABASE"))
+	config.DefaultQueryExecMode = pgx.QueryExecModeCacheStatement
+	config.StatementCacheCapacity = 2
+	config.DescriptionCacheCapacity = 4
+
+	conn := mustConnect(t, config)
+	defer closeConn(t, conn)
+
+	ctx := context.Background()
+	stats := conn.CacheStats()
+	require.Equal(t, pgx.StatementCacheStats{Cap: 2}, stats.StatementCache)
+	require.Equal(t, pgx.StatementCacheStats{Cap: 4}, stats.DescriptionCache)
+
+	for i := 0; i < 3; i++ {
+		_, err := conn.Exec(ctx, fmt.Sprintf("select %d", i))
+		require.NoError(t, err)
+	}
+	_, err := conn.Exec(ctx, "select 2")
+	require.NoError(t, err)
+
+	stats = conn.CacheStats()
+	require.Equal(t, pgx.StatementCacheStats{Len: 2, Cap: 2, Hits: 1, Misses: 3, Evictions: 1}, stats.StatementCache)
+
+	_, err = conn.Exec(ctx, "select 1", pgx.QueryExecModeCacheDescribe)
+	require.NoError(t, err)
+	_, err = conn.Exec(ctx, "select 1", pgx.QueryExecModeCacheDescribe)
+	require.NoError(t, err)
+
+	stats = conn.CacheStats()
+	require.Equal(t, pgx.StatementCacheStats{Len: 1, Cap: 4, Hits: 1, Misses: 1}, stats.DescriptionCache)
+
+	_, err = conn.Prepare(ctx, "ps", "select 1")
+	require.NoError(t, err)
+	require.Equal(t, 1, conn.CacheStats().PreparedStatements)
+}
+
+func TestConnDeallocateAllResetsCaches(t *testing.T) {
+	t.Parallel()
+
+	config := mustParseConfig(t, os.Getenv("PGX_TEST_DATABASE"))
+	config.DefaultQueryExecMode = pgx.QueryExecModeCacheStatement
+
+	conn := mustConnect(t, config)
+	defer closeConn(t, conn)
+
+	ctx := context.Background()
+	_, err := conn.Prepare(ctx, "ps", "select 1")
+	require.NoError(t, err)
+	_, err = conn.Exec(ctx, "select 2")
+	require.NoError(t, err)
+	_, err = conn.Exec(ctx, "select 3", pgx.QueryExecModeCacheDescribe)
+	require.NoError(t, err)
+
+	err = conn.DeallocateAll(ctx)
+	require.NoError(t, err)
+
+	stats := conn.CacheStats()
+	require.Equal(t, 0, stats.PreparedStatements)
+	require.Equal(t, 0, stats.StatementCache.Len)
+	require.Equal(t, 0, stats.DescriptionCache.Len)
+	require.EqualValues(t, 1, stats.StatementCache.Misses)
+	require.Nil(t, conn.PreparedStatement("ps"))
+
+	var n int32
+	err = conn.QueryRow(ctx, "select count(*)::int4 from pg_prepared_statements").Scan(&n)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, n) // The statement for this query.
+
+	// The cached statement is prepared again.
+	_, err = conn.Exec(ctx, "select 2")
+	require.NoError(t, err)
+	require.EqualValues(t, 3, conn.CacheStats().StatementCache.Misses)
+}
+
+func TestConnDeallocateAllFailureKeepsCaches(t *testing.T) {
+	t.Parallel()
+
+	config := mustParseConfig(t, os.Getenv("PGX_TEST_DATABASE"))
+	config.DefaultQueryExecMode = pgx.QueryExecModeCacheStatement
+
+	conn := mustConnect(t, config)
+	defer closeConn(t, conn)
+
+	ctx := context.Background()
+	_, err := conn.Exec(ctx, "select 2")
+	require.NoError(t, err)
+
+	tx, err := conn.Begin(ctx)
+	require.NoError(t, err)
+	_, err = tx.Exec(ctx, "select 1/0")
+	require.Error(t, err)
+
+	err = conn.DeallocateAll(ctx)
+	require.Error(t, err)
+	require.Equal(t, 2, conn.CacheStats().StatementCache.Len)
+
+	require.NoError(t, tx.Rollback(ctx))
+
+	_, err = conn.Exec(ctx, "select 2")
+	require.NoError(t, err)
+	require.EqualValues(t, 1, conn.CacheStats().StatementCache.Hits)
+}
@@ -0,0 +1,76 @@
+package pgx
+
+import (
+	"context"
+	"strconv"
+	"strings"
+)
+
+// OutParam is an OUT or INOUT parameter of a procedure called with Conn.Call. Use Out or InOut to create one.
+type OutParam struct {
+	value any
+	dest  any
+	inout bool
+}
+
+// Out returns an OUT parameter for Conn.Call. The value the procedure returns for the parameter is scanned into dest as
+// with Rows.Scan.
+func Out(dest any) OutParam {
+	return OutParam{dest: dest}
+}
+
+// InOut returns an INOUT parameter for Conn.Call. value is passed to the procedure and the value the procedure returns
+// for the parameter is scanned into dest as with Rows.Scan. value and dest may be the same pointer.
+func InOut(value, dest any) OutParam {
+	return OutParam{value: value, dest: dest, inout: true}
+}
+
+// Call calls the stored procedure name with args. args are passed to the procedure in order. An argument created with
+// Out or InOut binds an OUT or INOUT parameter of the procedure, and the value returned for the parameter is scanned
+// into its destination. For example, for a procedure declared as
+//
+//	create procedure add_one(in a int, inout b int, out c text)
+//
+// the following call passes 1 and 2 as a and b and scans the values returned for b and c into b and c.
+//
+//	var b int32 = 2
+//	var c string
+//	err := conn.Call(ctx, pgx.Identifier{"add_one"}, 1, pgx.InOut(b, &b), pgx.Out(&c))
+//
+// NULL is passed for an OUT parameter as PostgreSQL requires. The procedure is called with CALL so it may commit or
+// roll back transactions if c is not in an explicit transaction.
+func (c *Conn) Call(ctx context.Context, name Identifier, args ...any) error {
+	var sb strings.Builder
+	sb.WriteString("call ")
+	sb.WriteString(name.Sanitize())
+	sb.WriteByte('(')
+
+	values := make([]any, 0, len(args))
+	var dests []any
+	for i, arg := range args {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+
+		if p, ok := arg.(OutParam); ok {
+			dests = append(dests, p.dest)
+			if !p.inout {
+				sb.WriteString("null")
+				continue
+			}
+			arg = p.value
+		}
+
+		values = append(values, arg)
+		sb.WriteByte('$')
+		sb.WriteString(strconv.Itoa(len(values)))
+	}
+	sb.WriteByte(')')
+
+	if len(dests) == 0 {
+		_, err := c.Exec(ctx, sb.String(), values...)
+		return err
+	}
+
+	return c.QueryRow(ctx, sb.String(), values...).Scan(dests...)
+}
@@ -0,0 +1,57 @@
+package pgx_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxtest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnCall(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		pgxtest.SkipCockroachDB(t, conn, "Server does not support procedures with OUT parameters")
+		pgxtest.SkipPostgreSQLVersionLessThan(t, conn, 14)
+
+		_, err := conn.Exec(ctx, `create or replace procedure pg_temp.pgx_call_test(in a int, inout b int, out c text)
+language plpgsql as $$
+begin
+	b := a + b;
+	c := 'sum ' || b;
+end;
+$$`)
+		require.NoError(t, err)
+
+		var b int32 = 2
+		var c string
+		err = conn.Call(ctx, pgx.Identifier{"pg_temp", "pgx_call_test"}, 1, pgx.InOut(b, &b), pgx.Out(&c))
+		require.NoError(t, err)
+		require.EqualValues(t, 3, b)
+		require.Equal(t, "sum 3", c)
+	})
+}
+
+func TestConnCallWithoutOutParams(t *testing.T) {
+	t.Parallel()
+
+	defaultConnTestRunner.RunTest(context.Background(), t, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		pgxtest.SkipCockroachDB(t, conn, "Server does not support procedures")
+
+		_, err := conn.Exec(ctx, `create temporary table pgx_call_test(n int)`)
+		require.NoError(t, err)
+		_, err = conn.Exec(ctx, `create or replace procedure pg_temp.pgx_call_insert(a int)
+language sql as $$ insert into pgx_call_test values (a) $$`)
+		require.NoError(t, err)
+
+		err = conn.Call(ctx, pgx.Identifier{"pg_temp", "pgx_call_insert"}, 42)
+		require.NoError(t, err)
+
+		var n int32
+		err = conn.QueryRow(ctx, `select n from pgx_call_test`).Scan(&n)
+		require.NoError(t, err)
+		require.EqualValues(t, 42, n)
+	})
+}
@@ -0,0 +1,115 @@
+package pgx
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5/internal/nbconn"
+)
+
+// Capabilities describes the features supported by the server a Conn is connected to. It is derived from the
+// server_version and other parameters the server reports, so libraries built on pgx can feature-gate without parsing
+// version strings themselves.
+//
+// Features are reported as unsupported when the server is not PostgreSQL, e.g. CockroachDB, as such servers report a
+// PostgreSQL server_version that does not reflect the features they implement.
+type Capabilities struct {
+	// ServerVersion is the server version in the format of the server_version_num setting, e.g. 160002 for 16.2 and
+	// 90624 for 9.6.24. It is 0 if the server_version parameter could not be parsed.
+	ServerVersion int
+
+	// CockroachDB is true if the server is CockroachDB.
+	CockroachDB bool
+
+	// InHotStandby is true if the server is a hot standby that only accepts read-only queries. It is only reported by
+	// PostgreSQL 14 and later and may change while the connection is open, e.g. when the standby is promoted.
+	InHotStandby bool
+
+	// Merge is true if the server supports the MERGE statement (PostgreSQL 15).
+	Merge bool
+
+	// MergeReturning is true if the server supports RETURNING in MERGE statements (PostgreSQL 17).
+	MergeReturning bool
+
+	// NullsNotDistinct is true if the server supports NULLS NOT DISTINCT in unique constraints and indexes
+	// (PostgreSQL 15).
+	NullsNotDistinct bool
+
+	// SQLJSONConstructors is true if the server supports the SQL/JSON constructors JSON_ARRAY and JSON_OBJECT and the IS
+	// JSON predicate (PostgreSQL 16).
+	SQLJSONConstructors bool
+
+	// SQLJSONQueryFunctions is true if the server supports the SQL/JSON query functions JSON_TABLE, JSON_QUERY,
+	// JSON_VALUE and JSON_EXISTS (PostgreSQL 17).
+	SQLJSONQueryFunctions bool
+
+	// CopyOnError is true if the server supports the ON_ERROR option of COPY FROM (PostgreSQL 17).
+	CopyOnError bool
+
+	// SCRAMChannelBinding is true if the connection uses TLS and the server supports SCRAM-SHA-256-PLUS authentication
+	// with channel binding (PostgreSQL 11).
+	SCRAMChannelBinding bool
+}
+
+// AtLeast reports whether the server is PostgreSQL major.minor or later. It is always false for servers that are not
+// PostgreSQL.
+func (c Capabilities) AtLeast(major, minor int) bool {
+	if c.CockroachDB || c.ServerVersion == 0 {
+		return false
+	}
+	return c.ServerVersion >= serverVersionNum(major, minor, 0)
+}
+
+// Capabilities returns the features supported by the server c is connected to.
+func (c *Conn) Capabilities() Capabilities {
+	caps := Capabilities{
+		ServerVersion: parseServerVersion(c.pgConn.ParameterStatus("server_version")),
+		CockroachDB:   c.pgConn.ParameterStatus("crdb_version") != "",
+		InHotStandby:  c.pgConn.ParameterStatus("in_hot_standby") == "on",
+	}
+
+	caps.Merge = caps.AtLeast(15, 0)
+	caps.MergeReturning = caps.AtLeast(17, 0)
+	caps.NullsNotDistinct = caps.AtLeast(15, 0)
+	caps.SQLJSONConstructors = caps.AtLeast(16, 0)
+	caps.SQLJSONQueryFunctions = caps.AtLeast(17, 0)
+	caps.CopyOnError = caps.AtLeast(17, 0)
+
+	if _, ok := c.pgConn.Conn().(*nbconn.TLSConn); ok {
+		caps.SCRAMChannelBinding = caps.AtLeast(11, 0)
+	}
+
+	return caps
+}
+
+// parseServerVersion parses a server_version parameter such as "16.2 (Debian 16.2-1.pgdg120+2)", "9.6.24" or
+// "17beta1" into the format of server_version_num. It returns 0 if s cannot be parsed.
+func parseServerVersion(s string) int {
+	if i := strings.IndexFunc(s, func(r rune) bool { return r != '.' && (r < '0' || r > '9') }); i >= 0 {
+		s = s[:i]
+	}
+
+	parts := strings.Split(s, ".")
+	var nums [3]int
+	for i := 0; i < len(parts) && i < len(nums); i++ {
+		n, err := strconv.Atoi(parts[i])
+		if err != nil {
+			if i == 0 {
+				return 0
+			}
+			break
+		}
+		nums[i] = n
+	}
+
+	return serverVersionNum(nums[0], nums[1], nums[2])
+}
+
+// serverVersionNum returns the server_version_num of a PostgreSQL version. Since PostgreSQL 10 versions have two parts
+// and patch is ignored.
+func serverVersionNum(major, minor, patch int) int {
+	if major >= 10 {
+		return major*10000 + minor
+	}
+	return major*10000 + minor*100 + patch
+}
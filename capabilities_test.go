@@ -0,0 +1,53 @@
+package pgx_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnCapabilities(t *testing.T) {
+	t.Parallel()
+
+	defaultConnTestRunner.RunTest(context.Background(), t, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		caps := conn.Capabilities()
+
+		if conn.PgConn().ParameterStatus("crdb_version") != "" {
+			require.True(t, caps.CockroachDB)
+			require.False(t, caps.AtLeast(9, 0))
+			require.False(t, caps.Merge)
+			return
+		}
+
+		var serverVersionNum int
+		err := conn.QueryRow(ctx, "select current_setting('server_version_num')::int").Scan(&serverVersionNum)
+		require.NoError(t, err)
+
+		require.False(t, caps.CockroachDB)
+		require.Equal(t, serverVersionNum, caps.ServerVersion)
+		require.True(t, caps.AtLeast(serverVersionNum/10000, 0))
+		require.False(t, caps.AtLeast(serverVersionNum/10000+1, 0))
+		require.Equal(t, serverVersionNum >= 150000, caps.Merge)
+		require.Equal(t, serverVersionNum >= 150000, caps.NullsNotDistinct)
+		require.Equal(t, serverVersionNum >= 160000, caps.SQLJSONConstructors)
+		require.Equal(t, serverVersionNum >= 170000, caps.MergeReturning)
+		require.Equal(t, serverVersionNum >= 170000, caps.SQLJSONQueryFunctions)
+		require.Equal(t, serverVersionNum >= 170000, caps.CopyOnError)
+
+		var inHotStandby bool
+		err = conn.QueryRow(ctx, "select pg_is_in_recovery()").Scan(&inHotStandby)
+		require.NoError(t, err)
+		if serverVersionNum >= 140000 {
+			require.Equal(t, inHotStandby, caps.InHotStandby)
+		}
+
+		if caps.Merge {
+			_, err = conn.Exec(ctx, `create temporary table capabilities_merge (id int primary key)`)
+			require.NoError(t, err)
+			_, err = conn.Exec(ctx, `merge into capabilities_merge t using (select 1 as id) s on t.id = s.id when not matched then insert values (s.id)`)
+			require.NoError(t, err)
+		}
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
 	"strconv"
 	"strings"
 	"time"
@@ -39,6 +40,52 @@ type ConnConfig struct {
 	// functionality can be controlled on a per query basis by passing a QueryExecMode as the first query argument.
 	DefaultQueryExecMode QueryExecMode
 
+	// BatchResultsMiddleware, if set, is applied to the BatchResults of every batch sent on the connection before they
+	// are returned from SendBatch. Unlike a BatchTracer it can change the behavior of the results, e.g. to enforce that
+	// every batch is fully consumed. The wrapper should have an Unwrap() BatchResults method that returns the wrapped
+	// results so functions such as BatchAll and BatchResultsStats can read them.
+	BatchResultsMiddleware BatchResultsMiddleware
+
+	// StatementTimeoutFromDeadline, if true, limits each query run with Query, QueryRow, or Exec with a context that has a
+	// deadline to the time remaining until the deadline, as QueryOptions.StatementTimeout does. This makes the server stop
+	// running the query when the client gives up on it instead of relying only on a cancel request, which may be lost
+	// or ignored. It costs two additional round trips for each query with a deadline. An explicit
+	// QueryOptions.StatementTimeout takes precedence. Transaction control statements such as COMMIT and ROLLBACK, and
+	// queries in a failed transaction, are not limited.
+	StatementTimeoutFromDeadline bool
+
+	// QueryInterceptors are applied to every query run with Exec, Query, or QueryRow. The first interceptor is the
+	// outermost, i.e. it is called first and its next calls the second interceptor. See QueryInterceptor.
+	QueryInterceptors []QueryInterceptor
+
+	// RetryPolicy, if set, retries queries marked idempotent with QueryOptions.Idempotent that fail with a transient
+	// error. See RetryPolicy.
+	RetryPolicy *RetryPolicy
+
+	// NotifyPayloadEncoder, if set, encodes the payload passed to Conn.Notify. By default string and []byte payloads are
+	// sent as is and any other payload is encoded as JSON.
+	NotifyPayloadEncoder func(payload any) (string, error)
+
+	// SQLCommentTags, if set, returns tags such as a trace ID, application tags, or caller info that are appended to
+	// the SQL of every query run with Exec, Query, QueryRow, QueryMulti, SendBatch, or CopyFrom as a comment in the
+	// sqlcommenter format. This allows database-side monitoring such as pg_stat_statements or pg_stat_activity to
+	// attribute load to services. It is called with the context of the query. See AppendSQLComment.
+	//
+	// The comment is part of the SQL, so queries with different tags are different statements for the statement and
+	// description caches. Tags that change with every query such as a traceparent defeat the caches and should only be
+	// used with QueryExecModeExec or QueryExecModeSimpleProtocol.
+	SQLCommentTags func(ctx context.Context) map[string]string
+
+	// ErrorPositionSnippets wraps errors of Exec, Query, QueryRow, and Prepare that have a position in the SQL, such as
+	// syntax errors and unknown columns, in a *pgerr.PositionError. Its message includes the offending line of the SQL
+	// with a caret at the position. This helps to debug generated SQL. Errors of batches are not wrapped.
+	ErrorPositionSnippets bool
+
+	// ContextualErrNoRows returns a *NoRowsError with the SQL and the number of arguments of the query instead of
+	// ErrNoRows when QueryRow, CollectOneRow, or another function that expects a row finds none. This makes it possible
+	// to attribute the error to a query. errors.Is(err, ErrNoRows) remains true, but comparing err == ErrNoRows does not.
+	ContextualErrNoRows bool
+
 	createdByParseConfig bool // Used to enforce created by ParseConfig rule.
 }
 
@@ -69,13 +116,20 @@ type Conn struct {
 	statementCache     stmtcache.Cache
 	descriptionCache   stmtcache.Cache
 
-	queryTracer    QueryTracer
-	batchTracer    BatchTracer
-	copyFromTracer CopyFromTracer
-	prepareTracer  PrepareTracer
+	queryTracer      QueryTracer
+	batchTracer      BatchTracer
+	batchRetryTracer BatchRetryTracer
+	copyFromTracer   CopyFromTracer
+	prepareTracer    PrepareTracer
+
+	batchQueryInterceptor BatchQueryInterceptor
 
 	notifications []*pgconn.Notification
 
+	noticeHandler     pgconn.NoticeHandler // collects notices into queryNotices, created on first use
+	prevNoticeHandler pgconn.NoticeHandler // notice handler to restore when the query is done
+	queryNotices      *[]pgconn.Notice     // destination of the notices of the query being run, nil if not collecting
+
 	doneChan   chan struct{}
 	closedChan chan error
 
@@ -83,6 +137,11 @@ type Conn struct {
 
 	wbuf []byte
 	eqb  ExtendedQueryBuilder
+
+	batchInFlight bool // true while the results of a batch sent on this connection have not been closed
+
+	advisoryLocks map[int64]int      // number of times each advisory lock key is held through AdvisoryLock
+	sessionVars   map[string]*string // value of each parameter before it was first changed by SetSessionVar, nil if unset
 }
 
 // Identifier a PostgreSQL identifier or name. Identifiers can be composed of
@@ -102,6 +161,10 @@ func (ident Identifier) Sanitize() string {
 // ErrNoRows occurs when rows are expected but none are returned.
 var ErrNoRows = errors.New("no rows in result set")
 
+// ErrConnBusy occurs when a batch is sent while the results of a previous batch sent on the same connection have not
+// been closed.
+var ErrConnBusy = errors.New("conn busy: results of a previous batch have not been closed")
+
 var errDisabledStatementCache = fmt.Errorf("cannot use QueryExecModeCacheStatement with disabled statement cache")
 var errDisabledDescriptionCache = fmt.Errorf("cannot use QueryExecModeCacheDescribe with disabled description cache")
 
@@ -182,13 +245,24 @@ func ParseConfigWithOptions(connString string, options ParseConfigOptions) (*Con
 		}
 	}
 
+	statementTimeoutFromDeadline := false
+	if s, ok := config.RuntimeParams["statement_timeout_from_deadline"]; ok {
+		delete(config.RuntimeParams, "statement_timeout_from_deadline")
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse statement_timeout_from_deadline: %w", err)
+		}
+		statementTimeoutFromDeadline = b
+	}
+
 	connConfig := &ConnConfig{
-		Config:                   *config,
-		createdByParseConfig:     true,
-		StatementCacheCapacity:   statementCacheCapacity,
-		DescriptionCacheCapacity: descriptionCacheCapacity,
-		DefaultQueryExecMode:     defaultQueryExecMode,
-		connString:               connString,
+		Config:                       *config,
+		createdByParseConfig:         true,
+		StatementCacheCapacity:       statementCacheCapacity,
+		DescriptionCacheCapacity:     descriptionCacheCapacity,
+		DefaultQueryExecMode:         defaultQueryExecMode,
+		StatementTimeoutFromDeadline: statementTimeoutFromDeadline,
+		connString:                   connString,
 	}
 
 	return connConfig, nil
@@ -208,6 +282,9 @@ func ParseConfigWithOptions(connString string, options ParseConfigOptions) (*Con
 //   - description_cache_capacity.
 //     The maximum size of the description cache used when executing a query with "cache_describe" query exec mode.
 //     Default: 512.
+//
+//   - statement_timeout_from_deadline.
+//     Possible values: "true" and "false". See ConnConfig.StatementTimeoutFromDeadline. Default: "false".
 func ParseConfig(connString string) (*ConnConfig, error) {
 	return ParseConfigWithOptions(connString, ParseConfigOptions{})
 }
@@ -242,6 +319,12 @@ func connect(ctx context.Context, config *ConnConfig) (c *Conn, err error) {
 	if t, ok := c.queryTracer.(PrepareTracer); ok {
 		c.prepareTracer = t
 	}
+	if t, ok := c.queryTracer.(BatchQueryInterceptor); ok {
+		c.batchQueryInterceptor = t
+	}
+	if t, ok := c.queryTracer.(BatchRetryTracer); ok {
+		c.batchRetryTracer = t
+	}
 
 	// Only install pgx notification system if no other callback handler is present.
 	if config.Config.OnNotification == nil {
@@ -287,6 +370,11 @@ func (c *Conn) Close(ctx context.Context) error {
 // name and sql arguments. This allows a code path to Prepare and Query/Exec without
 // concern for if the statement has already been prepared.
 func (c *Conn) Prepare(ctx context.Context, name, sql string) (sd *pgconn.StatementDescription, err error) {
+	return c.prepare(ctx, name, sql, nil)
+}
+
+// prepare is Prepare with the parameter types set by paramOIDs.
+func (c *Conn) prepare(ctx context.Context, name, sql string, paramOIDs []uint32) (sd *pgconn.StatementDescription, err error) {
 	if c.prepareTracer != nil {
 		ctx = c.prepareTracer.TracePrepareStart(ctx, c, TracePrepareStartData{Name: name, SQL: sql})
 	}
@@ -307,9 +395,9 @@ func (c *Conn) Prepare(ctx context.Context, name, sql string) (sd *pgconn.Statem
 		}()
 	}
 
-	sd, err = c.pgConn.Prepare(ctx, name, sql, nil)
+	sd, err = c.pgConn.Prepare(ctx, name, sql, paramOIDs)
 	if err != nil {
-		return nil, err
+		return nil, c.withErrorPosition(err, sql)
 	}
 
 	if name != "" {
@@ -327,16 +415,50 @@ func (c *Conn) Deallocate(ctx context.Context, name string) error {
 }
 
 // DeallocateAll releases all previously prepared statements from the server and client, where it also resets the statement and description cache.
+//
+// The client state is only reset if the server released the statements, so the client and server agree on which
+// statements are prepared even if DeallocateAll fails. This makes it safe to use behind a connection pooler such as
+// PgBouncer that may hand out server connections with statements the client does not know about. The statistics
+// returned by CacheStats are not reset.
 func (c *Conn) DeallocateAll(ctx context.Context) error {
+	_, err := c.pgConn.Exec(ctx, "deallocate all").ReadAll()
+	if err != nil {
+		return err
+	}
+
 	c.preparedStatements = map[string]*pgconn.StatementDescription{}
-	if c.config.StatementCacheCapacity > 0 {
-		c.statementCache = stmtcache.NewLRUCache(c.config.StatementCacheCapacity)
+	for _, sc := range []stmtcache.Cache{c.statementCache, c.descriptionCache} {
+		if sc != nil {
+			sc.InvalidateAll()
+			// The invalidated statements were already released by deallocate all.
+			sc.HandleInvalidated()
+		}
 	}
-	if c.config.DescriptionCacheCapacity > 0 {
-		c.descriptionCache = stmtcache.NewLRUCache(c.config.DescriptionCacheCapacity)
+
+	return nil
+}
+
+// DeallocateBatchStatements releases the cached prepared statements that were used by b from the server and the
+// statement cache. Statements prepared explicitly with Prepare are not released. b must have been sent on c and its
+// results closed.
+//
+// If c is in a transaction the statements are removed from the cache immediately but are released from the server
+// before the next query run outside of a transaction.
+func (c *Conn) DeallocateBatchStatements(ctx context.Context, b *Batch) error {
+	if c.statementCache == nil {
+		return nil
 	}
-	_, err := c.pgConn.Exec(ctx, "deallocate all").ReadAll()
-	return err
+
+	for _, bi := range b.queuedQueries {
+		if bi.sd == nil || bi.sd.Name == "" {
+			continue
+		}
+		if c.statementCache.Get(bi.sd.SQL) == bi.sd {
+			c.statementCache.Invalidate(bi.sd.SQL)
+		}
+	}
+
+	return c.deallocateInvalidatedCachedStatements(ctx)
 }
 
 func (c *Conn) bufferNotifications(_ *pgconn.PgConn, n *pgconn.Notification) {
@@ -363,6 +485,70 @@ func (c *Conn) WaitForNotification(ctx context.Context) (*pgconn.Notification, e
 	return n, err
 }
 
+// NotificationFilter selects the notifications returned by WaitForNotificationFiltered.
+type NotificationFilter struct {
+	// Channels are the channels to wait on. If empty, notifications on any channel match.
+	Channels []string
+
+	// Payload reports whether a notification with payload matches. If nil, any payload matches. e.g. the MatchString
+	// method of a *regexp.Regexp can be used to match payloads against a pattern.
+	Payload func(payload string) bool
+}
+
+func (f *NotificationFilter) match(n *pgconn.Notification) bool {
+	if len(f.Channels) > 0 {
+		found := false
+		for _, channel := range f.Channels {
+			if n.Channel == channel {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	return f.Payload == nil || f.Payload(n.Payload)
+}
+
+// WaitForNotificationFiltered waits for a PostgreSQL notification that matches filter. The Channel of the returned
+// notification is the channel that fired. This allows a single connection that listens on multiple channels to wait on
+// a subset of them.
+//
+// Notifications that do not match filter are not discarded. They are returned by later calls to WaitForNotification
+// or WaitForNotificationFiltered in the order they were received.
+func (c *Conn) WaitForNotificationFiltered(ctx context.Context, filter NotificationFilter) (*pgconn.Notification, error) {
+	// Return already received notification immediately
+	if n := c.takeNotification(0, &filter); n != nil {
+		return n, nil
+	}
+
+	for {
+		checked := len(c.notifications)
+		err := c.pgConn.WaitForNotification(ctx)
+		if n := c.takeNotification(checked, &filter); n != nil {
+			return n, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// takeNotification removes and returns the first buffered notification at or after index start that matches filter. It
+// returns nil if there is none.
+func (c *Conn) takeNotification(start int, filter *NotificationFilter) *pgconn.Notification {
+	for i := start; i < len(c.notifications); i++ {
+		n := c.notifications[i]
+		if filter.match(n) {
+			c.notifications = append(c.notifications[:i], c.notifications[i+1:]...)
+			return n
+		}
+	}
+	return nil
+}
+
 // IsClosed reports if the connection has been closed.
 func (c *Conn) IsClosed() bool {
 	return c.pgConn.IsClosed()
@@ -389,6 +575,38 @@ func (c *Conn) Ping(ctx context.Context) error {
 	return err
 }
 
+// PingStats describes the result of a successful PingStats.
+type PingStats struct {
+	// RoundTripTime is the time from sending the ping until the server's response was read.
+	RoundTripTime time.Duration
+
+	// InTransaction is true if the connection is in a transaction.
+	InTransaction bool
+
+	// InFailedTransaction is true if the connection is in a transaction that failed. The transaction must be rolled back
+	// before the connection can run other queries.
+	InFailedTransaction bool
+}
+
+// PingStats is like Ping but also returns the measured round-trip time to the server and the transaction state of the
+// connection. It is intended for health checks that need more than whether the connection is usable.
+func (c *Conn) PingStats(ctx context.Context) (PingStats, error) {
+	// The ping is sent on the underlying connection so that only its round trip is measured. Exec could add statements
+	// such as setting the statement timeout that would also fail in a failed transaction.
+	start := time.Now()
+	_, err := c.pgConn.Exec(ctx, ";").ReadAll()
+	if err != nil {
+		return PingStats{}, err
+	}
+
+	txStatus := c.pgConn.TxStatus()
+	return PingStats{
+		RoundTripTime:       time.Since(start),
+		InTransaction:       txStatus == 'T' || txStatus == 'E',
+		InFailedTransaction: txStatus == 'E',
+	}, nil
+}
+
 // PgConn returns the underlying *pgconn.PgConn. This is an escape hatch method that allows lower level access to the
 // PostgreSQL connection than pgx exposes.
 //
@@ -405,8 +623,32 @@ func (c *Conn) Config() *ConnConfig { return c.config.Copy() }
 // Exec executes sql. sql can be either a prepared statement name or an SQL string. arguments should be referenced
 // positionally from the sql string as $1, $2, etc.
 func (c *Conn) Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error) {
-	if c.queryTracer != nil {
-		ctx = c.queryTracer.TraceQueryStart(ctx, c, TraceQueryStartData{SQL: sql, Args: arguments})
+	if len(c.config.QueryInterceptors) > 0 {
+		return c.interceptedExec(0)(ctx, sql, arguments)
+	}
+
+	return c.execWithRetry(ctx, sql, arguments...)
+}
+
+// execWithRetry runs Exec after the QueryInterceptors.
+func (c *Conn) execWithRetry(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error) {
+	if policy := c.retryPolicyFor(arguments); policy != nil {
+		var commandTag pgconn.CommandTag
+		err := c.retry(ctx, policy, func() (err error) {
+			commandTag, err = c.execTraced(ctx, sql, arguments...)
+			return err
+		})
+		return commandTag, err
+	}
+
+	return c.execTraced(ctx, sql, arguments...)
+}
+
+// execTraced runs a single attempt of Exec.
+func (c *Conn) execTraced(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error) {
+	tracer := c.queryTracerFor(arguments)
+	if tracer != nil {
+		ctx = tracer.TraceQueryStart(ctx, c, TraceQueryStartData{SQL: sql, Args: arguments})
 	}
 
 	if err := c.deallocateInvalidatedCachedStatements(ctx); err != nil {
@@ -415,8 +657,8 @@ func (c *Conn) Exec(ctx context.Context, sql string, arguments ...any) (pgconn.C
 
 	commandTag, err := c.exec(ctx, sql, arguments...)
 
-	if c.queryTracer != nil {
-		c.queryTracer.TraceQueryEnd(ctx, c, TraceQueryEndData{CommandTag: commandTag, Err: err})
+	if tracer != nil {
+		tracer.TraceQueryEnd(ctx, c, TraceQueryEndData{CommandTag: commandTag, Err: err})
 	}
 
 	return commandTag, err
@@ -425,6 +667,9 @@ func (c *Conn) Exec(ctx context.Context, sql string, arguments ...any) (pgconn.C
 func (c *Conn) exec(ctx context.Context, sql string, arguments ...any) (commandTag pgconn.CommandTag, err error) {
 	mode := c.config.DefaultQueryExecMode
 	var queryRewriter QueryRewriter
+	var statementTimeout time.Duration
+	var paramOIDs ParamOIDs
+	var notices *[]pgconn.Notice
 
 optionLoop:
 	for len(arguments) > 0 {
@@ -432,6 +677,19 @@ optionLoop:
 		case QueryExecMode:
 			mode = arg
 			arguments = arguments[1:]
+		case ParamOIDs:
+			paramOIDs = arg
+			arguments = arguments[1:]
+		case QueryOptions:
+			if arg.ExecMode != 0 {
+				mode = arg.ExecMode
+			}
+			if arg.ParamOIDs != nil {
+				paramOIDs = arg.ParamOIDs
+			}
+			statementTimeout = arg.StatementTimeout
+			notices = arg.Notices
+			arguments = arguments[1:]
 		case QueryRewriter:
 			queryRewriter = arg
 			arguments = arguments[1:]
@@ -447,6 +705,29 @@ optionLoop:
 		}
 	}
 
+	sql = c.annotateSQL(ctx, sql)
+
+	defer func() {
+		c.invalidateCachedStatement(sql, err)
+	}()
+
+	if statementTimeout == 0 && c.config.StatementTimeoutFromDeadline {
+		statementTimeout = c.deadlineStatementTimeout(ctx, sql)
+	}
+
+	if statementTimeout > 0 {
+		restore, setErr := c.setStatementTimeout(ctx, statementTimeout)
+		if setErr != nil {
+			return pgconn.CommandTag{}, setErr
+		}
+		defer restore()
+	}
+
+	if notices != nil {
+		c.startCollectingNotices(notices)
+		defer c.stopCollectingNotices(notices)
+	}
+
 	// Always use simple protocol when there are no arguments.
 	if len(arguments) == 0 {
 		mode = QueryExecModeSimpleProtocol
@@ -456,6 +737,19 @@ optionLoop:
 		return c.execPrepared(ctx, sd, arguments)
 	}
 
+	if paramOIDs != nil {
+		switch mode {
+		case QueryExecModeCacheStatement, QueryExecModeCacheDescribe, QueryExecModeDescribeExec:
+			sd, err := c.prepare(ctx, "", sql, paramOIDs)
+			if err != nil {
+				return pgconn.CommandTag{}, err
+			}
+			return c.execPrepared(ctx, sd, arguments)
+		case QueryExecModeExec:
+			return c.execSQLParams(ctx, sql, arguments, paramOIDs)
+		}
+	}
+
 	switch mode {
 	case QueryExecModeCacheStatement:
 		if c.statementCache == nil {
@@ -491,7 +785,7 @@ optionLoop:
 		}
 		return c.execPrepared(ctx, sd, arguments)
 	case QueryExecModeExec:
-		return c.execSQLParams(ctx, sql, arguments)
+		return c.execSQLParams(ctx, sql, arguments, nil)
 	case QueryExecModeSimpleProtocol:
 		return c.execSimpleProtocol(ctx, sql, arguments)
 	default:
@@ -512,7 +806,7 @@ func (c *Conn) execSimpleProtocol(ctx context.Context, sql string, arguments []a
 		commandTag, err = mrr.ResultReader().Close()
 	}
 	err = mrr.Close()
-	return commandTag, err
+	return commandTag, c.withErrorPosition(err, sql)
 }
 
 func (c *Conn) execParams(ctx context.Context, sd *pgconn.StatementDescription, arguments []any) (pgconn.CommandTag, error) {
@@ -523,7 +817,7 @@ func (c *Conn) execParams(ctx context.Context, sd *pgconn.StatementDescription,
 
 	result := c.pgConn.ExecParams(ctx, sd.SQL, c.eqb.ParamValues, sd.ParamOIDs, c.eqb.ParamFormats, c.eqb.ResultFormats).Read()
 	c.eqb.reset() // Allow c.eqb internal memory to be GC'ed as soon as possible.
-	return result.CommandTag, result.Err
+	return result.CommandTag, c.withErrorPosition(result.Err, sd.SQL)
 }
 
 func (c *Conn) execPrepared(ctx context.Context, sd *pgconn.StatementDescription, arguments []any) (pgconn.CommandTag, error) {
@@ -534,7 +828,7 @@ func (c *Conn) execPrepared(ctx context.Context, sd *pgconn.StatementDescription
 
 	result := c.pgConn.ExecPrepared(ctx, sd.Name, c.eqb.ParamValues, c.eqb.ParamFormats, c.eqb.ResultFormats).Read()
 	c.eqb.reset() // Allow c.eqb internal memory to be GC'ed as soon as possible.
-	return result.CommandTag, result.Err
+	return result.CommandTag, c.withErrorPosition(result.Err, sd.SQL)
 }
 
 type unknownArgumentTypeQueryExecModeExecError struct {
@@ -545,19 +839,30 @@ func (e *unknownArgumentTypeQueryExecModeExecError) Error() string {
 	return fmt.Sprintf("cannot use unregistered type %T as query argument in QueryExecModeExec", e.arg)
 }
 
-func (c *Conn) execSQLParams(ctx context.Context, sql string, args []any) (pgconn.CommandTag, error) {
-	err := c.eqb.Build(c.typeMap, nil, args)
+func (c *Conn) execSQLParams(ctx context.Context, sql string, args []any, paramOIDs ParamOIDs) (pgconn.CommandTag, error) {
+	var oids []uint32
+	var err error
+	if paramOIDs != nil {
+		oids, err = c.eqb.buildWithParamOIDs(c.typeMap, paramOIDs, args)
+	} else {
+		err = c.eqb.Build(c.typeMap, nil, args)
+	}
 	if err != nil {
 		return pgconn.CommandTag{}, err
 	}
 
-	result := c.pgConn.ExecParams(ctx, sql, c.eqb.ParamValues, nil, c.eqb.ParamFormats, c.eqb.ResultFormats).Read()
+	result := c.pgConn.ExecParams(ctx, sql, c.eqb.ParamValues, oids, c.eqb.ParamFormats, c.eqb.ResultFormats).Read()
 	c.eqb.reset() // Allow c.eqb internal memory to be GC'ed as soon as possible.
-	return result.CommandTag, result.Err
+	return result.CommandTag, c.withErrorPosition(result.Err, sql)
 }
 
 func (c *Conn) getRows(ctx context.Context, sql string, args []any) *baseRows {
-	r := &baseRows{}
+	return c.resetRows(&baseRows{}, ctx, sql, args)
+}
+
+// resetRows clears r and prepares it to read the results of sql.
+func (c *Conn) resetRows(r *baseRows, ctx context.Context, sql string, args []any) *baseRows {
+	*r = baseRows{}
 
 	r.ctx = ctx
 	r.queryTracer = c.queryTracer
@@ -635,6 +940,16 @@ type QueryResultFormats []int16
 // QueryResultFormatsByOID controls the result format (text=0, binary=1) of a query by the result column OID.
 type QueryResultFormatsByOID map[uint32]int16
 
+// ParamOIDs sets the type of each parameter of a query by position when used as the first arguments to a query method.
+// It allows parameters whose type the server cannot infer, e.g. in `select $1 is null` or as the argument of a
+// polymorphic function, to be used in the extended protocol without casting them in the SQL. An OID of 0 leaves the
+// type of that parameter to the server.
+//
+// With QueryExecModeCacheStatement, QueryExecModeCacheDescribe and QueryExecModeDescribeExec the query is described with
+// the parameter types each time it is run and its description is not cached. With QueryExecModeExec the types are sent
+// with the query. ParamOIDs is ignored by QueryExecModeSimpleProtocol and by statements prepared with Prepare.
+type ParamOIDs []uint32
+
 // QueryRewriter rewrites a query when used as the first arguments to a query method.
 type QueryRewriter interface {
 	RewriteQuery(ctx context.Context, conn *Conn, sql string, args []any) (newSQL string, newArgs []any, err error)
@@ -655,17 +970,51 @@ type QueryRewriter interface {
 // An implementor of QueryRewriter may be passed as the first element of args. It can rewrite the sql and change or
 // replace args. For example, NamedArgs is QueryRewriter that implements named arguments.
 //
-// For extra control over how the query is executed, the types QueryExecMode, QueryResultFormats, and
+// For extra control over how the query is executed, the types QueryOptions, QueryExecMode, QueryResultFormats, and
 // QueryResultFormatsByOID may be used as the first args to control exactly how the query is executed. This is rarely
 // needed. See the documentation for those types for details.
 func (c *Conn) Query(ctx context.Context, sql string, args ...any) (Rows, error) {
-	if c.queryTracer != nil {
-		ctx = c.queryTracer.TraceQueryStart(ctx, c, TraceQueryStartData{SQL: sql, Args: args})
+	if len(c.config.QueryInterceptors) > 0 {
+		return c.interceptedQuery(0)(ctx, sql, args)
+	}
+
+	return c.queryWithRetry(ctx, sql, args...)
+}
+
+// queryWithRetry runs Query after the QueryInterceptors.
+func (c *Conn) queryWithRetry(ctx context.Context, sql string, args ...any) (Rows, error) {
+	if policy := c.retryPolicyFor(args); policy != nil {
+		var rows *baseRows
+		err := c.retry(ctx, policy, func() error {
+			var err error
+			rows, err = c.query(ctx, sql, args...)
+			if err != nil {
+				return err
+			}
+
+			// Errors are usually only reported when the results are read. Read the first row so that a query that fails
+			// before returning any rows can be retried. It is returned by the first call of Next.
+			if rows.Next() {
+				rows.peeked = true
+			}
+			return rows.Err()
+		})
+		return rows, err
+	}
+
+	return c.query(ctx, sql, args...)
+}
+
+// query runs a single attempt of Query.
+func (c *Conn) query(ctx context.Context, sql string, args ...any) (*baseRows, error) {
+	tracer := c.queryTracerFor(args)
+	if tracer != nil {
+		ctx = tracer.TraceQueryStart(ctx, c, TraceQueryStartData{SQL: sql, Args: args})
 	}
 
 	if err := c.deallocateInvalidatedCachedStatements(ctx); err != nil {
-		if c.queryTracer != nil {
-			c.queryTracer.TraceQueryEnd(ctx, c, TraceQueryEndData{Err: err})
+		if tracer != nil {
+			tracer.TraceQueryEnd(ctx, c, TraceQueryEndData{Err: err})
 		}
 		return &baseRows{err: err, closed: true}, err
 	}
@@ -674,6 +1023,10 @@ func (c *Conn) Query(ctx context.Context, sql string, args ...any) (Rows, error)
 	var resultFormatsByOID QueryResultFormatsByOID
 	mode := c.config.DefaultQueryExecMode
 	var queryRewriter QueryRewriter
+	var statementTimeout time.Duration
+	var fetchSize int
+	var paramOIDs ParamOIDs
+	var notices *[]pgconn.Notice
 
 optionLoop:
 	for len(args) > 0 {
@@ -687,6 +1040,26 @@ optionLoop:
 		case QueryExecMode:
 			mode = arg
 			args = args[1:]
+		case ParamOIDs:
+			paramOIDs = arg
+			args = args[1:]
+		case QueryOptions:
+			if arg.ExecMode != 0 {
+				mode = arg.ExecMode
+			}
+			if arg.ResultFormats != nil {
+				resultFormats = arg.ResultFormats
+			}
+			if arg.ResultFormatsByOID != nil {
+				resultFormatsByOID = arg.ResultFormatsByOID
+			}
+			if arg.ParamOIDs != nil {
+				paramOIDs = arg.ParamOIDs
+			}
+			statementTimeout = arg.StatementTimeout
+			fetchSize = arg.FetchSize
+			notices = arg.Notices
+			args = args[1:]
 		case QueryRewriter:
 			queryRewriter = arg
 			args = args[1:]
@@ -702,12 +1075,15 @@ optionLoop:
 		sql, args, err = queryRewriter.RewriteQuery(ctx, c, sql, args)
 		if err != nil {
 			rows := c.getRows(ctx, originalSQL, originalArgs)
+			rows.queryTracer = tracer
 			err = fmt.Errorf("rewrite query failed: %v", err)
 			rows.fatal(err)
 			return rows, err
 		}
 	}
 
+	sql = c.annotateSQL(ctx, sql)
+
 	// Bypass any statement caching.
 	if sql == "" {
 		mode = QueryExecModeSimpleProtocol
@@ -716,10 +1092,47 @@ optionLoop:
 	c.eqb.reset()
 	anynil.NormalizeSlice(args)
 	rows := c.getRows(ctx, sql, args)
+	rows.queryTracer = tracer
+
+	if fetchSize < 0 || int64(fetchSize) > math.MaxUint32 {
+		err := fmt.Errorf("invalid FetchSize: %d", fetchSize)
+		rows.fatal(err)
+		return rows, err
+	}
+	if fetchSize > 0 && mode == QueryExecModeSimpleProtocol {
+		err := errors.New("FetchSize is not supported with QueryExecModeSimpleProtocol")
+		rows.fatal(err)
+		return rows, err
+	}
+	maxRows := uint32(fetchSize)
+
+	if statementTimeout == 0 && c.config.StatementTimeoutFromDeadline {
+		statementTimeout = c.deadlineStatementTimeout(ctx, sql)
+	}
 
 	var err error
+	if statementTimeout > 0 {
+		rows.restoreSettings, err = c.setStatementTimeout(ctx, statementTimeout)
+		if err != nil {
+			rows.fatal(err)
+			return rows, err
+		}
+	}
+
+	rows.noticesDst = notices
+	c.startCollectingNotices(&rows.notices)
+
 	sd, explicitPreparedStatement := c.preparedStatements[sql]
 	if sd != nil || mode == QueryExecModeCacheStatement || mode == QueryExecModeCacheDescribe || mode == QueryExecModeDescribeExec {
+		if sd == nil && paramOIDs != nil {
+			// Statements described with parameter types are not cached as the cache is keyed by sql alone.
+			mode = QueryExecModeDescribeExec
+			sd, err = c.prepare(ctx, "", sql, paramOIDs)
+			if err != nil {
+				rows.fatal(err)
+				return rows, err
+			}
+		}
 		if sd == nil {
 			sd, err = c.getStatementDescription(ctx, mode, sql)
 			if err != nil {
@@ -753,24 +1166,30 @@ optionLoop:
 		}
 
 		if !explicitPreparedStatement && mode == QueryExecModeCacheDescribe {
-			rows.resultReader = c.pgConn.ExecParams(ctx, sql, c.eqb.ParamValues, sd.ParamOIDs, c.eqb.ParamFormats, resultFormats)
+			rows.resultReader = c.pgConn.ExecParamsMaxRows(ctx, sql, c.eqb.ParamValues, sd.ParamOIDs, c.eqb.ParamFormats, resultFormats, maxRows)
 		} else {
-			rows.resultReader = c.pgConn.ExecPrepared(ctx, sd.Name, c.eqb.ParamValues, c.eqb.ParamFormats, resultFormats)
+			rows.resultReader = c.pgConn.ExecPreparedMaxRows(ctx, sd.Name, c.eqb.ParamValues, c.eqb.ParamFormats, resultFormats, maxRows)
 		}
 	} else if mode == QueryExecModeExec {
-		err := c.eqb.Build(c.typeMap, nil, args)
+		var oids []uint32
+		if paramOIDs != nil {
+			oids, err = c.eqb.buildWithParamOIDs(c.typeMap, paramOIDs, args)
+		} else {
+			err = c.eqb.Build(c.typeMap, nil, args)
+		}
 		if err != nil {
 			rows.fatal(err)
 			return rows, rows.err
 		}
 
-		rows.resultReader = c.pgConn.ExecParams(ctx, sql, c.eqb.ParamValues, nil, c.eqb.ParamFormats, c.eqb.ResultFormats)
+		rows.resultReader = c.pgConn.ExecParamsMaxRows(ctx, sql, c.eqb.ParamValues, oids, c.eqb.ParamFormats, c.eqb.ResultFormats, maxRows)
 	} else if mode == QueryExecModeSimpleProtocol {
 		sql, err = c.sanitizeForSimpleQuery(sql, args...)
 		if err != nil {
 			rows.fatal(err)
 			return rows, err
 		}
+		rows.sentSQL = sql
 
 		mrr := c.pgConn.Exec(ctx, sql)
 		if mrr.NextResult() {
@@ -841,21 +1260,150 @@ func (c *Conn) getStatementDescription(
 // error with ErrNoRows if no rows are returned.
 func (c *Conn) QueryRow(ctx context.Context, sql string, args ...any) Row {
 	rows, _ := c.Query(ctx, sql, args...)
-	return (*connRow)(rows.(*baseRows))
+	return rowsToRow(rows)
 }
 
 // SendBatch sends all queued queries to the server at once. All queries are run in an implicit transaction unless
 // explicit transaction control statements are executed. The returned BatchResults must be closed before the connection
 // is used again.
-func (c *Conn) SendBatch(ctx context.Context, b *Batch) (br BatchResults) {
-	if c.batchTracer != nil {
-		ctx = c.batchTracer.TraceBatchStart(ctx, c, TraceBatchStartData{Batch: b})
-		defer func() {
-			err := br.(interface{ earlyError() error }).earlyError()
-			if err != nil {
-				c.batchTracer.TraceBatchEnd(ctx, c, TraceBatchEndData{Err: err})
-			}
-		}()
+func (c *Conn) SendBatch(ctx context.Context, b *Batch) BatchResults {
+	return c.SendBatchInto(ctx, b, nil)
+}
+
+// SendBatchInto sends b like SendBatch but the returned BatchResults and the Rows returned by its Query and QueryRow
+// methods are stored in rbr instead of being allocated for each batch. This reduces allocations for applications that
+// send many batches. See ReusableBatchResults for the restrictions on the use of the results. If rbr is nil
+// SendBatchInto is equivalent to SendBatch.
+func (c *Conn) SendBatchInto(ctx context.Context, b *Batch, rbr *ReusableBatchResults) BatchResults {
+	return c.applyBatchResultsMiddleware(c.sendBatchInto(ctx, b, rbr))
+}
+
+// applyBatchResultsMiddleware returns br wrapped by ConnConfig.BatchResultsMiddleware if it is set.
+func (c *Conn) applyBatchResultsMiddleware(br sentBatchResults) BatchResults {
+	if c.config.BatchResultsMiddleware != nil {
+		return c.config.BatchResultsMiddleware(br)
+	}
+
+	return br
+}
+
+// sendBatchInto implements SendBatchInto without applying ConnConfig.BatchResultsMiddleware.
+func (c *Conn) sendBatchInto(ctx context.Context, b *Batch, rbr *ReusableBatchResults) sentBatchResults {
+	stats := c.startBatchStats(b)
+	br := c.sendBatchWithOptions(ctx, b, rbr)
+	br.recordStats(stats)
+	return br
+}
+
+// sendBatchWithOptions sends b as its Options specify.
+func (c *Conn) sendBatchWithOptions(ctx context.Context, b *Batch, rbr *ReusableBatchResults) sentBatchResults {
+	if b.Options.IsolateErrors {
+		return c.sendBatchIsolated(ctx, b)
+	}
+	if bounds := b.chunkBounds(); bounds != nil {
+		return c.sendBatchChunks(ctx, b, bounds)
+	}
+
+	tracer := c.batchTracerFor(b)
+	if tracer != nil {
+		acquireDuration, _ := ctx.Value(batchAcquireDurationCtxKey{}).(time.Duration)
+		ctx = tracer.TraceBatchStart(ctx, c, TraceBatchStartData{Batch: b, AcquireDuration: acquireDuration})
+	}
+
+	// Record the transaction status before anything is sent. Reading the results updates it.
+	inTx := c.pgConn.TxStatus() != 'I'
+
+	prevReadBufferSize := 0
+	if b.Options.ReadBufferSize > 0 {
+		prevReadBufferSize = c.pgConn.Frontend().ReadBufferSize()
+		c.pgConn.Frontend().SetReadBufferSize(b.Options.ReadBufferSize)
+	}
+
+	var br sentBatchResults = c.sendBatch(ctx, b, rbr)
+	br.setInTransaction(inTx)
+	br.setBatchTracer(tracer)
+	br.restoreReadBufferSizeOnClose(prevReadBufferSize)
+
+	if err := br.earlyError(); err != nil {
+		br.setUnsentBatch(b)
+		if tracer != nil {
+			tracer.TraceBatchEnd(ctx, c, TraceBatchEndData{Err: err})
+		}
+	} else {
+		if tracer != nil {
+			br.setFlushTime(time.Now())
+		}
+		br.collectNotices()
+
+		// The connection cannot be used for another batch until the results are closed. Buffered results are closed
+		// before they are returned.
+		c.batchInFlight = true
+		br.clearInFlightOnClose()
+
+		if b.Options.Tx != nil {
+			// Read the result of beginning the transaction so the first result read by the caller is of its first query.
+			// The result of committing it is read when the results are closed.
+			br.skipResult()
+			br.rollbackTxOnClose()
+		}
+		if b.readOnly {
+			// Read the result of making the transaction read only so the first result read by the caller is of its first
+			// query.
+			br.skipResult()
+		}
+		if b.applicationName != nil {
+			// Read the result of setting the application name so the first result read by the caller is of its first
+			// query. The result of restoring it is read when the results are closed.
+			br.skipResult()
+		}
+
+		if b.Options.Buffered {
+			br = bufferBatchResults(c, b, br)
+			br.setInTransaction(inTx)
+		}
+	}
+
+	return br
+}
+
+// batchTracerFor returns the tracer to use for b. It is nil if b is not traced.
+func (c *Conn) batchTracerFor(b *Batch) BatchTracer {
+	switch tracer := b.Options.Tracer.(type) {
+	case nil:
+		return c.batchTracer
+	case NoopBatchTracer:
+		return nil
+	default:
+		return tracer
+	}
+}
+
+// sentBatchResults is implemented by the BatchResults returned by sendBatch.
+type sentBatchResults interface {
+	BatchResults
+	earlyError() error
+	setInTransaction(inTx bool)
+	recordStats(r batchStatsRecorder)
+	setBatchTracer(t BatchTracer)
+	setFlushTime(t time.Time)
+	setUnsentBatch(b *Batch)
+	restoreReadBufferSizeOnClose(n int)
+	clearInFlightOnClose()
+	rollbackTxOnClose()
+	collectNotices()
+	skipResult()
+	AllFieldDescriptions() map[int][]pgconn.FieldDescription
+	ItemNotices(index int) []pgconn.Notice
+}
+
+// sendBatch sends b to the server using the connection's default query exec mode.
+func (c *Conn) sendBatch(ctx context.Context, b *Batch, rbr *ReusableBatchResults) sentBatchResults {
+	if c.batchInFlight {
+		return &batchResults{ctx: ctx, conn: c, err: ErrConnBusy}
+	}
+
+	if b.Options.Tx != nil && c.pgConn.TxStatus() != 'I' {
+		return &batchResults{ctx: ctx, conn: c, err: errBatchTxInTransaction}
 	}
 
 	if err := c.deallocateInvalidatedCachedStatements(ctx); err != nil {
@@ -865,39 +1413,46 @@ func (c *Conn) SendBatch(ctx context.Context, b *Batch) (br BatchResults) {
 	mode := c.config.DefaultQueryExecMode
 
 	for _, bi := range b.queuedQueries {
-		var queryRewriter QueryRewriter
-		sql := bi.query
-		arguments := bi.arguments
-
-	optionLoop:
-		for len(arguments) > 0 {
-			switch arg := arguments[0].(type) {
-			case QueryRewriter:
-				queryRewriter = arg
-				arguments = arguments[1:]
-			default:
-				break optionLoop
-			}
+		sql, arguments, err := c.rewriteQueuedQuery(ctx, bi)
+		if err != nil {
+			return &batchResults{ctx: ctx, conn: c, err: err}
 		}
 
-		if queryRewriter != nil {
-			var err error
-			sql, arguments, err = queryRewriter.RewriteQuery(ctx, c, sql, arguments)
-			if err != nil {
-				return &batchResults{ctx: ctx, conn: c, err: fmt.Errorf("rewrite query failed: %v", err)}
+		bi.query = c.annotateSQL(ctx, sql)
+		bi.arguments = arguments
+	}
+
+	if b.Options.RejectTypedNilArgs {
+		for i, bi := range b.queuedQueries {
+			for j, arg := range bi.arguments {
+				if arg != nil && anynil.Is(arg) {
+					return &batchResults{ctx: ctx, conn: c, err: fmt.Errorf("batch item %d arg %d: typed nil %T is not allowed with RejectTypedNilArgs", i, j, arg)}
+				}
 			}
 		}
+	}
 
-		bi.query = sql
-		bi.arguments = arguments
+	if b.readOnly {
+		for i, bi := range b.queuedQueries {
+			if classifyStatement(bi.query) == StatementKindWrite {
+				return &batchResults{ctx: ctx, conn: c, err: fmt.Errorf("batch item %d: write statement is not allowed in a read only batch", i)}
+			}
+		}
 	}
 
 	if mode == QueryExecModeSimpleProtocol {
-		return c.sendBatchQueryExecModeSimpleProtocol(ctx, b)
+		return c.sendBatchQueryExecModeSimpleProtocol(ctx, b, rbr)
+	}
+
+	if err := c.encodeBatchCopyData(ctx, b, mode); err != nil {
+		return &batchResults{ctx: ctx, conn: c, err: err}
 	}
 
 	// All other modes use extended protocol and thus can use prepared statements.
 	for _, bi := range b.queuedQueries {
+		if bi.execParams != nil || bi.copyFrom != nil {
+			continue
+		}
 		if sd, ok := c.preparedStatements[bi.query]; ok {
 			bi.sd = sd
 		}
@@ -905,75 +1460,189 @@ func (c *Conn) SendBatch(ctx context.Context, b *Batch) (br BatchResults) {
 
 	switch mode {
 	case QueryExecModeExec:
-		return c.sendBatchQueryExecModeExec(ctx, b)
+		return c.sendBatchQueryExecModeExec(ctx, b, rbr)
 	case QueryExecModeCacheStatement:
-		return c.sendBatchQueryExecModeCacheStatement(ctx, b)
+		return c.sendBatchQueryExecModeCacheStatement(ctx, b, rbr)
 	case QueryExecModeCacheDescribe:
-		return c.sendBatchQueryExecModeCacheDescribe(ctx, b)
+		return c.sendBatchQueryExecModeCacheDescribe(ctx, b, rbr)
 	case QueryExecModeDescribeExec:
-		return c.sendBatchQueryExecModeDescribeExec(ctx, b)
+		return c.sendBatchQueryExecModeDescribeExec(ctx, b, rbr)
 	default:
 		panic("unknown QueryExecMode")
 	}
 }
 
-func (c *Conn) sendBatchQueryExecModeSimpleProtocol(ctx context.Context, b *Batch) *batchResults {
+// rewriteQueuedQuery returns the SQL and arguments of bi after they are rewritten by the QueryRewriter bi was queued
+// with, if any. bi is not modified.
+func (c *Conn) rewriteQueuedQuery(ctx context.Context, bi *QueuedQuery) (string, []any, error) {
+	var queryRewriter QueryRewriter
+	sql := bi.query
+	arguments := bi.arguments
+
+optionLoop:
+	for len(arguments) > 0 {
+		switch arg := arguments[0].(type) {
+		case QueryRewriter:
+			queryRewriter = arg
+			arguments = arguments[1:]
+		default:
+			break optionLoop
+		}
+	}
+
+	if queryRewriter != nil {
+		var err error
+		sql, arguments, err = queryRewriter.RewriteQuery(ctx, c, sql, arguments)
+		if err != nil {
+			return "", nil, fmt.Errorf("rewrite query failed: %v", err)
+		}
+	}
+
+	return sql, arguments, nil
+}
+
+func (c *Conn) sendBatchQueryExecModeSimpleProtocol(ctx context.Context, b *Batch, rbr *ReusableBatchResults) *batchResults {
+	setAppName, restoreAppName, changeAppName := c.batchApplicationNames(b)
+
 	var sb strings.Builder
+	if b.Options.Tx != nil {
+		sb.WriteString(b.Options.Tx.beginSQL())
+	}
+	if b.readOnly {
+		if sb.Len() > 0 {
+			sb.WriteByte(';')
+		}
+		sb.WriteString(setReadOnlySQL)
+	}
+	if changeAppName {
+		sql, err := c.sanitizeForSimpleQuery(setApplicationNameSQL, setAppName)
+		if err != nil {
+			return &batchResults{ctx: ctx, conn: c, err: err}
+		}
+		if sb.Len() > 0 {
+			sb.WriteByte(';')
+		}
+		sb.WriteString(sql)
+	}
 	for i, bi := range b.queuedQueries {
-		if i > 0 {
+		if bi.execParams != nil {
+			return &batchResults{ctx: ctx, conn: c, err: fmt.Errorf("batch item %d: QueueExecParams is not supported with QueryExecModeSimpleProtocol", i)}
+		}
+		if bi.maxRows != 0 {
+			return &batchResults{ctx: ctx, conn: c, err: fmt.Errorf("batch item %d: QueueLimited is not supported with QueryExecModeSimpleProtocol", i)}
+		}
+		if bi.copyFrom != nil {
+			return &batchResults{ctx: ctx, conn: c, err: fmt.Errorf("batch item %d: QueueCopyFrom is not supported with QueryExecModeSimpleProtocol", i)}
+		}
+		if sb.Len() > 0 {
+			sb.WriteByte(';')
+		}
+		if bi.timeout > 0 {
+			sql, err := c.sanitizeForSimpleQuery(setItemStatementTimeoutSQL, statementTimeoutSetting(bi.timeout))
+			if err != nil {
+				return &batchResults{ctx: ctx, conn: c, err: err}
+			}
+			sb.WriteString(sql)
 			sb.WriteByte(';')
 		}
 		sql, err := c.sanitizeForSimpleQuery(bi.query, bi.arguments...)
+		if err != nil {
+			return &batchResults{ctx: ctx, conn: c, err: c.batchItemEncodeError(i, err)}
+		}
+		sb.WriteString(sql)
+		if bi.timeout > 0 {
+			sb.WriteByte(';')
+			sb.WriteString(restoreItemStatementTimeoutSQL)
+		}
+	}
+	if changeAppName {
+		sql, err := c.sanitizeForSimpleQuery(setApplicationNameSQL, restoreAppName)
 		if err != nil {
 			return &batchResults{ctx: ctx, conn: c, err: err}
 		}
+		sb.WriteByte(';')
 		sb.WriteString(sql)
 	}
+	if b.Options.Tx != nil {
+		sb.WriteString(";commit")
+	}
 	mrr := c.pgConn.Exec(ctx, sb.String())
-	return &batchResults{
+	b.notifyFlush(len(b.queuedQueries))
+	return rbr.batchResults(batchResults{
 		ctx:   ctx,
 		conn:  c,
 		mrr:   mrr,
 		b:     b,
 		qqIdx: 0,
-	}
+	})
 }
 
-func (c *Conn) sendBatchQueryExecModeExec(ctx context.Context, b *Batch) *batchResults {
+func (c *Conn) sendBatchQueryExecModeExec(ctx context.Context, b *Batch, rbr *ReusableBatchResults) *batchResults {
 	batch := &pgconn.Batch{}
 
-	for _, bi := range b.queuedQueries {
-		sd := bi.sd
-		if sd != nil {
+	if b.Options.Tx != nil {
+		batch.ExecParams(b.Options.Tx.beginSQL(), nil, nil, nil, nil)
+	}
+	if b.readOnly {
+		batch.ExecParams(setReadOnlySQL, nil, nil, nil, nil)
+	}
+
+	setAppName, restoreAppName, changeAppName := c.batchApplicationNames(b)
+	if changeAppName {
+		batch.ExecParams(setApplicationNameSQL, [][]byte{[]byte(setAppName)}, []uint32{pgtype.TextOID}, nil, nil)
+	}
+
+	for i, bi := range b.queuedQueries {
+		if bi.timeout > 0 {
+			batch.ExecParams(setItemStatementTimeoutSQL, [][]byte{[]byte(statementTimeoutSetting(bi.timeout))}, []uint32{pgtype.TextOID}, nil, nil)
+		}
+
+		if cf := bi.copyFrom; cf != nil {
+			batch.CopyFrom(bi.query, cf.data)
+		} else if ep := bi.execParams; ep != nil {
+			batch.ExecParams(bi.query, ep.paramValues, ep.paramOIDs, ep.paramFormats, ep.resultFormats)
+		} else if sd := bi.sd; sd != nil {
 			err := c.eqb.Build(c.typeMap, sd, bi.arguments)
 			if err != nil {
-				return &batchResults{ctx: ctx, conn: c, err: err}
+				return &batchResults{ctx: ctx, conn: c, err: c.batchItemEncodeError(i, err)}
 			}
 
-			batch.ExecPrepared(sd.Name, c.eqb.ParamValues, c.eqb.ParamFormats, c.eqb.ResultFormats)
+			batch.ExecPreparedMaxRows(sd.Name, c.eqb.ParamValues, c.eqb.ParamFormats, c.eqb.ResultFormats, bi.maxRows)
 		} else {
 			err := c.eqb.Build(c.typeMap, nil, bi.arguments)
 			if err != nil {
-				return &batchResults{ctx: ctx, conn: c, err: err}
+				return &batchResults{ctx: ctx, conn: c, err: c.batchItemEncodeError(i, err)}
 			}
-			batch.ExecParams(bi.query, c.eqb.ParamValues, nil, c.eqb.ParamFormats, c.eqb.ResultFormats)
+			batch.ExecParamsMaxRows(bi.query, c.eqb.ParamValues, nil, c.eqb.ParamFormats, c.eqb.ResultFormats, bi.maxRows)
+		}
+
+		if bi.timeout > 0 {
+			batch.ExecParams(restoreItemStatementTimeoutSQL, nil, nil, nil, nil)
 		}
 	}
 
+	if changeAppName {
+		batch.ExecParams(setApplicationNameSQL, [][]byte{[]byte(restoreAppName)}, []uint32{pgtype.TextOID}, nil, nil)
+	}
+	if b.Options.Tx != nil {
+		batch.ExecParams("commit", nil, nil, nil, nil)
+	}
+
 	c.eqb.reset() // Allow c.eqb internal memory to be GC'ed as soon as possible.
 
 	mrr := c.pgConn.ExecBatch(ctx, batch)
+	b.notifyFlush(len(b.queuedQueries))
 
-	return &batchResults{
+	return rbr.batchResults(batchResults{
 		ctx:   ctx,
 		conn:  c,
 		mrr:   mrr,
 		b:     b,
 		qqIdx: 0,
-	}
+	})
 }
 
-func (c *Conn) sendBatchQueryExecModeCacheStatement(ctx context.Context, b *Batch) (pbr *pipelineBatchResults) {
+func (c *Conn) sendBatchQueryExecModeCacheStatement(ctx context.Context, b *Batch, rbr *ReusableBatchResults) (pbr *pipelineBatchResults) {
 	if c.statementCache == nil {
 		return &pipelineBatchResults{ctx: ctx, conn: c, err: errDisabledStatementCache}
 	}
@@ -982,7 +1651,7 @@ func (c *Conn) sendBatchQueryExecModeCacheStatement(ctx context.Context, b *Batc
 	distinctNewQueriesIdxMap := make(map[string]int)
 
 	for _, bi := range b.queuedQueries {
-		if bi.sd == nil {
+		if bi.sd == nil && bi.execParams == nil && bi.copyFrom == nil {
 			sd := c.statementCache.Get(bi.query)
 			if sd != nil {
 				bi.sd = sd
@@ -1002,10 +1671,10 @@ func (c *Conn) sendBatchQueryExecModeCacheStatement(ctx context.Context, b *Batc
 		}
 	}
 
-	return c.sendBatchExtendedWithDescription(ctx, b, distinctNewQueries, c.statementCache)
+	return c.sendBatchExtendedWithDescription(ctx, b, distinctNewQueries, c.statementCache, rbr)
 }
 
-func (c *Conn) sendBatchQueryExecModeCacheDescribe(ctx context.Context, b *Batch) (pbr *pipelineBatchResults) {
+func (c *Conn) sendBatchQueryExecModeCacheDescribe(ctx context.Context, b *Batch, rbr *ReusableBatchResults) (pbr *pipelineBatchResults) {
 	if c.descriptionCache == nil {
 		return &pipelineBatchResults{ctx: ctx, conn: c, err: errDisabledDescriptionCache}
 	}
@@ -1014,7 +1683,7 @@ func (c *Conn) sendBatchQueryExecModeCacheDescribe(ctx context.Context, b *Batch
 	distinctNewQueriesIdxMap := make(map[string]int)
 
 	for _, bi := range b.queuedQueries {
-		if bi.sd == nil {
+		if bi.sd == nil && bi.execParams == nil && bi.copyFrom == nil {
 			sd := c.descriptionCache.Get(bi.query)
 			if sd != nil {
 				bi.sd = sd
@@ -1033,15 +1702,15 @@ func (c *Conn) sendBatchQueryExecModeCacheDescribe(ctx context.Context, b *Batch
 		}
 	}
 
-	return c.sendBatchExtendedWithDescription(ctx, b, distinctNewQueries, c.descriptionCache)
+	return c.sendBatchExtendedWithDescription(ctx, b, distinctNewQueries, c.descriptionCache, rbr)
 }
 
-func (c *Conn) sendBatchQueryExecModeDescribeExec(ctx context.Context, b *Batch) (pbr *pipelineBatchResults) {
+func (c *Conn) sendBatchQueryExecModeDescribeExec(ctx context.Context, b *Batch, rbr *ReusableBatchResults) (pbr *pipelineBatchResults) {
 	distinctNewQueries := []*pgconn.StatementDescription{}
 	distinctNewQueriesIdxMap := make(map[string]int)
 
 	for _, bi := range b.queuedQueries {
-		if bi.sd == nil {
+		if bi.sd == nil && bi.execParams == nil && bi.copyFrom == nil {
 			if idx, present := distinctNewQueriesIdxMap[bi.query]; present {
 				bi.sd = distinctNewQueries[idx]
 			} else {
@@ -1055,10 +1724,46 @@ func (c *Conn) sendBatchQueryExecModeDescribeExec(ctx context.Context, b *Batch)
 		}
 	}
 
-	return c.sendBatchExtendedWithDescription(ctx, b, distinctNewQueries, nil)
+	return c.sendBatchExtendedWithDescription(ctx, b, distinctNewQueries, nil, rbr)
 }
 
-func (c *Conn) sendBatchExtendedWithDescription(ctx context.Context, b *Batch, distinctNewQueries []*pgconn.StatementDescription, sdCache stmtcache.Cache) (pbr *pipelineBatchResults) {
+// checkBatchStatementNames returns an error if b queues a prepared statement by name and two items use statements
+// with the same name but different SQL. This could happen if a statement cached by the connection and a statement
+// prepared with Prepare had the same name. Sending the batch would silently execute the wrong query.
+func (c *Conn) checkBatchStatementNames(b *Batch) error {
+	byName := false
+	for _, bi := range b.queuedQueries {
+		if sd, ok := c.preparedStatements[bi.query]; ok && sd == bi.sd {
+			byName = true
+			break
+		}
+	}
+	if !byName {
+		return nil
+	}
+
+	nameToIdx := make(map[string]int)
+	for i, bi := range b.queuedQueries {
+		if bi.sd == nil || bi.sd.Name == "" {
+			continue
+		}
+		if j, ok := nameToIdx[bi.sd.Name]; ok {
+			if b.queuedQueries[j].sd.SQL != bi.sd.SQL {
+				return fmt.Errorf("batch items %d and %d use prepared statement %q with different SQL", j, i, bi.sd.Name)
+			}
+			continue
+		}
+		nameToIdx[bi.sd.Name] = i
+	}
+
+	return nil
+}
+
+func (c *Conn) sendBatchExtendedWithDescription(ctx context.Context, b *Batch, distinctNewQueries []*pgconn.StatementDescription, sdCache stmtcache.Cache, rbr *ReusableBatchResults) (pbr *pipelineBatchResults) {
+	if err := c.checkBatchStatementNames(b); err != nil {
+		return &pipelineBatchResults{ctx: ctx, conn: c, err: err}
+	}
+
 	pipeline := c.pgConn.StartPipeline(context.Background())
 	defer func() {
 		if pbr.err != nil {
@@ -1067,77 +1772,189 @@ func (c *Conn) sendBatchExtendedWithDescription(ctx context.Context, b *Batch, d
 	}()
 
 	// Prepare any needed queries
-	if len(distinctNewQueries) > 0 {
-		for _, sd := range distinctNewQueries {
-			pipeline.SendPrepare(sd.Name, sd.SQL, nil)
-		}
+	if err := prepareDistinctQueries(pipeline, distinctNewQueries); err != nil {
+		return &pipelineBatchResults{ctx: ctx, conn: c, err: err}
+	}
 
-		err := pipeline.Sync()
-		if err != nil {
-			return &pipelineBatchResults{ctx: ctx, conn: c, err: err}
+	// Put all statements into the cache. It's fine if it overflows because HandleInvalidated will clean them up later.
+	if sdCache != nil {
+		for _, sd := range distinctNewQueries {
+			sdCache.Put(sd)
 		}
+	}
 
-		for _, sd := range distinctNewQueries {
-			results, err := pipeline.GetResults()
+	if b.Options.AutoRegisterTypes {
+		if oids := c.unregisteredBatchResultOIDs(b); len(oids) > 0 {
+			// Types are loaded with regular queries so the pipeline must be closed while they are loaded.
+			err := pipeline.Close()
 			if err != nil {
 				return &pipelineBatchResults{ctx: ctx, conn: c, err: err}
 			}
 
-			resultSD, ok := results.(*pgconn.StatementDescription)
-			if !ok {
-				return &pipelineBatchResults{ctx: ctx, conn: c, err: fmt.Errorf("expected statement description, got %T", results)}
+			err = c.registerTypeOIDs(ctx, oids)
+			if err != nil {
+				return &pipelineBatchResults{ctx: ctx, conn: c, err: err}
 			}
 
-			// Fill in the previously empty / pending statement descriptions.
-			sd.ParamOIDs = resultSD.ParamOIDs
-			sd.Fields = resultSD.Fields
+			pipeline = c.pgConn.StartPipeline(context.Background())
 		}
+	}
 
-		results, err := pipeline.GetResults()
-		if err != nil {
-			return &pipelineBatchResults{ctx: ctx, conn: c, err: err}
+	// Queue the queries.
+	if b.Options.Tx != nil {
+		pipeline.SendQueryParams(b.Options.Tx.beginSQL(), nil, nil, nil, nil)
+	}
+	if b.readOnly {
+		pipeline.SendQueryParams(setReadOnlySQL, nil, nil, nil, nil)
+	}
+
+	setAppName, restoreAppName, changeAppName := c.batchApplicationNames(b)
+	if changeAppName {
+		pipeline.SendQueryParams(setApplicationNameSQL, [][]byte{[]byte(setAppName)}, []uint32{pgtype.TextOID}, nil, nil)
+	}
+
+	timedQueries := 0
+	for i, bi := range b.queuedQueries {
+		if bi.timeout > 0 {
+			pipeline.SendQueryParams(setItemStatementTimeoutSQL, [][]byte{[]byte(statementTimeoutSetting(bi.timeout))}, []uint32{pgtype.TextOID}, nil, nil)
+			timedQueries++
 		}
 
-		_, ok := results.(*pgconn.PipelineSync)
-		if !ok {
-			return &pipelineBatchResults{ctx: ctx, conn: c, err: fmt.Errorf("expected sync, got %T", results)}
+		if cf := bi.copyFrom; cf != nil {
+			pipeline.SendCopyFrom(bi.query, cf.data)
+		} else if ep := bi.execParams; ep != nil {
+			pipeline.SendQueryParams(bi.query, ep.paramValues, ep.paramOIDs, ep.paramFormats, ep.resultFormats)
+		} else {
+			err := c.eqb.Build(c.typeMap, bi.sd, bi.arguments)
+			if err != nil {
+				return &pipelineBatchResults{ctx: ctx, conn: c, err: c.batchItemEncodeError(i, err)}
+			}
+
+			if bi.sd.Name == "" {
+				pipeline.SendQueryParamsMaxRows(bi.sd.SQL, c.eqb.ParamValues, bi.sd.ParamOIDs, c.eqb.ParamFormats, c.eqb.ResultFormats, bi.maxRows)
+			} else {
+				pipeline.SendQueryPreparedMaxRows(bi.sd.Name, c.eqb.ParamValues, c.eqb.ParamFormats, c.eqb.ResultFormats, bi.maxRows)
+			}
 		}
-	}
 
-	// Put all statements into the cache. It's fine if it overflows because HandleInvalidated will clean them up later.
-	if sdCache != nil {
-		for _, sd := range distinctNewQueries {
-			sdCache.Put(sd)
+		if bi.timeout > 0 {
+			pipeline.SendQueryParams(restoreItemStatementTimeoutSQL, nil, nil, nil, nil)
 		}
 	}
 
-	// Queue the queries.
-	for _, bi := range b.queuedQueries {
-		err := c.eqb.Build(c.typeMap, bi.sd, bi.arguments)
+	if changeAppName {
+		pipeline.SendQueryParams(setApplicationNameSQL, [][]byte{[]byte(restoreAppName)}, []uint32{pgtype.TextOID}, nil, nil)
+	}
+	if b.Options.Tx != nil {
+		pipeline.SendQueryParams("commit", nil, nil, nil, nil)
+	}
+
+	err := pipeline.Sync()
+	if err != nil {
+		return &pipelineBatchResults{ctx: ctx, conn: c, err: err}
+	}
+	b.notifyFlush(len(b.queuedQueries))
+
+	// Every query is sent before the pipeline is synced so all of them are outstanding at the same time.
+	pipelineDepth := len(b.queuedQueries) + 2*timedQueries
+	if b.readOnly {
+		pipelineDepth++
+	}
+	if changeAppName {
+		pipelineDepth += 2
+	}
+	if b.Options.Tx != nil {
+		pipelineDepth += 2
+	}
+
+	return rbr.pipelineBatchResults(pipelineBatchResults{
+		ctx:              ctx,
+		conn:             c,
+		pipeline:         pipeline,
+		b:                b,
+		maxPipelineDepth: pipelineDepth,
+	})
+}
+
+// prepareDistinctQueries prepares the statements described by distinctNewQueries in a single round trip on pipeline
+// and fills in their parameter and result descriptions.
+func prepareDistinctQueries(pipeline *pgconn.Pipeline, distinctNewQueries []*pgconn.StatementDescription) error {
+	if len(distinctNewQueries) == 0 {
+		return nil
+	}
+
+	for _, sd := range distinctNewQueries {
+		pipeline.SendPrepare(sd.Name, sd.SQL, nil)
+	}
+
+	err := pipeline.Sync()
+	if err != nil {
+		return err
+	}
+
+	for _, sd := range distinctNewQueries {
+		results, err := pipeline.GetResults()
 		if err != nil {
-			// we wrap the error so we the user can understand which query failed inside the batch
-			err = fmt.Errorf("error building query %s: %w", bi.query, err)
-			return &pipelineBatchResults{ctx: ctx, conn: c, err: err}
+			return err
 		}
 
-		if bi.sd.Name == "" {
-			pipeline.SendQueryParams(bi.sd.SQL, c.eqb.ParamValues, bi.sd.ParamOIDs, c.eqb.ParamFormats, c.eqb.ResultFormats)
-		} else {
-			pipeline.SendQueryPrepared(bi.sd.Name, c.eqb.ParamValues, c.eqb.ParamFormats, c.eqb.ResultFormats)
+		resultSD, ok := results.(*pgconn.StatementDescription)
+		if !ok {
+			return fmt.Errorf("expected statement description, got %T", results)
 		}
+
+		// Fill in the previously empty / pending statement descriptions.
+		sd.ParamOIDs = resultSD.ParamOIDs
+		sd.Fields = resultSD.Fields
 	}
 
-	err := pipeline.Sync()
+	results, err := pipeline.GetResults()
 	if err != nil {
-		return &pipelineBatchResults{ctx: ctx, conn: c, err: err}
+		return err
+	}
+
+	_, ok := results.(*pgconn.PipelineSync)
+	if !ok {
+		return fmt.Errorf("expected sync, got %T", results)
 	}
 
-	return &pipelineBatchResults{
-		ctx:      ctx,
-		conn:     c,
-		pipeline: pipeline,
-		b:        b,
+	return nil
+}
+
+// setApplicationNameSQL is used to set application_name for the duration of a batch sent with
+// Batch.SetApplicationName. The setting is not local so it must be restored afterwards.
+const setApplicationNameSQL = "select set_config('application_name', $1, false)"
+
+// setReadOnlySQL makes the transaction of a batch sent with Batch.SetReadOnly read only. It is equivalent to SET
+// TRANSACTION READ ONLY but it does not warn when the batch is not in an explicit transaction block.
+const setReadOnlySQL = "select set_config('transaction_read_only', 'on', true)"
+
+// batchApplicationNames returns the application name to set before the queries in b are run and the application name
+// to restore after. changeAppName is false if b did not call SetApplicationName.
+func (c *Conn) batchApplicationNames(b *Batch) (setAppName, restoreAppName string, changeAppName bool) {
+	if b.applicationName == nil {
+		return "", "", false
+	}
+	return *b.applicationName, c.pgConn.ParameterStatus("application_name"), true
+}
+
+// batchItemEncodeError wraps err, which occurred while encoding the arguments of the batch item at itemIdx, so the
+// user can understand which item and argument failed inside the batch.
+func (c *Conn) batchItemEncodeError(itemIdx int, err error) error {
+	var argErr *argEncodeError
+	if !errors.As(err, &argErr) {
+		return fmt.Errorf("batch item %d: %w", itemIdx, err)
+	}
+
+	pgTypeName := "unknown"
+	if argErr.oid != 0 {
+		pgTypeName = fmt.Sprintf("OID %d", argErr.oid)
+		if dt, ok := c.typeMap.TypeForOID(argErr.oid); ok {
+			pgTypeName = dt.Name
+		}
 	}
+
+	return fmt.Errorf("batch item %d arg %d (%T -> %s): %w", itemIdx, argErr.argIdx, argErr.arg, pgTypeName, argErr.err)
 }
 
 func (c *Conn) sanitizeForSimpleQuery(sql string, args ...any) (string, error) {
@@ -1154,7 +1971,7 @@ func (c *Conn) sanitizeForSimpleQuery(sql string, args ...any) (string, error) {
 	for i, a := range args {
 		valueArgs[i], err = convertSimpleArgument(c.typeMap, a)
 		if err != nil {
-			return "", err
+			return "", &argEncodeError{argIdx: i, arg: a, err: err}
 		}
 	}
 
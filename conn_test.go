@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"os"
+	"regexp"
 	"strings"
 	"sync"
 	"testing"
@@ -176,6 +177,22 @@ func TestParseConfigExtractsStatementCacheOptions(t *testing.T) {
 	require.Equal(t, pgx.QueryExecModeSimpleProtocol, config.DefaultQueryExecMode)
 }
 
+func TestParseConfigExtractsStatementTimeoutFromDeadline(t *testing.T) {
+	t.Parallel()
+
+	config, err := pgx.ParseConfig("")
+	require.NoError(t, err)
+	require.False(t, config.StatementTimeoutFromDeadline)
+
+	config, err = pgx.ParseConfig("statement_timeout_from_deadline=true")
+	require.NoError(t, err)
+	require.True(t, config.StatementTimeoutFromDeadline)
+	require.NotContains(t, config.RuntimeParams, "statement_timeout_from_deadline")
+
+	_, err = pgx.ParseConfig("statement_timeout_from_deadline=maybe")
+	require.Error(t, err)
+}
+
 func TestParseConfigExtractsDefaultQueryExecMode(t *testing.T) {
 	t.Parallel()
 
@@ -534,6 +551,58 @@ func TestListenNotify(t *testing.T) {
 	assert.Equal(t, "chat", notification.Channel)
 }
 
+func TestWaitForNotificationFiltered(t *testing.T) {
+	t.Parallel()
+
+	listener := mustConnectString(t, os.Getenv("PGX_TEST_DATABASE"))
+	defer closeConn(t, listener)
+
+	pgxtest.SkipCockroachDB(t, listener, "Server does not support LISTEN / NOTIFY (https://github.com/cockroachdb/cockroach/issues/41522)")
+
+	mustExec(t, listener, "listen orders")
+	mustExec(t, listener, "listen invoices")
+	mustExec(t, listener, "listen audit")
+
+	notifier := mustConnectString(t, os.Getenv("PGX_TEST_DATABASE"))
+	defer closeConn(t, notifier)
+
+	mustExec(t, notifier, "notify audit, 'a1'")
+	mustExec(t, notifier, "notify orders, 'skip'")
+	mustExec(t, notifier, "notify invoices, 'id:42'")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	notification, err := listener.WaitForNotificationFiltered(ctx, pgx.NotificationFilter{
+		Channels: []string{"orders", "invoices"},
+		Payload:  regexp.MustCompile(`^id:\d+$`).MatchString,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "invoices", notification.Channel)
+	assert.Equal(t, "id:42", notification.Payload)
+
+	// Notifications that did not match are still available in the order they were received.
+	notification, err = listener.WaitForNotificationFiltered(ctx, pgx.NotificationFilter{Channels: []string{"orders"}})
+	require.NoError(t, err)
+	assert.Equal(t, "skip", notification.Payload)
+
+	notification, err = listener.WaitForNotification(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "audit", notification.Channel)
+
+	// when timeout occurs
+	timeoutCtx, timeoutCancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer timeoutCancel()
+	mustExec(t, notifier, "notify audit")
+	notification, err = listener.WaitForNotificationFiltered(timeoutCtx, pgx.NotificationFilter{Channels: []string{"orders"}})
+	assert.True(t, pgconn.Timeout(err))
+	assert.Nil(t, notification)
+
+	notification, err = listener.WaitForNotification(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "audit", notification.Channel)
+}
+
 func TestListenNotifyWhileBusyIsSafe(t *testing.T) {
 	t.Parallel()
 
@@ -1164,3 +1233,56 @@ func TestRawValuesUnderlyingMemoryReused(t *testing.T) {
 		t.Fatal("expected buffer from RawValues to be overwritten by subsequent queries but it was not")
 	})
 }
+
+func TestConnPingStats(t *testing.T) {
+	t.Parallel()
+
+	defaultConnTestRunner.RunTest(context.Background(), t, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		stats, err := conn.PingStats(ctx)
+		require.NoError(t, err)
+		require.Greater(t, stats.RoundTripTime, time.Duration(0))
+		require.False(t, stats.InTransaction)
+		require.False(t, stats.InFailedTransaction)
+
+		tx, err := conn.Begin(ctx)
+		require.NoError(t, err)
+		defer tx.Rollback(ctx)
+
+		stats, err = conn.PingStats(ctx)
+		require.NoError(t, err)
+		require.True(t, stats.InTransaction)
+		require.False(t, stats.InFailedTransaction)
+
+		_, err = tx.Exec(ctx, `select 1/0`)
+		require.Error(t, err)
+
+		stats, err = conn.PingStats(ctx)
+		require.NoError(t, err)
+		require.True(t, stats.InTransaction)
+		require.True(t, stats.InFailedTransaction)
+	})
+}
+
+func TestConnPingStatsInFailedTransactionWithStatementTimeoutFromDeadline(t *testing.T) {
+	t.Parallel()
+
+	config := mustParseConfig(t, os.Getenv("PGX_TEST_DATABASE"))
+	config.StatementTimeoutFromDeadline = true
+	conn := mustConnect(t, config)
+	defer closeConn(t, conn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	tx, err := conn.Begin(ctx)
+	require.NoError(t, err)
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `select 1/0`)
+	require.Error(t, err)
+
+	stats, err := conn.PingStats(ctx)
+	require.NoError(t, err)
+	require.True(t, stats.InTransaction)
+	require.True(t, stats.InFailedTransaction)
+}
@@ -97,14 +97,7 @@ func (ct *copyFrom) run(ctx context.Context) (int64, error) {
 	}
 
 	quotedTableName := ct.tableName.Sanitize()
-	cbuf := &bytes.Buffer{}
-	for i, cn := range ct.columnNames {
-		if i != 0 {
-			cbuf.WriteString(", ")
-		}
-		cbuf.WriteString(quoteIdentifier(cn))
-	}
-	quotedColumnNames := cbuf.String()
+	quotedColumnNames := quoteColumnNames(ct.columnNames)
 
 	var sd *pgconn.StatementDescription
 	switch ct.mode {
@@ -172,7 +165,8 @@ func (ct *copyFrom) run(ctx context.Context) (int64, error) {
 		w.Close()
 	}()
 
-	commandTag, err := ct.conn.pgConn.CopyFrom(ctx, r, fmt.Sprintf("copy %s ( %s ) from stdin binary;", quotedTableName, quotedColumnNames))
+	copySQL := ct.conn.annotateSQL(ctx, fmt.Sprintf("copy %s ( %s ) from stdin binary;", quotedTableName, quotedColumnNames))
+	commandTag, err := ct.conn.pgConn.CopyFrom(ctx, r, copySQL)
 
 	r.Close()
 	<-doneChan
@@ -187,6 +181,18 @@ func (ct *copyFrom) run(ctx context.Context) (int64, error) {
 	return commandTag.RowsAffected(), err
 }
 
+// quoteColumnNames returns columnNames quoted and separated by commas.
+func quoteColumnNames(columnNames []string) string {
+	cbuf := &bytes.Buffer{}
+	for i, cn := range columnNames {
+		if i != 0 {
+			cbuf.WriteString(", ")
+		}
+		cbuf.WriteString(quoteIdentifier(cn))
+	}
+	return cbuf.String()
+}
+
 func (ct *copyFrom) buildCopyBuf(buf []byte, sd *pgconn.StatementDescription) (bool, []byte, error) {
 
 	for ct.rowSrc.Next() {
@@ -0,0 +1,301 @@
+package pgx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// defaultCursorFetchSize is the number of rows fetched at a time by a Cursor when no fetch size is given.
+const defaultCursorFetchSize = 1000
+
+var cursorCounter int64
+
+var errCursorClosed = errors.New("cursor is closed")
+
+// Cursor reads the results of a query through a server-side cursor. The rows are fetched from the server fetchSize rows
+// at a time as they are read, so very large result sets can be read without the server sending the whole result at
+// once. Cursor implements Rows so it can be used with functions such as CollectRows and ForEachRow.
+//
+// The connection must not be used for anything else until the cursor is closed. As with Rows, the cursor is closed
+// automatically when all rows have been read and Err must be checked after it is closed.
+type Cursor struct {
+	conn      *Conn
+	ctx       context.Context
+	name      string
+	fetchSQL  string
+	fetchSize int64
+	ownTx     bool
+
+	simpleProtocol    bool
+	resultFormats     []int16
+	fieldDescriptions []pgconn.FieldDescription
+
+	rows     *baseRows
+	rowCount int64
+	notices  []pgconn.Notice // notices of the fetches whose rows have been closed
+
+	commandTag pgconn.CommandTag
+	err        error
+	closed     bool
+}
+
+var _ Rows = (*Cursor)(nil)
+
+// Cursor declares a server-side cursor for sql with args and fetches the first fetchSize rows. If fetchSize is 0 a
+// default of 1000 is used. As with Query, the first args may be a QueryRewriter such as NamedArgs.
+//
+// Cursors only exist in a transaction. If c is not in a transaction, Cursor begins one that is committed when the
+// cursor is closed. To declare a cursor in an explicit transaction call Cursor on the Conn of the transaction.
+//
+//	cur, err := tx.Conn().Cursor(ctx, 100, "select * from events")
+func (c *Conn) Cursor(ctx context.Context, fetchSize int, sql string, args ...any) (*Cursor, error) {
+	if fetchSize < 0 {
+		return nil, fmt.Errorf("cursor fetch size must not be negative, got %d", fetchSize)
+	}
+	if fetchSize == 0 {
+		fetchSize = defaultCursorFetchSize
+	}
+
+	cur := &Cursor{
+		conn:      c,
+		ctx:       ctx,
+		name:      "pgx_cursor_" + strconv.FormatInt(atomic.AddInt64(&cursorCounter, 1), 10),
+		fetchSize: int64(fetchSize),
+	}
+	cur.fetchSQL = "fetch " + strconv.Itoa(fetchSize) + " from " + quoteIdentifier(cur.name)
+
+	// Declaring the cursor with a cached statement would fill the statement cache with statements that are used once.
+	mode := c.config.DefaultQueryExecMode
+	switch mode {
+	case QueryExecModeCacheStatement, QueryExecModeCacheDescribe:
+		mode = QueryExecModeDescribeExec
+	case QueryExecModeSimpleProtocol:
+		cur.simpleProtocol = true
+	}
+
+	// The statements that begin and end the transaction and close the cursor are internal to the cursor so they are
+	// sent on the underlying connection.
+	if c.pgConn.TxStatus() == 'I' {
+		if _, err := c.pgConn.Exec(ctx, "begin").ReadAll(); err != nil {
+			return nil, err
+		}
+		cur.ownTx = true
+	}
+
+	declareArgs := make([]any, 0, len(args)+1)
+	declareArgs = append(declareArgs, mode)
+	declareArgs = append(declareArgs, args...)
+	_, err := c.Exec(ctx, "declare "+quoteIdentifier(cur.name)+" no scroll cursor for "+sql, declareArgs...)
+	if err != nil {
+		cur.err = err
+		cur.closed = true
+		cur.endTx()
+		return nil, err
+	}
+
+	if err := cur.fetch(); err != nil {
+		cur.fatal(err)
+		return nil, err
+	}
+
+	return cur, nil
+}
+
+// fetch fetches the next rows from the server.
+func (cur *Cursor) fetch() error {
+	c := cur.conn
+
+	if cur.rows != nil {
+		cur.notices = append(cur.notices, cur.rows.Notices()...)
+	}
+
+	if cur.simpleProtocol {
+		// The fetches are internal to the cursor so they bypass ConnConfig.QueryInterceptors.
+		rows, err := c.query(cur.ctx, cur.fetchSQL, QueryExecModeSimpleProtocol)
+		cur.rows = rows
+		if err != nil {
+			return err
+		}
+	} else {
+		if cur.resultFormats == nil {
+			sd, err := c.Prepare(cur.ctx, "", cur.fetchSQL)
+			if err != nil {
+				return err
+			}
+			cur.resultFormats = make([]int16, len(sd.Fields))
+			for i := range sd.Fields {
+				cur.resultFormats[i] = c.typeMap.FormatCodeForOID(sd.Fields[i].DataTypeOID)
+			}
+		}
+
+		ctx := cur.ctx
+		if c.queryTracer != nil {
+			ctx = c.queryTracer.TraceQueryStart(ctx, c, TraceQueryStartData{SQL: cur.fetchSQL})
+		}
+		cur.rows = c.getRows(ctx, cur.fetchSQL, nil)
+		c.startCollectingNotices(&cur.rows.notices)
+		cur.rows.resultReader = c.pgConn.ExecParams(ctx, cur.fetchSQL, nil, nil, nil, cur.resultFormats)
+	}
+
+	if cur.fieldDescriptions == nil {
+		fieldDescriptions := cur.rows.FieldDescriptions()
+		cur.fieldDescriptions = make([]pgconn.FieldDescription, len(fieldDescriptions))
+		copy(cur.fieldDescriptions, fieldDescriptions)
+	}
+
+	return nil
+}
+
+// Close closes the cursor on the server and commits the transaction begun by Conn.Cursor, if any. It is safe to call
+// Close after the cursor is already closed.
+func (cur *Cursor) Close() {
+	if cur.closed {
+		return
+	}
+	cur.closed = true
+
+	if cur.rows != nil {
+		cur.rows.Close()
+		if cur.err == nil {
+			cur.err = cur.rows.Err()
+		}
+		cur.notices = append(cur.notices, cur.rows.Notices()...)
+		cur.rows = nil
+	}
+
+	cur.commandTag = pgconn.NewCommandTag("FETCH " + strconv.FormatInt(cur.rowCount, 10))
+
+	if cur.err == nil && cur.conn.pgConn.TxStatus() == 'T' {
+		_, err := cur.conn.pgConn.Exec(cur.ctx, "close "+quoteIdentifier(cur.name)).ReadAll()
+		if err != nil {
+			cur.err = err
+		}
+	}
+
+	cur.endTx()
+}
+
+// endTx ends the transaction begun by Conn.Cursor. It is committed unless the cursor failed.
+func (cur *Cursor) endTx() {
+	if !cur.ownTx || cur.conn.IsClosed() {
+		return
+	}
+	cur.ownTx = false
+
+	sql := "commit"
+	if cur.err != nil || cur.conn.pgConn.TxStatus() != 'T' {
+		sql = "rollback"
+	}
+
+	_, err := cur.conn.pgConn.Exec(cur.ctx, sql).ReadAll()
+	if err != nil && cur.err == nil {
+		cur.err = err
+	}
+}
+
+// fatal records err and closes the cursor.
+func (cur *Cursor) fatal(err error) {
+	if cur.err == nil {
+		cur.err = err
+	}
+	cur.Close()
+}
+
+// Err returns any error that occurred while reading.
+func (cur *Cursor) Err() error {
+	return cur.err
+}
+
+// CommandTag returns a FETCH command tag with the total number of rows read. It is only available after the cursor is
+// closed.
+func (cur *Cursor) CommandTag() pgconn.CommandTag {
+	return cur.commandTag
+}
+
+func (cur *Cursor) FieldDescriptions() []pgconn.FieldDescription {
+	return cur.fieldDescriptions
+}
+
+// Next prepares the next row for reading. It fetches more rows from the server when all fetched rows have been read.
+// It returns false and closes the cursor when all rows have been read or an error occurs.
+func (cur *Cursor) Next() bool {
+	for !cur.closed {
+		if cur.rows.Next() {
+			cur.rowCount++
+			return true
+		}
+
+		if err := cur.rows.Err(); err != nil {
+			cur.fatal(err)
+			return false
+		}
+
+		if cur.rows.CommandTag().RowsAffected() < cur.fetchSize {
+			cur.Close()
+			return false
+		}
+
+		if err := cur.fetch(); err != nil {
+			cur.fatal(err)
+			return false
+		}
+	}
+
+	return false
+}
+
+func (cur *Cursor) Scan(dest ...any) error {
+	if cur.closed {
+		return errCursorClosed
+	}
+	return cur.rows.Scan(dest...)
+}
+
+func (cur *Cursor) Values() ([]any, error) {
+	if cur.closed {
+		return nil, errCursorClosed
+	}
+	return cur.rows.Values()
+}
+
+func (cur *Cursor) RawValues() [][]byte {
+	if cur.closed {
+		return nil
+	}
+	return cur.rows.RawValues()
+}
+
+func (cur *Cursor) Conn() *Conn {
+	return cur.conn
+}
+
+// Notices returns the notices received while fetching rows. The notices of the current fetch are only included once
+// its rows have been read.
+func (cur *Cursor) Notices() []pgconn.Notice {
+	return cur.notices
+}
+
+// FetchRefCursor fetches all rows of the cursor named refcursor, such as a refcursor value returned by a PL/pgSQL
+// function, and returns them as Rows. It must be called in the transaction that opened the cursor. The cursor is left
+// open and positioned after its last row. Use Cursor to read the results of a query through a cursor in pieces.
+//
+//	tx, err := conn.Begin(ctx)
+//	// ...
+//	var refcursor string
+//	err = tx.QueryRow(ctx, "select open_users_cursor()").Scan(&refcursor)
+//	// ...
+//	rows, err := tx.Conn().FetchRefCursor(ctx, refcursor)
+func (c *Conn) FetchRefCursor(ctx context.Context, refcursor string) (Rows, error) {
+	// Cursor names are often generated, such as "<unnamed portal 1>", so the statement is not cached.
+	mode := c.config.DefaultQueryExecMode
+	if mode == QueryExecModeCacheStatement || mode == QueryExecModeCacheDescribe {
+		mode = QueryExecModeDescribeExec
+	}
+
+	return c.Query(ctx, "fetch all from "+quoteIdentifier(refcursor), mode)
+}
@@ -0,0 +1,158 @@
+package pgx_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxtest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnCursor(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		cur, err := conn.Cursor(ctx, 10, `select n from generate_series(1, $1::int) n`, 95)
+		require.NoError(t, err)
+		require.Len(t, cur.FieldDescriptions(), 1)
+		require.Equal(t, byte('T'), conn.PgConn().TxStatus())
+
+		numbers, err := pgx.CollectRows[int32](cur, pgx.RowTo[int32])
+		require.NoError(t, err)
+		require.Len(t, numbers, 95)
+		for i, n := range numbers {
+			require.EqualValues(t, i+1, n)
+		}
+		require.Equal(t, "FETCH 95", cur.CommandTag().String())
+
+		require.Equal(t, byte('I'), conn.PgConn().TxStatus())
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestConnCursorFetchSizeMultiple(t *testing.T) {
+	t.Parallel()
+
+	defaultConnTestRunner.RunTest(context.Background(), t, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		cur, err := conn.Cursor(ctx, 5, `select n from generate_series(1, 20) n`)
+		require.NoError(t, err)
+
+		count := 0
+		for cur.Next() {
+			count++
+		}
+		require.NoError(t, cur.Err())
+		require.Equal(t, 20, count)
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestConnCursorCloseEarly(t *testing.T) {
+	t.Parallel()
+
+	defaultConnTestRunner.RunTest(context.Background(), t, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		cur, err := conn.Cursor(ctx, 10, `select n from generate_series(1, 1000) n`)
+		require.NoError(t, err)
+
+		require.True(t, cur.Next())
+		var n int32
+		require.NoError(t, cur.Scan(&n))
+		require.EqualValues(t, 1, n)
+
+		cur.Close()
+		require.NoError(t, cur.Err())
+		require.Equal(t, byte('I'), conn.PgConn().TxStatus())
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestConnCursorError(t *testing.T) {
+	t.Parallel()
+
+	defaultConnTestRunner.RunTest(context.Background(), t, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		_, err := conn.Cursor(ctx, 10, `select * from table_that_does_not_exist`)
+		var pgErr *pgconn.PgError
+		require.ErrorAs(t, err, &pgErr)
+		require.Equal(t, "42P01", pgErr.Code)
+		require.Equal(t, byte('I'), conn.PgConn().TxStatus())
+
+		cur, err := conn.Cursor(ctx, 3, `select 10 / (5 - n) from generate_series(1, 10) n`)
+		require.NoError(t, err)
+		for cur.Next() {
+		}
+		require.ErrorAs(t, cur.Err(), &pgErr)
+		require.Equal(t, "22012", pgErr.Code)
+		require.Equal(t, byte('I'), conn.PgConn().TxStatus())
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestTxCursor(t *testing.T) {
+	t.Parallel()
+
+	defaultConnTestRunner.RunTest(context.Background(), t, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		tx, err := conn.Begin(ctx)
+		require.NoError(t, err)
+		defer tx.Rollback(ctx)
+
+		_, err = tx.Exec(ctx, `create temporary table cursor_test(n int4) on commit drop`)
+		require.NoError(t, err)
+		_, err = tx.Exec(ctx, `insert into cursor_test select generate_series(1, 25)`)
+		require.NoError(t, err)
+
+		cur, err := tx.Conn().Cursor(ctx, 10, `select n from cursor_test order by n`)
+		require.NoError(t, err)
+		numbers, err := pgx.CollectRows[int32](cur, pgx.RowTo[int32])
+		require.NoError(t, err)
+		require.Len(t, numbers, 25)
+
+		// The cursor does not end the transaction.
+		require.Equal(t, byte('T'), conn.PgConn().TxStatus())
+		require.NoError(t, tx.Commit(ctx))
+	})
+}
+
+func TestConnFetchRefCursor(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		pgxtest.SkipCockroachDB(t, conn, "Server does not support PL/pgSQL cursors")
+
+		tx, err := conn.Begin(ctx)
+		require.NoError(t, err)
+		defer tx.Rollback(ctx)
+
+		_, err = tx.Exec(ctx, `create function pg_temp.pgx_refcursor_test() returns refcursor
+language plpgsql as $$
+declare
+	c refcursor;
+begin
+	open c for select n, n * 2 as m from generate_series(1, 5) n;
+	return c;
+end;
+$$`)
+		require.NoError(t, err)
+
+		var refcursor string
+		err = tx.QueryRow(ctx, `select pg_temp.pgx_refcursor_test()`).Scan(&refcursor)
+		require.NoError(t, err)
+
+		type pair struct {
+			N int32
+			M int32
+		}
+		rows, err := tx.Conn().FetchRefCursor(ctx, refcursor)
+		require.NoError(t, err)
+		pairs, err := pgx.CollectRows(rows, pgx.RowToStructByName[pair])
+		require.NoError(t, err)
+		require.Len(t, pairs, 5)
+		for i, p := range pairs {
+			require.EqualValues(t, i+1, p.N)
+			require.EqualValues(t, 2*(i+1), p.M)
+		}
+
+		require.NoError(t, tx.Commit(ctx))
+	})
+}
@@ -0,0 +1,12 @@
+package pgx
+
+import "github.com/jackc/pgx/v5/pgerr"
+
+// withErrorPosition wraps err in a *pgerr.PositionError with sql if ConnConfig.ErrorPositionSnippets is set. sql must
+// be the text the server received.
+func (c *Conn) withErrorPosition(err error, sql string) error {
+	if err == nil || !c.config.ErrorPositionSnippets {
+		return err
+	}
+	return pgerr.WithPosition(err, sql)
+}
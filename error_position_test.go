@@ -0,0 +1,77 @@
+package pgx_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgerr"
+	"github.com/jackc/pgx/v5/pgxtest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorPositionSnippets(t *testing.T) {
+	t.Parallel()
+
+	ctr := defaultConnTestRunner
+	ctr.CreateConfig = func(ctx context.Context, t testing.TB) *pgx.ConnConfig {
+		config := defaultConnTestRunner.CreateConfig(ctx, t)
+		config.ErrorPositionSnippets = true
+		return config
+	}
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, ctr, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		sql := "select n\nfrom generate_series(1, $1::int4) n\nwhere nme = 1"
+
+		_, err := conn.Exec(ctx, sql, 3)
+		var positionErr *pgerr.PositionError
+		require.ErrorAs(t, err, &positionErr)
+		require.Contains(t, err.Error(), "LINE 3: where nme = 1\n              ^")
+		var pgErr *pgconn.PgError
+		require.ErrorAs(t, err, &pgErr)
+		require.Equal(t, "42703", pgErr.Code)
+
+		rows, err := conn.Query(ctx, sql, 3)
+		if err == nil {
+			rows.Close()
+			err = rows.Err()
+		}
+		require.ErrorAs(t, err, &positionErr)
+		require.Contains(t, err.Error(), "LINE 3: where nme = 1\n              ^")
+
+		var n int32
+		err = conn.QueryRow(ctx, sql, 3).Scan(&n)
+		require.ErrorAs(t, err, &positionErr)
+
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestErrorPositionSnippetsPrepare(t *testing.T) {
+	t.Parallel()
+
+	ctr := defaultConnTestRunner
+	ctr.CreateConfig = func(ctx context.Context, t testing.TB) *pgx.ConnConfig {
+		config := defaultConnTestRunner.CreateConfig(ctx, t)
+		config.ErrorPositionSnippets = true
+		return config
+	}
+
+	ctr.RunTest(context.Background(), t, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		_, err := conn.Prepare(ctx, "ps", "select * fro foo")
+		require.EqualError(t, err, `ERROR: syntax error at or near "fro" (SQLSTATE 42601)`+"\nLINE 1: select * fro foo\n                 ^")
+	})
+}
+
+func TestErrorPositionSnippetsDisabled(t *testing.T) {
+	t.Parallel()
+
+	defaultConnTestRunner.RunTest(context.Background(), t, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		_, err := conn.Exec(ctx, "select * fro foo")
+		var positionErr *pgerr.PositionError
+		require.False(t, errors.As(err, &positionErr))
+		require.EqualError(t, err, `ERROR: syntax error at or near "fro" (SQLSTATE 42601)`)
+	})
+}
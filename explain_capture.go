@@ -0,0 +1,224 @@
+package pgx
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const defaultExplainCaptureTimeout = 5 * time.Second
+
+// ExplainCapture is a QueryInterceptor that captures the plan of slow queries. When a query run with Exec, Query, or
+// QueryRow takes at least Threshold it is run again with EXPLAIN and the plan is delivered to OnPlan. It is enabled by
+// adding it to ConnConfig.QueryInterceptors:
+//
+//	config.QueryInterceptors = append(config.QueryInterceptors, &pgx.ExplainCapture{
+//		Threshold:   time.Second,
+//		Analyze:     true,
+//		MinInterval: time.Minute,
+//		OnPlan: func(ctx context.Context, conn *pgx.Conn, plan *pgx.QueryPlan) {
+//			log.Printf("slow query %s took %v: %s", plan.SQL, plan.Duration, plan.Plan)
+//		},
+//	})
+//
+// The duration of a Query is measured until its Rows are closed, so it includes the time the application spends
+// between reading rows. Only queries that succeed are captured. Only SELECT, VALUES, TABLE, WITH, INSERT, UPDATE,
+// DELETE, and MERGE statements are explained. The plan is captured on the same connection, in the same transaction if
+// any, before Exec returns or the Rows are closed, so it adds to the latency of the slow query.
+//
+// An ExplainCapture may be shared by multiple connections.
+type ExplainCapture struct {
+	// Threshold is the minimum duration of a query whose plan is captured. A zero Threshold captures the plan of every
+	// query.
+	Threshold time.Duration
+
+	// Analyze runs read queries again with EXPLAIN (ANALYZE, BUFFERS) to capture actual row counts, timings, and buffer
+	// usage. As this runs the query a second time, queries that modify data are never analyzed; their plan is captured
+	// with plain EXPLAIN.
+	Analyze bool
+
+	// Timeout limits the time the server may spend running EXPLAIN with statement_timeout. Defaults to 5 seconds.
+	Timeout time.Duration
+
+	// MinInterval is the minimum time between captures across all connections that use the ExplainCapture. Slow queries
+	// that complete sooner after the previous capture are not explained. This bounds the additional load when many
+	// queries are slow at once. Zero does not limit captures.
+	MinInterval time.Duration
+
+	// OnPlan is called with each captured plan. It is required.
+	OnPlan func(ctx context.Context, conn *Conn, plan *QueryPlan)
+
+	mu          sync.Mutex
+	lastCapture time.Time
+}
+
+// QueryPlan is a plan captured by ExplainCapture.
+type QueryPlan struct {
+	// SQL is the SQL of the slow query.
+	SQL string
+
+	// Args are the arguments of the slow query.
+	Args []any
+
+	// Duration is the time the slow query took.
+	Duration time.Duration
+
+	// Analyzed is true if the plan was captured with EXPLAIN ANALYZE.
+	Analyzed bool
+
+	// Plan is the plan in the JSON format of EXPLAIN (FORMAT JSON). It is nil if Err is not nil.
+	Plan json.RawMessage
+
+	// Err is the error that occurred running EXPLAIN, if any.
+	Err error
+}
+
+// explainCaptureCtxKey marks the context of the EXPLAIN run by ExplainCapture so it is not itself captured.
+type explainCaptureCtxKey struct{}
+
+// InterceptExec implements QueryInterceptor.
+func (ec *ExplainCapture) InterceptExec(ctx context.Context, conn *Conn, sql string, args []any, next ExecFunc) (pgconn.CommandTag, error) {
+	if ctx.Value(explainCaptureCtxKey{}) != nil {
+		return next(ctx, sql, args)
+	}
+
+	start := time.Now()
+	commandTag, err := next(ctx, sql, args)
+	if err == nil {
+		ec.capture(ctx, conn, sql, args, time.Since(start))
+	}
+
+	return commandTag, err
+}
+
+// InterceptQuery implements QueryInterceptor.
+func (ec *ExplainCapture) InterceptQuery(ctx context.Context, conn *Conn, sql string, args []any, next QueryFunc) (Rows, error) {
+	if ctx.Value(explainCaptureCtxKey{}) != nil {
+		return next(ctx, sql, args)
+	}
+
+	start := time.Now()
+	rows, err := next(ctx, sql, args)
+	if err != nil {
+		return rows, err
+	}
+
+	return &explainCaptureRows{Rows: rows, onClose: func(rows Rows) {
+		if rows.Err() == nil {
+			ec.capture(ctx, conn, sql, args, time.Since(start))
+		}
+	}}, nil
+}
+
+// capture explains sql if it took at least ec.Threshold.
+func (ec *ExplainCapture) capture(ctx context.Context, conn *Conn, sql string, args []any, duration time.Duration) {
+	if duration < ec.Threshold || conn.IsClosed() || conn.PgConn().TxStatus() == 'E' {
+		return
+	}
+
+	var analyze bool
+	switch words := sqlKeywords(sql); {
+	case len(words) == 0:
+		return
+	case words[0] == "select" || words[0] == "values" || words[0] == "table" || words[0] == "with":
+		analyze = ec.Analyze && classifyStatement(sql) == StatementKindRead
+	case words[0] == "insert" || words[0] == "update" || words[0] == "delete" || words[0] == "merge":
+		// Queries that modify data are explained without running them again.
+	default:
+		return
+	}
+
+	if !ec.reserve() {
+		return
+	}
+
+	explainSQL := "explain (format json) " + sql
+	if analyze {
+		explainSQL = "explain (analyze, buffers, format json) " + sql
+	}
+
+	timeout := ec.Timeout
+	if timeout <= 0 {
+		timeout = defaultExplainCaptureTimeout
+	}
+
+	plan := &QueryPlan{SQL: sql, Args: args, Duration: duration, Analyzed: analyze}
+	var planJSON string
+	explainCtx := context.WithValue(ctx, explainCaptureCtxKey{}, true)
+	plan.Err = conn.QueryRow(explainCtx, explainSQL, explainArgs(args, timeout)...).Scan(&planJSON)
+	if plan.Err == nil {
+		plan.Plan = json.RawMessage(planJSON)
+	}
+
+	ec.OnPlan(ctx, conn, plan)
+}
+
+// reserve reports whether a plan may be captured now under ec.MinInterval and records the capture.
+func (ec *ExplainCapture) reserve() bool {
+	ec.mu.Lock()
+	defer ec.mu.Unlock()
+
+	now := time.Now()
+	if ec.MinInterval > 0 && !ec.lastCapture.IsZero() && now.Sub(ec.lastCapture) < ec.MinInterval {
+		return false
+	}
+	ec.lastCapture = now
+	return true
+}
+
+// explainArgs returns the arguments to explain a query with args. The options that control the result of the query
+// are removed as the EXPLAIN result has a single column, and the statement timeout is set to timeout.
+func explainArgs(args []any, timeout time.Duration) []any {
+	explainArgs := make([]any, 0, len(args)+1)
+	opts := QueryOptions{StatementTimeout: timeout}
+
+optionLoop:
+	for len(args) > 0 {
+		switch arg := args[0].(type) {
+		case QueryResultFormats, QueryResultFormatsByOID:
+		case QueryOptions:
+			opts.ExecMode = arg.ExecMode
+			opts.ParamOIDs = arg.ParamOIDs
+			opts.Tracer = arg.Tracer
+		case QueryExecMode, ParamOIDs, QueryRewriter:
+			explainArgs = append(explainArgs, arg)
+		default:
+			break optionLoop
+		}
+		args = args[1:]
+	}
+
+	explainArgs = append([]any{opts}, explainArgs...)
+	return append(explainArgs, args...)
+}
+
+// explainCaptureRows calls onClose when the Rows are closed.
+type explainCaptureRows struct {
+	Rows
+	onClose func(rows Rows)
+	closed  bool
+}
+
+func (rows *explainCaptureRows) Next() bool {
+	if rows.Rows.Next() {
+		return true
+	}
+	rows.Close()
+	return false
+}
+
+func (rows *explainCaptureRows) QuerySQL() (sql string, argCount int) {
+	return querySQL(rows.Rows)
+}
+
+func (rows *explainCaptureRows) Close() {
+	if rows.closed {
+		return
+	}
+	rows.closed = true
+	rows.Rows.Close()
+	rows.onClose(rows.Rows)
+}
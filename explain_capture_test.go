@@ -0,0 +1,122 @@
+package pgx_test
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxtest"
+	"github.com/stretchr/testify/require"
+)
+
+type capturedPlans struct {
+	mu    sync.Mutex
+	plans []*pgx.QueryPlan
+}
+
+func (cp *capturedPlans) onPlan(ctx context.Context, conn *pgx.Conn, plan *pgx.QueryPlan) {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	cp.plans = append(cp.plans, plan)
+}
+
+func (cp *capturedPlans) take() []*pgx.QueryPlan {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	plans := cp.plans
+	cp.plans = nil
+	return plans
+}
+
+func TestExplainCaptureSlowQuery(t *testing.T) {
+	t.Parallel()
+
+	cp := &capturedPlans{}
+	ec := &pgx.ExplainCapture{Threshold: 50 * time.Millisecond, Analyze: true, OnPlan: cp.onPlan}
+
+	ctr := connTestRunnerWithQueryInterceptors(ec)
+	pgxtest.RunWithQueryExecModes(context.Background(), t, ctr, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		pgxtest.SkipCockroachDB(t, conn, "Server does not support EXPLAIN (FORMAT JSON)")
+		cp.take()
+
+		var n int32
+		err := conn.QueryRow(ctx, "select 1").Scan(&n)
+		require.NoError(t, err)
+		require.Empty(t, cp.take())
+
+		rows, err := conn.Query(ctx, "select $1::int4, pg_sleep(0.1)::text", pgx.QueryResultFormats{pgx.BinaryFormatCode, pgx.TextFormatCode}, 42)
+		require.NoError(t, err)
+		for rows.Next() {
+		}
+		require.NoError(t, rows.Err())
+
+		plans := cp.take()
+		require.Len(t, plans, 1)
+		plan := plans[0]
+		require.NoError(t, plan.Err)
+		require.Equal(t, "select $1::int4, pg_sleep(0.1)::text", plan.SQL)
+		require.GreaterOrEqual(t, plan.Duration, 100*time.Millisecond)
+		require.True(t, plan.Analyzed)
+
+		var explain []map[string]any
+		err = json.Unmarshal(plan.Plan, &explain)
+		require.NoError(t, err)
+		require.Len(t, explain, 1)
+		require.Contains(t, explain[0]["Plan"], "Actual Total Time")
+
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestExplainCaptureDoesNotRunWritesAgain(t *testing.T) {
+	t.Parallel()
+
+	cp := &capturedPlans{}
+	ec := &pgx.ExplainCapture{Analyze: true, OnPlan: cp.onPlan}
+
+	ctr := connTestRunnerWithQueryInterceptors(ec)
+	ctr.RunTest(context.Background(), t, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		pgxtest.SkipCockroachDB(t, conn, "Server does not support EXPLAIN (FORMAT JSON)")
+
+		_, err := conn.Exec(ctx, "create temporary table explain_capture (a int)")
+		require.NoError(t, err)
+		require.Empty(t, cp.take())
+
+		_, err = conn.Exec(ctx, "insert into explain_capture values ($1)", 1)
+		require.NoError(t, err)
+
+		plans := cp.take()
+		require.Len(t, plans, 1)
+		require.NoError(t, plans[0].Err)
+		require.False(t, plans[0].Analyzed)
+		require.NotEmpty(t, plans[0].Plan)
+
+		var n int64
+		err = conn.QueryRow(ctx, "select count(*) from explain_capture").Scan(&n)
+		require.NoError(t, err)
+		require.EqualValues(t, 1, n)
+	})
+}
+
+func TestExplainCaptureMinInterval(t *testing.T) {
+	t.Parallel()
+
+	cp := &capturedPlans{}
+	ec := &pgx.ExplainCapture{MinInterval: time.Hour, OnPlan: cp.onPlan}
+
+	ctr := connTestRunnerWithQueryInterceptors(ec)
+	ctr.RunTest(context.Background(), t, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		pgxtest.SkipCockroachDB(t, conn, "Server does not support EXPLAIN (FORMAT JSON)")
+
+		for i := 0; i < 3; i++ {
+			var n int32
+			err := conn.QueryRow(ctx, "select $1::int4", i).Scan(&n)
+			require.NoError(t, err)
+		}
+
+		require.Len(t, cp.take(), 1)
+	})
+}
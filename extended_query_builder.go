@@ -2,6 +2,7 @@ package pgx
 
 import (
 	"database/sql/driver"
+	"errors"
 	"fmt"
 
 	"github.com/jackc/pgx/v5/internal/anynil"
@@ -34,9 +35,8 @@ func (eqb *ExtendedQueryBuilder) Build(m *pgtype.Map, sd *pgconn.StatementDescri
 	}
 
 	for i := range args {
-		err := eqb.appendParam(m, sd.ParamOIDs[i], -1, args[i])
+		err := eqb.appendParamForOID(m, i, sd.ParamOIDs[i], args[i])
 		if err != nil {
-			err = fmt.Errorf("failed to encode args[%d]: %v", i, err)
 			return err
 		}
 	}
@@ -48,6 +48,51 @@ func (eqb *ExtendedQueryBuilder) Build(m *pgtype.Map, sd *pgconn.StatementDescri
 	return nil
 }
 
+// buildWithParamOIDs is Build for QueryExecModeExec with the parameter types set by ParamOIDs. Arguments with a
+// parameter type are encoded as for a described statement and the others as in QueryExecModeExec. It returns the
+// parameter OIDs to send with the query, which has an entry for each argument.
+func (eqb *ExtendedQueryBuilder) buildWithParamOIDs(m *pgtype.Map, paramOIDs []uint32, args []any) ([]uint32, error) {
+	eqb.reset()
+
+	anynil.NormalizeSlice(args)
+
+	if len(paramOIDs) > len(args) {
+		return nil, fmt.Errorf("got %d ParamOIDs for %d arguments", len(paramOIDs), len(args))
+	}
+
+	oids := make([]uint32, len(args))
+	copy(oids, paramOIDs)
+
+	for i := range args {
+		var err error
+		if oids[i] == 0 {
+			err = eqb.appendParamForQueryExecModeExec(m, i, args[i])
+		} else {
+			err = eqb.appendParamForOID(m, i, oids[i], args[i])
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return oids, nil
+}
+
+// appendParamForOID appends args[i], which is arg, as a parameter of type oid.
+func (eqb *ExtendedQueryBuilder) appendParamForOID(m *pgtype.Map, i int, oid uint32, arg any) error {
+	var err error
+	if fa, ok := arg.(FormattedArg); ok {
+		err = eqb.appendParam(m, oid, fa.Format, fa.Arg)
+	} else {
+		err = eqb.appendParam(m, oid, -1, arg)
+	}
+	if err != nil {
+		return &argEncodeError{argIdx: i, oid: oid, arg: arg, err: err}
+	}
+
+	return nil
+}
+
 // appendParam appends a parameter to the query. format may be -1 to automatically choose the format. If arg is nil it
 // must be an untyped nil.
 func (eqb *ExtendedQueryBuilder) appendParam(m *pgtype.Map, oid uint32, format int16, arg any) error {
@@ -160,59 +205,114 @@ func (eqb *ExtendedQueryBuilder) chooseParameterFormatCode(m *pgtype.Map, oid ui
 // Given that the whole point of QueryExecModeExec is to operate without having to know the PostgreSQL types there is
 // no way to safely use binary or to specify the parameter OIDs.
 func (eqb *ExtendedQueryBuilder) appendParamsForQueryExecModeExec(m *pgtype.Map, args []any) error {
-	for _, arg := range args {
-		if arg == nil {
-			err := eqb.appendParam(m, 0, TextFormatCode, arg)
-			if err != nil {
-				return err
-			}
-		} else {
-			dt, ok := m.TypeForValue(arg)
-			if !ok {
-				var tv pgtype.TextValuer
-				if tv, ok = arg.(pgtype.TextValuer); ok {
-					t, err := tv.TextValue()
-					if err != nil {
-						return err
-					}
-
-					dt, ok = m.TypeForOID(pgtype.TextOID)
-					if ok {
-						arg = t
-					}
+	for i, arg := range args {
+		if err := eqb.appendParamForQueryExecModeExec(m, i, arg); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// appendParamForQueryExecModeExec appends args[i], which is arg, as a parameter of QueryExecModeExec.
+func (eqb *ExtendedQueryBuilder) appendParamForQueryExecModeExec(m *pgtype.Map, i int, arg any) error {
+	originalArg := arg
+	if fa, ok := arg.(FormattedArg); ok {
+		if fa.Format != TextFormatCode {
+			return &argEncodeError{argIdx: i, arg: arg, err: errFormattedArgRequiresText}
+		}
+		arg = anynil.Normalize(fa.Arg)
+	}
+	if arg == nil {
+		err := eqb.appendParam(m, 0, TextFormatCode, arg)
+		if err != nil {
+			return &argEncodeError{argIdx: i, arg: arg, err: err}
+		}
+	} else {
+		dt, ok := m.TypeForValue(arg)
+		if !ok {
+			var tv pgtype.TextValuer
+			if tv, ok = arg.(pgtype.TextValuer); ok {
+				t, err := tv.TextValue()
+				if err != nil {
+					return err
 				}
-			}
-			if !ok {
-				var dv driver.Valuer
-				if dv, ok = arg.(driver.Valuer); ok {
-					v, err := dv.Value()
-					if err != nil {
-						return err
-					}
-					dt, ok = m.TypeForValue(v)
-					if ok {
-						arg = v
-					}
+
+				dt, ok = m.TypeForOID(pgtype.TextOID)
+				if ok {
+					arg = t
 				}
 			}
-			if !ok {
-				var str fmt.Stringer
-				if str, ok = arg.(fmt.Stringer); ok {
-					dt, ok = m.TypeForOID(pgtype.TextOID)
-					if ok {
-						arg = str.String()
-					}
+		}
+		if !ok {
+			var dv driver.Valuer
+			if dv, ok = arg.(driver.Valuer); ok {
+				v, err := dv.Value()
+				if err != nil {
+					return err
+				}
+				dt, ok = m.TypeForValue(v)
+				if ok {
+					arg = v
 				}
 			}
-			if !ok {
-				return &unknownArgumentTypeQueryExecModeExecError{arg: arg}
-			}
-			err := eqb.appendParam(m, dt.OID, TextFormatCode, arg)
-			if err != nil {
-				return err
+		}
+		if !ok {
+			var str fmt.Stringer
+			if str, ok = arg.(fmt.Stringer); ok {
+				dt, ok = m.TypeForOID(pgtype.TextOID)
+				if ok {
+					arg = str.String()
+				}
 			}
 		}
+		if !ok {
+			return &unknownArgumentTypeQueryExecModeExecError{arg: arg}
+		}
+		err := eqb.appendParam(m, dt.OID, TextFormatCode, arg)
+		if err != nil {
+			return &argEncodeError{argIdx: i, oid: dt.OID, arg: originalArg, err: err}
+		}
 	}
 
 	return nil
 }
+
+// argEncodeError records which argument failed to encode.
+type argEncodeError struct {
+	argIdx int
+	oid    uint32 // 0 if the PostgreSQL type is unknown
+	arg    any
+	err    error
+}
+
+func (e *argEncodeError) Error() string {
+	return fmt.Sprintf("failed to encode args[%d]: %v", e.argIdx, e.err)
+}
+
+func (e *argEncodeError) Unwrap() error {
+	return e.err
+}
+
+// FormattedArg is a query argument that is always sent in Format regardless of the format that would otherwise be
+// chosen for Arg. Use Text or Binary to construct one.
+//
+// QueryExecModeExec and QueryExecModeSimpleProtocol always send arguments as text. In those modes a FormattedArg with
+// the binary format results in an error.
+type FormattedArg struct {
+	Arg    any
+	Format int16
+}
+
+// Text returns arg wrapped such that it is always sent to the server in the text format. e.g. This can be used to
+// preserve the exact precision of a numeric.
+func Text(arg any) FormattedArg {
+	return FormattedArg{Arg: arg, Format: TextFormatCode}
+}
+
+// Binary returns arg wrapped such that it is always sent to the server in the binary format.
+func Binary(arg any) FormattedArg {
+	return FormattedArg{Arg: arg, Format: BinaryFormatCode}
+}
+
+var errFormattedArgRequiresText = errors.New("binary format argument is not supported when the parameter type is unknown")
@@ -12,6 +12,7 @@ type LRUCache struct {
 	m            map[string]*list.Element
 	l            *list.List
 	invalidStmts []*pgconn.StatementDescription
+	stats        Stats
 }
 
 // NewLRUCache creates a new LRUCache. cap is the maximum size of the cache.
@@ -26,10 +27,12 @@ func NewLRUCache(cap int) *LRUCache {
 // Get returns the statement description for sql. Returns nil if not found.
 func (c *LRUCache) Get(key string) *pgconn.StatementDescription {
 	if el, ok := c.m[key]; ok {
+		c.stats.Hits++
 		c.l.MoveToFront(el)
 		return el.Value.(*pgconn.StatementDescription)
 	}
 
+	c.stats.Misses++
 	return nil
 
 }
@@ -89,10 +92,16 @@ func (c *LRUCache) Cap() int {
 	return c.cap
 }
 
+// Stats returns the cache statistics.
+func (c *LRUCache) Stats() Stats {
+	return c.stats
+}
+
 func (c *LRUCache) invalidateOldest() {
 	oldest := c.l.Back()
 	sd := oldest.Value.(*pgconn.StatementDescription)
 	c.invalidStmts = append(c.invalidStmts, sd)
 	delete(c.m, sd.SQL)
 	c.l.Remove(oldest)
+	c.stats.Evictions++
 }
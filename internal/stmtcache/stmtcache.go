@@ -2,6 +2,7 @@
 package stmtcache
 
 import (
+	"errors"
 	"strconv"
 	"sync/atomic"
 
@@ -38,11 +39,26 @@ type Cache interface {
 
 	// Cap returns the maximum number of cached prepared statement descriptions.
 	Cap() int
+
+	// Stats returns the cache statistics.
+	Stats() Stats
+}
+
+// Stats are the statistics of a Cache since it was created.
+type Stats struct {
+	// Hits is the number of Get calls that found a statement description.
+	Hits int64
+
+	// Misses is the number of Get calls that did not find a statement description.
+	Misses int64
+
+	// Evictions is the number of statement descriptions that were invalidated to make room for another.
+	Evictions int64
 }
 
 func IsStatementInvalid(err error) bool {
-	pgErr, ok := err.(*pgconn.PgError)
-	if !ok {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
 		return false
 	}
 
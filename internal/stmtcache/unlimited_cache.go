@@ -10,6 +10,7 @@ import (
 type UnlimitedCache struct {
 	m            map[string]*pgconn.StatementDescription
 	invalidStmts []*pgconn.StatementDescription
+	stats        Stats
 }
 
 // NewUnlimitedCache creates a new UnlimitedCache.
@@ -21,7 +22,13 @@ func NewUnlimitedCache() *UnlimitedCache {
 
 // Get returns the statement description for sql. Returns nil if not found.
 func (c *UnlimitedCache) Get(sql string) *pgconn.StatementDescription {
-	return c.m[sql]
+	sd := c.m[sql]
+	if sd != nil {
+		c.stats.Hits++
+	} else {
+		c.stats.Misses++
+	}
+	return sd
 }
 
 // Put stores sd in the cache. Put panics if sd.SQL is "". Put does nothing if sd.SQL already exists in the cache.
@@ -69,3 +76,8 @@ func (c *UnlimitedCache) Len() int {
 func (c *UnlimitedCache) Cap() int {
 	return math.MaxInt
 }
+
+// Stats returns the cache statistics. An UnlimitedCache never evicts.
+func (c *UnlimitedCache) Stats() Stats {
+	return c.stats
+}
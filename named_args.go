@@ -2,6 +2,8 @@ package pgx
 
 import (
 	"context"
+	"fmt"
+	"reflect"
 	"strconv"
 	"strings"
 	"unicode/utf8"
@@ -28,23 +30,80 @@ func (na NamedArgs) RewriteQuery(ctx context.Context, conn *Conn, sql string, ar
 		l.stateFn = l.stateFn(l)
 	}
 
+	// Assign ordinals in order of first appearance. An InListArg uses one ordinal per element.
+	ordinals := make(map[namedArg][]int, len(l.nameToOrdinal))
+	newArgs = make([]any, 0, len(l.nameToOrdinal))
+	for _, p := range l.parts {
+		name, ok := p.(namedArg)
+		if !ok {
+			continue
+		}
+		if _, found := ordinals[name]; found {
+			continue
+		}
+
+		if list, ok := na[string(name)].(InListArg); ok {
+			if len(list.values) == 0 {
+				return "", nil, fmt.Errorf("named arg %q: InList must not be empty", string(name))
+			}
+			nameOrdinals := make([]int, len(list.values))
+			for i, v := range list.values {
+				newArgs = append(newArgs, v)
+				nameOrdinals[i] = len(newArgs)
+			}
+			ordinals[name] = nameOrdinals
+		} else {
+			newArgs = append(newArgs, na[string(name)])
+			ordinals[name] = []int{len(newArgs)}
+		}
+	}
+
 	sb := strings.Builder{}
 	for _, p := range l.parts {
 		switch p := p.(type) {
 		case string:
 			sb.WriteString(p)
 		case namedArg:
-			sb.WriteRune('$')
-			sb.WriteString(strconv.Itoa(l.nameToOrdinal[p]))
+			for i, ordinal := range ordinals[p] {
+				if i > 0 {
+					sb.WriteString(", ")
+				}
+				sb.WriteRune('$')
+				sb.WriteString(strconv.Itoa(ordinal))
+			}
 		}
 	}
 
-	newArgs = make([]any, len(l.nameToOrdinal))
-	for name, ordinal := range l.nameToOrdinal {
-		newArgs[ordinal-1] = na[string(name)]
+	return sb.String(), newArgs, nil
+}
+
+// InListArg is a NamedArgs value that is expanded into a comma separated list of placeholders with one placeholder
+// and argument for each element. Use InList to construct one.
+type InListArg struct {
+	values []any
+}
+
+// InList returns a NamedArgs value for use in an IN list. e.g.
+//
+//	conn.Query(ctx, "select * from widgets where id in (@ids)", pgx.NamedArgs{"ids": pgx.InList([]int32{1, 2, 3})})
+//
+// is sent as "select * from widgets where id in ($1, $2, $3)" with the arguments 1, 2, and 3. slice must be a slice or
+// array and must not be empty when the query is sent. InList panics if slice is not a slice or array.
+//
+// A slice can also be passed directly as an array with "= any(@ids)". This sends a single array argument regardless
+// of the number of elements, which is preferable for large lists and allows the list to be empty.
+func InList(slice any) InListArg {
+	v := reflect.ValueOf(slice)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		panic(fmt.Sprintf("InList: expected slice or array, got %T", slice))
 	}
 
-	return sb.String(), newArgs, nil
+	values := make([]any, v.Len())
+	for i := range values {
+		values[i] = v.Index(i).Interface()
+	}
+
+	return InListArg{values: values}
 }
 
 type namedArg string
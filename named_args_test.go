@@ -93,6 +93,12 @@ func TestNamedArgsRewriteQuery(t *testing.T) {
 			where id = $1;`,
 			expectedArgs: []any{int32(42)},
 		},
+		{
+			sql:          "select * from t where a = @a and id in (@ids) and b = @b or id in (@ids)",
+			namedArgs:    pgx.NamedArgs{"a": int32(1), "ids": pgx.InList([]int32{7, 8, 9}), "b": "foo"},
+			expectedSQL:  "select * from t where a = $1 and id in ($2, $3, $4) and b = $5 or id in ($2, $3, $4)",
+			expectedArgs: []any{int32(1), int32(7), int32(8), int32(9), "foo"},
+		},
 
 		// test comments and quotes
 	} {
@@ -102,3 +108,12 @@ func TestNamedArgsRewriteQuery(t *testing.T) {
 		assert.Equalf(t, tt.expectedArgs, args, "%d", i)
 	}
 }
+
+func TestNamedArgsInListEmpty(t *testing.T) {
+	t.Parallel()
+
+	_, _, err := pgx.NamedArgs{"ids": pgx.InList([]int32{})}.RewriteQuery(context.Background(), nil, "select * from t where id in (@ids)", nil)
+	require.EqualError(t, err, `named arg "ids": InList must not be empty`)
+
+	require.Panics(t, func() { pgx.InList(42) })
+}
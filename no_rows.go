@@ -0,0 +1,65 @@
+package pgx
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxNoRowsErrorSQLLen is the maximum length of the SQL in the message of a NoRowsError.
+const maxNoRowsErrorSQLLen = 200
+
+// NoRowsError is returned instead of ErrNoRows when ConnConfig.ContextualErrNoRows is set. It identifies the query
+// that returned no rows. errors.Is(err, ErrNoRows) is true for a *NoRowsError but err == ErrNoRows is not.
+type NoRowsError struct {
+	// SQL is the SQL of the query. It never includes the argument values, even with QueryExecModeSimpleProtocol.
+	SQL string
+
+	// ArgCount is the number of arguments of the query.
+	ArgCount int
+}
+
+func (e *NoRowsError) Error() string {
+	sql := strings.Join(strings.Fields(e.SQL), " ")
+	if len(sql) > maxNoRowsErrorSQLLen {
+		n := maxNoRowsErrorSQLLen
+		for n > 0 && !utf8.RuneStart(sql[n]) {
+			n--
+		}
+		sql = sql[:n] + "..."
+	}
+	return fmt.Sprintf("%s: query %q with %d arguments", ErrNoRows.Error(), sql, e.ArgCount)
+}
+
+func (e *NoRowsError) Unwrap() error {
+	return ErrNoRows
+}
+
+// noRowsError returns the error for rows that returned no rows. It is a *NoRowsError if ConnConfig.ContextualErrNoRows
+// is set and rows can report their SQL. Otherwise it is ErrNoRows.
+func noRowsError(rows Rows) error {
+	conn := rows.Conn()
+	if conn == nil || !conn.config.ContextualErrNoRows {
+		return ErrNoRows
+	}
+
+	sql, argCount := querySQL(rows)
+	if sql == "" {
+		return ErrNoRows
+	}
+	return &NoRowsError{SQL: sql, ArgCount: argCount}
+}
+
+// querySQL returns the SQL and the number of arguments of the query of rows. sql is "" if rows do not implement
+// QuerySQL. Rows that wrap other Rows, such as the rows of a *pgxpool.Pool, implement QuerySQL by delegating.
+func querySQL(rows Rows) (sql string, argCount int) {
+	if qr, ok := rows.(interface{ QuerySQL() (string, int) }); ok {
+		return qr.QuerySQL()
+	}
+	return "", 0
+}
+
+// QuerySQL returns the SQL and the number of arguments of the query.
+func (rows *baseRows) QuerySQL() (sql string, argCount int) {
+	return rows.sql, len(rows.args)
+}
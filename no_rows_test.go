@@ -0,0 +1,66 @@
+package pgx_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxtest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNoRowsError(t *testing.T) {
+	t.Parallel()
+
+	err := &pgx.NoRowsError{SQL: "select id\n  from users\n  where email = $1", ArgCount: 1}
+	require.EqualError(t, err, `no rows in result set: query "select id from users where email = $1" with 1 arguments`)
+	require.True(t, errors.Is(err, pgx.ErrNoRows))
+
+	err = &pgx.NoRowsError{SQL: "select " + strings.Repeat("é", 200)}
+	require.EqualError(t, err, `no rows in result set: query "select `+strings.Repeat("é", 96)+`..." with 0 arguments`)
+}
+
+func TestContextualErrNoRows(t *testing.T) {
+	t.Parallel()
+
+	ctr := defaultConnTestRunner
+	ctr.CreateConfig = func(ctx context.Context, t testing.TB) *pgx.ConnConfig {
+		config := defaultConnTestRunner.CreateConfig(ctx, t)
+		config.ContextualErrNoRows = true
+		return config
+	}
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, ctr, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		sql := "select n from generate_series(1, 3) n where n > $1::int4"
+
+		var n int32
+		err := conn.QueryRow(ctx, sql, 5).Scan(&n)
+		require.ErrorIs(t, err, pgx.ErrNoRows)
+		var noRowsErr *pgx.NoRowsError
+		require.ErrorAs(t, err, &noRowsErr)
+		require.Equal(t, sql, noRowsErr.SQL)
+		require.Equal(t, 1, noRowsErr.ArgCount)
+
+		rows, err := conn.Query(ctx, sql, 5)
+		require.NoError(t, err)
+		_, err = pgx.CollectOneRow(rows, pgx.RowTo[int32])
+		require.ErrorAs(t, err, &noRowsErr)
+		require.Equal(t, sql, noRowsErr.SQL)
+
+		err = conn.QueryRow(ctx, sql, 1).Scan(&n)
+		require.NoError(t, err)
+		require.EqualValues(t, 2, n)
+	})
+}
+
+func TestContextualErrNoRowsDisabled(t *testing.T) {
+	t.Parallel()
+
+	defaultConnTestRunner.RunTest(context.Background(), t, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		var n int32
+		err := conn.QueryRow(ctx, "select 1 where false").Scan(&n)
+		require.Equal(t, pgx.ErrNoRows, err)
+	})
+}
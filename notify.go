@@ -0,0 +1,44 @@
+package pgx
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// Notify sends a notification on channel with payload using pg_notify. channel and payload are sent as query
+// parameters, so unlike a NOTIFY statement built by hand neither needs to be quoted.
+//
+// payload is encoded with ConnConfig.NotifyPayloadEncoder if it is set. Otherwise a string or []byte payload is sent as
+// is and any other payload, including nil, is encoded as JSON. As with NOTIFY, the notification is only delivered when
+// the current transaction, if any, commits.
+func (c *Conn) Notify(ctx context.Context, channel string, payload any) error {
+	encode := c.config.NotifyPayloadEncoder
+	if encode == nil {
+		encode = encodeNotifyPayload
+	}
+
+	s, err := encode(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode notify payload: %w", err)
+	}
+
+	_, err = c.Exec(ctx, "select pg_notify($1, $2)", channel, s)
+	return err
+}
+
+// encodeNotifyPayload is the default encoder for Conn.Notify payloads.
+func encodeNotifyPayload(payload any) (string, error) {
+	switch payload := payload.(type) {
+	case string:
+		return payload, nil
+	case []byte:
+		return string(payload), nil
+	default:
+		buf, err := json.Marshal(payload)
+		if err != nil {
+			return "", err
+		}
+		return string(buf), nil
+	}
+}
@@ -0,0 +1,108 @@
+package pgx_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxtest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnNotify(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	listener := mustConnectString(t, os.Getenv("PGX_TEST_DATABASE"))
+	defer closeConn(t, listener)
+	pgxtest.SkipCockroachDB(t, listener, "Server does not support LISTEN / NOTIFY (https://github.com/cockroachdb/cockroach/issues/41522)")
+
+	mustExec(t, listener, `listen "Mixed Case; Channel"`)
+
+	notifier := mustConnectString(t, os.Getenv("PGX_TEST_DATABASE"))
+	defer closeConn(t, notifier)
+
+	require.NoError(t, notifier.Notify(ctx, "Mixed Case; Channel", "it's a string"))
+	notification, err := listener.WaitForNotification(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "Mixed Case; Channel", notification.Channel)
+	require.Equal(t, "it's a string", notification.Payload)
+
+	type order struct {
+		ID    int    `json:"id"`
+		State string `json:"state"`
+	}
+	require.NoError(t, notifier.Notify(ctx, "Mixed Case; Channel", order{ID: 1, State: "paid"}))
+	notification, err = listener.WaitForNotification(ctx)
+	require.NoError(t, err)
+
+	var o order
+	require.NoError(t, json.Unmarshal([]byte(notification.Payload), &o))
+	require.Equal(t, order{ID: 1, State: "paid"}, o)
+
+	require.Error(t, notifier.Notify(ctx, "Mixed Case; Channel", make(chan int)))
+	ensureConnValid(t, notifier)
+}
+
+func TestConnNotifyPayloadEncoder(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	listener := mustConnectString(t, os.Getenv("PGX_TEST_DATABASE"))
+	defer closeConn(t, listener)
+	pgxtest.SkipCockroachDB(t, listener, "Server does not support LISTEN / NOTIFY (https://github.com/cockroachdb/cockroach/issues/41522)")
+
+	mustExec(t, listener, "listen encoded")
+
+	encodeErr := errors.New("unsupported payload")
+	config := mustParseConfig(t, os.Getenv("PGX_TEST_DATABASE"))
+	config.NotifyPayloadEncoder = func(payload any) (string, error) {
+		if n, ok := payload.(int); ok {
+			return "n=" + strconv.Itoa(n), nil
+		}
+		return "", encodeErr
+	}
+	notifier := mustConnect(t, config)
+	defer closeConn(t, notifier)
+
+	require.NoError(t, notifier.Notify(ctx, "encoded", 7))
+	notification, err := listener.WaitForNotification(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "n=7", notification.Payload)
+
+	require.ErrorIs(t, notifier.Notify(ctx, "encoded", "foo"), encodeErr)
+}
+
+func TestTxNotifyDeliveredOnCommit(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	listener := mustConnectString(t, os.Getenv("PGX_TEST_DATABASE"))
+	defer closeConn(t, listener)
+	pgxtest.SkipCockroachDB(t, listener, "Server does not support LISTEN / NOTIFY (https://github.com/cockroachdb/cockroach/issues/41522)")
+
+	mustExec(t, listener, "listen txnotify")
+
+	notifier := mustConnectString(t, os.Getenv("PGX_TEST_DATABASE"))
+	defer closeConn(t, notifier)
+
+	err := pgx.BeginFunc(ctx, notifier, func(tx pgx.Tx) error {
+		return tx.Conn().Notify(ctx, "txnotify", "committed")
+	})
+	require.NoError(t, err)
+
+	notification, err := listener.WaitForNotification(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "committed", notification.Payload)
+}
@@ -0,0 +1,176 @@
+// Package oteltracer provides a tracer that reports batches as OpenTelemetry spans.
+package oteltracer
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// BatchSizeKey is the attribute key of the number of queries in a batch.
+	BatchSizeKey = attribute.Key("pgx.batch.size")
+
+	// RowsAffectedKey is the attribute key of the number of rows affected or returned by a query.
+	RowsAffectedKey = attribute.Key("pgx.rows_affected")
+
+	// FirstByteLatencyKey is the attribute key of pgx.TraceBatchEndData.FirstByteLatency in milliseconds.
+	FirstByteLatencyKey = attribute.Key("pgx.batch.first_byte_latency_ms")
+)
+
+// Option configures a BatchTracer.
+type Option func(*BatchTracer)
+
+// WithAttributes adds attrs to every span created by the tracer.
+func WithAttributes(attrs ...attribute.KeyValue) Option {
+	return func(bt *BatchTracer) {
+		bt.attrs = append(bt.attrs, attrs...)
+	}
+}
+
+// WithoutStatement omits the db.statement attribute. Use this if the SQL may contain sensitive data.
+func WithoutStatement() Option {
+	return func(bt *BatchTracer) {
+		bt.omitStatement = true
+	}
+}
+
+// BatchTracer implements pgx.BatchTracer. It creates a span for each batch with a child span for each query read from
+// the batch. Spans are populated with the OpenTelemetry database semantic convention attributes.
+//
+// pgx does not report when the results of a query start to be read. The span of a query starts when the previous query
+// in the batch ended or the batch started.
+type BatchTracer struct {
+	tracer        trace.Tracer
+	attrs         []attribute.KeyValue
+	omitStatement bool
+}
+
+// NewOtelBatchTracer returns a BatchTracer that creates spans with tracer.
+func NewOtelBatchTracer(tracer trace.Tracer, opts ...Option) *BatchTracer {
+	bt := &BatchTracer{
+		tracer: tracer,
+		attrs:  []attribute.KeyValue{semconv.DBSystemPostgreSQL},
+	}
+	for _, opt := range opts {
+		opt(bt)
+	}
+	return bt
+}
+
+type ctxKey int
+
+const batchCtxKey ctxKey = 0
+
+type traceBatchData struct {
+	attrs       []attribute.KeyValue // attributes common to the spans of the batch and its queries
+	prevEndTime time.Time            // when the previous query in the batch ended or the batch started
+}
+
+func (bt *BatchTracer) TraceBatchStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceBatchStartData) context.Context {
+	commonAttrs := bt.connAttributes(conn)
+	attrs := commonAttrs
+	if data.Batch != nil {
+		attrs = append(attrs[:len(attrs):len(attrs)], BatchSizeKey.Int(data.Batch.Len()))
+	}
+
+	startTime := time.Now()
+	ctx, _ = bt.tracer.Start(ctx, "batch",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithTimestamp(startTime),
+		trace.WithAttributes(attrs...),
+	)
+
+	return context.WithValue(ctx, batchCtxKey, &traceBatchData{attrs: commonAttrs, prevEndTime: startTime})
+}
+
+func (bt *BatchTracer) TraceBatchQuery(ctx context.Context, conn *pgx.Conn, data pgx.TraceBatchQueryData) {
+	opts := []trace.SpanStartOption{trace.WithSpanKind(trace.SpanKindClient)}
+
+	var attrs []attribute.KeyValue
+	batchData, _ := ctx.Value(batchCtxKey).(*traceBatchData)
+	if batchData != nil {
+		opts = append(opts, trace.WithTimestamp(batchData.prevEndTime))
+		attrs = append(attrs, batchData.attrs...)
+	} else {
+		attrs = bt.connAttributes(conn)
+	}
+
+	operation := sqlOperation(data.SQL)
+	if operation != "" {
+		attrs = append(attrs, semconv.DBOperationKey.String(operation))
+	}
+	if !bt.omitStatement {
+		attrs = append(attrs, semconv.DBStatementKey.String(data.SQL))
+	}
+	opts = append(opts, trace.WithAttributes(attrs...))
+
+	name := operation
+	if name == "" {
+		name = "query"
+	}
+
+	_, span := bt.tracer.Start(ctx, name, opts...)
+	if data.Err != nil {
+		recordError(span, data.Err)
+	} else {
+		span.SetAttributes(RowsAffectedKey.Int64(data.CommandTag.RowsAffected()))
+	}
+
+	endTime := time.Now()
+	span.End(trace.WithTimestamp(endTime))
+	if batchData != nil {
+		batchData.prevEndTime = endTime
+	}
+}
+
+func (bt *BatchTracer) TraceBatchEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceBatchEndData) {
+	span := trace.SpanFromContext(ctx)
+
+	if data.FirstByteLatency > 0 {
+		span.SetAttributes(FirstByteLatencyKey.Float64(float64(data.FirstByteLatency) / float64(time.Millisecond)))
+	}
+	if data.Err != nil {
+		recordError(span, data.Err)
+	}
+
+	span.End()
+}
+
+func (bt *BatchTracer) connAttributes(conn *pgx.Conn) []attribute.KeyValue {
+	attrs := make([]attribute.KeyValue, 0, len(bt.attrs)+2)
+	attrs = append(attrs, bt.attrs...)
+	if conn != nil {
+		if config := conn.Config(); config != nil && config.Database != "" {
+			attrs = append(attrs, semconv.DBNameKey.String(config.Database))
+		}
+	}
+	return attrs
+}
+
+func recordError(span trace.Span, err error) {
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
+
+// sqlOperation returns the first keyword of sql in upper case. It returns "" if sql is empty or starts with a comment.
+func sqlOperation(sql string) string {
+	sql = strings.TrimLeft(sql, " \t\r\n(")
+	if strings.HasPrefix(sql, "--") || strings.HasPrefix(sql, "/*") {
+		return ""
+	}
+
+	end := strings.IndexFunc(sql, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r == '_')
+	})
+	if end == -1 {
+		end = len(sql)
+	}
+	return strings.ToUpper(sql[:end])
+}
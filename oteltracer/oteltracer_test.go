@@ -0,0 +1,133 @@
+package oteltracer_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/oteltracer"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type testSpan struct {
+	trace.Span // not implemented methods panic
+
+	name   string
+	parent *testSpan
+	config trace.SpanConfig
+	attrs  []attribute.KeyValue
+	status codes.Code
+	errs   []error
+	ended  bool
+}
+
+func (s *testSpan) End(options ...trace.SpanEndOption) { s.ended = true }
+
+func (s *testSpan) RecordError(err error, options ...trace.EventOption) { s.errs = append(s.errs, err) }
+
+func (s *testSpan) SetStatus(code codes.Code, description string) { s.status = code }
+
+func (s *testSpan) SetAttributes(kv ...attribute.KeyValue) { s.attrs = append(s.attrs, kv...) }
+
+func (s *testSpan) attr(key attribute.Key) (attribute.Value, bool) {
+	for _, kv := range append(s.config.Attributes(), s.attrs...) {
+		if kv.Key == key {
+			return kv.Value, true
+		}
+	}
+	return attribute.Value{}, false
+}
+
+type testTracer struct {
+	spans []*testSpan
+}
+
+func (t *testTracer) Start(ctx context.Context, spanName string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	span := &testSpan{name: spanName, config: trace.NewSpanStartConfig(opts...)}
+	if parent, ok := trace.SpanFromContext(ctx).(*testSpan); ok {
+		span.parent = parent
+	}
+	t.spans = append(t.spans, span)
+	return trace.ContextWithSpan(ctx, span), span
+}
+
+func TestBatchTracer(t *testing.T) {
+	t.Parallel()
+
+	tracer := &testTracer{}
+	bt := oteltracer.NewOtelBatchTracer(tracer, oteltracer.WithAttributes(attribute.String("service", "test")))
+
+	batch := &pgx.Batch{}
+	batch.Queue("select 1")
+	batch.Queue("insert into t values ($1)", 1)
+
+	ctx := bt.TraceBatchStart(context.Background(), nil, pgx.TraceBatchStartData{Batch: batch})
+	bt.TraceBatchQuery(ctx, nil, pgx.TraceBatchQueryData{SQL: "select 1", CommandTag: pgconn.NewCommandTag("SELECT 1")})
+	queryErr := errors.New("insert failed")
+	bt.TraceBatchQuery(ctx, nil, pgx.TraceBatchQueryData{SQL: "insert into t values ($1)", Args: []any{1}, Err: queryErr})
+	bt.TraceBatchEnd(ctx, nil, pgx.TraceBatchEndData{Err: queryErr})
+
+	require.Len(t, tracer.spans, 3)
+	batchSpan, selectSpan, insertSpan := tracer.spans[0], tracer.spans[1], tracer.spans[2]
+
+	require.Equal(t, "batch", batchSpan.name)
+	require.Nil(t, batchSpan.parent)
+	require.True(t, batchSpan.ended)
+	require.Equal(t, trace.SpanKindClient, batchSpan.config.SpanKind())
+	require.Equal(t, codes.Error, batchSpan.status)
+	v, ok := batchSpan.attr(oteltracer.BatchSizeKey)
+	require.True(t, ok)
+	require.EqualValues(t, 2, v.AsInt64())
+	v, ok = batchSpan.attr("db.system")
+	require.True(t, ok)
+	require.Equal(t, "postgresql", v.AsString())
+
+	require.Equal(t, "SELECT", selectSpan.name)
+	require.Same(t, batchSpan, selectSpan.parent)
+	require.True(t, selectSpan.ended)
+	require.Equal(t, codes.Unset, selectSpan.status)
+	require.False(t, selectSpan.config.Timestamp().Before(batchSpan.config.Timestamp()))
+	v, ok = selectSpan.attr("db.statement")
+	require.True(t, ok)
+	require.Equal(t, "select 1", v.AsString())
+	v, ok = selectSpan.attr("db.operation")
+	require.True(t, ok)
+	require.Equal(t, "SELECT", v.AsString())
+	v, ok = selectSpan.attr(oteltracer.RowsAffectedKey)
+	require.True(t, ok)
+	require.EqualValues(t, 1, v.AsInt64())
+	v, ok = selectSpan.attr("service")
+	require.True(t, ok)
+	require.Equal(t, "test", v.AsString())
+
+	require.Equal(t, "INSERT", insertSpan.name)
+	require.Same(t, batchSpan, insertSpan.parent)
+	require.Equal(t, codes.Error, insertSpan.status)
+	require.Equal(t, []error{queryErr}, insertSpan.errs)
+	_, ok = insertSpan.attr(oteltracer.RowsAffectedKey)
+	require.False(t, ok)
+}
+
+func TestBatchTracerWithoutStatement(t *testing.T) {
+	t.Parallel()
+
+	tracer := &testTracer{}
+	bt := oteltracer.NewOtelBatchTracer(tracer, oteltracer.WithoutStatement())
+
+	ctx := bt.TraceBatchStart(context.Background(), nil, pgx.TraceBatchStartData{})
+	bt.TraceBatchQuery(ctx, nil, pgx.TraceBatchQueryData{SQL: "/* comment */ select 1", CommandTag: pgconn.NewCommandTag("SELECT 1")})
+	bt.TraceBatchEnd(ctx, nil, pgx.TraceBatchEndData{})
+
+	require.Len(t, tracer.spans, 2)
+	querySpan := tracer.spans[1]
+	require.Equal(t, "query", querySpan.name)
+	_, ok := querySpan.attr("db.statement")
+	require.False(t, ok)
+	_, ok = querySpan.attr("db.operation")
+	require.False(t, ok)
+}
@@ -60,6 +60,18 @@ type Config struct {
 	// OnNotification is a callback function called when a notification from the LISTEN/NOTIFY system is received.
 	OnNotification NotificationHandler
 
+	// OnParameterStatus is a callback function called when the server reports that the value of a parameter such as
+	// TimeZone or server_version changed after the connection was established.
+	OnParameterStatus ParameterStatusHandler
+
+	// CancelWaitTimeout controls what happens when the context of a query is canceled. If it is 0, the query is
+	// interrupted immediately and the connection is closed, as the connection is left in an unknown state. If it is
+	// greater than 0, a cancel request is sent to the server instead and the query waits up to CancelWaitTimeout for the
+	// server to abort it. If the server responds in time, the query returns the error reported by the server, usually
+	// SQLSTATE 57014 (query_canceled), and the connection remains usable. Otherwise the connection is closed as if
+	// CancelWaitTimeout were 0. This avoids a burst of canceled queries closing all connections of a pool.
+	CancelWaitTimeout time.Duration
+
 	createdByParseConfig bool // Used to enforce created by ParseConfig rule.
 }
 
@@ -63,6 +63,12 @@ type NoticeHandler func(*PgConn, *Notice)
 // notice event.
 type NotificationHandler func(*PgConn, *Notification)
 
+// ParameterStatusHandler is a function that is called when the value of a parameter reported by the PostgreSQL server
+// changes, e.g. because SET was used to change TimeZone or because a proxy switched to another server. oldValue is the
+// value before the change. The *PgConn is provided so the handler is aware of the origin of the change, but it must not
+// invoke any query method.
+type ParameterStatusHandler func(pgConn *PgConn, name, oldValue, newValue string)
+
 // PgConn is a low-level PostgreSQL connection handle. It is not safe for concurrent usage.
 type PgConn struct {
 	conn              nbconn.Conn       // the non-blocking wrapper for the underlying TCP or unix domain socket connection
@@ -78,6 +84,8 @@ type PgConn struct {
 
 	peekedMsg pgproto3.BackendMessage
 
+	noticeHandler NoticeHandler // overrides config.OnNotice when not nil
+
 	// Reusable / preallocated resources
 	resultReader      ResultReader
 	multiResultReader MultiResultReader
@@ -355,6 +363,12 @@ func connect(ctx context.Context, config *Config, fallbackConfig *FallbackConfig
 			}
 		case *pgproto3.ReadyForQuery:
 			pgConn.status = connStatusIdle
+			if config.CancelWaitTimeout > 0 {
+				// The backend key data needed to send a cancel request has been received. Replace the context watcher so
+				// queries are canceled with a cancel request.
+				pgConn.contextWatcher.Unwatch()
+				pgConn.contextWatcher = pgConn.newCancelRequestContextWatcher()
+			}
 			if config.ValidateConnect != nil {
 				// ValidateConnect may execute commands that cause the context to be watched again. Unwatch first to avoid
 				// the watch already in progress panic. This is that last thing done by this method so there is no need to
@@ -392,6 +406,27 @@ func newContextWatcher(conn net.Conn) *ctxwatch.ContextWatcher {
 	)
 }
 
+// newCancelRequestContextWatcher returns a context watcher for Config.CancelWaitTimeout. When the context is canceled
+// it sends a cancel request and gives the server until CancelWaitTimeout to abort the query before the connection is
+// interrupted.
+func (pgConn *PgConn) newCancelRequestContextWatcher() *ctxwatch.ContextWatcher {
+	conn := pgConn.conn
+	wait := pgConn.config.CancelWaitTimeout
+
+	return ctxwatch.NewContextWatcher(
+		func() {
+			deadline := time.Now().Add(wait)
+			conn.SetDeadline(deadline)
+
+			ctx, cancel := context.WithDeadline(context.Background(), deadline)
+			defer cancel()
+			// If the cancel request cannot be delivered the deadline interrupts the query.
+			pgConn.CancelRequest(ctx)
+		},
+		func() { conn.SetDeadline(time.Time{}) },
+	)
+}
+
 func startTLS(conn *nbconn.NetConn, tlsConfig *tls.Config) (*nbconn.TLSConn, error) {
 	err := binary.Write(conn, binary.BigEndian, []int32{8, 80877103})
 	if err != nil {
@@ -498,7 +533,12 @@ func (pgConn *PgConn) receiveMessage() (pgproto3.BackendMessage, error) {
 	case *pgproto3.ReadyForQuery:
 		pgConn.txStatus = msg.TxStatus
 	case *pgproto3.ParameterStatus:
+		oldValue, ok := pgConn.parameterStatuses[msg.Name]
 		pgConn.parameterStatuses[msg.Name] = msg.Value
+		// The server reports all parameters when connecting. Only changes after that are passed to the handler.
+		if pgConn.config.OnParameterStatus != nil && pgConn.status != connStatusConnecting && (!ok || oldValue != msg.Value) {
+			pgConn.config.OnParameterStatus(pgConn, msg.Name, oldValue, msg.Value)
+		}
 	case *pgproto3.ErrorResponse:
 		if msg.Severity == "FATAL" {
 			pgConn.status = connStatusClosed
@@ -507,8 +547,8 @@ func (pgConn *PgConn) receiveMessage() (pgproto3.BackendMessage, error) {
 			return nil, ErrorResponseToPgError(msg)
 		}
 	case *pgproto3.NoticeResponse:
-		if pgConn.config.OnNotice != nil {
-			pgConn.config.OnNotice(pgConn, noticeResponseToNotice(msg))
+		if handler := pgConn.currentNoticeHandler(); handler != nil {
+			handler(pgConn, noticeResponseToNotice(msg))
 		}
 	case *pgproto3.NotificationResponse:
 		if pgConn.config.OnNotification != nil {
@@ -547,6 +587,23 @@ func (pgConn *PgConn) SecretKey() uint32 {
 	return pgConn.secretKey
 }
 
+// SetNoticeHandler sets the handler called when a notice response is received on this connection. It replaces
+// Config.OnNotice for this connection only. If handler is nil Config.OnNotice is used again. It returns the handler that
+// was previously in use so it can be restored or called by the new handler. SetNoticeHandler must not be called
+// concurrently with any other method of pgConn.
+func (pgConn *PgConn) SetNoticeHandler(handler NoticeHandler) NoticeHandler {
+	prev := pgConn.currentNoticeHandler()
+	pgConn.noticeHandler = handler
+	return prev
+}
+
+func (pgConn *PgConn) currentNoticeHandler() NoticeHandler {
+	if pgConn.noticeHandler != nil {
+		return pgConn.noticeHandler
+	}
+	return pgConn.config.OnNotice
+}
+
 // Frontend returns the underlying *pgproto3.Frontend. This rarely necessary.
 func (pgConn *PgConn) Frontend() *pgproto3.Frontend {
 	return pgConn.frontend
@@ -905,8 +962,10 @@ func (pgConn *PgConn) WaitForNotification(ctx context.Context) error {
 		default:
 		}
 
-		pgConn.contextWatcher.Watch(ctx)
-		defer pgConn.contextWatcher.Unwatch()
+		// There is no query to cancel so the wait is always interrupted immediately.
+		contextWatcher := newContextWatcher(pgConn.conn)
+		contextWatcher.Watch(ctx)
+		defer contextWatcher.Unwatch()
 	}
 
 	for {
@@ -999,6 +1058,27 @@ func (pgConn *PgConn) ExecParams(ctx context.Context, sql string, paramValues []
 	return result
 }
 
+// ExecParamsMaxRows is like ExecParams but the server returns at most maxRows rows at a time. When maxRows rows have
+// been read the result is suspended: NextRow returns false and Suspended returns true. Resume fetches the next maxRows
+// rows. The rows are not fetched until they are requested so this allows streaming a large result with backpressure.
+// Closing the ResultReader while it is suspended discards the remaining rows. A maxRows of 0 means no limit.
+//
+// The statement stays in progress until the ResultReader is closed so the implicit transaction it runs in is not
+// committed until then.
+func (pgConn *PgConn) ExecParamsMaxRows(ctx context.Context, sql string, paramValues [][]byte, paramOIDs []uint32, paramFormats []int16, resultFormats []int16, maxRows uint32) *ResultReader {
+	result := pgConn.execExtendedPrefix(ctx, paramValues)
+	if result.closed {
+		return result
+	}
+
+	pgConn.frontend.SendParse(&pgproto3.Parse{Query: sql, ParameterOIDs: paramOIDs})
+	pgConn.frontend.SendBind(&pgproto3.Bind{ParameterFormatCodes: paramFormats, Parameters: paramValues, ResultFormatCodes: resultFormats})
+
+	pgConn.execExtendedSuffixMaxRows(result, maxRows)
+
+	return result
+}
+
 // ExecPrepared enqueues the execution of a prepared statement via the PostgreSQL extended query protocol.
 //
 // paramValues are the parameter values. It must be encoded in the format given by paramFormats.
@@ -1024,6 +1104,21 @@ func (pgConn *PgConn) ExecPrepared(ctx context.Context, stmtName string, paramVa
 	return result
 }
 
+// ExecPreparedMaxRows is like ExecPrepared but the server returns at most maxRows rows at a time. See
+// ExecParamsMaxRows.
+func (pgConn *PgConn) ExecPreparedMaxRows(ctx context.Context, stmtName string, paramValues [][]byte, paramFormats []int16, resultFormats []int16, maxRows uint32) *ResultReader {
+	result := pgConn.execExtendedPrefix(ctx, paramValues)
+	if result.closed {
+		return result
+	}
+
+	pgConn.frontend.SendBind(&pgproto3.Bind{PreparedStatement: stmtName, ParameterFormatCodes: paramFormats, Parameters: paramValues, ResultFormatCodes: resultFormats})
+
+	pgConn.execExtendedSuffixMaxRows(result, maxRows)
+
+	return result
+}
+
 func (pgConn *PgConn) execExtendedPrefix(ctx context.Context, paramValues [][]byte) *ResultReader {
 	pgConn.resultReader = ResultReader{
 		pgConn: pgConn,
@@ -1060,9 +1155,20 @@ func (pgConn *PgConn) execExtendedPrefix(ctx context.Context, paramValues [][]by
 }
 
 func (pgConn *PgConn) execExtendedSuffix(result *ResultReader) {
+	pgConn.execExtendedSuffixMaxRows(result, 0)
+}
+
+func (pgConn *PgConn) execExtendedSuffixMaxRows(result *ResultReader, maxRows uint32) {
 	pgConn.frontend.SendDescribe(&pgproto3.Describe{ObjectType: 'P'})
-	pgConn.frontend.SendExecute(&pgproto3.Execute{})
-	pgConn.frontend.SendSync(&pgproto3.Sync{})
+	pgConn.frontend.SendExecute(&pgproto3.Execute{MaxRows: maxRows})
+	if maxRows == 0 {
+		pgConn.frontend.SendSync(&pgproto3.Sync{})
+	} else {
+		// The portal is destroyed by Sync so it is not sent until the ResultReader is closed.
+		pgConn.frontend.Send(&pgproto3.Flush{})
+		result.maxRows = maxRows
+		result.syncPending = true
+	}
 
 	err := pgConn.frontend.Flush()
 	if err != nil {
@@ -1373,6 +1479,10 @@ type ResultReader struct {
 	commandConcluded  bool
 	closed            bool
 	err               error
+
+	suspended   bool   // the row limit of the Execute was reached
+	maxRows     uint32 // the row limit of each Execute of ExecParamsMaxRows or ExecPreparedMaxRows
+	syncPending bool   // Sync must be sent before ReadyForQuery can be read
 }
 
 // Result is the saved query response that is returned by calling Read on a ResultReader.
@@ -1437,6 +1547,38 @@ func (rr *ResultReader) Values() [][]byte {
 	return rr.rowValues
 }
 
+// Suspended returns true if the server stopped returning rows because the row limit of ExecParamsMaxRows or
+// ExecPreparedMaxRows was reached. The rest of the rows can be fetched with Resume.
+func (rr *ResultReader) Suspended() bool {
+	return rr.suspended
+}
+
+// Resume asks the server for the next rows of a suspended result. It returns false if the result is not suspended.
+// Otherwise NextRow can be used to read the rows.
+func (rr *ResultReader) Resume() bool {
+	if !rr.suspended || !rr.syncPending || rr.closed {
+		return false
+	}
+
+	rr.suspended = false
+	rr.commandConcluded = false
+	rr.commandTag = CommandTag{}
+
+	rr.pgConn.frontend.SendExecute(&pgproto3.Execute{MaxRows: rr.maxRows})
+	rr.pgConn.frontend.Send(&pgproto3.Flush{})
+	err := rr.pgConn.frontend.Flush()
+	if err != nil {
+		rr.pgConn.asyncClose()
+		rr.concludeCommand(CommandTag{}, err)
+		rr.pgConn.contextWatcher.Unwatch()
+		rr.closed = true
+		rr.pgConn.unlock()
+		return false
+	}
+
+	return true
+}
+
 // Close consumes any remaining result data and returns the command tag or
 // error.
 func (rr *ResultReader) Close() (CommandTag, error) {
@@ -1452,6 +1594,19 @@ func (rr *ResultReader) Close() (CommandTag, error) {
 		}
 	}
 
+	if rr.syncPending {
+		rr.syncPending = false
+		rr.pgConn.frontend.SendSync(&pgproto3.Sync{})
+		err := rr.pgConn.frontend.Flush()
+		if err != nil {
+			rr.pgConn.asyncClose()
+			rr.concludeCommand(CommandTag{}, err)
+			rr.pgConn.contextWatcher.Unwatch()
+			rr.pgConn.unlock()
+			return CommandTag{}, rr.err
+		}
+	}
+
 	if rr.multiResultReader == nil && rr.pipeline == nil {
 		for {
 			msg, err := rr.receiveMessage()
@@ -1520,6 +1675,10 @@ func (rr *ResultReader) receiveMessage() (msg pgproto3.BackendMessage, err error
 		rr.concludeCommand(rr.pgConn.makeCommandTag(msg.CommandTag), nil)
 	case *pgproto3.EmptyQueryResponse:
 		rr.concludeCommand(CommandTag{}, nil)
+	case *pgproto3.PortalSuspended:
+		// The row limit of an Execute was reached. The server does not send a command tag for a suspended portal.
+		rr.concludeCommand(CommandTag{}, nil)
+		rr.suspended = true
 	case *pgproto3.ErrorResponse:
 		rr.concludeCommand(CommandTag{}, ErrorResponseToPgError(msg))
 	}
@@ -1554,11 +1713,46 @@ func (batch *Batch) ExecParams(sql string, paramValues [][]byte, paramOIDs []uin
 	batch.ExecPrepared("", paramValues, paramFormats, resultFormats)
 }
 
+// ExecParamsMaxRows is like ExecParams but the server stops returning rows after maxRows rows. A maxRows of 0 means no
+// limit. A result that reaches the limit has an empty command tag.
+func (batch *Batch) ExecParamsMaxRows(sql string, paramValues [][]byte, paramOIDs []uint32, paramFormats []int16, resultFormats []int16, maxRows uint32) {
+	batch.buf = (&pgproto3.Parse{Query: sql, ParameterOIDs: paramOIDs}).Encode(batch.buf)
+	batch.ExecPreparedMaxRows("", paramValues, paramFormats, resultFormats, maxRows)
+}
+
 // ExecPrepared appends an ExecPrepared e command to the batch. See PgConn.ExecPrepared for parameter descriptions.
 func (batch *Batch) ExecPrepared(stmtName string, paramValues [][]byte, paramFormats []int16, resultFormats []int16) {
+	batch.ExecPreparedMaxRows(stmtName, paramValues, paramFormats, resultFormats, 0)
+}
+
+// ExecPreparedMaxRows is like ExecPrepared but the server stops returning rows after maxRows rows. A maxRows of 0 means
+// no limit. A result that reaches the limit has an empty command tag.
+func (batch *Batch) ExecPreparedMaxRows(stmtName string, paramValues [][]byte, paramFormats []int16, resultFormats []int16, maxRows uint32) {
 	batch.buf = (&pgproto3.Bind{PreparedStatement: stmtName, ParameterFormatCodes: paramFormats, Parameters: paramValues, ResultFormatCodes: resultFormats}).Encode(batch.buf)
 	batch.buf = (&pgproto3.Describe{ObjectType: 'P'}).Encode(batch.buf)
+	batch.buf = (&pgproto3.Execute{MaxRows: maxRows}).Encode(batch.buf)
+}
+
+// maxCopyDataMessageSize is the maximum size of the data of a CopyData message sent for a COPY queued in a Batch or
+// Pipeline. Larger data is split into multiple messages.
+const maxCopyDataMessageSize = 65536
+
+// CopyFrom appends a COPY FROM STDIN command to the batch followed by data as its copy data. sql must be a COPY FROM
+// STDIN statement. data is sent as is so it must be in the format sql specifies. The result of the command is read like
+// that of any other command in the batch.
+func (batch *Batch) CopyFrom(sql string, data []byte) {
+	batch.buf = (&pgproto3.Parse{Query: sql}).Encode(batch.buf)
+	batch.buf = (&pgproto3.Bind{}).Encode(batch.buf)
 	batch.buf = (&pgproto3.Execute{}).Encode(batch.buf)
+	for len(data) > 0 {
+		n := len(data)
+		if n > maxCopyDataMessageSize {
+			n = maxCopyDataMessageSize
+		}
+		batch.buf = (&pgproto3.CopyData{Data: data[:n]}).Encode(batch.buf)
+		data = data[n:]
+	}
+	batch.buf = (&pgproto3.CopyDone{}).Encode(batch.buf)
 }
 
 // ExecBatch executes all the queries in batch in a single round-trip. Execution is implicitly transactional unless a
@@ -1695,6 +1889,9 @@ func Construct(hc *HijackedConn) (*PgConn, error) {
 	}
 
 	pgConn.contextWatcher = newContextWatcher(pgConn.conn)
+	if pgConn.config.CancelWaitTimeout > 0 {
+		pgConn.contextWatcher = pgConn.newCancelRequestContextWatcher()
+	}
 
 	return pgConn, nil
 }
@@ -1785,6 +1982,12 @@ func (p *Pipeline) SendDeallocate(name string) {
 
 // SendQueryParams is the pipeline version of *PgConn.QueryParams.
 func (p *Pipeline) SendQueryParams(sql string, paramValues [][]byte, paramOIDs []uint32, paramFormats []int16, resultFormats []int16) {
+	p.SendQueryParamsMaxRows(sql, paramValues, paramOIDs, paramFormats, resultFormats, 0)
+}
+
+// SendQueryParamsMaxRows is like SendQueryParams but the server stops returning rows after maxRows rows. A maxRows of
+// 0 means no limit. A result that reaches the limit has an empty command tag.
+func (p *Pipeline) SendQueryParamsMaxRows(sql string, paramValues [][]byte, paramOIDs []uint32, paramFormats []int16, resultFormats []int16, maxRows uint32) {
 	if p.closed {
 		return
 	}
@@ -1793,11 +1996,17 @@ func (p *Pipeline) SendQueryParams(sql string, paramValues [][]byte, paramOIDs [
 	p.conn.frontend.SendParse(&pgproto3.Parse{Query: sql, ParameterOIDs: paramOIDs})
 	p.conn.frontend.SendBind(&pgproto3.Bind{ParameterFormatCodes: paramFormats, Parameters: paramValues, ResultFormatCodes: resultFormats})
 	p.conn.frontend.SendDescribe(&pgproto3.Describe{ObjectType: 'P'})
-	p.conn.frontend.SendExecute(&pgproto3.Execute{})
+	p.conn.frontend.SendExecute(&pgproto3.Execute{MaxRows: maxRows})
 }
 
 // SendQueryPrepared is the pipeline version of *PgConn.QueryPrepared.
 func (p *Pipeline) SendQueryPrepared(stmtName string, paramValues [][]byte, paramFormats []int16, resultFormats []int16) {
+	p.SendQueryPreparedMaxRows(stmtName, paramValues, paramFormats, resultFormats, 0)
+}
+
+// SendQueryPreparedMaxRows is like SendQueryPrepared but the server stops returning rows after maxRows rows. A maxRows
+// of 0 means no limit. A result that reaches the limit has an empty command tag.
+func (p *Pipeline) SendQueryPreparedMaxRows(stmtName string, paramValues [][]byte, paramFormats []int16, resultFormats []int16, maxRows uint32) {
 	if p.closed {
 		return
 	}
@@ -1805,7 +2014,31 @@ func (p *Pipeline) SendQueryPrepared(stmtName string, paramValues [][]byte, para
 
 	p.conn.frontend.SendBind(&pgproto3.Bind{PreparedStatement: stmtName, ParameterFormatCodes: paramFormats, Parameters: paramValues, ResultFormatCodes: resultFormats})
 	p.conn.frontend.SendDescribe(&pgproto3.Describe{ObjectType: 'P'})
+	p.conn.frontend.SendExecute(&pgproto3.Execute{MaxRows: maxRows})
+}
+
+// SendCopyFrom sends a COPY FROM STDIN command followed by data as its copy data. sql must be a COPY FROM STDIN
+// statement. data is sent as is so it must be in the format sql specifies. The server reads the copy data in order with
+// the other requests of the pipeline so no round trip is needed. The result is returned by GetResults as a
+// *ResultReader with the command tag of the COPY.
+func (p *Pipeline) SendCopyFrom(sql string, data []byte) {
+	if p.closed {
+		return
+	}
+	p.pendingSync = true
+
+	p.conn.frontend.SendParse(&pgproto3.Parse{Query: sql})
+	p.conn.frontend.SendBind(&pgproto3.Bind{})
 	p.conn.frontend.SendExecute(&pgproto3.Execute{})
+	for len(data) > 0 {
+		n := len(data)
+		if n > maxCopyDataMessageSize {
+			n = maxCopyDataMessageSize
+		}
+		p.conn.frontend.Send(&pgproto3.CopyData{Data: data[:n]})
+		data = data[n:]
+	}
+	p.conn.frontend.Send(&pgproto3.CopyDone{})
 }
 
 // Flush flushes the queued requests without establishing a synchronization point.
@@ -791,6 +791,62 @@ func TestConnExecContextCanceled(t *testing.T) {
 	}
 }
 
+func TestConnExecContextCanceledWithCancelWaitTimeout(t *testing.T) {
+	t.Parallel()
+
+	config, err := pgconn.ParseConfig(os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	config.CancelWaitTimeout = 5 * time.Second
+
+	pgConn, err := pgconn.ConnectConfig(context.Background(), config)
+	require.NoError(t, err)
+	defer closeConn(t, pgConn)
+
+	if pgConn.ParameterStatus("crdb_version") != "" {
+		t.Skip("Server does not support query cancellation (https://github.com/cockroachdb/cockroach/issues/41335)")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	multiResult := pgConn.Exec(ctx, "select 'Hello, world', pg_sleep(5)")
+
+	for multiResult.NextResult() {
+	}
+	err = multiResult.Close()
+	var pgErr *pgconn.PgError
+	require.ErrorAs(t, err, &pgErr)
+	assert.Equal(t, "57014", pgErr.Code)
+	assert.False(t, pgConn.IsClosed())
+
+	result := pgConn.ExecParams(ctx, "select pg_sleep(5)", nil, nil, nil, nil).Read()
+	require.Error(t, result.Err)
+	assert.False(t, pgConn.IsClosed())
+
+	ensureConnValid(t, pgConn)
+}
+
+func TestConnWaitForNotificationContextCanceledWithCancelWaitTimeout(t *testing.T) {
+	t.Parallel()
+
+	config, err := pgconn.ParseConfig(os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	config.CancelWaitTimeout = 5 * time.Second
+
+	pgConn, err := pgconn.ConnectConfig(context.Background(), config)
+	require.NoError(t, err)
+	defer closeConn(t, pgConn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err = pgConn.WaitForNotification(ctx)
+	assert.True(t, pgconn.Timeout(err))
+	assert.Less(t, time.Since(start), 2*time.Second)
+
+	ensureConnValid(t, pgConn)
+}
+
 func TestConnExecContextPrecanceled(t *testing.T) {
 	t.Parallel()
 
@@ -832,6 +888,81 @@ func TestConnExecParams(t *testing.T) {
 	ensureConnValid(t, pgConn)
 }
 
+func TestConnExecParamsMaxRows(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	defer closeConn(t, pgConn)
+
+	result := pgConn.ExecParamsMaxRows(context.Background(), "select n from generate_series(1, 10) n", nil, nil, nil, nil, 3)
+	require.Len(t, result.FieldDescriptions(), 1)
+
+	var values []string
+	fetches := 0
+	for {
+		fetches++
+		for result.NextRow() {
+			values = append(values, string(result.Values()[0]))
+		}
+		if !result.Suspended() {
+			break
+		}
+		require.True(t, result.Resume())
+	}
+	require.Equal(t, []string{"1", "2", "3", "4", "5", "6", "7", "8", "9", "10"}, values)
+	require.Equal(t, 4, fetches)
+
+	_, err = result.Close()
+	require.NoError(t, err)
+
+	ensureConnValid(t, pgConn)
+}
+
+func TestConnExecParamsMaxRowsCloseWhileSuspended(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	defer closeConn(t, pgConn)
+
+	result := pgConn.ExecParamsMaxRows(context.Background(), "select n from generate_series(1, 1000) n", nil, nil, nil, nil, 2)
+	require.True(t, result.NextRow())
+	require.True(t, result.NextRow())
+	require.False(t, result.NextRow())
+	require.True(t, result.Suspended())
+
+	_, err = result.Close()
+	require.NoError(t, err)
+	require.False(t, result.Resume())
+
+	ensureConnValid(t, pgConn)
+}
+
+func TestConnExecParamsMaxRowsError(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	defer closeConn(t, pgConn)
+
+	result := pgConn.ExecParamsMaxRows(context.Background(), "select 10 / (5 - n) from generate_series(1, 10) n", nil, nil, nil, nil, 2)
+	for {
+		for result.NextRow() {
+		}
+		if !result.Resume() {
+			break
+		}
+	}
+
+	_, err = result.Close()
+	var pgErr *pgconn.PgError
+	require.ErrorAs(t, err, &pgErr)
+	require.Equal(t, "22012", pgErr.Code)
+
+	ensureConnValid(t, pgConn)
+}
+
 func TestConnExecParamsDeferredError(t *testing.T) {
 	t.Parallel()
 
@@ -1370,6 +1501,50 @@ end$$;`)
 	ensureConnValid(t, pgConn)
 }
 
+func TestConnSetNoticeHandler(t *testing.T) {
+	t.Parallel()
+
+	config, err := pgconn.ParseConfig(os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+
+	var configMsgs []string
+	config.OnNotice = func(c *pgconn.PgConn, notice *pgconn.Notice) {
+		configMsgs = append(configMsgs, notice.Message)
+	}
+	config.RuntimeParams["client_min_messages"] = "notice" // Ensure we only get the message we expect.
+
+	pgConn, err := pgconn.ConnectConfig(context.Background(), config)
+	require.NoError(t, err)
+	defer closeConn(t, pgConn)
+
+	if pgConn.ParameterStatus("crdb_version") != "" {
+		t.Skip("Server does not support PL/PGSQL (https://github.com/cockroachdb/cockroach/issues/17511)")
+	}
+
+	raiseNotice := func(msg string) {
+		err := pgConn.Exec(context.Background(), fmt.Sprintf(`do $$
+begin
+  raise notice '%s';
+end$$;`, msg)).Close()
+		require.NoError(t, err)
+	}
+
+	var overrideMsgs []string
+	prev := pgConn.SetNoticeHandler(func(c *pgconn.PgConn, notice *pgconn.Notice) {
+		overrideMsgs = append(overrideMsgs, notice.Message)
+	})
+	require.NotNil(t, prev)
+	raiseNotice("override")
+
+	pgConn.SetNoticeHandler(nil)
+	raiseNotice("config")
+
+	assert.Equal(t, []string{"override"}, overrideMsgs)
+	assert.Equal(t, []string{"config"}, configMsgs)
+
+	ensureConnValid(t, pgConn)
+}
+
 func TestConnOnNotification(t *testing.T) {
 	t.Parallel()
 
@@ -1442,6 +1617,49 @@ func TestConnWaitForNotification(t *testing.T) {
 	ensureConnValid(t, pgConn)
 }
 
+func TestConnOnParameterStatus(t *testing.T) {
+	t.Parallel()
+
+	config, err := pgconn.ParseConfig(os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+
+	type change struct {
+		name, oldValue, newValue string
+	}
+	var changes []change
+	config.OnParameterStatus = func(c *pgconn.PgConn, name, oldValue, newValue string) {
+		changes = append(changes, change{name, oldValue, newValue})
+	}
+
+	pgConn, err := pgconn.ConnectConfig(context.Background(), config)
+	require.NoError(t, err)
+	defer closeConn(t, pgConn)
+
+	// Parameters reported while connecting are not changes.
+	require.Empty(t, changes)
+
+	if pgConn.ParameterStatus("crdb_version") != "" {
+		t.Skip("Server does not report TimeZone changes")
+	}
+
+	oldTimeZone := pgConn.ParameterStatus("TimeZone")
+	newTimeZone := "America/Chicago"
+	if oldTimeZone == newTimeZone {
+		newTimeZone = "America/New_York"
+	}
+
+	_, err = pgConn.Exec(context.Background(), "set timezone to '"+newTimeZone+"'").ReadAll()
+	require.NoError(t, err)
+	require.Equal(t, []change{{"TimeZone", oldTimeZone, newTimeZone}}, changes)
+
+	// Setting the same value again is not a change.
+	_, err = pgConn.Exec(context.Background(), "set timezone to '"+newTimeZone+"'").ReadAll()
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+
+	ensureConnValid(t, pgConn)
+}
+
 func TestConnWaitForNotificationPrecanceled(t *testing.T) {
 	t.Parallel()
 
@@ -2962,3 +3180,46 @@ func TestSNISupport(t *testing.T) {
 		})
 	}
 }
+
+func TestPipelineCopyFrom(t *testing.T) {
+	t.Parallel()
+
+	pgConn, err := pgconn.Connect(context.Background(), os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	defer closeConn(t, pgConn)
+
+	_, err = pgConn.Exec(context.Background(), `create temporary table foo(a int4, b varchar)`).ReadAll()
+	require.NoError(t, err)
+
+	pipeline := pgConn.StartPipeline(context.Background())
+	pipeline.SendCopyFrom("copy foo from stdin", []byte("1\tfoo\n2\tbar\n"))
+	pipeline.SendQueryParams(`select count(*) from foo`, nil, nil, nil, nil)
+	err = pipeline.Sync()
+	require.NoError(t, err)
+
+	results, err := pipeline.GetResults()
+	require.NoError(t, err)
+	rr, ok := results.(*pgconn.ResultReader)
+	require.Truef(t, ok, "expected ResultReader, got: %#v", results)
+	readResult := rr.Read()
+	require.NoError(t, readResult.Err)
+	require.Equal(t, "COPY 2", readResult.CommandTag.String())
+
+	results, err = pipeline.GetResults()
+	require.NoError(t, err)
+	rr, ok = results.(*pgconn.ResultReader)
+	require.Truef(t, ok, "expected ResultReader, got: %#v", results)
+	readResult = rr.Read()
+	require.NoError(t, readResult.Err)
+	require.Equal(t, "2", string(readResult.Rows[0][0]))
+
+	results, err = pipeline.GetResults()
+	require.NoError(t, err)
+	_, ok = results.(*pgconn.PipelineSync)
+	require.Truef(t, ok, "expected PipelineSync, got: %#v", results)
+
+	err = pipeline.Close()
+	require.NoError(t, err)
+
+	ensureConnValid(t, pgConn)
+}
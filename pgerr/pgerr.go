@@ -0,0 +1,250 @@
+// Package pgerr classifies PostgreSQL errors by SQLSTATE so applications do not need to match on raw codes.
+//
+// Wrap converts an error that contains a *pgconn.PgError with a known SQLSTATE into a typed error that can be matched
+// with errors.As:
+//
+//	_, err := conn.Exec(ctx, "insert into users(email) values($1)", email)
+//	var uniqueViolation *pgerr.UniqueViolation
+//	if errors.As(pgerr.Wrap(err), &uniqueViolation) && uniqueViolation.ConstraintName == "users_email_key" {
+//		return ErrEmailTaken
+//	}
+//
+// The typed errors wrap the original error, so errors.As with a *pgconn.PgError target and errors.Is continue to work
+// on the result of Wrap. The fields of the *pgconn.PgError, such as ConstraintName and TableName, are promoted to the
+// typed errors.
+//
+// The helpers such as Code and ConstraintName accept any error and do not require Wrap.
+package pgerr
+
+import (
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// SQLSTATE codes of the errors that Wrap converts to typed errors. See
+// https://www.postgresql.org/docs/current/errcodes-appendix.html for all codes.
+const (
+	CodeStringDataRightTruncation = "22001"
+	CodeNumericValueOutOfRange    = "22003"
+	CodeInvalidTextRepresentation = "22P02"
+	CodeNotNullViolation          = "23502"
+	CodeForeignKeyViolation       = "23503"
+	CodeUniqueViolation           = "23505"
+	CodeCheckViolation            = "23514"
+	CodeExclusionViolation        = "23P01"
+	CodeReadOnlySQLTransaction    = "25006"
+	CodeSerializationFailure      = "40001"
+	CodeDeadlockDetected          = "40P01"
+	CodeInsufficientPrivilege     = "42501"
+	CodeUndefinedColumn           = "42703"
+	CodeUndefinedTable            = "42P01"
+	CodeTooManyConnections        = "53300"
+	CodeLockNotAvailable          = "55P03"
+	CodeQueryCanceled             = "57014"
+	CodeAdminShutdown             = "57P01"
+)
+
+// SQLSTATE classes, the first two characters of a code.
+const (
+	ClassDataException                = "22"
+	ClassIntegrityConstraintViolation = "23"
+	ClassTransactionRollback          = "40"
+	ClassSyntaxErrorOrAccessRule      = "42"
+	ClassInsufficientResources        = "53"
+	ClassObjectNotInPrerequisiteState = "55"
+	ClassOperatorIntervention         = "57"
+)
+
+// pgError is embedded in the typed errors. It promotes the fields of the *pgconn.PgError and makes the typed error
+// wrap the original error.
+type pgError struct {
+	*pgconn.PgError
+	err error
+}
+
+// Error returns the message of the original error.
+func (e pgError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the original error.
+func (e pgError) Unwrap() error {
+	return e.err
+}
+
+// StringDataRightTruncation is a string_data_right_truncation error (SQLSTATE 22001), e.g. a value too long for a
+// varchar column.
+type StringDataRightTruncation struct{ pgError }
+
+// NumericValueOutOfRange is a numeric_value_out_of_range error (SQLSTATE 22003).
+type NumericValueOutOfRange struct{ pgError }
+
+// InvalidTextRepresentation is an invalid_text_representation error (SQLSTATE 22P02), e.g. a malformed uuid.
+type InvalidTextRepresentation struct{ pgError }
+
+// NotNullViolation is a not_null_violation error (SQLSTATE 23502). ColumnName is the column that was null.
+type NotNullViolation struct{ pgError }
+
+// ForeignKeyViolation is a foreign_key_violation error (SQLSTATE 23503). ConstraintName is the violated constraint.
+type ForeignKeyViolation struct{ pgError }
+
+// UniqueViolation is a unique_violation error (SQLSTATE 23505). ConstraintName is the violated constraint or index.
+type UniqueViolation struct{ pgError }
+
+// CheckViolation is a check_violation error (SQLSTATE 23514). ConstraintName is the violated constraint.
+type CheckViolation struct{ pgError }
+
+// ExclusionViolation is an exclusion_violation error (SQLSTATE 23P01). ConstraintName is the violated constraint.
+type ExclusionViolation struct{ pgError }
+
+// ReadOnlySQLTransaction is a read_only_sql_transaction error (SQLSTATE 25006), e.g. a write on a hot standby.
+type ReadOnlySQLTransaction struct{ pgError }
+
+// SerializationFailure is a serialization_failure error (SQLSTATE 40001). The transaction may succeed if it is run
+// again.
+type SerializationFailure struct{ pgError }
+
+// DeadlockDetected is a deadlock_detected error (SQLSTATE 40P01). The transaction may succeed if it is run again.
+type DeadlockDetected struct{ pgError }
+
+// InsufficientPrivilege is an insufficient_privilege error (SQLSTATE 42501).
+type InsufficientPrivilege struct{ pgError }
+
+// UndefinedColumn is an undefined_column error (SQLSTATE 42703).
+type UndefinedColumn struct{ pgError }
+
+// UndefinedTable is an undefined_table error (SQLSTATE 42P01).
+type UndefinedTable struct{ pgError }
+
+// TooManyConnections is a too_many_connections error (SQLSTATE 53300).
+type TooManyConnections struct{ pgError }
+
+// LockNotAvailable is a lock_not_available error (SQLSTATE 55P03), e.g. from SELECT ... FOR UPDATE NOWAIT or when
+// lock_timeout expires.
+type LockNotAvailable struct{ pgError }
+
+// QueryCanceled is a query_canceled error (SQLSTATE 57014), e.g. when statement_timeout expires or the query is
+// canceled.
+type QueryCanceled struct{ pgError }
+
+// AdminShutdown is an admin_shutdown error (SQLSTATE 57P01). The server is shutting down or the connection was
+// terminated.
+type AdminShutdown struct{ pgError }
+
+// Wrap returns err as a typed error of this package if it contains a *pgconn.PgError with one of the codes of this
+// package. Otherwise err is returned unchanged. The typed error wraps err. Wrap returns nil if err is nil.
+func Wrap(err error) error {
+	pgErr := findPgError(err)
+	if pgErr == nil {
+		return err
+	}
+
+	e := pgError{PgError: pgErr, err: err}
+	switch pgErr.Code {
+	case CodeStringDataRightTruncation:
+		return &StringDataRightTruncation{e}
+	case CodeNumericValueOutOfRange:
+		return &NumericValueOutOfRange{e}
+	case CodeInvalidTextRepresentation:
+		return &InvalidTextRepresentation{e}
+	case CodeNotNullViolation:
+		return &NotNullViolation{e}
+	case CodeForeignKeyViolation:
+		return &ForeignKeyViolation{e}
+	case CodeUniqueViolation:
+		return &UniqueViolation{e}
+	case CodeCheckViolation:
+		return &CheckViolation{e}
+	case CodeExclusionViolation:
+		return &ExclusionViolation{e}
+	case CodeReadOnlySQLTransaction:
+		return &ReadOnlySQLTransaction{e}
+	case CodeSerializationFailure:
+		return &SerializationFailure{e}
+	case CodeDeadlockDetected:
+		return &DeadlockDetected{e}
+	case CodeInsufficientPrivilege:
+		return &InsufficientPrivilege{e}
+	case CodeUndefinedColumn:
+		return &UndefinedColumn{e}
+	case CodeUndefinedTable:
+		return &UndefinedTable{e}
+	case CodeTooManyConnections:
+		return &TooManyConnections{e}
+	case CodeLockNotAvailable:
+		return &LockNotAvailable{e}
+	case CodeQueryCanceled:
+		return &QueryCanceled{e}
+	case CodeAdminShutdown:
+		return &AdminShutdown{e}
+	default:
+		return err
+	}
+}
+
+// findPgError returns the *pgconn.PgError in err or nil if there is none.
+func findPgError(err error) *pgconn.PgError {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr
+	}
+	return nil
+}
+
+// Code returns the SQLSTATE of the *pgconn.PgError in err. It returns "" if err does not contain a *pgconn.PgError.
+func Code(err error) string {
+	if pgErr := findPgError(err); pgErr != nil {
+		return pgErr.Code
+	}
+	return ""
+}
+
+// Class returns the SQLSTATE class of the *pgconn.PgError in err, e.g. ClassIntegrityConstraintViolation. It returns
+// "" if err does not contain a *pgconn.PgError.
+func Class(err error) string {
+	code := Code(err)
+	if len(code) < 2 {
+		return ""
+	}
+	return code[:2]
+}
+
+// ConstraintName returns the name of the constraint violated by the *pgconn.PgError in err. It returns "" if err does
+// not contain a *pgconn.PgError or the error is not associated with a constraint.
+func ConstraintName(err error) string {
+	if pgErr := findPgError(err); pgErr != nil {
+		return pgErr.ConstraintName
+	}
+	return ""
+}
+
+// TableName returns the name of the table of the *pgconn.PgError in err. It returns "" if err does not contain a
+// *pgconn.PgError or the error is not associated with a table.
+func TableName(err error) string {
+	if pgErr := findPgError(err); pgErr != nil {
+		return pgErr.TableName
+	}
+	return ""
+}
+
+// ColumnName returns the name of the column of the *pgconn.PgError in err. It returns "" if err does not contain a
+// *pgconn.PgError or the error is not associated with a column.
+func ColumnName(err error) string {
+	if pgErr := findPgError(err); pgErr != nil {
+		return pgErr.ColumnName
+	}
+	return ""
+}
+
+// IsIntegrityConstraintViolation reports whether err contains a *pgconn.PgError in the integrity constraint violation
+// class (23), e.g. a unique, foreign key, not null, check, or exclusion violation.
+func IsIntegrityConstraintViolation(err error) bool {
+	return Class(err) == ClassIntegrityConstraintViolation
+}
+
+// IsTransactionRollback reports whether err contains a *pgconn.PgError in the transaction rollback class (40), e.g. a
+// serialization failure or deadlock. A transaction that fails with such an error may succeed if it is run again.
+func IsTransactionRollback(err error) bool {
+	return Class(err) == ClassTransactionRollback
+}
@@ -0,0 +1,100 @@
+package pgerr_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgerr"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWrap(t *testing.T) {
+	t.Parallel()
+
+	pgErr := &pgconn.PgError{Severity: "ERROR", Code: "23505", Message: "duplicate key value violates unique constraint \"users_email_key\"", TableName: "users", ConstraintName: "users_email_key"}
+	err := fmt.Errorf("insert user: %w", pgErr)
+
+	wrapped := pgerr.Wrap(err)
+	require.EqualError(t, wrapped, err.Error())
+
+	var uniqueViolation *pgerr.UniqueViolation
+	require.True(t, errors.As(wrapped, &uniqueViolation))
+	require.Equal(t, "users_email_key", uniqueViolation.ConstraintName)
+	require.Equal(t, "users", uniqueViolation.TableName)
+	require.Same(t, pgErr, uniqueViolation.PgError)
+
+	var foreignKeyViolation *pgerr.ForeignKeyViolation
+	require.False(t, errors.As(wrapped, &foreignKeyViolation))
+
+	var unwrapped *pgconn.PgError
+	require.True(t, errors.As(wrapped, &unwrapped))
+	require.Same(t, pgErr, unwrapped)
+	require.True(t, errors.Is(wrapped, err))
+}
+
+func TestWrapCodes(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		code   string
+		target any
+	}{
+		{pgerr.CodeStringDataRightTruncation, new(*pgerr.StringDataRightTruncation)},
+		{pgerr.CodeNumericValueOutOfRange, new(*pgerr.NumericValueOutOfRange)},
+		{pgerr.CodeInvalidTextRepresentation, new(*pgerr.InvalidTextRepresentation)},
+		{pgerr.CodeNotNullViolation, new(*pgerr.NotNullViolation)},
+		{pgerr.CodeForeignKeyViolation, new(*pgerr.ForeignKeyViolation)},
+		{pgerr.CodeUniqueViolation, new(*pgerr.UniqueViolation)},
+		{pgerr.CodeCheckViolation, new(*pgerr.CheckViolation)},
+		{pgerr.CodeExclusionViolation, new(*pgerr.ExclusionViolation)},
+		{pgerr.CodeReadOnlySQLTransaction, new(*pgerr.ReadOnlySQLTransaction)},
+		{pgerr.CodeSerializationFailure, new(*pgerr.SerializationFailure)},
+		{pgerr.CodeDeadlockDetected, new(*pgerr.DeadlockDetected)},
+		{pgerr.CodeInsufficientPrivilege, new(*pgerr.InsufficientPrivilege)},
+		{pgerr.CodeUndefinedColumn, new(*pgerr.UndefinedColumn)},
+		{pgerr.CodeUndefinedTable, new(*pgerr.UndefinedTable)},
+		{pgerr.CodeTooManyConnections, new(*pgerr.TooManyConnections)},
+		{pgerr.CodeLockNotAvailable, new(*pgerr.LockNotAvailable)},
+		{pgerr.CodeQueryCanceled, new(*pgerr.QueryCanceled)},
+		{pgerr.CodeAdminShutdown, new(*pgerr.AdminShutdown)},
+	} {
+		err := pgerr.Wrap(&pgconn.PgError{Code: tt.code})
+		require.Truef(t, errors.As(err, tt.target), "code %s", tt.code)
+	}
+}
+
+func TestWrapUnknown(t *testing.T) {
+	t.Parallel()
+
+	require.Nil(t, pgerr.Wrap(nil))
+
+	err := errors.New("not a PgError")
+	require.Same(t, err, pgerr.Wrap(err))
+
+	pgErr := &pgconn.PgError{Code: "XX000"}
+	require.Same(t, pgErr, pgerr.Wrap(pgErr))
+}
+
+func TestHelpers(t *testing.T) {
+	t.Parallel()
+
+	err := fmt.Errorf("wrapped: %w", &pgconn.PgError{Code: "23503", TableName: "orders", ColumnName: "user_id", ConstraintName: "orders_user_id_fkey"})
+	require.Equal(t, "23503", pgerr.Code(err))
+	require.Equal(t, pgerr.ClassIntegrityConstraintViolation, pgerr.Class(err))
+	require.Equal(t, "orders_user_id_fkey", pgerr.ConstraintName(err))
+	require.Equal(t, "orders", pgerr.TableName(err))
+	require.Equal(t, "user_id", pgerr.ColumnName(err))
+	require.True(t, pgerr.IsIntegrityConstraintViolation(err))
+	require.False(t, pgerr.IsTransactionRollback(err))
+
+	require.True(t, pgerr.IsTransactionRollback(&pgconn.PgError{Code: "40P01"}))
+
+	err = errors.New("not a PgError")
+	require.Equal(t, "", pgerr.Code(err))
+	require.Equal(t, "", pgerr.Class(err))
+	require.Equal(t, "", pgerr.ConstraintName(err))
+	require.False(t, pgerr.IsIntegrityConstraintViolation(err))
+	require.Equal(t, "", pgerr.Code(nil))
+}
@@ -0,0 +1,138 @@
+package pgerr
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// maxSnippetWidth is the maximum number of characters of the SQL line shown by PositionSnippet. Longer lines are cut
+// around the error position.
+const maxSnippetWidth = 80
+
+// PositionSnippet returns the line of sql at the error position of the *pgconn.PgError in err with a caret under the
+// position, in the format of psql:
+//
+//	LINE 2: where nme = $1
+//	              ^
+//
+// If the error has an InternalPosition instead, e.g. for an error in a PL/pgSQL function, the snippet is rendered
+// against the InternalQuery of the error and sql is not used. PositionSnippet returns "" if err does not contain a
+// *pgconn.PgError, the error has no position, or the position is outside of the SQL.
+//
+// sql must be the text the server received. Errors of a prepared statement refer to the SQL it was prepared with and
+// errors of a query sent with QueryExecModeSimpleProtocol refer to the SQL with the arguments interpolated.
+func PositionSnippet(err error, sql string) string {
+	pgErr := findPgError(err)
+	if pgErr == nil {
+		return ""
+	}
+
+	if pgErr.Position > 0 {
+		return positionSnippet(sql, int(pgErr.Position))
+	}
+	if pgErr.InternalPosition > 0 {
+		return positionSnippet(pgErr.InternalQuery, int(pgErr.InternalPosition))
+	}
+	return ""
+}
+
+// positionSnippet renders the line of sql at the 1-based character position.
+func positionSnippet(sql string, position int) string {
+	runes := []rune(sql)
+	idx := position - 1
+	// A syntax error at the end of the input has the position after the last character.
+	if idx > len(runes) {
+		return ""
+	}
+
+	lineStart := 0
+	lineNumber := 1
+	for i := 0; i < idx; i++ {
+		if runes[i] == '\n' {
+			lineStart = i + 1
+			lineNumber++
+		}
+	}
+	lineEnd := lineStart
+	for lineEnd < len(runes) && runes[lineEnd] != '\n' {
+		lineEnd++
+	}
+	if lineEnd > lineStart && runes[lineEnd-1] == '\r' {
+		lineEnd--
+	}
+
+	line := make([]rune, lineEnd-lineStart)
+	for i, r := range runes[lineStart:lineEnd] {
+		// Tabs are replaced so the caret lines up with the position regardless of tab width.
+		if r == '\t' {
+			r = ' '
+		}
+		line[i] = r
+	}
+	column := idx - lineStart
+
+	var prefix, suffix string
+	if len(line) > maxSnippetWidth {
+		start := column - maxSnippetWidth/2
+		if start < 0 {
+			start = 0
+		}
+		end := start + maxSnippetWidth
+		if end > len(line) {
+			end = len(line)
+			start = end - maxSnippetWidth
+		}
+		if start > 0 {
+			prefix = "..."
+		}
+		if end < len(line) {
+			suffix = "..."
+		}
+		line = line[start:end]
+		column -= start
+	}
+
+	label := "LINE " + strconv.Itoa(lineNumber) + ": "
+
+	sb := &strings.Builder{}
+	sb.WriteString(label)
+	sb.WriteString(prefix)
+	sb.WriteString(string(line))
+	sb.WriteString(suffix)
+	sb.WriteByte('\n')
+	sb.WriteString(strings.Repeat(" ", len(label)+len(prefix)+column))
+	sb.WriteByte('^')
+	return sb.String()
+}
+
+// PositionError is an error whose *pgconn.PgError has a position in SQL. Its message is the message of Err followed by
+// the PositionSnippet of the SQL.
+type PositionError struct {
+	Err error
+
+	// SQL is the text the position of the error refers to.
+	SQL string
+}
+
+func (e *PositionError) Error() string {
+	snippet := PositionSnippet(e.Err, e.SQL)
+	if snippet == "" {
+		return e.Err.Error()
+	}
+	return e.Err.Error() + "\n" + snippet
+}
+
+func (e *PositionError) Unwrap() error {
+	return e.Err
+}
+
+// WithPosition returns err wrapped in a *PositionError with sql if PositionSnippet can render its position. Otherwise
+// err is returned unchanged. err is also returned unchanged if it already contains a *PositionError.
+func WithPosition(err error, sql string) error {
+	var positionErr *PositionError
+	if errors.As(err, &positionErr) || PositionSnippet(err, sql) == "" {
+		return err
+	}
+	return &PositionError{Err: err, SQL: sql}
+}
@@ -0,0 +1,95 @@
+package pgerr_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgerr"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPositionSnippet(t *testing.T) {
+	t.Parallel()
+
+	longLine := "select " + strings.Repeat("a, ", 40) + "bogus, " + strings.Repeat("b, ", 40) + "c"
+
+	for i, tt := range []struct {
+		sql      string
+		position int32
+		expected string
+	}{
+		{
+			sql:      "select * fro foo",
+			position: 10,
+			expected: "LINE 1: select * fro foo\n                 ^",
+		},
+		{
+			sql:      "select id\nfrom users\nwhere nme = $1",
+			position: 28,
+			expected: "LINE 3: where nme = $1\n              ^",
+		},
+		{
+			sql:      "select id\r\nfrom\tusers\r\nwhere",
+			position: 17,
+			expected: "LINE 2: from users\n             ^",
+		},
+		{
+			sql:      "select 'ü', bogus",
+			position: 13,
+			expected: "LINE 1: select 'ü', bogus\n                    ^",
+		},
+		{
+			sql:      "select 1 +",
+			position: 11,
+			expected: "LINE 1: select 1 +\n" + strings.Repeat(" ", 18) + "^",
+		},
+		{
+			sql:      longLine,
+			position: int32(strings.Index(longLine, "bogus") + 1),
+			expected: "LINE 1: ..." + longLine[strings.Index(longLine, "bogus")-40:strings.Index(longLine, "bogus")+40] + "...\n" + strings.Repeat(" ", 51) + "^",
+		},
+		{
+			sql:      "select 1",
+			position: 20,
+			expected: "",
+		},
+	} {
+		err := &pgconn.PgError{Code: "42601", Position: tt.position}
+		require.Equalf(t, tt.expected, pgerr.PositionSnippet(err, tt.sql), "%d", i)
+	}
+}
+
+func TestPositionSnippetInternalPosition(t *testing.T) {
+	t.Parallel()
+
+	err := &pgconn.PgError{Code: "42703", InternalPosition: 8, InternalQuery: "select bogus"}
+	require.Equal(t, "LINE 1: select bogus\n               ^", pgerr.PositionSnippet(err, "select f()"))
+
+	require.Equal(t, "", pgerr.PositionSnippet(&pgconn.PgError{Code: "42703"}, "select 1"))
+	require.Equal(t, "", pgerr.PositionSnippet(errors.New("not a PgError"), "select 1"))
+}
+
+func TestWithPosition(t *testing.T) {
+	t.Parallel()
+
+	sql := "select * fro foo"
+	pgErr := &pgconn.PgError{Severity: "ERROR", Code: "42601", Message: `syntax error at or near "fro"`, Position: 10}
+	err := fmt.Errorf("query users: %w", pgErr)
+
+	wrapped := pgerr.WithPosition(err, sql)
+	require.EqualError(t, wrapped, err.Error()+"\nLINE 1: select * fro foo\n                 ^")
+
+	var positionErr *pgerr.PositionError
+	require.True(t, errors.As(wrapped, &positionErr))
+	require.Equal(t, sql, positionErr.SQL)
+	require.True(t, errors.Is(wrapped, pgErr))
+
+	require.Same(t, wrapped, pgerr.WithPosition(wrapped, sql))
+
+	withoutPosition := &pgconn.PgError{Code: "23505"}
+	require.Same(t, withoutPosition, pgerr.WithPosition(withoutPosition, sql))
+	require.Nil(t, pgerr.WithPosition(nil, sql))
+}
@@ -20,17 +20,19 @@ type chunkReader struct {
 	minBufSize int
 }
 
+// By historical reasons Postgres currently has 8KB send buffer inside, so here we want to have at least the same size
+// buffer.
+// @see https://github.com/postgres/postgres/blob/249d64999615802752940e017ee5166e726bc7cd/src/backend/libpq/pqcomm.c#L134
+// @see https://www.postgresql.org/message-id/0cdc5485-cb3c-5e16-4a46-e3b2f7a41322%40ya.ru
+//
+// In addition, testing has found no benefit of any larger buffer.
+const defaultMinBufSize = 8192
+
 // newChunkReader creates and returns a new chunkReader for r with default configuration. If minBufSize is <= 0 it uses
 // a default value.
 func newChunkReader(r io.Reader, minBufSize int) *chunkReader {
 	if minBufSize <= 0 {
-		// By historical reasons Postgres currently has 8KB send buffer inside,
-		// so here we want to have at least the same size buffer.
-		// @see https://github.com/postgres/postgres/blob/249d64999615802752940e017ee5166e726bc7cd/src/backend/libpq/pqcomm.c#L134
-		// @see https://www.postgresql.org/message-id/0cdc5485-cb3c-5e16-4a46-e3b2f7a41322%40ya.ru
-		//
-		// In addition, testing has found no benefit of any larger buffer.
-		minBufSize = 8192
+		minBufSize = defaultMinBufSize
 	}
 
 	return &chunkReader{
@@ -56,6 +56,9 @@ type Frontend struct {
 	msgType    byte
 	partialMsg bool
 	authType   uint32
+
+	bytesWritten uint64
+	bytesRead    uint64
 }
 
 // NewFrontend creates a new Frontend.
@@ -64,6 +67,21 @@ func NewFrontend(r io.Reader, w io.Writer) *Frontend {
 	return &Frontend{cr: cr, w: w}
 }
 
+// ReadBufferSize returns the minimum size of the buffer used to read messages from the backend.
+func (f *Frontend) ReadBufferSize() int {
+	return f.cr.minBufSize
+}
+
+// SetReadBufferSize sets the minimum size of the buffer used to read messages from the backend. A larger buffer can
+// reduce the number of reads required for large results at the cost of memory. If n <= 0 the default size is used. The
+// new size takes effect the next time the buffer is empty.
+func (f *Frontend) SetReadBufferSize(n int) {
+	if n <= 0 {
+		n = defaultMinBufSize
+	}
+	f.cr.minBufSize = n
+}
+
 // Send sends a message to the backend (i.e. the server). The message is not guaranteed to be written until Flush is
 // called.
 //
@@ -86,6 +104,7 @@ func (f *Frontend) Flush() error {
 	}
 
 	n, err := f.w.Write(f.wbuf)
+	f.bytesWritten += uint64(n)
 
 	const maxLen = 1024
 	if len(f.wbuf) > maxLen {
@@ -101,6 +120,17 @@ func (f *Frontend) Flush() error {
 	return nil
 }
 
+// BytesWritten returns the number of bytes written to the backend (i.e. the server) by Flush and
+// SendUnbufferedEncodedCopyData.
+func (f *Frontend) BytesWritten() uint64 {
+	return f.bytesWritten
+}
+
+// BytesRead returns the number of bytes of the messages received from the backend (i.e. the server) by Receive.
+func (f *Frontend) BytesRead() uint64 {
+	return f.bytesRead
+}
+
 // Trace starts tracing the message traffic to w. It writes in a similar format to that produced by the libpq function
 // PQtrace.
 func (f *Frontend) Trace(w io.Writer, options TracerOptions) {
@@ -196,6 +226,7 @@ func (f *Frontend) SendUnbufferedEncodedCopyData(msg []byte) error {
 	}
 
 	n, err := f.w.Write(msg)
+	f.bytesWritten += uint64(n)
 	if err != nil {
 		return &writeError{err: err, safeToRetry: n == 0}
 	}
@@ -239,6 +270,7 @@ func (f *Frontend) Receive() (BackendMessage, error) {
 	}
 
 	f.partialMsg = false
+	f.bytesRead += uint64(5 + f.bodyLen)
 
 	var msg BackendMessage
 	switch f.msgType {
@@ -1,6 +1,7 @@
 package pgproto3_test
 
 import (
+	"bytes"
 	"io"
 	"testing"
 
@@ -80,6 +81,43 @@ func TestFrontendReceiveUnexpectedEOF(t *testing.T) {
 	assert.Equal(t, io.ErrUnexpectedEOF, err)
 }
 
+func TestFrontendSetReadBufferSize(t *testing.T) {
+	t.Parallel()
+
+	server := &interruptReader{}
+	frontend := pgproto3.NewFrontend(server, nil)
+	defaultSize := frontend.ReadBufferSize()
+
+	frontend.SetReadBufferSize(65536)
+	assert.Equal(t, 65536, frontend.ReadBufferSize())
+
+	server.push([]byte{'Z', 0, 0, 0, 5, 'I'})
+	msg, err := frontend.Receive()
+	require.NoError(t, err)
+	assert.Equal(t, &pgproto3.ReadyForQuery{TxStatus: 'I'}, msg)
+
+	frontend.SetReadBufferSize(0)
+	assert.Equal(t, defaultSize, frontend.ReadBufferSize())
+}
+
+func TestFrontendBytesWrittenAndRead(t *testing.T) {
+	t.Parallel()
+
+	server := &interruptReader{}
+	var w bytes.Buffer
+	frontend := pgproto3.NewFrontend(server, &w)
+
+	frontend.Send(&pgproto3.Sync{})
+	assert.EqualValues(t, 0, frontend.BytesWritten())
+	require.NoError(t, frontend.Flush())
+	assert.EqualValues(t, 5, frontend.BytesWritten())
+
+	server.push([]byte{'Z', 0, 0, 0, 5, 'I'})
+	_, err := frontend.Receive()
+	require.NoError(t, err)
+	assert.EqualValues(t, 6, frontend.BytesRead())
+}
+
 func TestErrorResponse(t *testing.T) {
 	t.Parallel()
 
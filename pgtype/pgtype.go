@@ -1193,6 +1193,13 @@ func (m *Map) PlanScan(oid uint32, formatCode int16, target any) ScanPlan {
 	return plan
 }
 
+// CanScan reports whether a value of type oid in formatCode can be scanned into target. NULL is not considered: a NULL
+// may be scannable into a target that other values of type oid are not.
+func (m *Map) CanScan(oid uint32, formatCode int16, target any) bool {
+	_, failed := m.PlanScan(oid, formatCode, target).(*scanPlanFail)
+	return !failed
+}
+
 func (m *Map) planScan(oid uint32, formatCode int16, target any) ScanPlan {
 	if _, ok := target.(*UndecodedBytes); ok {
 		return scanPlanAnyToUndecodedBytes{}
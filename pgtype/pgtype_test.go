@@ -11,6 +11,7 @@ import (
 	"regexp"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
@@ -302,6 +303,23 @@ func TestMapScanPtrToPtrToSlice(t *testing.T) {
 	require.Equal(t, []string{"foo", "bar"}, *v)
 }
 
+func TestMapCanScan(t *testing.T) {
+	m := pgtype.NewMap()
+
+	var i32 int32
+	var pi32 *int32
+	var s string
+	var b bool
+	var tm time.Time
+
+	require.True(t, m.CanScan(pgtype.Int4OID, pgtype.BinaryFormatCode, &i32))
+	require.True(t, m.CanScan(pgtype.Int4OID, pgtype.BinaryFormatCode, &pi32))
+	require.True(t, m.CanScan(pgtype.Int4OID, pgtype.TextFormatCode, &s))
+	require.True(t, m.CanScan(pgtype.TimestamptzOID, pgtype.BinaryFormatCode, &tm))
+	require.False(t, m.CanScan(pgtype.Int4OID, pgtype.BinaryFormatCode, &b))
+	require.False(t, m.CanScan(pgtype.TimestamptzOID, pgtype.BinaryFormatCode, &i32))
+}
+
 type databaseValuerString string
 
 func (s databaseValuerString) Value() (driver.Value, error) {
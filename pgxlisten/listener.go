@@ -0,0 +1,301 @@
+// Package pgxlisten provides a Listener that receives PostgreSQL notifications on a dedicated connection.
+//
+// A Listener connects, runs LISTEN for each subscribed channel, and calls the handler of the channel for each
+// notification. If the connection is lost it reconnects with exponential backoff and LISTENs on all subscribed channels
+// again. Notifications sent while the Listener is disconnected are not received.
+package pgxlisten
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const (
+	defaultReconnectDelay    = time.Second
+	defaultMaxReconnectDelay = time.Minute
+)
+
+// HandlerFunc handles a notification received on a subscribed channel. conn is the connection of the Listener. It may
+// be used to run queries but must not be closed or used after HandlerFunc returns. Errors are passed to
+// Listener.LogError and do not stop the Listener.
+type HandlerFunc func(ctx context.Context, notification *pgconn.Notification, conn *pgx.Conn) error
+
+// Health is a snapshot of the state of a Listener.
+type Health struct {
+	// Listening is true if the Listener is connected and listening on all subscribed channels.
+	Listening bool
+
+	// Reconnects is the number of times the Listener has connected after the first connection attempt.
+	Reconnects int64
+
+	// Notifications is the number of notifications received.
+	Notifications int64
+
+	// LastError is the last error that stopped the connection of the Listener or occurred while connecting. It is nil if
+	// no such error has occurred.
+	LastError error
+
+	// LastErrorTime is the time LastError occurred.
+	LastErrorTime time.Time
+
+	// LastNotificationTime is the time the last notification was received.
+	LastNotificationTime time.Time
+}
+
+// Listener listens for notifications on a dedicated connection and dispatches them to the handlers of the subscribed
+// channels. Connect must be set before Listen is called. Subscribe and Unsubscribe may be called at any time, including
+// while Listen is running.
+type Listener struct {
+	// Connect establishes the connection the Listener uses. It is called again to reconnect when the connection is lost.
+	// The connection is closed by the Listener. Required.
+	Connect func(ctx context.Context) (*pgx.Conn, error)
+
+	// LogError is called with errors that do not stop the Listener, such as connection failures and errors returned by
+	// handlers. Optional.
+	LogError func(ctx context.Context, err error)
+
+	// ReconnectDelay is the delay before the first reconnect attempt after the connection is lost or fails to connect.
+	// The delay doubles after each consecutive failed attempt up to MaxReconnectDelay. Defaults to 1 second.
+	ReconnectDelay time.Duration
+
+	// MaxReconnectDelay is the maximum delay between reconnect attempts. Defaults to 1 minute.
+	MaxReconnectDelay time.Duration
+
+	mu       sync.Mutex
+	handlers map[string]HandlerFunc
+	changed  chan struct{}
+	health   Health
+}
+
+// Subscribe calls handler for each notification received on channel. It replaces the handler of channel if there is
+// one. If Listen is running, the Listener starts listening on channel without reconnecting.
+func (l *Listener) Subscribe(channel string, handler HandlerFunc) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.handlers == nil {
+		l.handlers = make(map[string]HandlerFunc)
+	}
+	l.handlers[channel] = handler
+	l.notifyChanged()
+}
+
+// Unsubscribe stops listening on channel and removes its handler.
+func (l *Listener) Unsubscribe(channel string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.handlers, channel)
+	l.notifyChanged()
+}
+
+// Health returns a snapshot of the state of l.
+func (l *Listener) Health() Health {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.health
+}
+
+// Listen connects and dispatches notifications to the handlers of the subscribed channels until ctx is canceled. If the
+// connection is lost or cannot be established, the error is passed to LogError and Listen reconnects after a delay.
+// Listen always returns a non-nil error: ctx.Err() when ctx is canceled, or an error if l is not configured correctly.
+// Listen must not be called concurrently on the same Listener.
+func (l *Listener) Listen(ctx context.Context) error {
+	if l.Connect == nil {
+		return errors.New("pgxlisten: Connect is nil")
+	}
+
+	l.mu.Lock()
+	if l.changed == nil {
+		l.changed = make(chan struct{}, 1)
+	}
+	l.mu.Unlock()
+
+	reconnectDelay := l.ReconnectDelay
+	if reconnectDelay <= 0 {
+		reconnectDelay = defaultReconnectDelay
+	}
+	maxReconnectDelay := l.MaxReconnectDelay
+	if maxReconnectDelay <= 0 {
+		maxReconnectDelay = defaultMaxReconnectDelay
+	}
+
+	delay := reconnectDelay
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 {
+			l.mu.Lock()
+			l.health.Reconnects++
+			l.mu.Unlock()
+		}
+
+		connected, err := l.listen(ctx)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		l.mu.Lock()
+		l.health.Listening = false
+		l.health.LastError = err
+		l.health.LastErrorTime = time.Now()
+		l.mu.Unlock()
+		l.logError(ctx, err)
+
+		// The backoff only grows while attempts keep failing to connect.
+		if connected {
+			delay = reconnectDelay
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+
+		delay *= 2
+		if delay > maxReconnectDelay {
+			delay = maxReconnectDelay
+		}
+	}
+}
+
+// listen connects and dispatches notifications until an error occurs. connected reports whether the connection was
+// established.
+func (l *Listener) listen(ctx context.Context) (connected bool, err error) {
+	conn, err := l.Connect(ctx)
+	if err != nil {
+		return false, fmt.Errorf("pgxlisten: connect: %w", err)
+	}
+	defer func() {
+		closeCtx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancel()
+		conn.Close(closeCtx)
+	}()
+
+	listening := make(map[string]struct{})
+	for {
+		err := l.syncChannels(ctx, conn, listening)
+		if err != nil {
+			return true, err
+		}
+
+		notification, err := l.waitForNotification(ctx, conn)
+		if notification != nil {
+			l.dispatch(ctx, notification, conn)
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return true, ctx.Err()
+			}
+			// WaitForNotification was interrupted because the subscribed channels changed.
+			if (errors.Is(err, context.Canceled) || pgconn.Timeout(err)) && !conn.IsClosed() {
+				continue
+			}
+			return true, fmt.Errorf("pgxlisten: wait for notification: %w", err)
+		}
+	}
+}
+
+// syncChannels runs LISTEN and UNLISTEN on conn so that the channels in listening match the subscribed channels.
+func (l *Listener) syncChannels(ctx context.Context, conn *pgx.Conn, listening map[string]struct{}) error {
+	l.mu.Lock()
+	var listen, unlisten []string
+	for channel := range l.handlers {
+		if _, ok := listening[channel]; !ok {
+			listen = append(listen, channel)
+		}
+	}
+	for channel := range listening {
+		if _, ok := l.handlers[channel]; !ok {
+			unlisten = append(unlisten, channel)
+		}
+	}
+	l.mu.Unlock()
+
+	for _, channel := range listen {
+		_, err := conn.Exec(ctx, "listen "+pgx.Identifier{channel}.Sanitize())
+		if err != nil {
+			return fmt.Errorf("pgxlisten: listen %s: %w", channel, err)
+		}
+		listening[channel] = struct{}{}
+	}
+
+	for _, channel := range unlisten {
+		_, err := conn.Exec(ctx, "unlisten "+pgx.Identifier{channel}.Sanitize())
+		if err != nil {
+			return fmt.Errorf("pgxlisten: unlisten %s: %w", channel, err)
+		}
+		delete(listening, channel)
+	}
+
+	l.mu.Lock()
+	l.health.Listening = true
+	l.mu.Unlock()
+
+	return nil
+}
+
+// waitForNotification waits for a notification on conn. It is interrupted when the subscribed channels change so they
+// can be synced.
+func (l *Listener) waitForNotification(ctx context.Context, conn *pgx.Conn) (*pgconn.Notification, error) {
+	waitCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		select {
+		case <-l.changed:
+			cancel()
+		case <-done:
+		}
+	}()
+
+	return conn.WaitForNotification(waitCtx)
+}
+
+// dispatch calls the handler of the channel of notification. Notifications on channels that are no longer subscribed
+// are dropped.
+func (l *Listener) dispatch(ctx context.Context, notification *pgconn.Notification, conn *pgx.Conn) {
+	l.mu.Lock()
+	handler := l.handlers[notification.Channel]
+	l.health.Notifications++
+	l.health.LastNotificationTime = time.Now()
+	l.mu.Unlock()
+
+	if handler == nil {
+		return
+	}
+
+	err := handler(ctx, notification, conn)
+	if err != nil {
+		l.logError(ctx, fmt.Errorf("pgxlisten: handle notification on %s: %w", notification.Channel, err))
+	}
+}
+
+// notifyChanged signals a running Listen that the subscribed channels changed. l.mu must be held.
+func (l *Listener) notifyChanged() {
+	if l.changed == nil {
+		l.changed = make(chan struct{}, 1)
+	}
+
+	select {
+	case l.changed <- struct{}{}:
+	default:
+	}
+}
+
+func (l *Listener) logError(ctx context.Context, err error) {
+	if l.LogError != nil {
+		l.LogError(ctx, err)
+	}
+}
@@ -0,0 +1,217 @@
+package pgxlisten_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxlisten"
+	"github.com/stretchr/testify/require"
+)
+
+func connect(ctx context.Context) (*pgx.Conn, error) {
+	return pgx.Connect(ctx, os.Getenv("PGX_TEST_DATABASE"))
+}
+
+func mustConnect(t *testing.T) *pgx.Conn {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	conn, err := connect(ctx)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close(context.Background()) })
+
+	if conn.PgConn().ParameterStatus("crdb_version") != "" {
+		t.Skip("Server does not support LISTEN / NOTIFY (https://github.com/cockroachdb/cockroach/issues/41522)")
+	}
+
+	return conn
+}
+
+func startListener(t *testing.T, listener *pgxlisten.Listener) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- listener.Listen(ctx) }()
+
+	t.Cleanup(func() {
+		cancel()
+		require.ErrorIs(t, <-done, context.Canceled)
+	})
+
+	require.Eventually(t, func() bool { return listener.Health().Listening }, 30*time.Second, 10*time.Millisecond)
+}
+
+func receiveNotification(t *testing.T, notifications chan *pgconn.Notification) *pgconn.Notification {
+	select {
+	case n := <-notifications:
+		return n
+	case <-time.After(30 * time.Second):
+		t.Fatal("timed out waiting for notification")
+		return nil
+	}
+}
+
+func TestListenerRequiresConnect(t *testing.T) {
+	t.Parallel()
+
+	listener := &pgxlisten.Listener{}
+	require.Error(t, listener.Listen(context.Background()))
+}
+
+func TestListenerReconnectsWithBackoff(t *testing.T) {
+	t.Parallel()
+
+	connectErr := errors.New("connect failed")
+	var mu sync.Mutex
+	var attempts []time.Time
+
+	listener := &pgxlisten.Listener{
+		Connect: func(ctx context.Context) (*pgx.Conn, error) {
+			mu.Lock()
+			attempts = append(attempts, time.Now())
+			mu.Unlock()
+			return nil, connectErr
+		},
+		ReconnectDelay:    10 * time.Millisecond,
+		MaxReconnectDelay: 40 * time.Millisecond,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	err := listener.Listen(ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.GreaterOrEqual(t, len(attempts), 4)
+	require.Less(t, len(attempts), 15)
+	for i := 1; i < len(attempts); i++ {
+		require.GreaterOrEqual(t, attempts[i].Sub(attempts[i-1]), 10*time.Millisecond)
+	}
+
+	health := listener.Health()
+	require.False(t, health.Listening)
+	require.ErrorIs(t, health.LastError, connectErr)
+	require.EqualValues(t, len(attempts)-1, health.Reconnects)
+}
+
+func TestListenerSubscribe(t *testing.T) {
+	t.Parallel()
+
+	conn := mustConnect(t)
+
+	notifications := make(chan *pgconn.Notification, 10)
+	listener := &pgxlisten.Listener{Connect: connect}
+	listener.Subscribe("pgxlisten_a", func(ctx context.Context, n *pgconn.Notification, conn *pgx.Conn) error {
+		notifications <- n
+		return nil
+	})
+	startListener(t, listener)
+
+	_, err := conn.Exec(context.Background(), "select pg_notify('pgxlisten_a', 'hello')")
+	require.NoError(t, err)
+
+	n := receiveNotification(t, notifications)
+	require.Equal(t, "pgxlisten_a", n.Channel)
+	require.Equal(t, "hello", n.Payload)
+
+	// Subscribing while listening does not require a reconnect.
+	listener.Subscribe("pgxlisten_B", func(ctx context.Context, n *pgconn.Notification, conn *pgx.Conn) error {
+		notifications <- n
+		return nil
+	})
+	require.Eventually(t, func() bool {
+		_, err := conn.Exec(context.Background(), "select pg_notify('pgxlisten_B', 'world')")
+		require.NoError(t, err)
+		select {
+		case n := <-notifications:
+			require.Equal(t, "pgxlisten_B", n.Channel)
+			require.Equal(t, "world", n.Payload)
+			return true
+		case <-time.After(50 * time.Millisecond):
+			return false
+		}
+	}, 30*time.Second, 10*time.Millisecond)
+
+	require.EqualValues(t, 0, listener.Health().Reconnects)
+}
+
+func TestListenerReconnects(t *testing.T) {
+	t.Parallel()
+
+	conn := mustConnect(t)
+
+	var pidMu sync.Mutex
+	var pid uint32
+	notifications := make(chan *pgconn.Notification, 10)
+	listener := &pgxlisten.Listener{
+		Connect: func(ctx context.Context) (*pgx.Conn, error) {
+			listenConn, err := connect(ctx)
+			if err == nil {
+				pidMu.Lock()
+				pid = listenConn.PgConn().PID()
+				pidMu.Unlock()
+			}
+			return listenConn, err
+		},
+		ReconnectDelay: 10 * time.Millisecond,
+	}
+	listener.Subscribe("pgxlisten_reconnect", func(ctx context.Context, n *pgconn.Notification, conn *pgx.Conn) error {
+		notifications <- n
+		return nil
+	})
+	startListener(t, listener)
+
+	pidMu.Lock()
+	oldPID := pid
+	pidMu.Unlock()
+	_, err := conn.Exec(context.Background(), "select pg_terminate_backend($1)", oldPID)
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		health := listener.Health()
+		return health.Reconnects > 0 && health.Listening
+	}, 30*time.Second, 10*time.Millisecond)
+	require.Error(t, listener.Health().LastError)
+
+	_, err = conn.Exec(context.Background(), "select pg_notify('pgxlisten_reconnect', 'again')")
+	require.NoError(t, err)
+
+	n := receiveNotification(t, notifications)
+	require.Equal(t, "again", n.Payload)
+}
+
+func TestListenerHandlerErrorsAreLogged(t *testing.T) {
+	t.Parallel()
+
+	conn := mustConnect(t)
+
+	handlerErr := errors.New("handler failed")
+	logged := make(chan error, 10)
+	listener := &pgxlisten.Listener{
+		Connect:  connect,
+		LogError: func(ctx context.Context, err error) { logged <- err },
+	}
+	listener.Subscribe("pgxlisten_error", func(ctx context.Context, n *pgconn.Notification, conn *pgx.Conn) error {
+		return handlerErr
+	})
+	startListener(t, listener)
+
+	_, err := conn.Exec(context.Background(), "select pg_notify('pgxlisten_error', '')")
+	require.NoError(t, err)
+
+	select {
+	case err := <-logged:
+		require.ErrorIs(t, err, handlerErr)
+	case <-time.After(30 * time.Second):
+		t.Fatal("timed out waiting for error")
+	}
+
+	require.True(t, listener.Health().Listening)
+	require.EqualValues(t, 1, listener.Health().Notifications)
+}
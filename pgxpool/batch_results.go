@@ -7,12 +7,17 @@ import (
 
 type errBatchResults struct {
 	err error
+	b   *pgx.Batch // the batch that could not be sent
 }
 
 func (br errBatchResults) Exec() (pgconn.CommandTag, error) {
 	return pgconn.CommandTag{}, br.err
 }
 
+func (br errBatchResults) ExecSelect() (int64, error) {
+	return 0, br.err
+}
+
 func (br errBatchResults) Query() (pgx.Rows, error) {
 	return errRows{err: br.err}, br.err
 }
@@ -25,6 +30,73 @@ func (br errBatchResults) Close() error {
 	return br.err
 }
 
+func (br errBatchResults) InTransaction() bool {
+	return false
+}
+
+func (br errBatchResults) VerifyComplete() error {
+	return br.err
+}
+
+func (br errBatchResults) DiscardNext() error {
+	return br.err
+}
+
+func (br errBatchResults) Skip(n int) error {
+	return br.err
+}
+
+func (br errBatchResults) AllFieldDescriptions() map[int][]pgconn.FieldDescription {
+	return nil
+}
+
+func (br errBatchResults) ExecAt(index int) (pgconn.CommandTag, error) {
+	return pgconn.CommandTag{}, br.err
+}
+
+func (br errBatchResults) QueryAt(index int) (pgx.Rows, error) {
+	return errRows{err: br.err}, br.err
+}
+
+func (br errBatchResults) Snapshots() ([]pgx.BatchResultSnapshot, error) {
+	return nil, br.err
+}
+
+func (br errBatchResults) AsRows() pgx.Rows {
+	return errRows{err: br.err}
+}
+
+func (br errBatchResults) QueryProject(cols []string) (pgx.Rows, error) {
+	return errRows{err: br.err}, br.err
+}
+
+func (br errBatchResults) CurrentTag() any {
+	return nil
+}
+
+func (br errBatchResults) All() ([]pgx.BatchItemResult, error) {
+	return nil, br.err
+}
+
+func (br errBatchResults) Stats() pgx.BatchStats {
+	return pgx.BatchStats{}
+}
+
+func (br errBatchResults) Items() func(yield func(*pgx.BatchItem) bool) {
+	return func(yield func(*pgx.BatchItem) bool) {}
+}
+
+func (br errBatchResults) ItemNotices(index int) []pgconn.Notice {
+	return nil
+}
+
+func (br errBatchResults) FailedItems() *pgx.Batch {
+	if br.b == nil {
+		return &pgx.Batch{}
+	}
+	return br.b.Clone()
+}
+
 type poolBatchResults struct {
 	br pgx.BatchResults
 	c  *Conn
@@ -42,6 +114,25 @@ func (br *poolBatchResults) QueryRow() pgx.Row {
 	return br.br.QueryRow()
 }
 
+// Unwrap returns the results of the connection so functions such as pgx.BatchResultsStats can read them.
+func (br *poolBatchResults) Unwrap() pgx.BatchResults {
+	return br.br
+}
+
+func (br *poolBatchResults) Items() func(yield func(*pgx.BatchItem) bool) {
+	items := pgx.BatchItems(br.br)
+	return func(yield func(*pgx.BatchItem) bool) {
+		defer br.Close()
+		items(yield)
+	}
+}
+
+func (br *poolBatchResults) All() ([]pgx.BatchItemResult, error) {
+	results, err := pgx.BatchAll(br.br)
+	br.Close()
+	return results, err
+}
+
 func (br *poolBatchResults) Close() error {
 	err := br.br.Close()
 	if br.c != nil {
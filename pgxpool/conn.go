@@ -3,6 +3,7 @@ package pgxpool
 import (
 	"context"
 	"sync/atomic"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
@@ -46,6 +47,28 @@ func (c *Conn) Release() {
 		return
 	}
 
+	// Run-time parameters changed with SetSessionVar are restored and advisory locks are released so they do not leak to
+	// the next user of the connection. The connection is destroyed if that fails.
+	if conn.SessionVarsChanged() || conn.HoldsAdvisoryLocks() {
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+			err := conn.ResetSessionVars(ctx)
+			if err == nil && conn.HoldsAdvisoryLocks() {
+				err = conn.UnlockAllAdvisoryLocks(ctx)
+			}
+			cancel()
+			if err == nil && (c.p.afterRelease == nil || c.p.afterRelease(conn)) {
+				res.Release()
+			} else {
+				res.Destroy()
+				// Signal to the health check to run since we just destroyed a connections
+				// and we might be below minConns now
+				c.p.triggerHealthCheck()
+			}
+		}()
+		return
+	}
+
 	if c.p.afterRelease == nil {
 		res.Release()
 		return
@@ -91,6 +114,47 @@ func (c *Conn) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
 	return c.Conn().QueryRow(ctx, sql, args...)
 }
 
+func (c *Conn) Call(ctx context.Context, name pgx.Identifier, args ...any) error {
+	return c.Conn().Call(ctx, name, args...)
+}
+
+func (c *Conn) QueryMulti(ctx context.Context, sql string, args ...any) (*pgx.MultiRows, error) {
+	return c.Conn().QueryMulti(ctx, sql, args...)
+}
+
+func (c *Conn) ExecMulti(ctx context.Context, sql string, args ...any) func(yield func(pgx.StatementResult, error) bool) {
+	return c.Conn().ExecMulti(ctx, sql, args...)
+}
+
+func (c *Conn) FetchRefCursor(ctx context.Context, refcursor string) (pgx.Rows, error) {
+	return c.Conn().FetchRefCursor(ctx, refcursor)
+}
+
+func (c *Conn) AdvisoryLock(ctx context.Context, key int64) (*pgx.AdvisoryLock, error) {
+	return c.Conn().AdvisoryLock(ctx, key)
+}
+
+func (c *Conn) TryAdvisoryLock(ctx context.Context, key int64) (*pgx.AdvisoryLock, bool, error) {
+	return c.Conn().TryAdvisoryLock(ctx, key)
+}
+
+// SetSessionVar sets a run-time parameter for the rest of the session. The parameter is restored when c is released.
+func (c *Conn) SetSessionVar(ctx context.Context, name string, value any) error {
+	return c.Conn().SetSessionVar(ctx, name, value)
+}
+
+func (c *Conn) SetLocalVar(ctx context.Context, name string, value any) error {
+	return c.Conn().SetLocalVar(ctx, name, value)
+}
+
+func (c *Conn) GetVar(ctx context.Context, name string, dest any) error {
+	return c.Conn().GetVar(ctx, name, dest)
+}
+
+func (c *Conn) Cursor(ctx context.Context, fetchSize int, sql string, args ...any) (*pgx.Cursor, error) {
+	return c.Conn().Cursor(ctx, fetchSize, sql, args...)
+}
+
 func (c *Conn) SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults {
 	return c.Conn().SendBatch(ctx, b)
 }
@@ -113,6 +177,14 @@ func (c *Conn) Ping(ctx context.Context) error {
 	return c.Conn().Ping(ctx)
 }
 
+func (c *Conn) PingStats(ctx context.Context) (pgx.PingStats, error) {
+	return c.Conn().PingStats(ctx)
+}
+
+func (c *Conn) Notify(ctx context.Context, channel string, payload any) error {
+	return c.Conn().Notify(ctx, channel, payload)
+}
+
 func (c *Conn) Conn() *pgx.Conn {
 	return c.connResource().conn
 }
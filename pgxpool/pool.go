@@ -568,6 +568,21 @@ func (p *Pool) Stat() *Stat {
 // Arguments should be referenced positionally from the SQL string as $1, $2, etc.
 // The acquired connection is returned to the pool when the Exec function returns.
 func (p *Pool) Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error) {
+	if policy := p.retryPolicyFor(arguments); policy != nil {
+		var commandTag pgconn.CommandTag
+		err := policy.Retry(ctx, func() (bool, error) {
+			c, err := p.Acquire(ctx)
+			if err != nil {
+				return true, err
+			}
+			defer c.Release()
+
+			commandTag, err = c.Exec(ctx, sql, arguments...)
+			return err != nil && c.Conn().IsClosed(), err
+		})
+		return commandTag, err
+	}
+
 	c, err := p.Acquire(ctx)
 	if err != nil {
 		return pgconn.CommandTag{}, err
@@ -577,6 +592,17 @@ func (p *Pool) Exec(ctx context.Context, sql string, arguments ...any) (pgconn.C
 	return c.Exec(ctx, sql, arguments...)
 }
 
+// Call acquires a connection and calls a stored procedure. See pgx.Conn.Call.
+func (p *Pool) Call(ctx context.Context, name pgx.Identifier, args ...any) error {
+	c, err := p.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer c.Release()
+
+	return c.Call(ctx, name, args...)
+}
+
 // Query acquires a connection and executes a query that returns pgx.Rows.
 // Arguments should be referenced positionally from the SQL string as $1, $2, etc.
 // See pgx.Rows documentation to close the returned Rows and return the acquired connection to the Pool.
@@ -588,6 +614,32 @@ func (p *Pool) Exec(ctx context.Context, sql string, arguments ...any) (pgconn.C
 // QueryResultFormatsByOID may be used as the first args to control exactly how the query is executed. This is rarely
 // needed. See the documentation for those types for details.
 func (p *Pool) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	if policy := p.retryPolicyFor(args); policy != nil {
+		var rows pgx.Rows
+		err := policy.Retry(ctx, func() (bool, error) {
+			c, err := p.Acquire(ctx)
+			if err != nil {
+				return true, err
+			}
+
+			// pgx.Conn.Query reads the first row of an idempotent query so an error that occurs before any rows are
+			// returned is reported here.
+			r, err := c.Query(ctx, sql, args...)
+			if err != nil {
+				connLost := c.Conn().IsClosed()
+				c.Release()
+				return connLost, err
+			}
+
+			rows = c.getPoolRows(r)
+			return false, nil
+		})
+		if err != nil {
+			return errRows{err: err}, err
+		}
+		return rows, nil
+	}
+
 	c, err := p.Acquire(ctx)
 	if err != nil {
 		return errRows{err: err}, err
@@ -615,6 +667,11 @@ func (p *Pool) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, er
 // QueryResultFormatsByOID may be used as the first args to control exactly how the query is executed. This is rarely
 // needed. See the documentation for those types for details.
 func (p *Pool) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	if p.retryPolicyFor(args) != nil {
+		rows, _ := p.Query(ctx, sql, args...)
+		return &rowsRow{rows: rows}
+	}
+
 	c, err := p.Acquire(ctx)
 	if err != nil {
 		return errRow{err: err}
@@ -625,10 +682,12 @@ func (p *Pool) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
 }
 
 func (p *Pool) SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults {
+	acquireStart := time.Now()
 	c, err := p.Acquire(ctx)
 	if err != nil {
-		return errBatchResults{err: err}
+		return errBatchResults{err: err, b: b}
 	}
+	ctx = pgx.ContextWithBatchAcquireDuration(ctx, time.Since(acquireStart))
 
 	br := c.SendBatch(ctx, b)
 	return &poolBatchResults{br: br, c: c}
@@ -681,3 +740,37 @@ func (p *Pool) Ping(ctx context.Context) error {
 	defer c.Release()
 	return c.Ping(ctx)
 }
+
+// PingStats acquires a connection from the Pool and pings it. See pgx.Conn.PingStats.
+func (p *Pool) PingStats(ctx context.Context) (pgx.PingStats, error) {
+	c, err := p.Acquire(ctx)
+	if err != nil {
+		return pgx.PingStats{}, err
+	}
+	defer c.Release()
+	return c.PingStats(ctx)
+}
+
+// Notify acquires a connection from the Pool and sends a notification on channel with payload. See pgx.Conn.Notify.
+func (p *Pool) Notify(ctx context.Context, channel string, payload any) error {
+	c, err := p.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer c.Release()
+	return c.Notify(ctx, channel, payload)
+}
+
+// retryPolicyFor returns the retry policy for a query with args. It is nil if the query is not retried. The pool
+// retries a query on another connection when the connection it ran on was lost. Other retryable errors are retried by
+// the connection itself.
+func (p *Pool) retryPolicyFor(args []any) *pgx.RetryPolicy {
+	policy := p.config.ConnConfig.RetryPolicy
+	if policy == nil || len(args) == 0 {
+		return nil
+	}
+	if opts, ok := args[0].(pgx.QueryOptions); ok && opts.Idempotent {
+		return policy
+	}
+	return nil
+}
@@ -621,6 +621,73 @@ func TestPoolSendBatch(t *testing.T) {
 	assert.EqualValues(t, 1, stats.TotalConns())
 }
 
+func TestPoolSendBatchAcquireErrorIsNotComplete(t *testing.T) {
+	t.Parallel()
+
+	pool, err := pgxpool.New(context.Background(), os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	pool.Close()
+
+	batch := &pgx.Batch{}
+	batch.Queue("select 1")
+	br := pool.SendBatch(context.Background(), batch)
+	require.Error(t, pgx.VerifyBatchComplete(br))
+	require.Error(t, br.Close())
+}
+
+type acquireDurationTracer struct {
+	acquireDurations chan time.Duration
+}
+
+func (tt *acquireDurationTracer) TraceQueryStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	return ctx
+}
+
+func (tt *acquireDurationTracer) TraceQueryEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryEndData) {
+}
+
+func (tt *acquireDurationTracer) TraceBatchStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceBatchStartData) context.Context {
+	tt.acquireDurations <- data.AcquireDuration
+	return ctx
+}
+
+func (tt *acquireDurationTracer) TraceBatchQuery(ctx context.Context, conn *pgx.Conn, data pgx.TraceBatchQueryData) {
+}
+
+func (tt *acquireDurationTracer) TraceBatchEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceBatchEndData) {
+}
+
+func TestPoolSendBatchTracesAcquireDuration(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	config, err := pgxpool.ParseConfig(os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	tracer := &acquireDurationTracer{acquireDurations: make(chan time.Duration, 1)}
+	config.ConnConfig.Tracer = tracer
+	config.MaxConns = 1
+
+	pool, err := pgxpool.NewWithConfig(ctx, config)
+	require.NoError(t, err)
+	defer pool.Close()
+
+	c, err := pool.Acquire(ctx)
+	require.NoError(t, err)
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		c.Release()
+	}()
+
+	batch := &pgx.Batch{}
+	batch.Queue("select 1")
+	err = pool.SendBatch(ctx, batch).Close()
+	require.NoError(t, err)
+
+	require.GreaterOrEqual(t, <-tracer.acquireDurations, 100*time.Millisecond)
+}
+
 func TestPoolCopyFrom(t *testing.T) {
 	// Not able to use testCopyFrom because it relies on temporary tables and the pool may run subsequent calls under
 	// different connections.
@@ -1028,3 +1095,186 @@ func TestPoolSendBatchBatchCloseTwice(t *testing.T) {
 		assert.NoError(t, err)
 	}
 }
+
+func TestPoolQueryAllAndQueryOne(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	pool, err := pgxpool.New(ctx, os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	defer pool.Close()
+
+	type point struct {
+		X int32
+		Y int32
+	}
+
+	points, err := pgx.QueryAll[point](ctx, pool, `select n as x, n * 2 as y from generate_series(1, 3) n`)
+	require.NoError(t, err)
+	require.Equal(t, []point{{1, 2}, {2, 4}, {3, 6}}, points)
+
+	p, err := pgx.QueryOne[point](ctx, pool, `select 1 as x, $1::int4 as y`, 5)
+	require.NoError(t, err)
+	require.Equal(t, point{1, 5}, p)
+
+	waitForReleaseToComplete()
+	require.EqualValues(t, 0, pool.Stat().AcquiredConns())
+}
+
+func TestConnReleaseUnlocksAdvisoryLocks(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	config, err := pgxpool.ParseConfig(os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	config.MaxConns = 1
+
+	pool, err := pgxpool.NewWithConfig(ctx, config)
+	require.NoError(t, err)
+	defer pool.Close()
+
+	c, err := pool.Acquire(ctx)
+	require.NoError(t, err)
+	if c.Conn().PgConn().ParameterStatus("crdb_version") != "" {
+		c.Release()
+		t.Skip("Server does not support advisory locks")
+	}
+
+	key := pgx.AdvisoryLockKey(t.Name())
+	_, err = c.AdvisoryLock(ctx, key)
+	require.NoError(t, err)
+	c.Release()
+
+	waitForReleaseToComplete()
+	require.EqualValues(t, 1, pool.Stat().TotalConns())
+
+	c, err = pool.Acquire(ctx)
+	require.NoError(t, err)
+	defer c.Release()
+	require.False(t, c.Conn().HoldsAdvisoryLocks())
+
+	var held int64
+	err = c.QueryRow(ctx, "select count(*) from pg_locks where locktype = 'advisory' and pid = pg_backend_pid()").Scan(&held)
+	require.NoError(t, err)
+	require.EqualValues(t, 0, held)
+
+	lock, err := c.AdvisoryLock(ctx, key)
+	require.NoError(t, err)
+	require.NoError(t, lock.Unlock(ctx))
+}
+
+func TestConnReleaseRestoresSessionVars(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	config, err := pgxpool.ParseConfig(os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	config.MaxConns = 1
+
+	pool, err := pgxpool.NewWithConfig(ctx, config)
+	require.NoError(t, err)
+	defer pool.Close()
+
+	c, err := pool.Acquire(ctx)
+	require.NoError(t, err)
+	var original string
+	require.NoError(t, c.GetVar(ctx, "application_name", &original))
+	require.NoError(t, c.SetSessionVar(ctx, "application_name", "pgxpool_session_var"))
+	require.NoError(t, c.SetSessionVar(ctx, "pgx.test_var", "foo"))
+	c.Release()
+
+	waitForReleaseToComplete()
+
+	c, err = pool.Acquire(ctx)
+	require.NoError(t, err)
+	defer c.Release()
+
+	var applicationName string
+	require.NoError(t, c.GetVar(ctx, "application_name", &applicationName))
+	require.Equal(t, original, applicationName)
+
+	var testVar string
+	require.NoError(t, c.QueryRow(ctx, "select coalesce(current_setting('pgx.test_var', true), '')").Scan(&testVar))
+	require.Equal(t, "", testVar)
+	require.False(t, c.Conn().SessionVarsChanged())
+}
+
+func TestPoolNotify(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	pool, err := pgxpool.New(ctx, os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	defer pool.Close()
+
+	listener, err := pool.Acquire(ctx)
+	require.NoError(t, err)
+	defer listener.Release()
+	pgxtest.SkipCockroachDB(t, listener.Conn(), "Server does not support LISTEN / NOTIFY (https://github.com/cockroachdb/cockroach/issues/41522)")
+
+	_, err = listener.Exec(ctx, "listen pool_notify")
+	require.NoError(t, err)
+
+	require.NoError(t, pool.Notify(ctx, "pool_notify", map[string]int{"id": 1}))
+
+	notification, err := listener.Conn().WaitForNotification(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "pool_notify", notification.Channel)
+	require.JSONEq(t, `{"id": 1}`, notification.Payload)
+
+	// Stop listening so the LISTEN does not leak to the next user of the connection.
+	_, err = listener.Exec(ctx, "unlisten pool_notify")
+	require.NoError(t, err)
+}
+
+func TestPoolRetryIdempotentQueryOnNewConnection(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	config, err := pgxpool.ParseConfig(os.Getenv("PGX_TEST_DATABASE"))
+	require.NoError(t, err)
+	config.ConnConfig.RetryPolicy = &pgx.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond}
+	config.MaxConns = 1
+
+	pool, err := pgxpool.NewWithConfig(ctx, config)
+	require.NoError(t, err)
+	defer pool.Close()
+
+	c, err := pool.Acquire(ctx)
+	require.NoError(t, err)
+	if c.Conn().PgConn().ParameterStatus("crdb_version") != "" {
+		c.Release()
+		t.Skip("Server does not support pg_terminate_backend() (https://github.com/cockroachdb/cockroach/issues/35897)")
+	}
+	c.Release()
+
+	// The query fails each time because it terminates its own connection. The pool retries it on a new connection.
+	_, err = pool.Exec(ctx, "select pg_terminate_backend(pg_backend_pid())", pgx.QueryOptions{Idempotent: true})
+	require.Error(t, err)
+	require.True(t, pgx.IsRetryableError(err))
+
+	waitForReleaseToComplete()
+	require.EqualValues(t, 3, pool.Stat().NewConnsCount())
+
+	// A query that is not idempotent is not retried.
+	_, err = pool.Exec(ctx, "select pg_terminate_backend(pg_backend_pid())")
+	require.Error(t, err)
+
+	waitForReleaseToComplete()
+	require.EqualValues(t, 4, pool.Stat().NewConnsCount())
+
+	var n int32
+	err = pool.QueryRow(ctx, "select 1", pgx.QueryOptions{Idempotent: true}).Scan(&n)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, n)
+}
@@ -25,6 +25,18 @@ type errRow struct {
 
 func (e errRow) Scan(dest ...any) error { return e.err }
 
+// rowsRow implements pgx.Row for the rows of a query retried by Pool.QueryRow.
+type rowsRow struct {
+	rows pgx.Rows
+}
+
+func (r *rowsRow) Scan(dest ...any) error {
+	_, err := pgx.CollectOneRow(r.rows, func(row pgx.CollectableRow) (struct{}, error) {
+		return struct{}{}, row.Scan(dest...)
+	})
+	return err
+}
+
 type poolRows struct {
 	r   pgx.Rows
 	c   *Conn
@@ -90,6 +102,19 @@ func (rows *poolRows) Conn() *pgx.Conn {
 	return rows.r.Conn()
 }
 
+func (rows *poolRows) Notices() []pgconn.Notice {
+	return pgx.RowsNotices(rows.r)
+}
+
+// QuerySQL returns the SQL and the number of arguments of the query. It is used by pgx to report the query with
+// ConnConfig.ContextualErrNoRows.
+func (rows *poolRows) QuerySQL() (sql string, argCount int) {
+	if qr, ok := rows.r.(interface{ QuerySQL() (string, int) }); ok {
+		return qr.QuerySQL()
+	}
+	return "", 0
+}
+
 type poolRow struct {
 	r   pgx.Row
 	c   *Conn
@@ -0,0 +1,60 @@
+package pgx
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// ExecFunc runs a query as Conn.Exec does. It is the next step of the chain passed to QueryInterceptor.InterceptExec.
+type ExecFunc func(ctx context.Context, sql string, args []any) (pgconn.CommandTag, error)
+
+// QueryFunc runs a query as Conn.Query does. It is the next step of the chain passed to
+// QueryInterceptor.InterceptQuery.
+type QueryFunc func(ctx context.Context, sql string, args []any) (Rows, error)
+
+// QueryInterceptor is middleware for queries run with Conn.Exec, Conn.Query, and Conn.QueryRow, including those run
+// through a Tx or a *pgxpool.Pool. Unlike a QueryTracer, which can only observe queries, an interceptor controls how
+// the query is run: it can rewrite sql or args before calling next, not call next at all and return its own result, or
+// change the result or error returned by next. This makes it possible to implement e.g. read-only guards, tenancy
+// filters, and result caches. Interceptors are registered with ConnConfig.QueryInterceptors.
+//
+// args are the arguments as passed to Exec or Query, including any QueryExecMode, QueryOptions, or QueryRewriter at the
+// start. Interceptors run before the query is traced and retried, so next runs every attempt of the query.
+type QueryInterceptor interface {
+	// InterceptExec is called for each call of Conn.Exec. It must call next to run the query on conn.
+	InterceptExec(ctx context.Context, conn *Conn, sql string, args []any, next ExecFunc) (pgconn.CommandTag, error)
+
+	// InterceptQuery is called for each call of Conn.Query and Conn.QueryRow. It must call next to run the query on
+	// conn. Rows returned without calling next must not depend on conn. Most errors of a query are only reported by the
+	// returned Rows, so an interceptor that changes errors should wrap the Rows as well.
+	InterceptQuery(ctx context.Context, conn *Conn, sql string, args []any, next QueryFunc) (Rows, error)
+}
+
+// interceptedExec returns the ExecFunc that runs ConnConfig.QueryInterceptors from index i on.
+func (c *Conn) interceptedExec(i int) ExecFunc {
+	if i == len(c.config.QueryInterceptors) {
+		return func(ctx context.Context, sql string, args []any) (pgconn.CommandTag, error) {
+			return c.execWithRetry(ctx, sql, args...)
+		}
+	}
+
+	interceptor := c.config.QueryInterceptors[i]
+	return func(ctx context.Context, sql string, args []any) (pgconn.CommandTag, error) {
+		return interceptor.InterceptExec(ctx, c, sql, args, c.interceptedExec(i+1))
+	}
+}
+
+// interceptedQuery returns the QueryFunc that runs ConnConfig.QueryInterceptors from index i on.
+func (c *Conn) interceptedQuery(i int) QueryFunc {
+	if i == len(c.config.QueryInterceptors) {
+		return func(ctx context.Context, sql string, args []any) (Rows, error) {
+			return c.queryWithRetry(ctx, sql, args...)
+		}
+	}
+
+	interceptor := c.config.QueryInterceptors[i]
+	return func(ctx context.Context, sql string, args []any) (Rows, error) {
+		return interceptor.InterceptQuery(ctx, c, sql, args, c.interceptedQuery(i+1))
+	}
+}
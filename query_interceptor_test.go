@@ -0,0 +1,187 @@
+package pgx_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxtest"
+	"github.com/stretchr/testify/require"
+)
+
+type funcQueryInterceptor struct {
+	interceptExec  func(ctx context.Context, conn *pgx.Conn, sql string, args []any, next pgx.ExecFunc) (pgconn.CommandTag, error)
+	interceptQuery func(ctx context.Context, conn *pgx.Conn, sql string, args []any, next pgx.QueryFunc) (pgx.Rows, error)
+}
+
+func (i *funcQueryInterceptor) InterceptExec(ctx context.Context, conn *pgx.Conn, sql string, args []any, next pgx.ExecFunc) (pgconn.CommandTag, error) {
+	if i.interceptExec == nil {
+		return next(ctx, sql, args)
+	}
+	return i.interceptExec(ctx, conn, sql, args, next)
+}
+
+func (i *funcQueryInterceptor) InterceptQuery(ctx context.Context, conn *pgx.Conn, sql string, args []any, next pgx.QueryFunc) (pgx.Rows, error) {
+	if i.interceptQuery == nil {
+		return next(ctx, sql, args)
+	}
+	return i.interceptQuery(ctx, conn, sql, args, next)
+}
+
+func connTestRunnerWithQueryInterceptors(interceptors ...pgx.QueryInterceptor) pgxtest.ConnTestRunner {
+	ctr := defaultConnTestRunner
+	ctr.CreateConfig = func(ctx context.Context, t testing.TB) *pgx.ConnConfig {
+		config := defaultConnTestRunner.CreateConfig(ctx, t)
+		config.QueryInterceptors = interceptors
+		return config
+	}
+	return ctr
+}
+
+func TestQueryInterceptorRewritesQuery(t *testing.T) {
+	t.Parallel()
+
+	// Replace @tenant with a parameter for the current tenant.
+	rewrite := func(sql string, args []any) (string, []any) {
+		sql = strings.ReplaceAll(sql, "@tenant", fmt.Sprintf("$%d", len(args)+1))
+		return sql, append(args, "acme")
+	}
+	tenancy := &funcQueryInterceptor{
+		interceptExec: func(ctx context.Context, conn *pgx.Conn, sql string, args []any, next pgx.ExecFunc) (pgconn.CommandTag, error) {
+			sql, args = rewrite(sql, args)
+			return next(ctx, sql, args)
+		},
+		interceptQuery: func(ctx context.Context, conn *pgx.Conn, sql string, args []any, next pgx.QueryFunc) (pgx.Rows, error) {
+			sql, args = rewrite(sql, args)
+			return next(ctx, sql, args)
+		},
+	}
+
+	ctr := connTestRunnerWithQueryInterceptors(tenancy)
+	pgxtest.RunWithQueryExecModes(context.Background(), t, ctr, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		var s string
+		err := conn.QueryRow(ctx, "select $1::text || '/' || @tenant::text", "users").Scan(&s)
+		require.NoError(t, err)
+		require.Equal(t, "users/acme", s)
+
+		commandTag, err := conn.Exec(ctx, "select @tenant::text")
+		require.NoError(t, err)
+		require.Equal(t, "SELECT 1", commandTag.String())
+	})
+}
+
+func TestQueryInterceptorShortCircuits(t *testing.T) {
+	t.Parallel()
+
+	errReadOnly := errors.New("connection is read-only")
+	readOnly := &funcQueryInterceptor{
+		interceptExec: func(ctx context.Context, conn *pgx.Conn, sql string, args []any, next pgx.ExecFunc) (pgconn.CommandTag, error) {
+			if strings.HasPrefix(strings.ToLower(sql), "insert") {
+				return pgconn.CommandTag{}, errReadOnly
+			}
+			return next(ctx, sql, args)
+		},
+	}
+
+	var interceptedConn *pgx.Conn
+	cache := &funcQueryInterceptor{
+		interceptQuery: func(ctx context.Context, conn *pgx.Conn, sql string, args []any, next pgx.QueryFunc) (pgx.Rows, error) {
+			interceptedConn = conn
+			if sql == "select 2" {
+				return &staticRows{values: []string{"42"}, typeMap: conn.TypeMap()}, nil
+			}
+			return next(ctx, sql, args)
+		},
+	}
+
+	ctr := connTestRunnerWithQueryInterceptors(readOnly, cache)
+	pgxtest.RunWithQueryExecModes(context.Background(), t, ctr, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		_, err := conn.Exec(ctx, "create temporary table t (a int)")
+		require.NoError(t, err)
+
+		_, err = conn.Exec(ctx, "insert into t values (1)")
+		require.ErrorIs(t, err, errReadOnly)
+
+		var n int32
+		err = conn.QueryRow(ctx, "select 2").Scan(&n)
+		require.NoError(t, err)
+		require.EqualValues(t, 42, n)
+		require.Same(t, conn, interceptedConn)
+
+		err = conn.QueryRow(ctx, "select count(*)::int4 from t").Scan(&n)
+		require.NoError(t, err)
+		require.EqualValues(t, 0, n)
+
+		tx, err := conn.Begin(ctx)
+		require.NoError(t, err)
+		err = tx.QueryRow(ctx, "select 2").Scan(&n)
+		require.NoError(t, err)
+		require.EqualValues(t, 42, n)
+		require.NoError(t, tx.Rollback(ctx))
+	})
+}
+
+func TestQueryInterceptorPostProcessesErrors(t *testing.T) {
+	t.Parallel()
+
+	wrapErrors := &funcQueryInterceptor{
+		interceptExec: func(ctx context.Context, conn *pgx.Conn, sql string, args []any, next pgx.ExecFunc) (pgconn.CommandTag, error) {
+			commandTag, err := next(ctx, sql, args)
+			if err != nil {
+				err = fmt.Errorf("exec %q: %w", sql, err)
+			}
+			return commandTag, err
+		},
+	}
+
+	ctr := connTestRunnerWithQueryInterceptors(wrapErrors)
+	pgxtest.RunWithQueryExecModes(context.Background(), t, ctr, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		_, err := conn.Exec(ctx, "select 1/0")
+		require.Error(t, err)
+		require.True(t, strings.HasPrefix(err.Error(), `exec "select 1/0": `))
+
+		var pgErr *pgconn.PgError
+		require.ErrorAs(t, err, &pgErr)
+		require.Equal(t, "22012", pgErr.Code)
+	})
+}
+
+func TestQueryInterceptorsAreComposed(t *testing.T) {
+	t.Parallel()
+
+	var calls []string
+	logging := func(name string) pgx.QueryInterceptor {
+		return &funcQueryInterceptor{
+			interceptExec: func(ctx context.Context, conn *pgx.Conn, sql string, args []any, next pgx.ExecFunc) (pgconn.CommandTag, error) {
+				calls = append(calls, name+" before")
+				commandTag, err := next(ctx, sql, args)
+				calls = append(calls, name+" after")
+				return commandTag, err
+			},
+			interceptQuery: func(ctx context.Context, conn *pgx.Conn, sql string, args []any, next pgx.QueryFunc) (pgx.Rows, error) {
+				calls = append(calls, name+" query")
+				return next(ctx, sql, args)
+			},
+		}
+	}
+
+	ctr := connTestRunnerWithQueryInterceptors(logging("outer"), logging("inner"))
+	ctr.RunTest(context.Background(), t, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		calls = nil
+
+		_, err := conn.Exec(ctx, "select 1")
+		require.NoError(t, err)
+		require.Equal(t, []string{"outer before", "inner before", "inner after", "outer after"}, calls)
+
+		calls = nil
+		rows, err := conn.Query(ctx, "select 1")
+		require.NoError(t, err)
+		rows.Close()
+		require.NoError(t, rows.Err())
+		require.Equal(t, []string{"outer query", "inner query"}, calls)
+	})
+}
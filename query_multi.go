@@ -0,0 +1,211 @@
+package pgx
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// MultiRows reads the result sets of a query sent with Conn.QueryMulti. Each result set is read with the Rows returned
+// by Rows after NextResult returns true.
+//
+// MultiRows must be closed before the connection can be used again.
+type MultiRows struct {
+	conn   *Conn
+	ctx    context.Context
+	sql    string
+	tracer QueryTracer
+
+	mrr  *pgconn.MultiResultReader
+	rows *baseRows
+
+	commandTag pgconn.CommandTag
+	err        error
+	closed     bool
+}
+
+// QueryMulti sends sql, which may contain multiple statements separated by semicolons, and returns a MultiRows to read
+// the result set of each statement in order. Query only returns the first result set of such sql.
+//
+// QueryMulti always uses the simple protocol, as the extended protocol does not allow multiple statements. args are
+// interpolated into sql client side as with QueryExecModeSimpleProtocol. As with Query, the first args may be a
+// QueryRewriter such as NamedArgs. The statements run in a single implicit transaction unless sql contains transaction
+// control statements. If a statement fails the statements after it are not run.
+//
+// Errors sending the query are returned and also available from Close. Errors of a statement are available from Err of
+// its Rows and from Close.
+func (c *Conn) QueryMulti(ctx context.Context, sql string, args ...any) (*MultiRows, error) {
+	if c.queryTracer != nil {
+		ctx = c.queryTracer.TraceQueryStart(ctx, c, TraceQueryStartData{SQL: sql, Args: args})
+	}
+
+	mr := &MultiRows{conn: c, ctx: ctx, sql: sql, tracer: c.queryTracer}
+
+	if err := c.deallocateInvalidatedCachedStatements(ctx); err != nil {
+		mr.fatal(err)
+		return mr, err
+	}
+
+	if len(args) > 0 {
+		if queryRewriter, ok := args[0].(QueryRewriter); ok {
+			var err error
+			sql, args, err = queryRewriter.RewriteQuery(ctx, c, sql, args[1:])
+			if err != nil {
+				err = fmt.Errorf("rewrite query failed: %v", err)
+				mr.fatal(err)
+				return mr, err
+			}
+		}
+	}
+
+	if len(args) > 0 {
+		var err error
+		sql, err = c.sanitizeForSimpleQuery(sql, args...)
+		if err != nil {
+			mr.fatal(err)
+			return mr, err
+		}
+	}
+
+	sql = c.annotateSQL(ctx, sql)
+	mr.mrr = c.pgConn.Exec(ctx, sql)
+
+	return mr, nil
+}
+
+// NextResult advances to the next result set. The Rows of the previous result set are closed. It returns false when
+// there are no more result sets or an error occurred. MultiRows is closed automatically when it returns false.
+func (mr *MultiRows) NextResult() bool {
+	if mr.closed {
+		return false
+	}
+
+	if mr.rows != nil {
+		mr.rows.Close()
+		mr.commandTag = mr.rows.CommandTag()
+		if mr.err == nil {
+			mr.err = mr.rows.Err()
+		}
+	}
+
+	if !mr.mrr.NextResult() {
+		mr.Close()
+		return false
+	}
+
+	mr.rows = mr.conn.getRows(mr.ctx, mr.sql, nil)
+	mr.rows.queryTracer = nil
+	mr.rows.resultReader = mr.mrr.ResultReader()
+
+	return true
+}
+
+// Rows returns the Rows of the current result set. It returns nil if NextResult has not returned true. The Rows are
+// closed when NextResult is called again or MultiRows is closed.
+func (mr *MultiRows) Rows() Rows {
+	if mr.rows == nil {
+		return nil
+	}
+	return mr.rows
+}
+
+// Close closes the MultiRows, discarding any result sets that have not been read, and returns the first error that
+// occurred. It is safe to call Close after MultiRows is already closed.
+func (mr *MultiRows) Close() error {
+	if mr.closed {
+		return mr.err
+	}
+	mr.closed = true
+
+	if mr.rows != nil {
+		mr.rows.Close()
+		mr.commandTag = mr.rows.CommandTag()
+		if mr.err == nil {
+			mr.err = mr.rows.Err()
+		}
+	}
+
+	if mr.mrr != nil {
+		err := mr.mrr.Close()
+		if mr.err == nil {
+			mr.err = err
+		}
+	}
+
+	if mr.tracer != nil {
+		mr.tracer.TraceQueryEnd(mr.ctx, mr.conn, TraceQueryEndData{CommandTag: mr.commandTag, Err: mr.err})
+	}
+
+	return mr.err
+}
+
+// Err returns the first error that occurred. It is only complete after MultiRows is closed.
+func (mr *MultiRows) Err() error {
+	return mr.err
+}
+
+// fatal records err and closes mr.
+func (mr *MultiRows) fatal(err error) {
+	if mr.err == nil {
+		mr.err = err
+	}
+	mr.Close()
+}
+
+// StatementResult is the result of one statement of sql run with Conn.ExecMulti.
+type StatementResult struct {
+	// Index is the zero-based position of the statement in sql.
+	Index int
+
+	// CommandTag is the command tag of the statement. It is empty if the statement failed.
+	CommandTag pgconn.CommandTag
+}
+
+// ExecMulti sends sql, which may contain multiple statements separated by semicolons, and returns an iterator over the
+// result of each statement in order. It is intended for migration style scripts where Exec would only report the
+// command tag of the last statement. The iterator is compatible with iter.Seq2[StatementResult, error] so with Go 1.23
+// or later it can be used in a range statement:
+//
+//	for result, err := range conn.ExecMulti(ctx, script) {
+//		if err != nil {
+//			return fmt.Errorf("statement %d: %w", result.Index, err)
+//		}
+//		log.Printf("statement %d: %s", result.Index, result.CommandTag)
+//	}
+//
+// sql and args are sent as with QueryMulti. Any rows returned by a statement are discarded. Each successful statement is
+// yielded with a nil error. If a statement fails it is yielded with its error and the iteration ends, as the server
+// does not run the statements after it. The Index of the failed statement is the number of statements that succeeded
+// before it. If sending sql fails a StatementResult with Index 0 is yielded with the error.
+//
+// The query is only sent when the iteration starts. The connection is busy until the iteration ends, including when it
+// is stopped early.
+func (c *Conn) ExecMulti(ctx context.Context, sql string, args ...any) func(yield func(StatementResult, error) bool) {
+	return func(yield func(StatementResult, error) bool) {
+		mr, err := c.QueryMulti(ctx, sql, args...)
+		if err != nil {
+			yield(StatementResult{}, err)
+			return
+		}
+		defer mr.Close()
+
+		i := 0
+		for ; mr.NextResult(); i++ {
+			rows := mr.Rows()
+			rows.Close()
+			if err := rows.Err(); err != nil {
+				yield(StatementResult{Index: i}, err)
+				return
+			}
+
+			if !yield(StatementResult{Index: i, CommandTag: rows.CommandTag()}, nil) {
+				return
+			}
+		}
+
+		if err := mr.Close(); err != nil {
+			yield(StatementResult{Index: i}, err)
+		}
+	}
+}
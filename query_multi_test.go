@@ -0,0 +1,157 @@
+package pgx_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnQueryMulti(t *testing.T) {
+	t.Parallel()
+
+	defaultConnTestRunner.RunTest(context.Background(), t, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		mr, err := conn.QueryMulti(ctx, `select 1 as a; select 'foo' as b, 'bar' as c; create temporary table query_multi_test(n int); select n from generate_series(1, $1::int) n`, 3)
+		require.NoError(t, err)
+
+		require.True(t, mr.NextResult())
+		n, err := pgx.CollectOneRow(mr.Rows(), pgx.RowTo[int32])
+		require.NoError(t, err)
+		require.EqualValues(t, 1, n)
+
+		require.True(t, mr.NextResult())
+		require.Equal(t, "b", mr.Rows().FieldDescriptions()[0].Name)
+		pair, err := pgx.CollectOneRow(mr.Rows(), pgx.RowToMap)
+		require.NoError(t, err)
+		require.Equal(t, map[string]any{"b": "foo", "c": "bar"}, pair)
+
+		require.True(t, mr.NextResult())
+		rows := mr.Rows()
+		require.False(t, rows.Next())
+		require.NoError(t, rows.Err())
+		require.Equal(t, "CREATE TABLE", rows.CommandTag().String())
+
+		require.True(t, mr.NextResult())
+		numbers, err := pgx.CollectRows(mr.Rows(), pgx.RowTo[int32])
+		require.NoError(t, err)
+		require.Equal(t, []int32{1, 2, 3}, numbers)
+
+		require.False(t, mr.NextResult())
+		require.NoError(t, mr.Close())
+
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestConnQueryMultiCloseEarly(t *testing.T) {
+	t.Parallel()
+
+	defaultConnTestRunner.RunTest(context.Background(), t, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		mr, err := conn.QueryMulti(ctx, `select n from generate_series(1, 100) n; select 2`)
+		require.NoError(t, err)
+
+		require.True(t, mr.NextResult())
+		require.True(t, mr.Rows().Next())
+		require.NoError(t, mr.Close())
+
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestConnQueryMultiError(t *testing.T) {
+	t.Parallel()
+
+	defaultConnTestRunner.RunTest(context.Background(), t, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		mr, err := conn.QueryMulti(ctx, `select 1; select 1/0; select 3`)
+		require.NoError(t, err)
+
+		require.True(t, mr.NextResult())
+		require.True(t, mr.NextResult())
+		rows := mr.Rows()
+		for rows.Next() {
+		}
+		var pgErr *pgconn.PgError
+		require.ErrorAs(t, rows.Err(), &pgErr)
+		require.Equal(t, "22012", pgErr.Code)
+
+		require.False(t, mr.NextResult())
+		require.ErrorAs(t, mr.Close(), &pgErr)
+		require.Equal(t, "22012", pgErr.Code)
+
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestConnExecMulti(t *testing.T) {
+	t.Parallel()
+
+	defaultConnTestRunner.RunTest(context.Background(), t, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		var results []pgx.StatementResult
+		conn.ExecMulti(ctx, `create temporary table exec_multi (a int); insert into exec_multi values (1), (2); select * from exec_multi`)(
+			func(result pgx.StatementResult, err error) bool {
+				require.NoError(t, err)
+				results = append(results, result)
+				return true
+			},
+		)
+
+		require.Len(t, results, 3)
+		for i, result := range results {
+			require.Equal(t, i, result.Index)
+		}
+		require.Equal(t, "CREATE TABLE", results[0].CommandTag.String())
+		require.Equal(t, "INSERT 0 2", results[1].CommandTag.String())
+		require.Equal(t, "SELECT 2", results[2].CommandTag.String())
+
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestConnExecMultiError(t *testing.T) {
+	t.Parallel()
+
+	defaultConnTestRunner.RunTest(context.Background(), t, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		for _, sql := range []string{
+			`select 1; select 2; select 1/0; select 4`,
+			`select 1; select 2; insert into exec_multi_missing values (1); select 4`,
+		} {
+			var results []pgx.StatementResult
+			var errs []error
+			conn.ExecMulti(ctx, sql)(func(result pgx.StatementResult, err error) bool {
+				results = append(results, result)
+				errs = append(errs, err)
+				return true
+			})
+
+			require.Len(t, results, 3, sql)
+			require.NoError(t, errs[0])
+			require.NoError(t, errs[1])
+			require.Error(t, errs[2])
+			require.Equal(t, 2, results[2].Index)
+			require.Equal(t, "", results[2].CommandTag.String())
+
+			var pgErr *pgconn.PgError
+			require.ErrorAs(t, errs[2], &pgErr)
+
+			ensureConnValid(t, conn)
+		}
+	})
+}
+
+func TestConnExecMultiStopEarly(t *testing.T) {
+	t.Parallel()
+
+	defaultConnTestRunner.RunTest(context.Background(), t, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		var results []pgx.StatementResult
+		conn.ExecMulti(ctx, `select 1; select 2; select 3`)(func(result pgx.StatementResult, err error) bool {
+			require.NoError(t, err)
+			results = append(results, result)
+			return false
+		})
+
+		require.Len(t, results, 1)
+		ensureConnValid(t, conn)
+	})
+}
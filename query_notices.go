@@ -0,0 +1,48 @@
+package pgx
+
+import "github.com/jackc/pgx/v5/pgconn"
+
+// RowsNotices returns the notices, such as those raised by RAISE NOTICE or RAISE WARNING in PL/pgSQL, that were
+// received while the query of rows ran. They are only complete after rows is closed. Notices are still passed to the
+// notice handler of the connection. RowsNotices returns nil if rows do not implement Notices() []pgconn.Notice. The
+// Rows returned by Conn, Tx, Batch and pgxpool queries implement it.
+func RowsNotices(rows Rows) []pgconn.Notice {
+	if nr, ok := rows.(interface{ Notices() []pgconn.Notice }); ok {
+		return nr.Notices()
+	}
+	return nil
+}
+
+// startCollectingNotices appends the notices received on c to dst until stopCollectingNotices is called with dst. The
+// notices are still passed to the previous notice handler. It does nothing if c is already collecting notices.
+func (c *Conn) startCollectingNotices(dst *[]pgconn.Notice) {
+	if c.queryNotices != nil {
+		return
+	}
+
+	if c.noticeHandler == nil {
+		c.noticeHandler = func(pgConn *pgconn.PgConn, n *pgconn.Notice) {
+			if c.queryNotices != nil {
+				*c.queryNotices = append(*c.queryNotices, *n)
+			}
+			if c.prevNoticeHandler != nil {
+				c.prevNoticeHandler(pgConn, n)
+			}
+		}
+	}
+
+	c.queryNotices = dst
+	c.prevNoticeHandler = c.pgConn.SetNoticeHandler(c.noticeHandler)
+}
+
+// stopCollectingNotices stops collecting notices into dst and restores the previous notice handler. It does nothing if
+// c is not collecting notices into dst.
+func (c *Conn) stopCollectingNotices(dst *[]pgconn.Notice) {
+	if c.queryNotices != dst {
+		return
+	}
+
+	c.pgConn.SetNoticeHandler(c.prevNoticeHandler)
+	c.queryNotices = nil
+	c.prevNoticeHandler = nil
+}
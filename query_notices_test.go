@@ -0,0 +1,87 @@
+package pgx_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxtest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryNotices(t *testing.T) {
+	t.Parallel()
+
+	var connNotices []string
+	ctr := defaultConnTestRunner
+	ctr.CreateConfig = func(ctx context.Context, t testing.TB) *pgx.ConnConfig {
+		config := defaultConnTestRunner.CreateConfig(ctx, t)
+		config.OnNotice = func(c *pgconn.PgConn, notice *pgconn.Notice) {
+			connNotices = append(connNotices, notice.Message)
+		}
+		config.RuntimeParams["client_min_messages"] = "notice"
+		return config
+	}
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, ctr, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		pgxtest.SkipCockroachDB(t, conn, "Server does not support PL/PGSQL (https://github.com/cockroachdb/cockroach/issues/17511)")
+
+		_, err := conn.Exec(ctx, `create function pg_temp.notify_n(n int4) returns int4 language plpgsql as $$
+begin
+  raise notice 'n is %', n;
+  if n > 1 then
+    raise warning 'n is large';
+  end if;
+  return n;
+end$$`)
+		require.NoError(t, err)
+
+		connNotices = nil
+		rows, err := conn.Query(ctx, `select pg_temp.notify_n(n) from generate_series(1, $1::int4) n`, 2)
+		require.NoError(t, err)
+		_, err = pgx.CollectRows(rows, pgx.RowTo[int32])
+		require.NoError(t, err)
+
+		notices := pgx.RowsNotices(rows)
+		require.Len(t, notices, 3)
+		require.Equal(t, "NOTICE", notices[0].Severity)
+		require.Equal(t, "n is 1", notices[0].Message)
+		require.Equal(t, "NOTICE", notices[1].Severity)
+		require.Equal(t, "n is 2", notices[1].Message)
+		require.Equal(t, "WARNING", notices[2].Severity)
+		require.Equal(t, "n is large", notices[2].Message)
+		require.Equal(t, []string{"n is 1", "n is 2", "n is large"}, connNotices)
+
+		rows, err = conn.Query(ctx, `select 1`)
+		require.NoError(t, err)
+		rows.Close()
+		require.NoError(t, rows.Err())
+		require.Empty(t, pgx.RowsNotices(rows))
+
+		var execNotices []pgconn.Notice
+		_, err = conn.Exec(ctx, `select pg_temp.notify_n($1::int4)`, pgx.QueryOptions{Notices: &execNotices}, 1)
+		require.NoError(t, err)
+		require.Len(t, execNotices, 1)
+		require.Equal(t, "n is 1", execNotices[0].Message)
+
+		var queryRowNotices []pgconn.Notice
+		var n int32
+		err = conn.QueryRow(ctx, `select pg_temp.notify_n($1::int4)`, pgx.QueryOptions{Notices: &queryRowNotices}, 3).Scan(&n)
+		require.NoError(t, err)
+		require.EqualValues(t, 3, n)
+		require.Len(t, queryRowNotices, 2)
+		require.Equal(t, "n is 3", queryRowNotices[0].Message)
+		require.Equal(t, "n is large", queryRowNotices[1].Message)
+
+		// Notices of later queries are not collected.
+		connNotices = nil
+		_, err = conn.Exec(ctx, `select pg_temp.notify_n(1)`)
+		require.NoError(t, err)
+		require.Len(t, execNotices, 1)
+		require.Len(t, queryRowNotices, 2)
+		require.Equal(t, []string{"n is 1"}, connNotices)
+
+		ensureConnValid(t, conn)
+	})
+}
@@ -0,0 +1,138 @@
+package pgx
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// QueryOptions configures a single call of Query, QueryRow, or Exec when passed as the first argument. It combines
+// the effects of the other types that can be passed before the query arguments, such as QueryExecMode and
+// QueryResultFormats, and adds settings that have no such type. The zero value of each field leaves the corresponding
+// behavior of the connection unchanged. A QueryRewriter such as NamedArgs may follow QueryOptions.
+//
+//	rows, err := conn.Query(ctx, sql, pgx.QueryOptions{ExecMode: pgx.QueryExecModeExec, StatementTimeout: time.Second}, arg1, arg2)
+type QueryOptions struct {
+	// ExecMode is used instead of DefaultQueryExecMode of the connection's config.
+	ExecMode QueryExecMode
+
+	// ResultFormats controls the result format of each column as QueryResultFormats does. It is ignored by Exec.
+	ResultFormats QueryResultFormats
+
+	// ResultFormatsByOID controls the result format of each column as QueryResultFormatsByOID does. It is ignored by
+	// Exec.
+	ResultFormatsByOID QueryResultFormatsByOID
+
+	// ParamOIDs sets the type of each query parameter as ParamOIDs does.
+	ParamOIDs ParamOIDs
+
+	// StatementTimeout limits the time the server may spend running the query. It is enforced by the server with
+	// statement_timeout, which is set before the query and restored when the query completes, i.e. when Exec returns or
+	// the Rows are closed. This costs two additional round trips. In a transaction the setting is local to the
+	// transaction. Otherwise the connection is closed if the previous value cannot be restored. StatementTimeout is
+	// rounded up to a whole millisecond.
+	StatementTimeout time.Duration
+
+	// FetchSize causes the rows to be fetched from the server FetchSize rows at a time as they are read instead of the
+	// server sending all rows at once. The query runs in a single portal that is suspended after each FetchSize rows
+	// and resumed when more rows are read, so a large result can be streamed without the server getting ahead of the
+	// application. The query stays in progress, and its implicit transaction open, until the Rows are closed. Each
+	// fetch costs a round trip. FetchSize is not supported with QueryExecModeSimpleProtocol and is ignored by Exec.
+	FetchSize int
+
+	// Idempotent marks the query as safe to run more than once. It is retried if it fails with a transient error and
+	// ConnConfig.RetryPolicy is set. See RetryPolicy.
+	Idempotent bool
+
+	// Notices, if set, receives the notices, such as those raised by RAISE NOTICE or RAISE WARNING in PL/pgSQL, that are
+	// received while the query runs. They are appended when Exec returns or the Rows are closed. The notices of a Query
+	// are also available from RowsNotices. Notices are still passed to the notice handler of the connection.
+	Notices *[]pgconn.Notice
+
+	// Tracer is used to trace the query instead of the QueryTracer of the connection. Set it to NoopQueryTracer{} to not
+	// trace the query.
+	Tracer QueryTracer
+}
+
+// queryTracerFor returns the tracer to use for a query with args. It is nil if the query is not traced.
+func (c *Conn) queryTracerFor(args []any) QueryTracer {
+	if len(args) > 0 {
+		if opts, ok := args[0].(QueryOptions); ok && opts.Tracer != nil {
+			if _, ok := opts.Tracer.(NoopQueryTracer); ok {
+				return nil
+			}
+			return opts.Tracer
+		}
+	}
+
+	return c.queryTracer
+}
+
+// deadlineStatementTimeout returns the time remaining until the deadline of ctx for
+// ConnConfig.StatementTimeoutFromDeadline. It returns 0 if ctx has no deadline or the deadline has passed, in which case
+// the query fails with the context error anyway. It also returns 0 for transaction control statements and in a failed
+// transaction, where setting statement_timeout would fail and prevent the transaction from being ended.
+func (c *Conn) deadlineStatementTimeout(ctx context.Context, sql string) time.Duration {
+	if c.pgConn.TxStatus() == 'E' || isTransactionControlStatement(sql) {
+		return 0
+	}
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return 0
+	}
+
+	d := time.Until(deadline)
+	if d <= 0 {
+		return 0
+	}
+	return d
+}
+
+// setStatementTimeoutSQL sets statement_timeout to $1 for the session and returns the previous value.
+const setStatementTimeoutSQL = "select current_setting('statement_timeout'), set_config('statement_timeout', $1, false)"
+
+// restoreStatementTimeoutSQL restores the statement_timeout returned by setStatementTimeoutSQL.
+const restoreStatementTimeoutSQL = "select set_config('statement_timeout', $1, false)"
+
+// setLocalStatementTimeoutSQL is like setStatementTimeoutSQL but the setting is local to the current transaction.
+const setLocalStatementTimeoutSQL = "select current_setting('statement_timeout'), set_config('statement_timeout', $1, true)"
+
+// restoreLocalStatementTimeoutSQL restores the statement_timeout returned by setLocalStatementTimeoutSQL.
+const restoreLocalStatementTimeoutSQL = "select set_config('statement_timeout', $1, true)"
+
+// setStatementTimeout sets statement_timeout to d for QueryOptions.StatementTimeout. It returns a function that
+// restores the previous value. In a transaction the setting is local to the transaction so it is also reverted when the
+// transaction ends or fails. Otherwise it is set for the session and the connection is closed if it cannot be restored
+// so that later queries do not run with d.
+func (c *Conn) setStatementTimeout(ctx context.Context, d time.Duration) (restore func(), err error) {
+	local := c.pgConn.TxStatus() == 'T'
+	setSQL, restoreSQL := setStatementTimeoutSQL, restoreStatementTimeoutSQL
+	if local {
+		setSQL, restoreSQL = setLocalStatementTimeoutSQL, restoreLocalStatementTimeoutSQL
+	}
+
+	result := c.pgConn.ExecParams(ctx, setSQL, [][]byte{[]byte(statementTimeoutSetting(d))}, nil, nil, nil).Read()
+	if result.Err != nil {
+		return nil, result.Err
+	}
+	prev := result.Rows[0][0]
+
+	return func() {
+		if local && c.pgConn.TxStatus() != 'T' {
+			// The transaction was committed, rolled back, or failed by the query. Either way the setting no longer applies
+			// or is reverted by the rollback.
+			return
+		}
+
+		// The context of the query may be canceled or past its deadline by now. The previous value must be restored anyway.
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		err := c.pgConn.ExecParams(ctx, restoreSQL, [][]byte{prev}, nil, nil, nil).Read().Err
+		if err != nil && !local {
+			c.die(fmt.Errorf("failed to restore statement_timeout: %w", err))
+		}
+	}, nil
+}
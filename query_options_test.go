@@ -0,0 +1,304 @@
+package pgx_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxtest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryOptionsExecModeAndResultFormats(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		rows, err := conn.Query(ctx, `select $1::int4`, pgx.QueryOptions{ExecMode: pgx.QueryExecModeDescribeExec, ResultFormats: pgx.QueryResultFormats{pgx.TextFormatCode}}, 42)
+		require.NoError(t, err)
+		require.True(t, rows.Next())
+		require.Equal(t, []byte("42"), rows.RawValues()[0])
+		rows.Close()
+		require.NoError(t, rows.Err())
+
+		commandTag, err := conn.Exec(ctx, `select $1::int4`, pgx.QueryOptions{ExecMode: pgx.QueryExecModeExec}, 42)
+		require.NoError(t, err)
+		require.Equal(t, "SELECT 1", commandTag.String())
+	})
+}
+
+func TestQueryOptionsWithNamedArgs(t *testing.T) {
+	t.Parallel()
+
+	defaultConnTestRunner.RunTest(context.Background(), t, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		var n int32
+		err := conn.QueryRow(ctx, `select @n::int4`, pgx.QueryOptions{ExecMode: pgx.QueryExecModeExec}, pgx.NamedArgs{"n": 7}).Scan(&n)
+		require.NoError(t, err)
+		require.EqualValues(t, 7, n)
+	})
+}
+
+func TestQueryOptionsStatementTimeout(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		var before string
+		err := conn.QueryRow(ctx, `show statement_timeout`).Scan(&before)
+		require.NoError(t, err)
+
+		_, err = conn.Exec(ctx, `select pg_sleep(1)`, pgx.QueryOptions{StatementTimeout: 50 * time.Millisecond})
+		var pgErr *pgconn.PgError
+		require.ErrorAs(t, err, &pgErr)
+		require.Equal(t, "57014", pgErr.Code)
+
+		rows, _ := conn.Query(ctx, `select pg_sleep(1)`, pgx.QueryOptions{StatementTimeout: 50 * time.Millisecond})
+		rows.Close()
+		require.ErrorAs(t, rows.Err(), &pgErr)
+		require.Equal(t, "57014", pgErr.Code)
+
+		var n int32
+		err = conn.QueryRow(ctx, `select 1`, pgx.QueryOptions{StatementTimeout: time.Second}).Scan(&n)
+		require.NoError(t, err)
+		require.EqualValues(t, 1, n)
+
+		var after string
+		err = conn.QueryRow(ctx, `show statement_timeout`).Scan(&after)
+		require.NoError(t, err)
+		require.Equal(t, before, after)
+	})
+}
+
+func TestQueryOptionsStatementTimeoutInTransaction(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		var before string
+		err := conn.QueryRow(ctx, `show statement_timeout`).Scan(&before)
+		require.NoError(t, err)
+
+		tx, err := conn.Begin(ctx)
+		require.NoError(t, err)
+		defer tx.Rollback(ctx)
+
+		var n int32
+		err = tx.QueryRow(ctx, `select 1`, pgx.QueryOptions{StatementTimeout: time.Second}).Scan(&n)
+		require.NoError(t, err)
+
+		var during string
+		err = tx.QueryRow(ctx, `show statement_timeout`).Scan(&during)
+		require.NoError(t, err)
+		require.Equal(t, before, during)
+
+		// The query fails the transaction so the setting is only reverted by the rollback.
+		_, err = tx.Exec(ctx, `select pg_sleep(1)`, pgx.QueryOptions{StatementTimeout: 50 * time.Millisecond})
+		var pgErr *pgconn.PgError
+		require.ErrorAs(t, err, &pgErr)
+		require.Equal(t, "57014", pgErr.Code)
+		require.NoError(t, tx.Rollback(ctx))
+
+		var after string
+		err = conn.QueryRow(ctx, `show statement_timeout`).Scan(&after)
+		require.NoError(t, err)
+		require.Equal(t, before, after)
+	})
+}
+
+func TestStatementTimeoutFromDeadline(t *testing.T) {
+	t.Parallel()
+
+	ctr := defaultConnTestRunner
+	ctr.CreateConfig = func(ctx context.Context, t testing.TB) *pgx.ConnConfig {
+		config := defaultConnTestRunner.CreateConfig(ctx, t)
+		config.StatementTimeoutFromDeadline = true
+		return config
+	}
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, ctr, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		var before string
+		err := conn.QueryRow(ctx, `show statement_timeout`).Scan(&before)
+		require.NoError(t, err)
+
+		deadlineCtx, cancel := context.WithTimeout(ctx, 10*time.Minute)
+		defer cancel()
+
+		var timeout time.Duration
+		err = conn.QueryRow(deadlineCtx, `select current_setting('statement_timeout')::interval`).Scan(&timeout)
+		require.NoError(t, err)
+		require.Greater(t, timeout, 9*time.Minute)
+		require.LessOrEqual(t, timeout, 10*time.Minute)
+
+		// An explicit StatementTimeout takes precedence.
+		err = conn.QueryRow(deadlineCtx, `select current_setting('statement_timeout')::interval`, pgx.QueryOptions{StatementTimeout: time.Second}).Scan(&timeout)
+		require.NoError(t, err)
+		require.Equal(t, time.Second, timeout)
+
+		_, err = conn.Exec(deadlineCtx, `select 1`)
+		require.NoError(t, err)
+
+		var after string
+		err = conn.QueryRow(ctx, `show statement_timeout`).Scan(&after)
+		require.NoError(t, err)
+		require.Equal(t, before, after)
+	})
+}
+
+func TestStatementTimeoutFromDeadlineFailedTransaction(t *testing.T) {
+	t.Parallel()
+
+	ctr := defaultConnTestRunner
+	ctr.CreateConfig = func(ctx context.Context, t testing.TB) *pgx.ConnConfig {
+		config := defaultConnTestRunner.CreateConfig(ctx, t)
+		config.StatementTimeoutFromDeadline = true
+		return config
+	}
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, ctr, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		deadlineCtx, cancel := context.WithTimeout(ctx, time.Minute)
+		defer cancel()
+
+		tx, err := conn.Begin(deadlineCtx)
+		require.NoError(t, err)
+
+		_, err = tx.Exec(deadlineCtx, `select 1/0`)
+		require.Error(t, err)
+		require.Equal(t, byte('E'), conn.PgConn().TxStatus())
+
+		require.NoError(t, tx.Rollback(deadlineCtx))
+		require.False(t, conn.IsClosed())
+		require.Equal(t, byte('I'), conn.PgConn().TxStatus())
+
+		tx, err = conn.Begin(deadlineCtx)
+		require.NoError(t, err)
+
+		nestedTx, err := tx.Begin(deadlineCtx)
+		require.NoError(t, err)
+		_, err = nestedTx.Exec(deadlineCtx, `select 1/0`)
+		require.Error(t, err)
+		require.NoError(t, nestedTx.Rollback(deadlineCtx))
+
+		_, err = tx.Exec(deadlineCtx, `select 1`)
+		require.NoError(t, err)
+		require.NoError(t, tx.Commit(deadlineCtx))
+
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestQueryOptionsTracer(t *testing.T) {
+	t.Parallel()
+
+	connTracer := &testTracer{}
+
+	ctr := defaultConnTestRunner
+	ctr.CreateConfig = func(ctx context.Context, t testing.TB) *pgx.ConnConfig {
+		config := defaultConnTestRunner.CreateConfig(ctx, t)
+		config.Tracer = connTracer
+		return config
+	}
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, ctr, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		connTracerCalls := 0
+		connTracer.traceQueryStart = func(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+			connTracerCalls++
+			return ctx
+		}
+
+		queryTracerEnds := 0
+		queryTracer := &testTracer{
+			traceQueryEnd: func(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryEndData) {
+				queryTracerEnds++
+			},
+		}
+
+		rows, _ := conn.Query(ctx, `select 1`, pgx.QueryOptions{Tracer: queryTracer})
+		rows.Close()
+		require.NoError(t, rows.Err())
+		_, err := conn.Exec(ctx, `select 1`, pgx.QueryOptions{Tracer: queryTracer})
+		require.NoError(t, err)
+		require.Equal(t, 2, queryTracerEnds)
+		require.Equal(t, 0, connTracerCalls)
+
+		_, err = conn.Exec(ctx, `select 1`, pgx.QueryOptions{Tracer: pgx.NoopQueryTracer{}})
+		require.NoError(t, err)
+		require.Equal(t, 0, connTracerCalls)
+
+		_, err = conn.Exec(ctx, `select 1`)
+		require.NoError(t, err)
+		require.Equal(t, 1, connTracerCalls)
+	})
+}
+
+func TestQueryOptionsFetchSize(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		if conn.Config().DefaultQueryExecMode == pgx.QueryExecModeSimpleProtocol {
+			_, err := conn.Query(ctx, `select 1`, pgx.QueryOptions{FetchSize: 10})
+			require.Error(t, err)
+			return
+		}
+
+		rows, _ := conn.Query(ctx, `select n from generate_series(1, $1::int) n`, pgx.QueryOptions{FetchSize: 10}, 95)
+		numbers, err := pgx.CollectRows(rows, pgx.RowTo[int32])
+		require.NoError(t, err)
+		require.Len(t, numbers, 95)
+		for i, n := range numbers {
+			require.EqualValues(t, i+1, n)
+		}
+
+		rows, _ = conn.Query(ctx, `select n from generate_series(1, 1000) n`, pgx.QueryOptions{FetchSize: 5})
+		require.True(t, rows.Next())
+		rows.Close()
+		require.NoError(t, rows.Err())
+
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestParamOIDs(t *testing.T) {
+	t.Parallel()
+
+	modes := []pgx.QueryExecMode{
+		pgx.QueryExecModeCacheStatement,
+		pgx.QueryExecModeCacheDescribe,
+		pgx.QueryExecModeDescribeExec,
+		pgx.QueryExecModeExec,
+	}
+	pgxtest.RunWithQueryExecModes(context.Background(), t, defaultConnTestRunner, modes, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		// The server cannot infer the type of $1 without a hint.
+		_, err := conn.Exec(ctx, `select $1 is null`, "foo")
+		require.Error(t, err)
+
+		var isNull bool
+		err = conn.QueryRow(ctx, `select $1 is null`, pgx.ParamOIDs{pgtype.TextOID}, "foo").Scan(&isNull)
+		require.NoError(t, err)
+		require.False(t, isNull)
+
+		commandTag, err := conn.Exec(ctx, `select $1 is null`, pgx.ParamOIDs{pgtype.TextOID}, nil)
+		require.NoError(t, err)
+		require.Equal(t, "SELECT 1", commandTag.String())
+
+		// A zero OID leaves the type to the server.
+		var n int32
+		var typeName string
+		err = conn.QueryRow(ctx, `select $1::int4 + 1, pg_typeof($2)::text`, pgx.QueryOptions{ParamOIDs: pgx.ParamOIDs{0, pgtype.Int8OID}}, int32(41), int64(7)).Scan(&n, &typeName)
+		require.NoError(t, err)
+		require.EqualValues(t, 42, n)
+		require.Equal(t, "bigint", typeName)
+
+		// The description of a statement run with ParamOIDs is not cached for the same sql without them.
+		err = conn.QueryRow(ctx, `select pg_typeof($1)::text`, pgx.ParamOIDs{pgtype.Int8OID}, int32(1)).Scan(&typeName)
+		require.NoError(t, err)
+		require.Equal(t, "bigint", typeName)
+		err = conn.QueryRow(ctx, `select pg_typeof($1)::text`, pgx.ParamOIDs{pgtype.Int2OID}, int32(1)).Scan(&typeName)
+		require.NoError(t, err)
+		require.Equal(t, "smallint", typeName)
+
+		_, err = conn.Exec(ctx, `select $1::text`, pgx.ParamOIDs{pgtype.TextOID, pgtype.TextOID}, "foo")
+		require.Error(t, err)
+
+		ensureConnValid(t, conn)
+	})
+}
@@ -0,0 +1,223 @@
+package pgx
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/internal/stmtcache"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const (
+	defaultRetryMaxAttempts    = 3
+	defaultRetryInitialBackoff = 50 * time.Millisecond
+	defaultRetryMaxBackoff     = time.Second
+)
+
+// RetryPolicy controls how queries marked idempotent with QueryOptions.Idempotent are retried when they fail with a
+// transient error. It is enabled by setting ConnConfig.RetryPolicy. Queries that are not marked idempotent are never
+// retried.
+//
+// A Conn retries a query on the same connection if the connection is still usable and the query did not run in an
+// explicit transaction, as a failed statement aborts the transaction it ran in. A *pgxpool.Pool additionally retries
+// on another connection when the connection is lost, e.g. because the server was shut down (SQLSTATE 57P01).
+//
+// A RetryPolicy may be shared by multiple connections.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of times a query is run, including the first attempt. Defaults to 3.
+	MaxAttempts int
+
+	// InitialBackoff is the delay before the first retry. The delay doubles after each retry up to MaxBackoff. A random
+	// jitter of up to half of the delay is subtracted so that clients that failed at the same time do not retry at the
+	// same time. Defaults to 50ms.
+	InitialBackoff time.Duration
+
+	// MaxBackoff is the maximum delay between retries. Defaults to 1 second.
+	MaxBackoff time.Duration
+
+	// Budget, if set, limits the number of retries across all queries that use the policy.
+	Budget *RetryBudget
+
+	// IsRetryable reports whether a query that failed with err may be retried. Defaults to IsRetryableError.
+	IsRetryable func(err error) bool
+}
+
+// Retry calls fn until it succeeds, fails with an error that is not retryable, or the policy is exhausted, waiting
+// between attempts as configured by p. fn returns canRetry false if its error must not be retried even if it is
+// retryable, e.g. because the connection it used is no longer usable. Retry returns the error of the last attempt. If
+// ctx is canceled while waiting to retry, the error of the last attempt is returned.
+//
+// Retry is used by Conn and *pgxpool.Pool. It is exported so other wrappers of Conn can retry with the same policy.
+func (p *RetryPolicy) Retry(ctx context.Context, fn func() (canRetry bool, err error)) error {
+	maxAttempts := p.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultRetryMaxAttempts
+	}
+	isRetryable := p.IsRetryable
+	if isRetryable == nil {
+		isRetryable = IsRetryableError
+	}
+
+	if p.Budget != nil {
+		p.Budget.deposit()
+	}
+
+	for attempt := 1; ; attempt++ {
+		canRetry, err := fn()
+		if err == nil || !canRetry || attempt >= maxAttempts || !isRetryable(err) {
+			return err
+		}
+
+		if p.Budget != nil && !p.Budget.withdraw() {
+			return err
+		}
+
+		timer := time.NewTimer(p.backoff(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// backoff returns the delay before retrying after attempt failed.
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	d := p.InitialBackoff
+	if d <= 0 {
+		d = defaultRetryInitialBackoff
+	}
+	maxBackoff := p.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = defaultRetryMaxBackoff
+	}
+
+	for i := 1; i < attempt && d < maxBackoff; i++ {
+		d *= 2
+	}
+	if d > maxBackoff {
+		d = maxBackoff
+	}
+
+	return d - time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+// RetryBudget limits retries so that they cannot multiply the load on a server that is already failing. Each query
+// that may be retried deposits a fraction of a retry in the budget and each retry withdraws a whole retry. A query is
+// not retried when the budget is empty. The budget starts full.
+//
+// A RetryBudget is safe for concurrent use.
+type RetryBudget struct {
+	mu      sync.Mutex
+	ratio   float64
+	max     float64
+	balance float64
+}
+
+// NewRetryBudget returns a RetryBudget that allows ratio retries per query, e.g. 0.1 allows one retry for every ten
+// queries, and holds at most maxRetries retries.
+func NewRetryBudget(ratio float64, maxRetries int) *RetryBudget {
+	return &RetryBudget{ratio: ratio, max: float64(maxRetries), balance: float64(maxRetries)}
+}
+
+func (b *RetryBudget) deposit() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.balance += b.ratio
+	if b.balance > b.max {
+		b.balance = b.max
+	}
+}
+
+func (b *RetryBudget) withdraw() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.balance < 1 {
+		return false
+	}
+	b.balance--
+	return true
+}
+
+// IsRetryableError reports whether err is a transient error after which an idempotent query may succeed if it is run
+// again. These are serialization failures (SQLSTATE 40001), deadlocks (40P01), server shutdowns (57P01, 57P02, 57P03),
+// connection exceptions (class 08), network errors, and errors that pgconn.SafeToRetry reports as safe to retry.
+// Context cancellation and timeouts are not retryable.
+func IsRetryableError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) || pgconn.Timeout(err) {
+		return false
+	}
+
+	if pgconn.SafeToRetry(err) {
+		return true
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case "40001", "40P01", "57P01", "57P02", "57P03":
+			return true
+		}
+		return len(pgErr.Code) == 5 && pgErr.Code[:2] == "08"
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// retryPolicyFor returns the retry policy for a query with args. It is nil if the query is not retried.
+func (c *Conn) retryPolicyFor(args []any) *RetryPolicy {
+	if c.config.RetryPolicy == nil || len(args) == 0 {
+		return nil
+	}
+	if opts, ok := args[0].(QueryOptions); ok && opts.Idempotent {
+		return c.config.RetryPolicy
+	}
+	return nil
+}
+
+// retry runs fn, which runs a query marked idempotent, with policy. The query is only retried while c is usable and not
+// in a transaction. If the query fails because a cached statement is no longer valid, e.g. because a table it uses was
+// altered, the statement has already been invalidated and it is run again immediately without counting as a retry.
+func (c *Conn) retry(ctx context.Context, policy *RetryPolicy, fn func() error) error {
+	if c.pgConn.TxStatus() != 'I' {
+		return fn()
+	}
+
+	return policy.Retry(ctx, func() (bool, error) {
+		err := fn()
+		if err != nil && stmtcache.IsStatementInvalid(err) && c.canRetry() {
+			err = fn()
+		}
+		return c.canRetry(), err
+	})
+}
+
+// canRetry reports whether a failed query can be retried on c.
+func (c *Conn) canRetry() bool {
+	return !c.IsClosed() && c.pgConn.TxStatus() == 'I'
+}
+
+// invalidateCachedStatement removes sql from the statement and description caches after it failed with err if err
+// indicates that the cached statement is no longer valid.
+func (c *Conn) invalidateCachedStatement(sql string, err error) {
+	if err == nil || sql == "" || !stmtcache.IsStatementInvalid(err) {
+		return
+	}
+
+	if sc := c.statementCache; sc != nil {
+		sc.Invalidate(sql)
+	}
+
+	if sc := c.descriptionCache; sc != nil {
+		sc.Invalidate(sql)
+	}
+}
@@ -0,0 +1,238 @@
+package pgx_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxtest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsRetryableError(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		err       error
+		retryable bool
+	}{
+		{nil, false},
+		{&pgconn.PgError{Code: "40001"}, true},
+		{&pgconn.PgError{Code: "40P01"}, true},
+		{&pgconn.PgError{Code: "57P01"}, true},
+		{&pgconn.PgError{Code: "08006"}, true},
+		{fmt.Errorf("wrapped: %w", &pgconn.PgError{Code: "40001"}), true},
+		{&pgconn.PgError{Code: "23505"}, false},
+		{io.ErrUnexpectedEOF, true},
+		{context.Canceled, false},
+		{context.DeadlineExceeded, false},
+		{errors.New("other"), false},
+	} {
+		require.Equalf(t, tt.retryable, pgx.IsRetryableError(tt.err), "%v", tt.err)
+	}
+}
+
+func TestRetryPolicyRetry(t *testing.T) {
+	t.Parallel()
+
+	serializationFailure := &pgconn.PgError{Code: "40001"}
+	policy := &pgx.RetryPolicy{MaxAttempts: 4, InitialBackoff: time.Millisecond, MaxBackoff: 2 * time.Millisecond}
+
+	attempts := 0
+	err := policy.Retry(context.Background(), func() (bool, error) {
+		attempts++
+		if attempts < 3 {
+			return true, serializationFailure
+		}
+		return true, nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 3, attempts)
+
+	attempts = 0
+	err = policy.Retry(context.Background(), func() (bool, error) {
+		attempts++
+		return true, serializationFailure
+	})
+	require.ErrorIs(t, err, serializationFailure)
+	require.Equal(t, 4, attempts)
+
+	attempts = 0
+	err = policy.Retry(context.Background(), func() (bool, error) {
+		attempts++
+		return false, serializationFailure
+	})
+	require.ErrorIs(t, err, serializationFailure)
+	require.Equal(t, 1, attempts)
+
+	attempts = 0
+	err = policy.Retry(context.Background(), func() (bool, error) {
+		attempts++
+		return true, &pgconn.PgError{Code: "23505"}
+	})
+	require.Error(t, err)
+	require.Equal(t, 1, attempts)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	attempts = 0
+	err = policy.Retry(ctx, func() (bool, error) {
+		attempts++
+		return true, serializationFailure
+	})
+	require.ErrorIs(t, err, serializationFailure)
+	require.Equal(t, 1, attempts)
+}
+
+func TestRetryBudget(t *testing.T) {
+	t.Parallel()
+
+	serializationFailure := &pgconn.PgError{Code: "40001"}
+	policy := &pgx.RetryPolicy{
+		MaxAttempts:    10,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     time.Millisecond,
+		Budget:         pgx.NewRetryBudget(0.5, 2),
+	}
+
+	attempts := 0
+	err := policy.Retry(context.Background(), func() (bool, error) {
+		attempts++
+		return true, serializationFailure
+	})
+	require.ErrorIs(t, err, serializationFailure)
+	require.Equal(t, 3, attempts) // The budget starts with 2 retries.
+
+	// Each query deposits half a retry.
+	attempts = 0
+	policy.Retry(context.Background(), func() (bool, error) {
+		attempts++
+		return true, serializationFailure
+	})
+	require.Equal(t, 1, attempts)
+
+	attempts = 0
+	policy.Retry(context.Background(), func() (bool, error) {
+		attempts++
+		return true, serializationFailure
+	})
+	require.Equal(t, 2, attempts)
+}
+
+func mustConnectWithRetryPolicy(t *testing.T) *pgx.Conn {
+	config := mustParseConfig(t, os.Getenv("PGX_TEST_DATABASE"))
+	config.RetryPolicy = &pgx.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond}
+	conn := mustConnect(t, config)
+
+	pgxtest.SkipCockroachDB(t, conn, "Server does not support temporary functions")
+
+	mustExec(t, conn, "create temporary sequence pgx_retry_seq")
+	mustExec(t, conn, `create function pg_temp.fail_twice() returns int language plpgsql as $$
+begin
+	if nextval('pgx_retry_seq') < 3 then
+		raise exception 'try again' using errcode = '40001';
+	end if;
+	return 42;
+end
+$$`)
+
+	return conn
+}
+
+func TestConnRetryIdempotentQuery(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	conn := mustConnectWithRetryPolicy(t)
+	defer closeConn(t, conn)
+
+	var n int32
+	err := conn.QueryRow(ctx, "select pg_temp.fail_twice()", pgx.QueryOptions{Idempotent: true}).Scan(&n)
+	require.NoError(t, err)
+	require.EqualValues(t, 42, n)
+
+	mustExec(t, conn, "select setval('pgx_retry_seq', 1, false)")
+	commandTag, err := conn.Exec(ctx, "select pg_temp.fail_twice()", pgx.QueryOptions{Idempotent: true})
+	require.NoError(t, err)
+	require.EqualValues(t, 1, commandTag.RowsAffected())
+
+	mustExec(t, conn, "select setval('pgx_retry_seq', 1, false)")
+	rows, err := conn.Query(ctx, "select pg_temp.fail_twice() from generate_series(1, 3)", pgx.QueryOptions{Idempotent: true})
+	require.NoError(t, err)
+	values, err := pgx.CollectRows(rows, pgx.RowTo[int32])
+	require.NoError(t, err)
+	require.Equal(t, []int32{42, 42, 42}, values)
+
+	ensureConnValid(t, conn)
+}
+
+func TestConnRetryRequiresIdempotent(t *testing.T) {
+	t.Parallel()
+
+	conn := mustConnectWithRetryPolicy(t)
+	defer closeConn(t, conn)
+
+	var n int32
+	err := conn.QueryRow(context.Background(), "select pg_temp.fail_twice()").Scan(&n)
+	var pgErr *pgconn.PgError
+	require.ErrorAs(t, err, &pgErr)
+	require.Equal(t, "40001", pgErr.Code)
+
+	ensureConnValid(t, conn)
+}
+
+func TestConnRetryNotInTransaction(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	conn := mustConnectWithRetryPolicy(t)
+	defer closeConn(t, conn)
+
+	tx, err := conn.Begin(ctx)
+	require.NoError(t, err)
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, "select pg_temp.fail_twice()", pgx.QueryOptions{Idempotent: true})
+	var pgErr *pgconn.PgError
+	require.ErrorAs(t, err, &pgErr)
+	require.Equal(t, "40001", pgErr.Code)
+}
+
+func TestConnRetryInvalidatedCachedStatement(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	config := mustParseConfig(t, os.Getenv("PGX_TEST_DATABASE"))
+	config.DefaultQueryExecMode = pgx.QueryExecModeCacheStatement
+	config.RetryPolicy = &pgx.RetryPolicy{MaxAttempts: 1}
+	conn := mustConnect(t, config)
+	defer closeConn(t, conn)
+
+	pgxtest.SkipCockroachDB(t, conn, "Server does not report cached plan changes")
+
+	mustExec(t, conn, "create temporary table retry_cached (a int)")
+	mustExec(t, conn, "insert into retry_cached values (1)")
+
+	var a int32
+	err := conn.QueryRow(ctx, "select * from retry_cached where a = $1", 1).Scan(&a)
+	require.NoError(t, err)
+
+	mustExec(t, conn, "alter table retry_cached add column b int")
+
+	// The cached statement no longer matches the table. It is invalidated and the query is run again even though the
+	// policy does not allow retries.
+	var b *int32
+	err = conn.QueryRow(ctx, "select * from retry_cached where a = $1", pgx.QueryOptions{Idempotent: true}, 1).Scan(&a, &b)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, a)
+	require.Nil(t, b)
+}
@@ -8,7 +8,6 @@ import (
 	"strings"
 	"time"
 
-	"github.com/jackc/pgx/v5/internal/stmtcache"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgtype"
 )
@@ -99,7 +98,7 @@ func (r *connRow) Scan(dest ...any) (err error) {
 
 	if !rows.Next() {
 		if rows.Err() == nil {
-			return ErrNoRows
+			return noRowsError(rows)
 		}
 		return rows.Err()
 	}
@@ -109,6 +108,37 @@ func (r *connRow) Scan(dest ...any) (err error) {
 	return rows.Err()
 }
 
+// rowsToRow returns a Row that reads the first row of rows. Rows that did not come from a *Conn, such as those served
+// by a BatchQueryInterceptor, are wrapped in a genericRow.
+func rowsToRow(rows Rows) Row {
+	if br, ok := rows.(*baseRows); ok {
+		return (*connRow)(br)
+	}
+	return &genericRow{rows: rows}
+}
+
+// genericRow implements the Row interface for any Rows.
+type genericRow struct {
+	rows Rows
+}
+
+func (r *genericRow) Scan(dest ...any) error {
+	defer r.rows.Close()
+
+	if !r.rows.Next() {
+		if r.rows.Err() == nil {
+			return noRowsError(r.rows)
+		}
+		return r.rows.Err()
+	}
+
+	if err := r.rows.Scan(dest...); err != nil {
+		return err
+	}
+	r.rows.Close()
+	return r.rows.Err()
+}
+
 // baseRows implements the Rows interface for Conn.Query.
 type baseRows struct {
 	typeMap      *pgtype.Map
@@ -133,6 +163,16 @@ type baseRows struct {
 	sql         string
 	args        []any
 	rowCount    int
+
+	batchItemIdx int // index of the batch query plus one if the rows are the results of a batch query. Otherwise 0.
+
+	sentSQL string // sql with the arguments interpolated if it was sent with the simple protocol.
+
+	notices    []pgconn.Notice  // notices received while the query ran.
+	noticesDst *[]pgconn.Notice // QueryOptions.Notices, which the notices are appended to when the rows are closed.
+
+	restoreSettings func() // restores settings changed for the query by QueryOptions when the rows are closed.
+	peeked          bool   // true if the current row was read ahead by a retried Query and not yet returned by Next.
 }
 
 func (rows *baseRows) FieldDescriptions() []pgconn.FieldDescription {
@@ -161,18 +201,32 @@ func (rows *baseRows) Close() {
 		}
 	}
 
-	if rows.err != nil && rows.conn != nil && rows.sql != "" {
-		if stmtcache.IsStatementInvalid(rows.err) {
-			if sc := rows.conn.statementCache; sc != nil {
-				sc.Invalidate(rows.sql)
-			}
+	if rows.conn != nil {
+		rows.conn.stopCollectingNotices(&rows.notices)
+	}
+	if rows.noticesDst != nil {
+		*rows.noticesDst = append(*rows.noticesDst, rows.notices...)
+	}
 
-			if sc := rows.conn.descriptionCache; sc != nil {
-				sc.Invalidate(rows.sql)
+	if rows.restoreSettings != nil {
+		rows.restoreSettings()
+	}
+
+	if rows.conn != nil {
+		rows.conn.invalidateCachedStatement(rows.sql, rows.err)
+		if rows.batchItemIdx == 0 {
+			sentSQL := rows.sql
+			if rows.sentSQL != "" {
+				sentSQL = rows.sentSQL
 			}
+			rows.err = rows.conn.withErrorPosition(rows.err, sentSQL)
 		}
 	}
 
+	if rows.err != nil && rows.batchItemIdx > 0 {
+		rows.err = wrapBatchItemErr(rows.batchItemIdx-1, rows.sql, rows.args, rows.err)
+	}
+
 	if rows.batchTracer != nil {
 		rows.batchTracer.TraceBatchQuery(rows.ctx, rows.conn, TraceBatchQueryData{SQL: rows.sql, Args: rows.args, CommandTag: rows.commandTag, Err: rows.err})
 	} else if rows.queryTracer != nil {
@@ -200,17 +254,27 @@ func (rows *baseRows) fatal(err error) {
 }
 
 func (rows *baseRows) Next() bool {
+	if rows.peeked {
+		rows.peeked = false
+		return true
+	}
+
 	if rows.closed {
 		return false
 	}
 
-	if rows.resultReader.NextRow() {
-		rows.rowCount++
-		rows.values = rows.resultReader.Values()
-		return true
-	} else {
-		rows.Close()
-		return false
+	for {
+		if rows.resultReader.NextRow() {
+			rows.rowCount++
+			rows.values = rows.resultReader.Values()
+			return true
+		}
+
+		// Fetch the next rows of a query run with QueryOptions.FetchSize.
+		if !rows.resultReader.Suspended() || !rows.resultReader.Resume() {
+			rows.Close()
+			return false
+		}
 	}
 }
 
@@ -272,21 +336,31 @@ func (rows *baseRows) Values() ([]any, error) {
 		return nil, errors.New("rows is closed")
 	}
 
-	values := make([]any, 0, len(rows.FieldDescriptions()))
+	values, err := decodeRowValues(rows.typeMap, rows.FieldDescriptions(), rows.values)
+	if err != nil {
+		rows.fatal(err)
+	}
+
+	return values, rows.Err()
+}
+
+// decodeRowValues decodes the raw values of a row into the Go values returned by Rows.Values.
+func decodeRowValues(m *pgtype.Map, fieldDescriptions []pgconn.FieldDescription, rawValues [][]byte) ([]any, error) {
+	values := make([]any, 0, len(fieldDescriptions))
 
-	for i := range rows.FieldDescriptions() {
-		buf := rows.values[i]
-		fd := &rows.FieldDescriptions()[i]
+	for i := range fieldDescriptions {
+		buf := rawValues[i]
+		fd := &fieldDescriptions[i]
 
 		if buf == nil {
 			values = append(values, nil)
 			continue
 		}
 
-		if dt, ok := rows.typeMap.TypeForOID(fd.DataTypeOID); ok {
-			value, err := dt.Codec.DecodeValue(rows.typeMap, fd.DataTypeOID, fd.Format, buf)
+		if dt, ok := m.TypeForOID(fd.DataTypeOID); ok {
+			value, err := dt.Codec.DecodeValue(m, fd.DataTypeOID, fd.Format, buf)
 			if err != nil {
-				rows.fatal(err)
+				return nil, err
 			}
 			values = append(values, value)
 		} else {
@@ -298,16 +372,12 @@ func (rows *baseRows) Values() ([]any, error) {
 				copy(newBuf, buf)
 				values = append(values, newBuf)
 			default:
-				rows.fatal(errors.New("Unknown format code"))
+				return nil, errors.New("Unknown format code")
 			}
 		}
-
-		if rows.Err() != nil {
-			return nil, rows.Err()
-		}
 	}
 
-	return values, rows.Err()
+	return values, nil
 }
 
 func (rows *baseRows) RawValues() [][]byte {
@@ -318,6 +388,11 @@ func (rows *baseRows) Conn() *Conn {
 	return rows.conn
 }
 
+// Notices returns the notices received while the query ran. See RowsNotices.
+func (rows *baseRows) Notices() []pgconn.Notice {
+	return rows.notices
+}
+
 type ScanArgError struct {
 	ColumnIndex int
 	Err         error
@@ -437,7 +512,7 @@ func CollectOneRow[T any](rows Rows, fn RowToFunc[T]) (T, error) {
 		if err = rows.Err(); err != nil {
 			return value, err
 		}
-		return value, ErrNoRows
+		return value, noRowsError(rows)
 	}
 
 	value, err = fn(rows)
@@ -449,6 +524,84 @@ func CollectOneRow[T any](rows Rows, fn RowToFunc[T]) (T, error) {
 	return value, rows.Err()
 }
 
+// QueryAll runs sql with args on db and collects all rows into a slice of T with RowToStructByName. db is typically a
+// *Conn, a Tx, or a *pgxpool.Pool. It is a shortcut for calling Query followed by CollectRows:
+//
+//	users, err := pgx.QueryAll[User](ctx, conn, "select id, name from users where active = $1", true)
+//
+// Use Query with CollectRows directly to collect rows in another way.
+func QueryAll[T any](
+	ctx context.Context,
+	db interface {
+		Query(ctx context.Context, sql string, args ...any) (Rows, error)
+	},
+	sql string,
+	args ...any,
+) ([]T, error) {
+	rows, err := db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return CollectRows(rows, RowToStructByName[T])
+}
+
+// QueryOne runs sql with args on db and scans the first row into a T with RowToStructByName. db is typically a *Conn,
+// a Tx, or a *pgxpool.Pool. If no rows are found returns an error where errors.Is(ErrNoRows) is true. QueryOne is to
+// QueryAll as QueryRow is to Query.
+func QueryOne[T any](
+	ctx context.Context,
+	db interface {
+		Query(ctx context.Context, sql string, args ...any) (Rows, error)
+	},
+	sql string,
+	args ...any,
+) (T, error) {
+	rows, err := db.Query(ctx, sql, args...)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+
+	return CollectOneRow(rows, RowToStructByName[T])
+}
+
+// RowsIterator returns an iterator over rows that calls fn for each row and yields the result. It is compatible with
+// iter.Seq2[T, error] so with Go 1.23 or later it can be used in a range statement:
+//
+//	for user, err := range pgx.RowsIterator(rows, pgx.RowToStructByName[User]) {
+//		if err != nil {
+//			return err
+//		}
+//		// ...
+//	}
+//
+// Each row is yielded with a nil error. If fn fails or reading rows fails a final zero T is yielded with the error and
+// the iteration ends, so rows.Err() does not need to be checked after the loop. Rows will be closed when the iteration
+// ends, including when it is stopped early.
+func RowsIterator[T any](rows Rows, fn RowToFunc[T]) func(yield func(T, error) bool) {
+	return func(yield func(T, error) bool) {
+		defer rows.Close()
+
+		for rows.Next() {
+			value, err := fn(rows)
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+			if !yield(value, nil) {
+				return
+			}
+		}
+
+		if err := rows.Err(); err != nil {
+			var zero T
+			yield(zero, err)
+		}
+	}
+}
+
 // RowTo returns a T scanned from row.
 func RowTo[T any](row CollectableRow) (T, error) {
 	var value T
@@ -549,6 +702,7 @@ func (rs *positionalStructRowScanner) appendScanTargets(dstElemValue reflect.Val
 // RowToStructByName returns a T scanned from row. T must be a struct. T must have the same number of named public
 // fields as row has fields. The row and T fields will by matched by name. The match is case-insensitive. The database
 // column name can be overridden with a "db" struct tag. If the "db" struct tag is "-" then the field will be ignored.
+// See StructScanOptions.TagKey for the options of the tag. Use RowToStructByNameWith to configure the mapping.
 func RowToStructByName[T any](row CollectableRow) (T, error) {
 	var value T
 	err := row.Scan(&namedStructRowScanner{ptrToStruct: &value})
@@ -565,8 +719,86 @@ func RowToAddrOfStructByName[T any](row CollectableRow) (*T, error) {
 	return &value, err
 }
 
+// StructScanOptions configures how RowToStructByNameWith and RowToAddrOfStructByNameWith map row fields to struct
+// fields.
+type StructScanOptions struct {
+	// TagKey is the key of the struct tag that overrides the row field name of a struct field. Defaults to "db". It
+	// allows existing model structs with e.g. json tags to be scanned without duplicating the tags.
+	//
+	// The tag value is the row field name followed by comma separated options. If the name is empty the struct field
+	// name is used. A name of "-" or the omit option ignores the struct field. The default option makes the row field
+	// optional: if the row has no field with the name the struct field is left at its zero value instead of returning
+	// an error. Other options, such as omitempty, are ignored. For example, with TagKey "pg":
+	//
+	//	type User struct {
+	//		ID        int32
+	//		Email     string    `pg:"email_address"`
+	//		Password  string    `pg:"-"`
+	//		LastLogin time.Time `pg:",default"`
+	//	}
+	TagKey string
+
+	// NestedSeparator, if set, maps the fields of a nested struct field to row fields named by the name of the nested
+	// struct field, NestedSeparator, and the name of the field of the nested struct. For example, with NestedSeparator
+	// "_" the Name field of an Author field is scanned from the author_name row field. This allows the results of a
+	// join to be scanned into composed structs. Nested structs may be nested further.
+	//
+	// A struct field is only treated as nested if the row has no field with its name, so struct types that are
+	// scanned from a single row field, such as time.Time, are not affected. Pointers to structs are not nested.
+	NestedSeparator string
+}
+
+// RowToStructByNameWith returns a RowToFunc that scans a row into a T like RowToStructByName with the mapping
+// configured by opts, e.g. to use another struct tag or to scan nested structs.
+//
+//	type Book struct {
+//		ID     int32
+//		Title  string
+//		Author struct {
+//			ID   int32
+//			Name string
+//		}
+//	}
+//
+//	rows, _ := conn.Query(ctx, `select b.id, b.title, a.id as author_id, a.name as author_name
+//	  from books b join authors a on a.id = b.author_id`)
+//	books, err := pgx.CollectRows(rows, pgx.RowToStructByNameWith[Book](pgx.StructScanOptions{NestedSeparator: "_"}))
+func RowToStructByNameWith[T any](opts StructScanOptions) RowToFunc[T] {
+	return func(row CollectableRow) (T, error) {
+		var value T
+		err := row.Scan(&namedStructRowScanner{ptrToStruct: &value, opts: opts})
+		return value, err
+	}
+}
+
+// RowToAddrOfStructByNameWith returns a RowToFunc that scans a row into the address of a T like
+// RowToAddrOfStructByName with the mapping configured by opts.
+func RowToAddrOfStructByNameWith[T any](opts StructScanOptions) RowToFunc[*T] {
+	return func(row CollectableRow) (*T, error) {
+		var value T
+		err := row.Scan(&namedStructRowScanner{ptrToStruct: &value, opts: opts})
+		return &value, err
+	}
+}
+
 type namedStructRowScanner struct {
 	ptrToStruct any
+	opts        StructScanOptions
+}
+
+// parseStructTag parses the tag of a struct field into the row field name and options.
+func parseStructTag(tag string) (name string, opts []string) {
+	parts := strings.Split(tag, ",")
+	return parts[0], parts[1:]
+}
+
+func hasStructTagOption(opts []string, opt string) bool {
+	for _, o := range opts {
+		if strings.TrimSpace(o) == opt {
+			return true
+		}
+	}
+	return false
 }
 
 func (rs *namedStructRowScanner) ScanRow(rows Rows) error {
@@ -577,7 +809,7 @@ func (rs *namedStructRowScanner) ScanRow(rows Rows) error {
 	}
 
 	dstElemValue := dstValue.Elem()
-	scanTargets, err := rs.appendScanTargets(dstElemValue, nil, rows.FieldDescriptions())
+	scanTargets, err := rs.appendScanTargets(dstElemValue, nil, rows.FieldDescriptions(), "")
 
 	if err != nil {
 		return err
@@ -604,7 +836,9 @@ func fieldPosByName(fldDescs []pgconn.FieldDescription, field string) (i int) {
 	return
 }
 
-func (rs *namedStructRowScanner) appendScanTargets(dstElemValue reflect.Value, scanTargets []any, fldDescs []pgconn.FieldDescription) ([]any, error) {
+// appendScanTargets sets the scan targets of the fields of dstElemValue. prefix is prepended to the row field names of
+// the fields of a nested struct.
+func (rs *namedStructRowScanner) appendScanTargets(dstElemValue reflect.Value, scanTargets []any, fldDescs []pgconn.FieldDescription, prefix string) ([]any, error) {
 	var err error
 	dstElemType := dstElemValue.Type()
 
@@ -612,6 +846,11 @@ func (rs *namedStructRowScanner) appendScanTargets(dstElemValue reflect.Value, s
 		scanTargets = make([]any, len(fldDescs))
 	}
 
+	tagKey := rs.opts.TagKey
+	if tagKey == "" {
+		tagKey = structTagKey
+	}
+
 	for i := 0; i < dstElemType.NumField(); i++ {
 		sf := dstElemType.Field(i)
 		if sf.PkgPath != "" && !sf.Anonymous {
@@ -620,24 +859,37 @@ func (rs *namedStructRowScanner) appendScanTargets(dstElemValue reflect.Value, s
 		}
 		// Handle anoymous struct embedding, but do not try to handle embedded pointers.
 		if sf.Anonymous && sf.Type.Kind() == reflect.Struct {
-			scanTargets, err = rs.appendScanTargets(dstElemValue.Field(i), scanTargets, fldDescs)
+			scanTargets, err = rs.appendScanTargets(dstElemValue.Field(i), scanTargets, fldDescs, prefix)
 			if err != nil {
 				return nil, err
 			}
 		} else {
-			dbTag, dbTagPresent := sf.Tag.Lookup(structTagKey)
+			var tagOpts []string
+			dbTag, dbTagPresent := sf.Tag.Lookup(tagKey)
 			if dbTagPresent {
-				dbTag = strings.Split(dbTag, ",")[0]
+				dbTag, tagOpts = parseStructTag(dbTag)
 			}
-			if dbTag == "-" {
+			if dbTag == "-" || hasStructTagOption(tagOpts, "omit") {
 				// Field is ignored, skip it.
 				continue
 			}
 			colName := dbTag
-			if !dbTagPresent {
+			if colName == "" {
 				colName = sf.Name
 			}
+			colName = prefix + colName
 			fpos := fieldPosByName(fldDescs, colName)
+			if fpos == -1 && rs.opts.NestedSeparator != "" && sf.Type.Kind() == reflect.Struct {
+				scanTargets, err = rs.appendScanTargets(dstElemValue.Field(i), scanTargets, fldDescs, colName+rs.opts.NestedSeparator)
+				if err != nil {
+					return nil, err
+				}
+				continue
+			}
+			if fpos == -1 && hasStructTagOption(tagOpts, "default") {
+				// Field is optional, leave it at its zero value.
+				continue
+			}
 			if fpos == -1 || fpos >= len(scanTargets) {
 				return nil, fmt.Errorf("cannot find field %s in returned row", colName)
 			}
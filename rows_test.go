@@ -177,6 +177,102 @@ func ExampleCollectRows() {
 	// [1 2 3 4 5]
 }
 
+func TestQueryAll(t *testing.T) {
+	type person struct {
+		Name string
+		Age  int32
+	}
+
+	defaultConnTestRunner.RunTest(context.Background(), t, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		people, err := pgx.QueryAll[person](ctx, conn, `select 'Joe' as name, n as age from generate_series(0, $1::int) n`, 9)
+		require.NoError(t, err)
+
+		require.Len(t, people, 10)
+		for i := range people {
+			assert.Equal(t, "Joe", people[i].Name)
+			assert.EqualValues(t, i, people[i].Age)
+		}
+
+		_, err = pgx.QueryAll[person](ctx, conn, `select 'Joe' as name, n as age, 'x' as missing from generate_series(0, 9) n`)
+		require.ErrorContains(t, err, "missing")
+	})
+}
+
+func TestQueryOne(t *testing.T) {
+	type person struct {
+		Name string
+		Age  int32
+	}
+
+	defaultConnTestRunner.RunTest(context.Background(), t, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		p, err := pgx.QueryOne[person](ctx, conn, `select $1::text as name, 42 as age`, "Joe")
+		require.NoError(t, err)
+		assert.Equal(t, person{Name: "Joe", Age: 42}, p)
+
+		_, err = pgx.QueryOne[person](ctx, conn, `select 'Joe' as name, 42 as age where false`)
+		require.ErrorIs(t, err, pgx.ErrNoRows)
+	})
+}
+
+func TestRowsIterator(t *testing.T) {
+	defaultConnTestRunner.RunTest(context.Background(), t, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		rows, _ := conn.Query(ctx, `select n from generate_series(0, 99) n`)
+		var numbers []int32
+		pgx.RowsIterator(rows, pgx.RowTo[int32])(func(n int32, err error) bool {
+			require.NoError(t, err)
+			numbers = append(numbers, n)
+			return true
+		})
+
+		assert.Len(t, numbers, 100)
+		for i := range numbers {
+			assert.Equal(t, int32(i), numbers[i])
+		}
+		assert.True(t, rows.CommandTag().Select())
+	})
+}
+
+func TestRowsIteratorStop(t *testing.T) {
+	defaultConnTestRunner.RunTest(context.Background(), t, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		rows, _ := conn.Query(ctx, `select n from generate_series(0, 99) n`)
+		count := 0
+		pgx.RowsIterator(rows, pgx.RowTo[int32])(func(n int32, err error) bool {
+			require.NoError(t, err)
+			count++
+			return count < 3
+		})
+		assert.Equal(t, 3, count)
+
+		var n int32
+		err := conn.QueryRow(ctx, `select 42`).Scan(&n)
+		require.NoError(t, err)
+		assert.EqualValues(t, 42, n)
+	})
+}
+
+func TestRowsIteratorError(t *testing.T) {
+	defaultConnTestRunner.RunTest(context.Background(), t, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		rows, _ := conn.Query(ctx, `select 10 / (5 - n) from generate_series(1, 10) n`)
+		var numbers []int32
+		var iterErr error
+		calls := 0
+		pgx.RowsIterator(rows, pgx.RowTo[int32])(func(n int32, err error) bool {
+			calls++
+			if err != nil {
+				iterErr = err
+				return false
+			}
+			numbers = append(numbers, n)
+			return true
+		})
+
+		var pgErr *pgconn.PgError
+		require.ErrorAs(t, iterErr, &pgErr)
+		assert.Equal(t, "22012", pgErr.Code)
+		assert.Equal(t, len(numbers)+1, calls)
+	})
+}
+
 func TestCollectOneRow(t *testing.T) {
 	defaultConnTestRunner.RunTest(context.Background(), t, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
 		rows, _ := conn.Query(ctx, `select 42`)
@@ -576,6 +672,89 @@ func TestRowToStructByNameEmbeddedStruct(t *testing.T) {
 	})
 }
 
+func TestRowToStructByNameWithNestedStruct(t *testing.T) {
+	type address struct {
+		City string
+	}
+
+	type author struct {
+		ID      int32
+		Name    string
+		Address address
+	}
+
+	type book struct {
+		ID        int32
+		Title     string
+		Author    author `db:"writer"`
+		CreatedAt time.Time
+	}
+
+	defaultConnTestRunner.RunTest(context.Background(), t, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		opts := pgx.StructScanOptions{NestedSeparator: "_"}
+
+		rows, _ := conn.Query(ctx, `select n as id, 'Book ' || n as title, n + 100 as writer_id, 'Jane' as writer_name,
+  'Berlin' as writer_address_city, '2024-01-02 03:04:05+00'::timestamptz as createdat
+from generate_series(1, 3) n`)
+		slice, err := pgx.CollectRows(rows, pgx.RowToStructByNameWith[book](opts))
+		require.NoError(t, err)
+
+		require.Len(t, slice, 3)
+		for i := range slice {
+			assert.EqualValues(t, i+1, slice[i].ID)
+			assert.Equal(t, fmt.Sprintf("Book %d", i+1), slice[i].Title)
+			assert.EqualValues(t, i+101, slice[i].Author.ID)
+			assert.Equal(t, "Jane", slice[i].Author.Name)
+			assert.Equal(t, "Berlin", slice[i].Author.Address.City)
+			assert.True(t, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC).Equal(slice[i].CreatedAt))
+		}
+
+		rows, _ = conn.Query(ctx, `select 1 as id, 'Book' as title, 2 as writer_id, 'Jane' as writer_name, now() as createdat`)
+		_, err = pgx.CollectRows(rows, pgx.RowToAddrOfStructByNameWith[book](opts))
+		assert.ErrorContains(t, err, "cannot find field writer_Address_City in returned row")
+
+		// Nested structs are only flattened with a separator.
+		rows, _ = conn.Query(ctx, `select 1 as id, 'Book' as title, 2 as writer_id, 'Jane' as writer_name,
+  'Berlin' as writer_address_city, now() as createdat`)
+		_, err = pgx.CollectRows(rows, pgx.RowToStructByName[book])
+		assert.ErrorContains(t, err, "cannot find field writer in returned row")
+	})
+}
+
+func TestRowToStructByNameWithTagKey(t *testing.T) {
+	type person struct {
+		First    string `pg:"first_name"`
+		Last     string `pg:"last_name"`
+		Age      int32  `pg:",default"`
+		Password string `pg:"-"`
+	}
+
+	defaultConnTestRunner.RunTest(context.Background(), t, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		opts := pgx.StructScanOptions{TagKey: "pg"}
+
+		rows, _ := conn.Query(ctx, `select 'John' as first_name, 'Smith' as last_name, n as age from generate_series(0, 9) n`)
+		slice, err := pgx.CollectRows(rows, pgx.RowToStructByNameWith[person](opts))
+		require.NoError(t, err)
+
+		require.Len(t, slice, 10)
+		for i := range slice {
+			assert.Equal(t, "John", slice[i].First)
+			assert.Equal(t, "Smith", slice[i].Last)
+			assert.EqualValues(t, i, slice[i].Age)
+		}
+
+		// Age is optional.
+		rows, _ = conn.Query(ctx, `select 'John' as first_name, 'Smith' as last_name`)
+		p, err := pgx.CollectOneRow(rows, pgx.RowToAddrOfStructByNameWith[person](opts))
+		require.NoError(t, err)
+		assert.Equal(t, &person{First: "John", Last: "Smith"}, p)
+
+		rows, _ = conn.Query(ctx, `select 'John' as first_name, 'Smith' as last_name, 'secret' as password`)
+		_, err = pgx.CollectRows(rows, pgx.RowToStructByNameWith[person](opts))
+		assert.ErrorContains(t, err, "struct doesn't have corresponding row field password")
+	})
+}
+
 func ExampleRowToStructByName() {
 	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 	defer cancel()
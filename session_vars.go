@@ -0,0 +1,109 @@
+package pgx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// SetSessionVar sets the run-time parameter name, such as search_path or a custom parameter like app.user_id, to value
+// for the rest of the session. It uses set_config so neither name nor value need to be quoted. value is converted to
+// the text of the setting as follows: a string is used as is, a bool is on or off, a time.Duration is a whole number of
+// milliseconds rounded up, and any other value is formatted with fmt.Sprint.
+//
+// The value the parameter had before the first SetSessionVar for it is remembered so it can be restored with
+// ResetSessionVars. A *pgxpool.Conn restores them automatically when it is released so the settings do not leak to the
+// next user of the connection.
+func (c *Conn) SetSessionVar(ctx context.Context, name string, value any) error {
+	text := sessionVarText(value)
+
+	if _, saved := c.sessionVars[name]; saved {
+		_, err := c.Exec(ctx, "select set_config($1, $2, false)", name, text)
+		return err
+	}
+
+	// The parameter may not exist yet if it is a custom parameter. In that case NULL is saved, which resets the parameter
+	// when it is restored.
+	var prev *string
+	err := c.QueryRow(ctx, "select current_setting($1, true), set_config($1, $2, false)", name, text).Scan(&prev, nil)
+	if err != nil {
+		return err
+	}
+
+	if c.sessionVars == nil {
+		c.sessionVars = make(map[string]*string)
+	}
+	c.sessionVars[name] = prev
+
+	return nil
+}
+
+// SetLocalVar sets the run-time parameter name to value until the end of the current transaction. It returns an error
+// if c is not in a transaction. value is converted to text as with SetSessionVar.
+func (c *Conn) SetLocalVar(ctx context.Context, name string, value any) error {
+	if c.pgConn.TxStatus() != 'T' {
+		return errors.New("SetLocalVar must be called in a transaction")
+	}
+
+	_, err := c.Exec(ctx, "select set_config($1, $2, true)", name, sessionVarText(value))
+	return err
+}
+
+// GetVar reads the current value of the run-time parameter name into dest. If dest is a pointer to a type with a
+// registered PostgreSQL type, the setting is cast to that type on the server. e.g. on and off can be read into a *bool
+// and 5s into a *time.Duration. Otherwise dest is scanned from the text of the setting.
+func (c *Conn) GetVar(ctx context.Context, name string, dest any) error {
+	sql := "select current_setting($1)"
+	if dt, ok := c.typeMap.TypeForValue(dest); ok {
+		sql += "::" + dt.Name
+	}
+
+	return c.QueryRow(ctx, sql, name).Scan(dest)
+}
+
+// SessionVarsChanged returns true if run-time parameters have been changed with SetSessionVar since c was established
+// or ResetSessionVars was last called.
+func (c *Conn) SessionVarsChanged() bool {
+	return len(c.sessionVars) > 0
+}
+
+// ResetSessionVars restores the run-time parameters changed with SetSessionVar to the values they had before they were
+// first changed.
+func (c *Conn) ResetSessionVars(ctx context.Context) error {
+	if len(c.sessionVars) == 0 {
+		return nil
+	}
+
+	b := &Batch{}
+	for name, value := range c.sessionVars {
+		b.Queue("select set_config($1, $2, false)", name, value)
+	}
+
+	err := c.SendBatch(ctx, b).Close()
+	if err != nil {
+		return err
+	}
+
+	c.sessionVars = nil
+	return nil
+}
+
+// sessionVarText returns the text of the setting for value.
+func sessionVarText(value any) string {
+	switch value := value.(type) {
+	case string:
+		return value
+	case bool:
+		if value {
+			return "on"
+		}
+		return "off"
+	case time.Duration:
+		ms := (value + time.Millisecond - 1) / time.Millisecond
+		return strconv.FormatInt(int64(ms), 10) + "ms"
+	default:
+		return fmt.Sprint(value)
+	}
+}
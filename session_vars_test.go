@@ -0,0 +1,95 @@
+package pgx_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnSetSessionVar(t *testing.T) {
+	t.Parallel()
+
+	defaultConnTestRunner.RunTest(context.Background(), t, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		var original string
+		require.NoError(t, conn.GetVar(ctx, "search_path", &original))
+		require.False(t, conn.SessionVarsChanged())
+
+		require.NoError(t, conn.SetSessionVar(ctx, "search_path", `"it's", public`))
+		require.True(t, conn.SessionVarsChanged())
+
+		var searchPath string
+		require.NoError(t, conn.GetVar(ctx, "search_path", &searchPath))
+		require.Equal(t, `"it's", public`, searchPath)
+
+		require.NoError(t, conn.SetSessionVar(ctx, "search_path", "public"))
+		require.NoError(t, conn.ResetSessionVars(ctx))
+		require.False(t, conn.SessionVarsChanged())
+
+		require.NoError(t, conn.GetVar(ctx, "search_path", &searchPath))
+		require.Equal(t, original, searchPath)
+	})
+}
+
+func TestConnSetSessionVarCustomParameter(t *testing.T) {
+	t.Parallel()
+
+	defaultConnTestRunner.RunTest(context.Background(), t, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		require.NoError(t, conn.SetSessionVar(ctx, "pgx.user_id", 42))
+
+		var userID int64
+		require.NoError(t, conn.GetVar(ctx, "pgx.user_id", &userID))
+		require.EqualValues(t, 42, userID)
+
+		require.NoError(t, conn.ResetSessionVars(ctx))
+
+		var s *string
+		require.NoError(t, conn.QueryRow(ctx, "select nullif(current_setting('pgx.user_id', true), '')").Scan(&s))
+		require.Nil(t, s)
+	})
+}
+
+func TestConnGetVarTyped(t *testing.T) {
+	t.Parallel()
+
+	defaultConnTestRunner.RunTest(context.Background(), t, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		require.NoError(t, conn.SetSessionVar(ctx, "statement_timeout", 1500*time.Millisecond))
+		require.NoError(t, conn.SetSessionVar(ctx, "enable_seqscan", false))
+
+		var timeout time.Duration
+		require.NoError(t, conn.GetVar(ctx, "statement_timeout", &timeout))
+		require.Equal(t, 1500*time.Millisecond, timeout)
+
+		var enableSeqscan bool
+		require.NoError(t, conn.GetVar(ctx, "enable_seqscan", &enableSeqscan))
+		require.False(t, enableSeqscan)
+
+		require.NoError(t, conn.ResetSessionVars(ctx))
+
+		require.NoError(t, conn.GetVar(ctx, "enable_seqscan", &enableSeqscan))
+		require.True(t, enableSeqscan)
+	})
+}
+
+func TestConnSetLocalVar(t *testing.T) {
+	t.Parallel()
+
+	defaultConnTestRunner.RunTest(context.Background(), t, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		require.Error(t, conn.SetLocalVar(ctx, "pgx.local_var", "foo"))
+
+		tx, err := conn.Begin(ctx)
+		require.NoError(t, err)
+		require.NoError(t, conn.SetLocalVar(ctx, "pgx.local_var", "foo"))
+
+		var s string
+		require.NoError(t, conn.GetVar(ctx, "pgx.local_var", &s))
+		require.Equal(t, "foo", s)
+		require.NoError(t, tx.Rollback(ctx))
+		require.False(t, conn.SessionVarsChanged())
+
+		require.NoError(t, conn.QueryRow(ctx, "select coalesce(current_setting('pgx.local_var', true), '')").Scan(&s))
+		require.Equal(t, "", s)
+	})
+}
@@ -0,0 +1,73 @@
+package pgx
+
+import (
+	"context"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// annotateSQL returns sql with the tags of ConnConfig.SQLCommentTags appended as a comment. sql is returned unchanged
+// if it is the name of a statement prepared with Prepare.
+func (c *Conn) annotateSQL(ctx context.Context, sql string) string {
+	if c.config.SQLCommentTags == nil || sql == "" {
+		return sql
+	}
+	if _, ok := c.preparedStatements[sql]; ok {
+		return sql
+	}
+
+	return AppendSQLComment(sql, c.config.SQLCommentTags(ctx))
+}
+
+// AppendSQLComment returns sql with tags appended as a comment in the sqlcommenter format
+// (https://google.github.io/sqlcommenter/spec/), e.g.
+//
+//	select 1 /*application='billing',traceparent='00-5bd66ef5095369c7b0d1f8f4bd33716a-c532cb4098ac3dd2-01'*/
+//
+// The tags are sorted by key and the keys and values are URL encoded so they cannot terminate the comment. If sql ends
+// with a semicolon the comment is placed before it. sql is returned unchanged if tags is empty or sql already contains a
+// comment, as required by the specification.
+//
+// AppendSQLComment is used by ConnConfig.SQLCommentTags. It is exported for SQL that is sent by other means.
+func AppendSQLComment(sql string, tags map[string]string) string {
+	if len(tags) == 0 || strings.Contains(sql, "/*") || strings.Contains(sql, "--") {
+		return sql
+	}
+
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	body := strings.TrimRight(sql, " \t\r\n")
+	terminated := strings.HasSuffix(body, ";")
+	if terminated {
+		body = body[:len(body)-1]
+	}
+
+	sb := &strings.Builder{}
+	sb.WriteString(body)
+	sb.WriteString(" /*")
+	for i, k := range keys {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(sqlCommentEscape(k))
+		sb.WriteString("='")
+		sb.WriteString(sqlCommentEscape(tags[k]))
+		sb.WriteByte('\'')
+	}
+	sb.WriteString("*/")
+	if terminated {
+		sb.WriteByte(';')
+	}
+
+	return sb.String()
+}
+
+// sqlCommentEscape URL encodes s for a sqlcommenter comment. It encodes spaces as %20 rather than +.
+func sqlCommentEscape(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
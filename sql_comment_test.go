@@ -0,0 +1,127 @@
+package pgx_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxtest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppendSQLComment(t *testing.T) {
+	t.Parallel()
+
+	for i, tt := range []struct {
+		sql      string
+		tags     map[string]string
+		expected string
+	}{
+		{
+			sql:      "select 1",
+			tags:     map[string]string{"traceparent": "00-5bd66ef5095369c7b0d1f8f4bd33716a-c532cb4098ac3dd2-01", "application": "billing"},
+			expected: "select 1 /*application='billing',traceparent='00-5bd66ef5095369c7b0d1f8f4bd33716a-c532cb4098ac3dd2-01'*/",
+		},
+		{
+			sql:      "select 1;\n",
+			tags:     map[string]string{"route": "/users/{id}"},
+			expected: "select 1 /*route='%2Fusers%2F%7Bid%7D'*/;",
+		},
+		{
+			sql:      "select 1",
+			tags:     map[string]string{"it's": "a */ drop table users; --", "caller": "main.run foo.go:12"},
+			expected: "select 1 /*caller='main.run%20foo.go%3A12',it%27s='a%20%2A%2F%20drop%20table%20users%3B%20--'*/",
+		},
+		{
+			sql:      "select 1",
+			tags:     nil,
+			expected: "select 1",
+		},
+		{
+			sql:      "select 1 /* existing */",
+			tags:     map[string]string{"application": "billing"},
+			expected: "select 1 /* existing */",
+		},
+		{
+			sql:      "select 1 -- existing",
+			tags:     map[string]string{"application": "billing"},
+			expected: "select 1 -- existing",
+		},
+	} {
+		require.Equalf(t, tt.expected, pgx.AppendSQLComment(tt.sql, tt.tags), "%d", i)
+	}
+}
+
+type sqlCommentTagsKey struct{}
+
+func sqlCommentConnTestRunner() pgxtest.ConnTestRunner {
+	ctr := defaultConnTestRunner
+	ctr.CreateConfig = func(ctx context.Context, t testing.TB) *pgx.ConnConfig {
+		config := defaultConnTestRunner.CreateConfig(ctx, t)
+		config.SQLCommentTags = func(ctx context.Context) map[string]string {
+			tags := map[string]string{"application": "pgx_test"}
+			if route, ok := ctx.Value(sqlCommentTagsKey{}).(string); ok {
+				tags["route"] = route
+			}
+			return tags
+		}
+		return config
+	}
+	return ctr
+}
+
+func TestSQLCommentTags(t *testing.T) {
+	t.Parallel()
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, sqlCommentConnTestRunner(), nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		var query string
+		err := conn.QueryRow(ctx, "select current_query()").Scan(&query)
+		require.NoError(t, err)
+		require.Equal(t, "select current_query() /*application='pgx_test'*/", query)
+
+		routeCtx := context.WithValue(ctx, sqlCommentTagsKey{}, "/users")
+		err = conn.QueryRow(routeCtx, "select current_query()").Scan(&query)
+		require.NoError(t, err)
+		require.Equal(t, "select current_query() /*application='pgx_test',route='%2Fusers'*/", query)
+
+		_, err = conn.Exec(ctx, "create temporary table sql_comment (q text)")
+		require.NoError(t, err)
+		_, err = conn.Exec(ctx, "insert into sql_comment values (current_query())")
+		require.NoError(t, err)
+		err = conn.QueryRow(ctx, "select q from sql_comment").Scan(&query)
+		require.NoError(t, err)
+		require.Equal(t, "insert into sql_comment values (current_query()) /*application='pgx_test'*/", query)
+
+		batch := &pgx.Batch{}
+		batch.Queue("select current_query()").QueryRow(func(row pgx.Row) error {
+			return row.Scan(&query)
+		})
+		err = conn.SendBatch(ctx, batch).Close()
+		require.NoError(t, err)
+		require.Contains(t, query, "select current_query() /*application='pgx_test'*/")
+
+		_, err = conn.CopyFrom(ctx, pgx.Identifier{"sql_comment"}, []string{"q"}, pgx.CopyFromRows([][]any{{"a"}}))
+		require.NoError(t, err)
+	})
+}
+
+func TestSQLCommentTagsSkipsPreparedStatementNames(t *testing.T) {
+	t.Parallel()
+
+	config := mustParseConfig(t, os.Getenv("PGX_TEST_DATABASE"))
+	config.SQLCommentTags = func(ctx context.Context) map[string]string {
+		return map[string]string{"application": "pgx_test"}
+	}
+	conn := mustConnect(t, config)
+	defer closeConn(t, conn)
+
+	ctx := context.Background()
+	_, err := conn.Prepare(ctx, "ps", "select current_query()")
+	require.NoError(t, err)
+
+	var query string
+	err = conn.QueryRow(ctx, "ps").Scan(&query)
+	require.NoError(t, err)
+	require.Equal(t, "select current_query()", query)
+}
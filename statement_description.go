@@ -0,0 +1,110 @@
+package pgx
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// PreparedStatement returns the description of the statement prepared with name by Prepare. It returns nil if no
+// statement with name has been prepared.
+func (c *Conn) PreparedStatement(name string) *pgconn.StatementDescription {
+	return c.preparedStatements[name]
+}
+
+// StatementTypes are the parameter and result field types of a prepared statement mapped through a *pgtype.Map.
+type StatementTypes struct {
+	// Params are the types of the statement parameters. An element is nil if the type is not registered.
+	Params []*pgtype.Type
+
+	// Fields are the types of the statement result fields. An element is nil if the type is not registered.
+	Fields []*pgtype.Type
+}
+
+// DescribeStatementTypes maps the parameter OIDs and result field types of sd through m. Types that are not
+// registered in m, e.g. enums or composites that have not been loaded with LoadType, are nil.
+func DescribeStatementTypes(m *pgtype.Map, sd *pgconn.StatementDescription) StatementTypes {
+	st := StatementTypes{
+		Params: make([]*pgtype.Type, len(sd.ParamOIDs)),
+		Fields: make([]*pgtype.Type, len(sd.Fields)),
+	}
+
+	for i, oid := range sd.ParamOIDs {
+		st.Params[i], _ = m.TypeForOID(oid)
+	}
+	for i := range sd.Fields {
+		st.Fields[i], _ = m.TypeForOID(sd.Fields[i].DataTypeOID)
+	}
+
+	return st
+}
+
+// CheckStatementArgs returns an error if args cannot be encoded as the parameters of sd with m. The args are encoded
+// as they would be when sd is executed, so the check also covers their values, e.g. an int64 that overflows an int4
+// parameter.
+func CheckStatementArgs(m *pgtype.Map, sd *pgconn.StatementDescription, args ...any) error {
+	var eqb ExtendedQueryBuilder
+	return eqb.Build(m, sd, args)
+}
+
+// CheckStatementScan returns an error if the result fields of sd cannot be scanned into dest with m. The error is a
+// ScanArgError for the first field that is incompatible with its destination. A destination of nil skips the field,
+// as with Rows.Scan.
+func CheckStatementScan(m *pgtype.Map, sd *pgconn.StatementDescription, dest ...any) error {
+	if len(sd.Fields) != len(dest) {
+		return fmt.Errorf("number of field descriptions must equal number of destinations, got %d and %d", len(sd.Fields), len(dest))
+	}
+
+	for i, d := range dest {
+		if d == nil {
+			continue
+		}
+
+		oid := sd.Fields[i].DataTypeOID
+		if !m.CanScan(oid, m.FormatCodeForOID(oid), d) {
+			return ScanArgError{ColumnIndex: i, Err: fmt.Errorf("cannot scan %s into %T", statementFieldTypeName(m, &sd.Fields[i]), d)}
+		}
+	}
+
+	return nil
+}
+
+// CheckStatementStruct returns an error if the result of sd cannot be scanned into a T with RowToStructByName or
+// RowToAddrOfStructByName. It can be used to check at startup that a query and the struct it is scanned into are
+// compatible.
+func CheckStatementStruct[T any](m *pgtype.Map, sd *pgconn.StatementDescription) error {
+	return CheckStatementStructWith[T](m, sd, StructScanOptions{})
+}
+
+// CheckStatementStructWith returns an error if the result of sd cannot be scanned into a T with
+// RowToStructByNameWith or RowToAddrOfStructByNameWith and opts.
+func CheckStatementStructWith[T any](m *pgtype.Map, sd *pgconn.StatementDescription, opts StructScanOptions) error {
+	var value T
+	dstElemValue := reflect.ValueOf(&value).Elem()
+	if dstElemValue.Kind() != reflect.Struct {
+		return fmt.Errorf("%T is not a struct", value)
+	}
+
+	rs := &namedStructRowScanner{ptrToStruct: &value, opts: opts}
+	scanTargets, err := rs.appendScanTargets(dstElemValue, nil, sd.Fields, "")
+	if err != nil {
+		return err
+	}
+
+	for i, t := range scanTargets {
+		if t == nil {
+			return fmt.Errorf("struct doesn't have corresponding row field %s", sd.Fields[i].Name)
+		}
+	}
+
+	return CheckStatementScan(m, sd, scanTargets...)
+}
+
+func statementFieldTypeName(m *pgtype.Map, fd *pgconn.FieldDescription) string {
+	if dt, ok := m.TypeForOID(fd.DataTypeOID); ok {
+		return fmt.Sprintf("%s %s", fd.Name, dt.Name)
+	}
+	return fmt.Sprintf("%s (OID %d)", fd.Name, fd.DataTypeOID)
+}
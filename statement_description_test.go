@@ -0,0 +1,169 @@
+package pgx_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreparedStatementDescription(t *testing.T) {
+	t.Parallel()
+
+	defaultConnTestRunner.RunTest(context.Background(), t, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		require.Nil(t, conn.PreparedStatement("ps"))
+
+		sd, err := conn.Prepare(ctx, "ps", "select $1::int4 as id, $2::text as name, now() as created_at")
+		require.NoError(t, err)
+		require.Same(t, sd, conn.PreparedStatement("ps"))
+
+		st := pgx.DescribeStatementTypes(conn.TypeMap(), sd)
+		require.Len(t, st.Params, 2)
+		require.Equal(t, "int4", st.Params[0].Name)
+		require.Equal(t, "text", st.Params[1].Name)
+		require.Len(t, st.Fields, 3)
+		require.Equal(t, "int4", st.Fields[0].Name)
+		require.Equal(t, "text", st.Fields[1].Name)
+		require.Equal(t, "timestamptz", st.Fields[2].Name)
+
+		require.NoError(t, conn.Deallocate(ctx, "ps"))
+		require.Nil(t, conn.PreparedStatement("ps"))
+	})
+}
+
+func newCheckStatementDescription() *pgconn.StatementDescription {
+	return &pgconn.StatementDescription{
+		SQL:       "select id, name, created_at from widgets where id = $1 and name = $2",
+		ParamOIDs: []uint32{pgtype.Int4OID, pgtype.TextOID},
+		Fields: []pgconn.FieldDescription{
+			{Name: "id", DataTypeOID: pgtype.Int4OID},
+			{Name: "name", DataTypeOID: pgtype.TextOID},
+			{Name: "created_at", DataTypeOID: pgtype.TimestamptzOID},
+		},
+	}
+}
+
+func TestDescribeStatementTypesUnregisteredType(t *testing.T) {
+	t.Parallel()
+
+	m := pgtype.NewMap()
+	sd := &pgconn.StatementDescription{
+		ParamOIDs: []uint32{pgtype.Int8OID, 999999},
+		Fields:    []pgconn.FieldDescription{{Name: "e", DataTypeOID: 999999}},
+	}
+
+	st := pgx.DescribeStatementTypes(m, sd)
+	require.Equal(t, "int8", st.Params[0].Name)
+	require.Nil(t, st.Params[1])
+	require.Nil(t, st.Fields[0])
+}
+
+func TestCheckStatementArgs(t *testing.T) {
+	t.Parallel()
+
+	m := pgtype.NewMap()
+	sd := newCheckStatementDescription()
+
+	require.NoError(t, pgx.CheckStatementArgs(m, sd, int32(1), "foo"))
+	require.NoError(t, pgx.CheckStatementArgs(m, sd, nil, nil))
+	require.Error(t, pgx.CheckStatementArgs(m, sd, int32(1)))
+	require.Error(t, pgx.CheckStatementArgs(m, sd, int64(1<<40), "foo"))
+	require.Error(t, pgx.CheckStatementArgs(m, sd, time.Now(), "foo"))
+}
+
+func TestCheckStatementScan(t *testing.T) {
+	t.Parallel()
+
+	m := pgtype.NewMap()
+	sd := newCheckStatementDescription()
+
+	var id int32
+	var name string
+	var createdAt time.Time
+	require.NoError(t, pgx.CheckStatementScan(m, sd, &id, &name, &createdAt))
+	require.NoError(t, pgx.CheckStatementScan(m, sd, &id, nil, nil))
+	require.Error(t, pgx.CheckStatementScan(m, sd, &id, &name))
+
+	var b bool
+	err := pgx.CheckStatementScan(m, sd, &id, &name, &b)
+	var scanArgErr pgx.ScanArgError
+	require.ErrorAs(t, err, &scanArgErr)
+	require.Equal(t, 2, scanArgErr.ColumnIndex)
+	require.Contains(t, err.Error(), "created_at timestamptz")
+}
+
+func TestCheckStatementStruct(t *testing.T) {
+	t.Parallel()
+
+	m := pgtype.NewMap()
+	sd := newCheckStatementDescription()
+
+	type widget struct {
+		ID        int32
+		Name      string
+		CreatedAt time.Time `db:"created_at"`
+	}
+	require.NoError(t, pgx.CheckStatementStruct[widget](m, sd))
+
+	type wrongType struct {
+		ID        bool
+		Name      string
+		CreatedAt time.Time `db:"created_at"`
+	}
+	require.Error(t, pgx.CheckStatementStruct[wrongType](m, sd))
+
+	type missingField struct {
+		ID   int32
+		Name string
+	}
+	require.Error(t, pgx.CheckStatementStruct[missingField](m, sd))
+
+	type extraField struct {
+		ID        int32
+		Name      string
+		CreatedAt time.Time `db:"created_at"`
+		UpdatedAt time.Time `db:"updated_at"`
+	}
+	require.Error(t, pgx.CheckStatementStruct[extraField](m, sd))
+
+	require.Error(t, pgx.CheckStatementStruct[int32](m, sd))
+}
+
+func TestCheckStatementStructWith(t *testing.T) {
+	t.Parallel()
+
+	m := pgtype.NewMap()
+	sd := newCheckStatementDescription()
+	opts := pgx.StructScanOptions{TagKey: "json"}
+
+	type widget struct {
+		ID        int32     `json:"id"`
+		Label     string    `json:"name,omitempty"`
+		CreatedAt time.Time `json:"created_at"`
+		Secret    string    `json:"-"`
+		Internal  string    `json:"internal,omit"`
+		UpdatedAt time.Time `json:"updated_at,default"`
+		Owner     string    `json:",default"`
+	}
+	require.NoError(t, pgx.CheckStatementStructWith[widget](m, sd, opts))
+
+	// The db tag is not used.
+	type dbTagged struct {
+		ID        int32
+		Name      string
+		CreatedAt time.Time `db:"created_at"`
+	}
+	require.Error(t, pgx.CheckStatementStructWith[dbTagged](m, sd, opts))
+	require.NoError(t, pgx.CheckStatementStruct[dbTagged](m, sd))
+
+	type missingField struct {
+		ID        int32     `json:"id"`
+		CreatedAt time.Time `json:"created_at"`
+		UpdatedAt time.Time `json:"updated_at"`
+	}
+	require.EqualError(t, pgx.CheckStatementStructWith[missingField](m, sd, opts), "cannot find field updated_at in returned row")
+}
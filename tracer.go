@@ -2,6 +2,7 @@ package pgx
 
 import (
 	"context"
+	"time"
 
 	"github.com/jackc/pgx/v5/pgconn"
 )
@@ -25,6 +26,16 @@ type TraceQueryEndData struct {
 	Err        error
 }
 
+// NoopQueryTracer is a QueryTracer that does nothing. Set QueryOptions.Tracer to NoopQueryTracer{} to not trace a query
+// run on a connection that has a tracer.
+type NoopQueryTracer struct{}
+
+func (NoopQueryTracer) TraceQueryStart(ctx context.Context, conn *Conn, data TraceQueryStartData) context.Context {
+	return ctx
+}
+
+func (NoopQueryTracer) TraceQueryEnd(ctx context.Context, conn *Conn, data TraceQueryEndData) {}
+
 // BatchTracer traces SendBatch.
 type BatchTracer interface {
 	// TraceBatchStart is called at the beginning of SendBatch calls. The returned context is used for the
@@ -35,8 +46,32 @@ type BatchTracer interface {
 	TraceBatchEnd(ctx context.Context, conn *Conn, data TraceBatchEndData)
 }
 
+// NoopBatchTracer is a BatchTracer that does nothing. Set SendBatchOptions.Tracer to NoopBatchTracer{} to not trace a
+// batch sent on a connection that has a tracer.
+type NoopBatchTracer struct{}
+
+func (NoopBatchTracer) TraceBatchStart(ctx context.Context, conn *Conn, data TraceBatchStartData) context.Context {
+	return ctx
+}
+
+func (NoopBatchTracer) TraceBatchQuery(ctx context.Context, conn *Conn, data TraceBatchQueryData) {}
+
+func (NoopBatchTracer) TraceBatchEnd(ctx context.Context, conn *Conn, data TraceBatchEndData) {}
+
 type TraceBatchStartData struct {
 	Batch *Batch
+
+	// AcquireDuration is the time spent acquiring the connection the batch is sent on. It is only set when the batch is
+	// sent through a connection pool that reports it with ContextWithBatchAcquireDuration (e.g. pgxpool.Pool.SendBatch).
+	AcquireDuration time.Duration
+}
+
+type batchAcquireDurationCtxKey struct{}
+
+// ContextWithBatchAcquireDuration returns a copy of ctx that reports d as the time spent acquiring a connection to
+// TraceBatchStart when ctx is passed to SendBatch. It is intended for connection pool implementations.
+func ContextWithBatchAcquireDuration(ctx context.Context, d time.Duration) context.Context {
+	return context.WithValue(ctx, batchAcquireDurationCtxKey{}, d)
 }
 
 type TraceBatchQueryData struct {
@@ -48,6 +83,52 @@ type TraceBatchQueryData struct {
 
 type TraceBatchEndData struct {
 	Err error
+
+	// FirstByteLatency is the time from when the batch was flushed to the server until its first result was received.
+	// It includes the network round trip and the time until the server sent its first response. The server usually
+	// buffers responses until the end of the batch unless they exceed its send buffer, so this is typically the time
+	// until the batch finished on the server as observed by the client. It does not include the time taken to read the
+	// results. It is zero if no result was read or the batch was not traced.
+	FirstByteLatency time.Duration
+
+	// MaxPipelineDepth is the largest number of queries that were sent in pipeline mode but not yet synced. It includes
+	// statements pgx adds to the batch such as those of Batch.SetApplicationName. It is zero for batches that were not
+	// pipelined, i.e. those sent with QueryExecModeExec or QueryExecModeSimpleProtocol.
+	MaxPipelineDepth int
+}
+
+// BatchRetryTracer traces retries of batched queries. It is enabled by setting ConnConfig.Tracer to a value that also
+// implements BatchRetryTracer. It is only called by features that retry batches such as SendBatchWithRetry.
+type BatchRetryTracer interface {
+	// TraceBatchRetry is called each time a batch or a query in a batch is about to be retried.
+	TraceBatchRetry(ctx context.Context, conn *Conn, data TraceBatchRetryData)
+}
+
+type TraceBatchRetryData struct {
+	// ItemIndex is the index of the query whose failure caused the retry.
+	ItemIndex int
+
+	// Attempt is the number of the attempt that is about to be made. The first retry is attempt 2.
+	Attempt int
+
+	// Reason is a short description of why the retry is happening.
+	Reason string
+
+	// Err is the error that caused the retry.
+	Err error
+}
+
+// BatchQueryInterceptor can serve the results of queries read with BatchResults.Query or BatchResults.QueryRow from
+// somewhere other than the server such as a cache. It is enabled by setting ConnConfig.Tracer to a value that also
+// implements BatchQueryInterceptor.
+type BatchQueryInterceptor interface {
+	// InterceptQuery is called before the result of each batched query read with BatchResults.Query or
+	// BatchResults.QueryRow. If it returns true the returned Rows are given to the caller instead of the server's result.
+	// The server's result is still read and discarded to keep the connection synchronized. If reading it fails the
+	// returned Rows are closed and the error is returned instead.
+	//
+	// The returned Rows must not depend on the connection.
+	InterceptQuery(ctx context.Context, sql string, args []any) (Rows, bool)
 }
 
 // CopyFromTracer traces CopyFrom.
@@ -2,9 +2,13 @@ package pgx_test
 
 import (
 	"context"
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxtest"
 	"github.com/stretchr/testify/require"
 )
@@ -21,6 +25,7 @@ type testTracer struct {
 	tracePrepareEnd    func(ctx context.Context, conn *pgx.Conn, data pgx.TracePrepareEndData)
 	traceConnectStart  func(ctx context.Context, data pgx.TraceConnectStartData) context.Context
 	traceConnectEnd    func(ctx context.Context, data pgx.TraceConnectEndData)
+	traceBatchRetry    func(ctx context.Context, conn *pgx.Conn, data pgx.TraceBatchRetryData)
 }
 
 func (tt *testTracer) TraceQueryStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
@@ -94,6 +99,12 @@ func (tt *testTracer) TraceConnectEnd(ctx context.Context, data pgx.TraceConnect
 	}
 }
 
+func (tt *testTracer) TraceBatchRetry(ctx context.Context, conn *pgx.Conn, data pgx.TraceBatchRetryData) {
+	if tt.traceBatchRetry != nil {
+		tt.traceBatchRetry(ctx, conn, data)
+	}
+}
+
 func TestTraceExec(t *testing.T) {
 	t.Parallel()
 
@@ -170,6 +181,80 @@ func TestTraceQuery(t *testing.T) {
 	})
 }
 
+func TestTraceBatchFirstByteLatency(t *testing.T) {
+	t.Parallel()
+
+	tracer := &testTracer{}
+
+	ctr := defaultConnTestRunner
+	ctr.CreateConfig = func(ctx context.Context, t testing.TB) *pgx.ConnConfig {
+		config := defaultConnTestRunner.CreateConfig(ctx, t)
+		config.Tracer = tracer
+		return config
+	}
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, ctr, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		var firstByteLatency time.Duration
+		tracer.traceBatchEnd = func(ctx context.Context, conn *pgx.Conn, data pgx.TraceBatchEndData) {
+			firstByteLatency = data.FirstByteLatency
+		}
+		defer func() { tracer.traceBatchEnd = nil }()
+
+		batch := &pgx.Batch{}
+		batch.Queue(`select pg_sleep(0.05)`)
+		batch.Queue(`select n from generate_series(1, 10) n`)
+
+		start := time.Now()
+		br := conn.SendBatch(context.Background(), batch)
+		_, err := br.Exec()
+		require.NoError(t, err)
+
+		// Time spent by the client after the first result was received is not included.
+		time.Sleep(100 * time.Millisecond)
+		err = br.Close()
+		require.NoError(t, err)
+
+		require.GreaterOrEqual(t, firstByteLatency, 50*time.Millisecond)
+		require.Less(t, firstByteLatency, time.Since(start)-100*time.Millisecond)
+	})
+}
+
+func TestTraceBatchMaxPipelineDepth(t *testing.T) {
+	t.Parallel()
+
+	tracer := &testTracer{}
+
+	ctr := defaultConnTestRunner
+	ctr.CreateConfig = func(ctx context.Context, t testing.TB) *pgx.ConnConfig {
+		config := defaultConnTestRunner.CreateConfig(ctx, t)
+		config.Tracer = tracer
+		return config
+	}
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, ctr, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		maxPipelineDepth := -1
+		tracer.traceBatchEnd = func(ctx context.Context, conn *pgx.Conn, data pgx.TraceBatchEndData) {
+			maxPipelineDepth = data.MaxPipelineDepth
+		}
+		defer func() { tracer.traceBatchEnd = nil }()
+
+		batch := &pgx.Batch{}
+		batch.Queue(`select 1`)
+		batch.Queue(`select 2`)
+		batch.Queue(`select 3`)
+
+		err := conn.SendBatch(context.Background(), batch).Close()
+		require.NoError(t, err)
+
+		switch conn.Config().DefaultQueryExecMode {
+		case pgx.QueryExecModeExec, pgx.QueryExecModeSimpleProtocol:
+			require.Equal(t, 0, maxPipelineDepth)
+		default:
+			require.Equal(t, 3, maxPipelineDepth)
+		}
+	})
+}
+
 func TestTraceBatchNormal(t *testing.T) {
 	t.Parallel()
 
@@ -278,6 +363,44 @@ func TestTraceBatchClose(t *testing.T) {
 	})
 }
 
+func TestTraceBatchDiscardNext(t *testing.T) {
+	t.Parallel()
+
+	tracer := &testTracer{}
+
+	ctr := defaultConnTestRunner
+	ctr.CreateConfig = func(ctx context.Context, t testing.TB) *pgx.ConnConfig {
+		config := defaultConnTestRunner.CreateConfig(ctx, t)
+		config.Tracer = tracer
+		return config
+	}
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, ctr, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		var tracedSQL []string
+		tracer.traceBatchQuery = func(ctx context.Context, conn *pgx.Conn, data pgx.TraceBatchQueryData) {
+			tracedSQL = append(tracedSQL, data.SQL)
+		}
+
+		batch := &pgx.Batch{}
+		batch.Queue(`select 1`)
+		batch.Queue(`select 2`)
+		batch.Queue(`select 3`)
+
+		br := conn.SendBatch(context.Background(), batch)
+		require.NoError(t, pgx.BatchDiscardNext(br))
+
+		var n int32
+		err := br.QueryRow().Scan(&n)
+		require.NoError(t, err)
+		require.EqualValues(t, 2, n)
+
+		require.NoError(t, pgx.BatchDiscardNext(br))
+		require.NoError(t, pgx.VerifyBatchComplete(br))
+		require.NoError(t, br.Close())
+		require.Equal(t, []string{`select 2`}, tracedSQL)
+	})
+}
+
 func TestTraceBatchErrorWhileReadingResults(t *testing.T) {
 	t.Parallel()
 
@@ -397,6 +520,129 @@ func TestTraceBatchErrorWhileReadingResultsWhileClosing(t *testing.T) {
 	})
 }
 
+type interceptingTracer struct {
+	testTracer
+	interceptQuery func(ctx context.Context, sql string, args []any) (pgx.Rows, bool)
+}
+
+func (it *interceptingTracer) InterceptQuery(ctx context.Context, sql string, args []any) (pgx.Rows, bool) {
+	return it.interceptQuery(ctx, sql, args)
+}
+
+// staticRows is a pgx.Rows of a single text formatted int4 column.
+type staticRows struct {
+	values  []string
+	idx     int
+	closed  bool
+	typeMap *pgtype.Map
+}
+
+func (r *staticRows) Close() {
+	r.closed = true
+}
+
+func (r *staticRows) Err() error {
+	return nil
+}
+
+func (r *staticRows) CommandTag() pgconn.CommandTag {
+	return pgconn.NewCommandTag("SELECT " + strconv.Itoa(len(r.values)))
+}
+
+func (r *staticRows) FieldDescriptions() []pgconn.FieldDescription {
+	return []pgconn.FieldDescription{{Name: "n", DataTypeOID: pgtype.Int4OID, Format: pgx.TextFormatCode}}
+}
+
+func (r *staticRows) Next() bool {
+	if r.closed || r.idx >= len(r.values) {
+		r.Close()
+		return false
+	}
+	r.idx++
+	return true
+}
+
+func (r *staticRows) Scan(dest ...any) error {
+	return pgx.ScanRow(r.typeMap, r.FieldDescriptions(), r.RawValues(), dest...)
+}
+
+func (r *staticRows) Values() ([]any, error) {
+	return []any{r.values[r.idx-1]}, nil
+}
+
+func (r *staticRows) RawValues() [][]byte {
+	return [][]byte{[]byte(r.values[r.idx-1])}
+}
+
+func (r *staticRows) Conn() *pgx.Conn {
+	return nil
+}
+
+func TestTraceBatchQueryInterceptor(t *testing.T) {
+	t.Parallel()
+
+	tracer := &interceptingTracer{}
+
+	ctr := defaultConnTestRunner
+	ctr.CreateConfig = func(ctx context.Context, t testing.TB) *pgx.ConnConfig {
+		config := defaultConnTestRunner.CreateConfig(ctx, t)
+		config.Tracer = tracer
+		return config
+	}
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, ctr, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		var interceptedSQL []string
+		tracer.interceptQuery = func(ctx context.Context, sql string, args []any) (pgx.Rows, bool) {
+			interceptedSQL = append(interceptedSQL, sql)
+			if sql == "select 2" {
+				return &staticRows{values: []string{"42"}, typeMap: conn.TypeMap()}, true
+			}
+			if sql == "select 4" {
+				return &staticRows{values: []string{"not a number"}, typeMap: conn.TypeMap()}, true
+			}
+			return nil, false
+		}
+
+		traceBatchQueryCalledCount := 0
+		tracer.traceBatchQuery = func(ctx context.Context, conn *pgx.Conn, data pgx.TraceBatchQueryData) {
+			traceBatchQueryCalledCount++
+			require.NoError(t, data.Err)
+		}
+
+		batch := &pgx.Batch{}
+		batch.Queue(`select 1`)
+		batch.Queue(`select 2`)
+		batch.Queue(`select 3`)
+		batch.Queue(`select 4`)
+
+		br := conn.SendBatch(context.Background(), batch)
+
+		var n int32
+		err := br.QueryRow().Scan(&n)
+		require.NoError(t, err)
+		require.EqualValues(t, 1, n)
+
+		err = br.QueryRow().Scan(&n)
+		require.NoError(t, err)
+		require.EqualValues(t, 42, n)
+
+		// The server's result for the intercepted query was consumed so the next result lines up.
+		err = br.QueryRow().Scan(&n)
+		require.NoError(t, err)
+		require.EqualValues(t, 3, n)
+
+		// Scan errors of intercepted rows are returned.
+		err = br.QueryRow().Scan(&n)
+		require.Error(t, err)
+
+		err = br.Close()
+		require.NoError(t, err)
+
+		require.Equal(t, []string{"select 1", "select 2", "select 3", "select 4"}, interceptedSQL)
+		require.EqualValues(t, 4, traceBatchQueryCalledCount)
+	})
+}
+
 func TestTraceCopyFrom(t *testing.T) {
 	t.Parallel()
 
@@ -536,3 +782,58 @@ func TestTraceConnect(t *testing.T) {
 	require.True(t, traceConnectStartCalled)
 	require.True(t, traceConnectEndCalled)
 }
+
+func TestTraceBatchOptionsTracer(t *testing.T) {
+	t.Parallel()
+
+	connTracer := &testTracer{}
+
+	ctr := defaultConnTestRunner
+	ctr.CreateConfig = func(ctx context.Context, t testing.TB) *pgx.ConnConfig {
+		config := defaultConnTestRunner.CreateConfig(ctx, t)
+		config.Tracer = connTracer
+		return config
+	}
+
+	pgxtest.RunWithQueryExecModes(context.Background(), t, ctr, nil, func(ctx context.Context, t testing.TB, conn *pgx.Conn) {
+		connTracerCalled := false
+		connTracer.traceBatchStart = func(ctx context.Context, conn *pgx.Conn, data pgx.TraceBatchStartData) context.Context {
+			connTracerCalled = true
+			return ctx
+		}
+
+		batchTracerQueryCount := 0
+		batchTracerEndCalled := false
+		batchTracer := &testTracer{
+			traceBatchQuery: func(ctx context.Context, conn *pgx.Conn, data pgx.TraceBatchQueryData) {
+				batchTracerQueryCount++
+			},
+			traceBatchEnd: func(ctx context.Context, conn *pgx.Conn, data pgx.TraceBatchEndData) {
+				batchTracerEndCalled = true
+			},
+		}
+
+		batch := &pgx.Batch{}
+		batch.Options.Tracer = batchTracer
+		batch.Queue(`select 1`)
+		batch.Queue(`select 2`)
+		err := conn.SendBatch(context.Background(), batch).Close()
+		require.NoError(t, err)
+		require.False(t, connTracerCalled)
+		require.Equal(t, 2, batchTracerQueryCount)
+		require.True(t, batchTracerEndCalled)
+
+		batch = &pgx.Batch{}
+		batch.Options.Tracer = pgx.NoopBatchTracer{}
+		batch.Queue(`select 1`)
+		err = conn.SendBatch(context.Background(), batch).Close()
+		require.NoError(t, err)
+		require.False(t, connTracerCalled)
+
+		batch = &pgx.Batch{}
+		batch.Queue(`select 1`)
+		err = conn.SendBatch(context.Background(), batch).Close()
+		require.NoError(t, err)
+		require.True(t, connTracerCalled)
+	})
+}
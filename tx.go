@@ -237,7 +237,7 @@ func (tx *dbTx) Query(ctx context.Context, sql string, args ...any) (Rows, error
 // QueryRow delegates to the underlying *Conn
 func (tx *dbTx) QueryRow(ctx context.Context, sql string, args ...any) Row {
 	rows, _ := tx.Query(ctx, sql, args...)
-	return (*connRow)(rows.(*baseRows))
+	return rowsToRow(rows)
 }
 
 // CopyFrom delegates to the underlying *Conn
@@ -339,7 +339,7 @@ func (sp *dbSimulatedNestedTx) Query(ctx context.Context, sql string, args ...an
 // QueryRow delegates to the underlying Tx
 func (sp *dbSimulatedNestedTx) QueryRow(ctx context.Context, sql string, args ...any) Row {
 	rows, _ := sp.Query(ctx, sql, args...)
-	return (*connRow)(rows.(*baseRows))
+	return rowsToRow(rows)
 }
 
 // CopyFrom delegates to the underlying *Conn
@@ -15,6 +15,13 @@ const (
 )
 
 func convertSimpleArgument(m *pgtype.Map, arg any) (any, error) {
+	if fa, ok := arg.(FormattedArg); ok {
+		if fa.Format != TextFormatCode {
+			return nil, errFormattedArgRequiresText
+		}
+		arg = fa.Arg
+	}
+
 	if anynil.Is(arg) {
 		return nil, nil
 	}